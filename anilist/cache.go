@@ -1,11 +1,12 @@
 package anilist
 
 import (
+	"fmt"
 	"github.com/metafates/gache"
+	"github.com/metafates/mangal/cache"
 	"github.com/metafates/mangal/filesystem"
 	"github.com/metafates/mangal/where"
 	"github.com/samber/mo"
-	"path/filepath"
 	"time"
 )
 
@@ -72,35 +73,35 @@ var relationCacher = &cacher[string, int]{
 	keyWrapper: normalizedName,
 }
 
-var searchCacher = &cacher[string, []int]{
-	internal: gache.New[*cacheData[string, []int]](
-		&gache.Options{
-			Path:       filepath.Join(where.Cache(), "anilist_search_cache.json"),
-			Lifetime:   time.Hour * 24 * 10,
-			FileSystem: &filesystem.GacheFs{},
-		},
-	),
+var searchCacher = &cached[string, []int]{
+	internal:   cache.New[[]int]("anilist", cache.Search),
 	keyWrapper: normalizedName,
 }
 
-var idCacher = &cacher[int, *Manga]{
-	internal: gache.New[*cacheData[int, *Manga]](
-		&gache.Options{
-			Path:       filepath.Join(where.Cache(), "anilist_id_cache.json"),
-			Lifetime:   time.Hour * 24 * 2,
-			FileSystem: &filesystem.GacheFs{},
-		},
-	),
+var idCacher = &cached[int, *Manga]{
+	internal:   cache.New[*Manga]("anilist", cache.Metadata),
 	keyWrapper: func(id int) int { return id },
 }
 
-var failCacher = &cacher[string, bool]{
-	internal: gache.New[*cacheData[string, bool]](
-		&gache.Options{
-			Path:       filepath.Join(where.Cache(), "anilist_fail_cache.json"),
-			Lifetime:   time.Minute,
-			FileSystem: &filesystem.GacheFs{},
-		},
-	),
+var failCacher = &cached[string, bool]{
+	internal:   cache.New[bool]("anilist", "failures").WithLifetime(time.Minute),
 	keyWrapper: normalizedName,
 }
+
+// cached wraps the unified cache, keys are normalized the same way as for the binds
+type cached[K comparable, T any] struct {
+	internal   *cache.Cache[T]
+	keyWrapper func(K) K
+}
+
+func (c *cached[K, T]) Get(key K) mo.Option[T] {
+	return c.internal.Get(fmt.Sprint(c.keyWrapper(key)))
+}
+
+func (c *cached[K, T]) Set(key K, t T) error {
+	return c.internal.Set(fmt.Sprint(c.keyWrapper(key)), t)
+}
+
+func (c *cached[K, T]) Delete(key K) error {
+	return c.internal.Delete(fmt.Sprint(c.keyWrapper(key)))
+}
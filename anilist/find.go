@@ -34,9 +34,26 @@ func SetRelation(name string, to *Manga) error {
 	return nil
 }
 
+// related returns the manga that the name was related with by SetRelation.
+// Relations don't expire, so the manga is fetched by its id again once the cached one has expired
+func related(name string) (manga *Manga, ok bool, err error) {
+	id, ok := relationCacher.Get(name).Get()
+	if !ok || id == -1 {
+		return nil, false, nil
+	}
+
+	manga, err = GetByID(id)
+	return manga, true, err
+}
+
 // FindClosest returns the closest manga to the given name.
 // It will levenshtein compare the given name with all the manga names in the cache.
 func FindClosest(name string) (*Manga, error) {
+	// relation set by the user takes precedence and must not be replaced by the closest match
+	if manga, ok, err := related(name); ok {
+		return manga, err
+	}
+
 	name = normalizedName(name)
 	
 	// Try exact match first
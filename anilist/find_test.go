@@ -1,8 +1,14 @@
 package anilist
 
 import (
-	. "github.com/smartystreets/goconvey/convey"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
+
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/network"
+	. "github.com/smartystreets/goconvey/convey"
 )
 
 func TestFindClosest(t *testing.T) {
@@ -20,3 +26,44 @@ func TestFindClosest(t *testing.T) {
 		})
 	})
 }
+
+// roundTripFunc stubs the Anilist API
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestFindClosestRelation(t *testing.T) {
+	Convey("Given a manga related with the Anilist manga by the user", t, func() {
+		filesystem.SetMemMapFs()
+
+		transport := network.Client.Transport
+		defer func() { network.Client.Transport = transport }()
+
+		var requests int
+		network.Client.Transport = roundTripFunc(func(*http.Request) (*http.Response, error) {
+			requests++
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"data": {"media": {"id": 42, "title": {"english": "Bound"}}}}`)),
+				Header:     make(http.Header),
+			}, nil
+		})
+
+		So(SetRelation("Some Manga", &Manga{ID: 42}), ShouldBeNil)
+
+		Convey("When the cached Anilist manga has expired", func() {
+			So(idCacher.Delete(42), ShouldBeNil)
+
+			manga, err := FindClosest("Some Manga")
+
+			Convey("Then it should be fetched again by the id of the relation", func() {
+				So(err, ShouldBeNil)
+				So(manga.ID, ShouldEqual, 42)
+				So(manga.Title.English, ShouldEqual, "Bound")
+				So(requests, ShouldEqual, 1)
+			})
+		})
+	})
+}
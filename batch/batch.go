@@ -0,0 +1,344 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/metafates/mangal/downloader"
+	"github.com/metafates/mangal/event"
+	"github.com/metafates/mangal/fallback"
+	"github.com/metafates/mangal/inline"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/provider"
+	"github.com/metafates/mangal/search"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	"github.com/spf13/viper"
+)
+
+// Options of the batch run
+type Options struct {
+	// Concurrency overrides the concurrency of the manifest if positive
+	Concurrency int
+	// ContinueOnError keeps downloading other chapters and entries when something fails.
+	// The run is stopped on the first failure otherwise, downloader.stop_on_error is not used
+	ContinueOnError bool
+	// Handler receives progress events of all entries
+	Handler event.Handler
+}
+
+// runner runs the entries of the manifest
+type runner struct {
+	options *Options
+	sources *sharedSources
+	// download is replaced in tests
+	download func(ctx context.Context, chapter *source.Chapter, handler event.Handler) (string, error)
+}
+
+// Run downloads the entries of the manifest and returns the report of the run.
+// Entries share the sources and their caches
+func Run(manifest *Manifest, options *Options) *Report {
+	r := &runner{
+		options:  options,
+		sources:  newSharedSources(),
+		download: downloader.DownloadContext,
+	}
+
+	return r.run(manifest)
+}
+
+func (r *runner) run(manifest *Manifest) *Report {
+	concurrency := r.options.Concurrency
+	if concurrency <= 0 {
+		concurrency = manifest.Concurrency
+	}
+
+	if concurrency <= 0 {
+		concurrency = viper.GetInt(key.DownloaderWorkers)
+	}
+
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	report := &Report{Entries: make([]*EntryReport, len(manifest.Entries))}
+	entries := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for index := range entries {
+				entryReport := r.runEntry(ctx, manifest.Entries[index])
+				report.Entries[index] = entryReport
+
+				if entryReport.Status == StatusFailed && !r.options.ContinueOnError {
+					stop()
+				}
+			}
+		}()
+	}
+
+	for i := range manifest.Entries {
+		entries <- i
+	}
+
+	close(entries)
+	wg.Wait()
+
+	return report
+}
+
+// runEntry finds the manga of the entry and downloads the selected chapters
+func (r *runner) runEntry(ctx context.Context, entry *Entry) *EntryReport {
+	report := &EntryReport{
+		Entry:      entry.String(),
+		Downloaded: make([]string, 0),
+		Skipped:    make([]string, 0),
+		Failed:     make([]*ChapterFailure, 0),
+	}
+
+	if ctx.Err() != nil {
+		report.Status = StatusSkipped
+		report.Error = "stopped after a failure of another entry"
+		return report
+	}
+
+	manga, chapters, err := r.chapters(entry)
+	if err != nil {
+		log.Error(err)
+		report.Status = StatusFailed
+		report.Error = err.Error()
+		return report
+	}
+
+	report.Manga = manga.Name
+	report.Source = manga.Source.Name()
+
+	var stopped bool
+	for _, chapter := range chapters {
+		if ctx.Err() != nil {
+			stopped = true
+			break
+		}
+
+		var skipped bool
+		path, err := r.download(ctx, chapter, func(e *event.Event) {
+			if e.Kind == event.Skipped {
+				skipped = true
+			}
+
+			r.options.Handler.Emit(e)
+		})
+
+		switch {
+		case err != nil && errors.Is(err, context.Canceled):
+			stopped = true
+		case err != nil:
+			report.Failed = append(report.Failed, &ChapterFailure{Chapter: chapter.Name, Error: err.Error()})
+		case skipped:
+			report.Skipped = append(report.Skipped, path)
+		default:
+			report.Downloaded = append(report.Downloaded, path)
+		}
+
+		if stopped || err != nil && !r.options.ContinueOnError {
+			break
+		}
+	}
+
+	switch {
+	case len(report.Failed) > 0:
+		report.Status = StatusFailed
+	case stopped:
+		report.Status = StatusSkipped
+		report.Error = "stopped after a failure of another entry"
+	default:
+		report.Status = StatusDone
+	}
+
+	return report
+}
+
+// chapters finds the manga of the entry and selects its chapters
+func (r *runner) chapters(entry *Entry) (*source.Manga, []*source.Chapter, error) {
+	found, err := r.manga(entry)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// search results are cached by the shared source, entries must not change the manga of each other
+	copied := *found
+	manga := &copied
+
+	if entry.Format != "" {
+		manga.DownloadFormat = entry.Format
+	}
+
+	if entry.Path != "" {
+		if manga.DownloadPath, err = filepath.Abs(expandPath(entry.Path)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	chapters, err := manga.Source.ChaptersOf(manga)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get chapters of %s: %w", manga.Name, err)
+	}
+
+	// chapters are cached by the shared source and may belong to the manga of another entry
+	chapters = lo.Map(chapters, func(chapter *source.Chapter, _ int) *source.Chapter {
+		return chapter.WithManga(manga)
+	})
+	manga.Chapters = chapters
+
+	if fallback.Enabled() {
+		chapters = fallback.Complete(manga, chapters)
+	}
+
+	selector := entry.Chapters
+	if selector == "" {
+		selector = "all"
+	}
+
+	filter, err := inline.ParseChaptersFilter(selector)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	chapters, err = filter(chapters)
+	return manga, chapters, err
+}
+
+// expandPath resolves the home directory and environment variables the same way as downloader.path
+func expandPath(path string) string {
+	if home, err := os.UserHomeDir(); err == nil {
+		if path == "~" {
+			path = home
+		} else if strings.HasPrefix(path, fmt.Sprintf("%c%c", '~', os.PathSeparator)) {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+
+	return os.ExpandEnv(path)
+}
+
+// manga gets the manga of the entry by URL or searches it by query
+func (r *runner) manga(entry *Entry) (*source.Manga, error) {
+	if entry.URL != "" {
+		return r.mangaByURL(entry)
+	}
+
+	names := viper.GetStringSlice(key.DownloaderDefaultSources)
+	if entry.Source != "" {
+		names = []string{entry.Source}
+	}
+
+	if len(names) == 0 {
+		return nil, errors.New("source not set")
+	}
+
+	sources := make([]source.Source, len(names))
+	for i, name := range names {
+		p, ok := provider.Get(name)
+		if !ok {
+			return nil, fmt.Errorf("source not found: %s", name)
+		}
+
+		src, err := r.sources.get(p)
+		if err != nil {
+			return nil, err
+		}
+
+		sources[i] = src
+	}
+
+	mangas, err := search.Mangas(search.Sources(sources, entry.Query, search.Timeout()))
+	if err != nil {
+		return nil, err
+	}
+
+	picker := entry.Manga
+	if picker == "" {
+		picker = "first"
+	}
+
+	pick, err := inline.ParseMangaPicker(entry.Query, picker)
+	if err != nil {
+		return nil, err
+	}
+
+	manga := pick(mangas)
+	if manga == nil {
+		return nil, fmt.Errorf("manga %q not found", entry.Query)
+	}
+
+	return manga, nil
+}
+
+// mangaByURL gets the manga without searching
+func (r *runner) mangaByURL(entry *Entry) (*source.Manga, error) {
+	var (
+		p   *provider.Provider
+		err error
+	)
+
+	if entry.Source != "" {
+		var ok bool
+		if p, ok = provider.Get(entry.Source); !ok {
+			return nil, fmt.Errorf("source not found: %s", entry.Source)
+		}
+	} else if p, err = provider.ForURL(entry.URL); err != nil {
+		return nil, err
+	}
+
+	src, err := r.sources.get(p)
+	if err != nil {
+		return nil, err
+	}
+
+	getter, ok := src.(source.MangaGetter)
+	if !ok {
+		return nil, fmt.Errorf("source %s can't get manga without searching", p.Name)
+	}
+
+	return getter.GetManga(entry.URL)
+}
+
+// sharedSources creates each source once and shares it between the entries
+type sharedSources struct {
+	mu      sync.Mutex
+	created map[string]source.Source
+}
+
+func newSharedSources() *sharedSources {
+	return &sharedSources{created: make(map[string]source.Source)}
+}
+
+func (s *sharedSources) get(p *provider.Provider) (source.Source, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if src, ok := s.created[p.ID]; ok {
+		return src, nil
+	}
+
+	src, err := p.CreateSource()
+	if err != nil {
+		return nil, err
+	}
+
+	s.created[p.ID] = src
+	return src, nil
+}
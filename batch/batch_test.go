@@ -0,0 +1,241 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/metafates/mangal/event"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/where"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+)
+
+const definitionYAML = `
+base_url: %[1]s
+search:
+  url: %[1]s/search?q={query}
+manga:
+  selector: .manga
+  name:
+    selector: a
+  url:
+    selector: a
+    attribute: href
+chapters:
+  selector: .chapter
+  name:
+    selector: a
+  url:
+    selector: a
+    attribute: href
+manga_name:
+  selector: h1
+pages:
+  selector: img
+  url:
+    attribute: src
+`
+
+func newTestServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<html><body><div class="manga"><a href="/manga/one">One</a></div></body></html>`)
+	})
+	mux.HandleFunc("/manga/one", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<html><body><h1>One</h1>
+<div class="chapter"><a href="/chapter/1">Chapter 1</a></div>
+<div class="chapter"><a href="/chapter/2">Chapter 2</a></div>
+</body></html>`)
+	})
+	mux.HandleFunc("/chapter/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `<html><body><img src="%[1]s/1.png"><img src="%[1]s/2.png"></body></html>`, r.URL.Path)
+	})
+
+	return httptest.NewServer(mux)
+}
+
+// testDownload fails Chapter 2 and skips the chapters that were downloaded before
+func testDownload() func(context.Context, *source.Chapter, event.Handler) (string, error) {
+	var (
+		mu         sync.Mutex
+		downloaded = make(map[string]bool)
+	)
+
+	return func(_ context.Context, chapter *source.Chapter, handler event.Handler) (string, error) {
+		if chapter.Name == "Chapter 2" {
+			return "", errors.New("no pages")
+		}
+
+		path := filepath.Join(chapter.Manga.DownloadPath, chapter.Name+"."+chapter.Manga.Format())
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if downloaded[path] {
+			handler.Emit(&event.Event{Kind: event.Skipped})
+		}
+
+		downloaded[path] = true
+		return path, nil
+	}
+}
+
+func TestParse(t *testing.T) {
+	Convey("Given a yaml manifest", t, func() {
+		data := []byte(`
+concurrency: 2
+entries:
+  - query: one
+    source: test
+    chapters: c1
+    format: zip
+    path: /manga
+  - url: https://example.com/manga/one
+`)
+
+		Convey("When parsing it", func() {
+			manifest, err := Parse(data, ".yaml")
+
+			Convey("Then entries should be parsed", func() {
+				So(err, ShouldBeNil)
+				So(manifest.Concurrency, ShouldEqual, 2)
+				So(manifest.Entries, ShouldHaveLength, 2)
+				So(manifest.Entries[0].Format, ShouldEqual, "zip")
+				So(manifest.Entries[1].String(), ShouldEqual, "https://example.com/manga/one")
+			})
+		})
+	})
+
+	Convey("Given a manifest with unknown field", t, func() {
+		_, err := Parse([]byte(`{"entries": [{"qeury": "one"}]}`), ".json")
+
+		Convey("Then error should mention the field", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "qeury")
+		})
+	})
+
+	Convey("Given an invalid manifest", t, func() {
+		manifest := &Manifest{Entries: []*Entry{
+			{},
+			{Query: "one", URL: "https://example.com"},
+			{Query: "one", Source: "unknown", Chapters: "chapter 1", Format: "docx"},
+		}}
+
+		Convey("When validating it", func() {
+			err := manifest.Validate()
+
+			Convey("Then all errors should be reported with paths", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "entries[0]: query or url is required")
+				So(err.Error(), ShouldContainSubstring, "entries[1]: query and url can't be used together")
+				So(err.Error(), ShouldContainSubstring, "entries[2].source")
+				So(err.Error(), ShouldContainSubstring, "entries[2].chapters")
+				So(err.Error(), ShouldContainSubstring, "entries[2].format")
+			})
+		})
+	})
+}
+
+func TestRun(t *testing.T) {
+	defer viper.Reset()
+
+	Convey("Given a manifest of the local source", t, func() {
+		filesystem.SetMemMapFs()
+		viper.Set(key.FormatsUse, "pdf")
+
+		server := newTestServer()
+		defer server.Close()
+
+		definition := filepath.Join(where.Sources(), "test.yaml")
+		So(filesystem.Api().WriteFile(definition, []byte(fmt.Sprintf(definitionYAML, server.URL)), 0o644), ShouldBeNil)
+
+		manifest := &Manifest{Entries: []*Entry{
+			{Query: "one", Source: "test", Chapters: "c1", Format: "zip", Path: "/manga"},
+			{Name: "by url", URL: server.URL + "/manga/one", Path: "/manga"},
+			{Query: "one", Source: "test", Chapters: "c1", Format: "zip", Path: "/manga"},
+		}}
+		So(manifest.Validate(), ShouldBeNil)
+
+		run := func(options *Options) *Report {
+			r := &runner{options: options, sources: newSharedSources(), download: testDownload()}
+			return r.run(manifest)
+		}
+
+		Convey("When it is run with continue on error", func() {
+			report := run(&Options{Concurrency: 1, ContinueOnError: true})
+
+			Convey("Then all entries should be reported", func() {
+				So(report.Entries[0].Status, ShouldEqual, StatusDone)
+				So(report.Entries[0].Manga, ShouldEqual, "One")
+				So(report.Entries[0].Downloaded, ShouldResemble, []string{"/manga/Chapter 1.zip"})
+
+				So(report.Entries[1].Status, ShouldEqual, StatusFailed)
+				So(report.Entries[1].Entry, ShouldEqual, "by url")
+				So(report.Entries[1].Downloaded, ShouldResemble, []string{"/manga/Chapter 1.pdf"})
+				So(report.Entries[1].Failed, ShouldHaveLength, 1)
+				So(report.Entries[1].Failed[0].Chapter, ShouldEqual, "Chapter 2")
+
+				So(report.Entries[2].Status, ShouldEqual, StatusDone)
+				So(report.Entries[2].Skipped, ShouldResemble, []string{"/manga/Chapter 1.zip"})
+
+				So(report.Failed(), ShouldBeTrue)
+				So(report.Summary(), ShouldContainSubstring, "2 done, 1 failed, 0 skipped")
+			})
+		})
+
+		Convey("When it is run without continue on error", func() {
+			report := run(&Options{Concurrency: 1})
+
+			Convey("Then entries after the failure should be skipped", func() {
+				So(report.Entries[0].Status, ShouldEqual, StatusDone)
+				So(report.Entries[1].Status, ShouldEqual, StatusFailed)
+				So(report.Entries[2].Status, ShouldEqual, StatusSkipped)
+			})
+		})
+
+		Convey("When entries of the same source are run concurrently", func() {
+			manifest := &Manifest{Entries: []*Entry{
+				{Query: "one", Source: "test", Format: "zip", Path: "/zip"},
+				{Query: "one", Source: "test", Format: "cbz", Path: "/cbz"},
+			}}
+
+			// pages are got from the shared source, so the entries request them at the same time
+			download := func(_ context.Context, chapter *source.Chapter, _ event.Handler) (string, error) {
+				pages, err := chapter.PagesSource().PagesOf(chapter)
+				if err != nil {
+					return "", err
+				}
+
+				for _, page := range pages {
+					if page.Chapter != chapter || !strings.HasPrefix(page.URL, "/chapter/") {
+						return "", fmt.Errorf("page %s doesn't belong to %s", page.URL, chapter.Name)
+					}
+				}
+
+				return filepath.Join(chapter.Manga.DownloadPath, fmt.Sprintf("%s.%s", chapter.Name, chapter.Manga.Format())), nil
+			}
+
+			r := &runner{options: &Options{Concurrency: 2}, sources: newSharedSources(), download: download}
+			report := r.run(manifest)
+
+			Convey("Then each entry should download its chapters with its own options", func() {
+				So(report.Entries[0].Status, ShouldEqual, StatusDone)
+				So(report.Entries[0].Downloaded, ShouldResemble, []string{"/zip/Chapter 1.zip", "/zip/Chapter 2.zip"})
+
+				So(report.Entries[1].Status, ShouldEqual, StatusDone)
+				So(report.Entries[1].Downloaded, ShouldResemble, []string{"/cbz/Chapter 1.cbz", "/cbz/Chapter 2.cbz"})
+			})
+		})
+	})
+}
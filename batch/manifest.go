@@ -0,0 +1,159 @@
+// Package batch downloads the list of manga described by a manifest file
+package batch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/metafates/mangal/converter"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/inline"
+	"github.com/metafates/mangal/provider"
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest is the list of the entries to download
+type Manifest struct {
+	// Concurrency is the number of entries downloaded at the same time
+	Concurrency int `yaml:"concurrency" json:"concurrency"`
+	// Entries to download
+	Entries []*Entry `yaml:"entries" json:"entries"`
+}
+
+// Entry is a single manga to download
+type Entry struct {
+	// Name of the entry in the report. Query or URL is used if empty
+	Name string `yaml:"name" json:"name"`
+	// Query to search the manga by
+	Query string `yaml:"query" json:"query"`
+	// URL of the manga page, the manga is got without searching
+	URL string `yaml:"url" json:"url"`
+	// Source to use, default sources are used for queries and the source is found by the host for URLs
+	Source string `yaml:"source" json:"source"`
+	// Manga selector of the search results: first, last, exact or index. "first" by default
+	Manga string `yaml:"manga" json:"manga"`
+	// Chapters selector, see inline.SelectorSyntax. "all" by default
+	Chapters string `yaml:"chapters" json:"chapters"`
+	// Format of the downloaded chapters, formats.use by default
+	Format string `yaml:"format" json:"format"`
+	// Path to download the manga to, downloader.path by default
+	Path string `yaml:"path" json:"path"`
+}
+
+// String returns the name of the entry
+func (e *Entry) String() string {
+	switch {
+	case e.Name != "":
+		return e.Name
+	case e.URL != "":
+		return e.URL
+	default:
+		return e.Query
+	}
+}
+
+// Parse parses the manifest, ext is the extension of the file it was read from.
+// Unknown fields are treated as errors, so typos are not ignored silently
+func Parse(data []byte, ext string) (*Manifest, error) {
+	var manifest Manifest
+
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&manifest); err != nil {
+			return nil, err
+		}
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&manifest); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported manifest format %q, expected .yaml, .yml or .json", ext)
+	}
+
+	return &manifest, nil
+}
+
+// Load reads, parses and validates the manifest file
+func Load(path string) (*Manifest, error) {
+	data, err := filesystem.Api().ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := Parse(data, filepath.Ext(path))
+	if err == nil {
+		err = manifest.Validate()
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+
+	return manifest, nil
+}
+
+// Validate checks all entries before anything is downloaded.
+// All found errors are returned at once
+func (m *Manifest) Validate() error {
+	var errs []error
+	add := func(path, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", path, fmt.Sprintf(format, args...)))
+	}
+
+	if m.Concurrency < 0 {
+		add("concurrency", "must not be negative")
+	}
+
+	if len(m.Entries) == 0 {
+		add("entries", "at least one entry is required")
+	}
+
+	for i, entry := range m.Entries {
+		path := fmt.Sprintf("entries[%d]", i)
+
+		switch {
+		case entry.Query == "" && entry.URL == "":
+			add(path, "query or url is required")
+		case entry.Query != "" && entry.URL != "":
+			add(path, "query and url can't be used together")
+		}
+
+		if entry.URL != "" && entry.Manga != "" {
+			add(path+".manga", "can't be used with url")
+		}
+
+		if entry.Source != "" {
+			if _, ok := provider.Get(entry.Source); !ok {
+				add(path+".source", "unknown source %q", entry.Source)
+			}
+		}
+
+		if entry.Manga != "" {
+			if _, err := inline.ParseMangaPicker(entry.Query, entry.Manga); err != nil {
+				add(path+".manga", "%s", err)
+			}
+		}
+
+		if entry.Chapters != "" {
+			if _, err := inline.ParseChaptersFilter(entry.Chapters); err != nil {
+				add(path+".chapters", "%s", err)
+			}
+		}
+
+		if entry.Format != "" {
+			if _, err := converter.Get(entry.Format); err != nil {
+				add(path+".format", "%s", err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
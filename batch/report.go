@@ -0,0 +1,106 @@
+package batch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/metafates/mangal/util"
+)
+
+// Status of the entry after the run
+type Status string
+
+const (
+	// StatusDone means that all selected chapters are downloaded or were downloaded before
+	StatusDone Status = "done"
+	// StatusFailed means that the manga was not found or some of its chapters failed
+	StatusFailed Status = "failed"
+	// StatusSkipped means that the entry was not finished because the run was stopped after a failure
+	StatusSkipped Status = "skipped"
+)
+
+// Report of the batch run
+type Report struct {
+	// Entries in the manifest order
+	Entries []*EntryReport `json:"entries"`
+}
+
+// EntryReport is the result of a single entry
+type EntryReport struct {
+	// Entry is the name of the entry
+	Entry string `json:"entry"`
+	// Status of the entry
+	Status Status `json:"status"`
+	// Manga is the name of the found manga
+	Manga string `json:"manga,omitempty"`
+	// Source that the manga was found in
+	Source string `json:"source,omitempty"`
+	// Downloaded are the paths of the downloaded chapters
+	Downloaded []string `json:"downloaded"`
+	// Skipped are the paths of the chapters that were already downloaded
+	Skipped []string `json:"skipped"`
+	// Failed are the chapters that failed to download
+	Failed []*ChapterFailure `json:"failed"`
+	// Error of the entry itself, e.g. when the manga was not found
+	Error string `json:"error,omitempty"`
+}
+
+// ChapterFailure is the chapter that failed to download
+type ChapterFailure struct {
+	// Chapter is the name of the chapter
+	Chapter string `json:"chapter"`
+	// Error message
+	Error string `json:"error"`
+}
+
+// Count returns the number of entries with the status
+func (r *Report) Count(status Status) (count int) {
+	for _, entry := range r.Entries {
+		if entry.Status == status {
+			count++
+		}
+	}
+
+	return
+}
+
+// Failed checks if any of the entries has failed
+func (r *Report) Failed() bool {
+	return r.Count(StatusFailed) > 0
+}
+
+// Summary returns the human-readable summary of the run
+func (r *Report) Summary() string {
+	var sb strings.Builder
+
+	for _, entry := range r.Entries {
+		sb.WriteString(fmt.Sprintf("%s %s", entry.Status, entry.Entry))
+		if entry.Manga != "" && entry.Manga != entry.Entry {
+			sb.WriteString(fmt.Sprintf(" (%s, %s)", entry.Manga, entry.Source))
+		}
+
+		sb.WriteString(fmt.Sprintf(
+			": %s downloaded, %d already downloaded, %d failed\n",
+			util.Quantify(len(entry.Downloaded), "chapter", "chapters"),
+			len(entry.Skipped),
+			len(entry.Failed),
+		))
+
+		for _, failure := range entry.Failed {
+			sb.WriteString(fmt.Sprintf("  %s: %s\n", failure.Chapter, failure.Error))
+		}
+
+		if entry.Error != "" {
+			sb.WriteString(fmt.Sprintf("  %s\n", entry.Error))
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf(
+		"%d done, %d failed, %d skipped\n",
+		r.Count(StatusDone),
+		r.Count(StatusFailed),
+		r.Count(StatusSkipped),
+	))
+
+	return sb.String()
+}
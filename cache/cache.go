@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/samber/mo"
+	"github.com/spf13/viper"
+)
+
+// Kind of the cached data. Each kind has its own lifetime
+type Kind string
+
+const (
+	Search   Kind = "search"
+	Chapters Kind = "chapters"
+	Pages    Kind = "pages"
+	Metadata Kind = "metadata"
+)
+
+// Cache stores values of the namespace, each value in its own file.
+// Namespace is the source and the kind of the data, e.g. mangadex/chapters
+type Cache[T any] struct {
+	namespace Namespace
+	// lifetime is used when it is not configured for the namespace
+	lifetime time.Duration
+}
+
+// New creates a cache for the source and the kind of the data
+func New[T any](source string, kind Kind) *Cache[T] {
+	return &Cache[T]{
+		namespace: Namespace{Source: normalize(source), Kind: kind},
+	}
+}
+
+// WithLifetime sets the lifetime of the values, unless it is configured for the namespace
+func (c *Cache[T]) WithLifetime(lifetime time.Duration) *Cache[T] {
+	c.lifetime = lifetime
+	return c
+}
+
+// Namespace of the cache
+func (c *Cache[T]) Namespace() Namespace {
+	return c.namespace
+}
+
+// Get returns the value if it is cached and not expired.
+// Nothing is returned if cache is disabled
+func (c *Cache[T]) Get(key string) mo.Option[T] {
+	if !Enabled() {
+		return mo.None[T]()
+	}
+
+	path := c.namespace.path(key)
+	e, err := readEntry(path)
+	if err != nil || e.Key != key {
+		return mo.None[T]()
+	}
+
+	if time.Since(e.Created) > c.namespace.lifetime(c.lifetime) {
+		_ = filesystem.Api().Remove(path)
+		return mo.None[T]()
+	}
+
+	var value T
+	if err = json.Unmarshal(e.Value, &value); err != nil {
+		return mo.None[T]()
+	}
+
+	// modification time is the last access time, used to evict least recently used entries
+	now := time.Now()
+	_ = filesystem.Api().Chtimes(path, now, now)
+
+	return mo.Some(value)
+}
+
+// Set caches the value.
+// Values are cached even if cache is disabled for the run, so that the fresh ones are used later
+func (c *Cache[T]) Set(key string, value T) error {
+	if c.namespace.lifetime(c.lifetime) <= 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	if err = writeEntry(c.namespace.path(key), &entry{
+		Key:      key,
+		Created:  time.Now(),
+		Lifetime: c.lifetime,
+		Value:    data,
+	}); err != nil {
+		return err
+	}
+
+	pruneAfterWrite()
+
+	return nil
+}
+
+// Delete removes the value
+func (c *Cache[T]) Delete(key string) error {
+	err := filesystem.Api().Remove(c.namespace.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	return err
+}
+
+// disabled is set by Disable for the current run only
+var disabled atomic.Bool
+
+// Disable stops using the cached values for the current run.
+// Unlike cache.enabled, it is never saved to the config
+func Disable() {
+	disabled.Store(true)
+}
+
+// Enabled checks if the cached values are used
+func Enabled() bool {
+	return !disabled.Load() && viper.GetBool(key.CacheEnabled)
+}
@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+)
+
+func TestCache(t *testing.T) {
+	filesystem.SetMemMapFs()
+	defer filesystem.SetOsFs()
+	defer viper.Reset()
+
+	Convey("Given a cache of the source", t, func() {
+		background.Wait()
+		disabled.Store(false)
+		So(Clear(""), ShouldBeNil)
+		viper.Set(key.CacheEnabled, true)
+		viper.Set(key.CacheTTLChapters, "24h")
+		viper.Set(key.CacheTTLOverrides, []string{})
+		viper.Set(key.CacheMaxSize, 0)
+
+		chapters := New[[]string]("MangaDex", Chapters)
+
+		Convey("When a value is set", func() {
+			So(chapters.Set("url", []string{"1", "2"}), ShouldBeNil)
+
+			Convey("Then it should be returned", func() {
+				So(chapters.Get("url").OrEmpty(), ShouldResemble, []string{"1", "2"})
+				So(chapters.Get("other").IsAbsent(), ShouldBeTrue)
+			})
+
+			Convey("Then it should be counted in the namespace", func() {
+				stats, err := Stats("mangadex")
+				So(err, ShouldBeNil)
+				So(stats, ShouldHaveLength, 1)
+				So(stats[0].Namespace, ShouldEqual, "mangadex/chapters")
+				So(stats[0].Entries, ShouldEqual, 1)
+				So(stats[0].Lifetime, ShouldEqual, 24*time.Hour)
+			})
+
+			Convey("And cache is disabled", func() {
+				viper.Set(key.CacheEnabled, false)
+
+				Convey("Then it should not be returned", func() {
+					So(chapters.Get("url").IsAbsent(), ShouldBeTrue)
+				})
+			})
+
+			Convey("And cache is disabled for the run", func() {
+				Disable()
+
+				Convey("Then it should not be returned", func() {
+					So(chapters.Get("url").IsAbsent(), ShouldBeTrue)
+				})
+
+				Convey("And the config should be left as is", func() {
+					So(viper.GetBool(key.CacheEnabled), ShouldBeTrue)
+				})
+			})
+
+			Convey("And the lifetime of the namespace is overridden", func() {
+				viper.Set(key.CacheTTLOverrides, []string{"mangadex=1h", "mangadex/chapters=0s"})
+
+				Convey("Then it should be expired and pruned", func() {
+					So(chapters.Get("url").IsAbsent(), ShouldBeTrue)
+
+					So(chapters.Set("url", []string{"1"}), ShouldBeNil)
+					stats, err := Stats("")
+					So(err, ShouldBeNil)
+					So(stats, ShouldBeEmpty)
+				})
+			})
+
+			Convey("And the namespace is cleared", func() {
+				So(Clear("mangadex/chapters"), ShouldBeNil)
+
+				Convey("Then it should not be returned", func() {
+					So(chapters.Get("url").IsAbsent(), ShouldBeTrue)
+				})
+			})
+		})
+
+		Convey("When a value is set by the cache with its own lifetime", func() {
+			ids := New[[]int]("anilist", Chapters).WithLifetime(72 * time.Hour)
+			So(ids.Set("query", []int{1}), ShouldBeNil)
+
+			// older than the lifetime of the kind, but not of the cache
+			created := time.Now().Add(-48 * time.Hour)
+			e, err := readEntry(ids.namespace.path("query"))
+			So(err, ShouldBeNil)
+			e.Created = created
+			So(writeEntry(ids.namespace.path("query"), e), ShouldBeNil)
+
+			Convey("Then its lifetime should be used by stats and pruning", func() {
+				stats, err := Stats("anilist")
+				So(err, ShouldBeNil)
+				So(stats[0].Lifetime, ShouldEqual, 72*time.Hour)
+				So(stats[0].Expired, ShouldEqual, 0)
+
+				pruned, err := Prune()
+				So(err, ShouldBeNil)
+				So(pruned.Expired, ShouldEqual, 0)
+				So(ids.Get("query").OrEmpty(), ShouldResemble, []int{1})
+			})
+		})
+
+		Convey("When clearing unknown namespace", func() {
+			err := Clear("unknown")
+
+			Convey("Then error should be returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When the cache is written many times after the first write", func() {
+			viper.Set(key.CacheMaxSize, 1)
+			writes.Store(1)
+			pages := New[string]("session", Pages)
+			value := strings.Repeat("x", 100*1024)
+
+			for i := 0; i < pruneInterval; i++ {
+				So(pages.Set(fmt.Sprint(i), value), ShouldBeNil)
+			}
+			background.Wait()
+
+			Convey("Then it should be pruned to the size limit again", func() {
+				stats, err := Stats("session")
+				So(err, ShouldBeNil)
+				So(stats[0].Size, ShouldBeLessThanOrEqualTo, 1024*1024)
+			})
+		})
+
+		Convey("When the cache exceeds the size limit", func() {
+			viper.Set(key.CacheMaxSize, 1)
+			pages := New[string]("example", Pages)
+			large := strings.Repeat("x", 400*1024)
+
+			for i, name := range []string{"first", "second", "third"} {
+				So(pages.Set(name, large), ShouldBeNil)
+
+				accessed := time.Now().Add(time.Duration(i-10) * time.Minute)
+				So(filesystem.Api().Chtimes(pages.namespace.path(name), accessed, accessed), ShouldBeNil)
+			}
+
+			// first is used recently
+			So(pages.Get("first").IsPresent(), ShouldBeTrue)
+
+			pruned, err := Prune()
+
+			Convey("Then least recently used entries should be evicted", func() {
+				So(err, ShouldBeNil)
+				So(pruned.Evicted, ShouldEqual, 1)
+				So(pages.Get("second").IsAbsent(), ShouldBeTrue)
+				So(pages.Get("first").IsPresent(), ShouldBeTrue)
+				So(pages.Get("third").IsPresent(), ShouldBeTrue)
+			})
+		})
+	})
+}
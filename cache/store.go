@@ -0,0 +1,411 @@
+package cache
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/util"
+	"github.com/metafates/mangal/where"
+	"github.com/spf13/viper"
+)
+
+// defaultLifetime is used for the kinds without configured lifetime
+const defaultLifetime = 24 * time.Hour
+
+// pruneInterval is the number of writes between the prunings of the cache.
+// The first write prunes it too, so that long sessions stay within the size limit
+const pruneInterval = 100
+
+var (
+	// writes counts the writes of the process
+	writes atomic.Int64
+	// pruning is held while the cache is pruned after the writes
+	pruning sync.Mutex
+	// background is the pruning started by the writes
+	background sync.WaitGroup
+)
+
+// pruneAfterWrite prunes the cache in the background on the first write and then every pruneInterval writes.
+// Writes do not wait for it, and it is not started again while it runs
+func pruneAfterWrite() {
+	if (writes.Add(1)-1)%pruneInterval != 0 || !pruning.TryLock() {
+		return
+	}
+
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		defer pruning.Unlock()
+
+		_, _ = Prune()
+	}()
+}
+
+// entry is the file of the cached value
+type entry struct {
+	Key     string    `json:"key"`
+	Created time.Time `json:"created"`
+	// Lifetime is the lifetime set by the cache with WithLifetime,
+	// so that it is known when the entry is pruned by another cache or process
+	Lifetime time.Duration   `json:"lifetime,omitempty"`
+	Value    json.RawMessage `json:"value"`
+}
+
+// Namespace is the source and the kind of the cached data
+type Namespace struct {
+	Source string
+	Kind   Kind
+}
+
+func (n Namespace) String() string {
+	return n.Source + "/" + string(n.Kind)
+}
+
+// normalize makes the name of the source usable as a directory and in the config
+func normalize(source string) string {
+	return util.SanitizeFilename(strings.ToLower(strings.TrimSpace(source)))
+}
+
+// Root is the directory of the cache
+func Root() string {
+	return filepath.Join(where.Cache(), "store")
+}
+
+func (n Namespace) dir() string {
+	return filepath.Join(Root(), n.Source, string(n.Kind))
+}
+
+func (n Namespace) path(key string) string {
+	sum := sha1.Sum([]byte(key))
+	return filepath.Join(n.dir(), hex.EncodeToString(sum[:])+".json")
+}
+
+// lifetime of the values of the namespace.
+// Overrides for the namespace go first, then for the source, then the fallback, then the kind lifetime
+func (n Namespace) lifetime(fallback time.Duration) time.Duration {
+	overrides := make(map[string]time.Duration)
+	for _, override := range viper.GetStringSlice(key.CacheTTLOverrides) {
+		namespace, value, ok := strings.Cut(override, "=")
+		if !ok {
+			continue
+		}
+
+		lifetime, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+
+		overrides[strings.ToLower(strings.TrimSpace(namespace))] = lifetime
+	}
+
+	for _, namespace := range []string{n.String(), n.Source} {
+		if lifetime, ok := overrides[namespace]; ok {
+			return lifetime
+		}
+	}
+
+	if fallback > 0 {
+		return fallback
+	}
+
+	keys := map[Kind]string{
+		Search:   key.CacheTTLSearch,
+		Chapters: key.CacheTTLChapters,
+		Pages:    key.CacheTTLPages,
+		Metadata: key.CacheTTLMetadata,
+	}
+
+	if k, ok := keys[n.Kind]; ok {
+		if lifetime, err := time.ParseDuration(viper.GetString(k)); err == nil {
+			return lifetime
+		}
+	}
+
+	return defaultLifetime
+}
+
+func readEntry(path string) (*entry, error) {
+	data, err := filesystem.Api().ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var e entry
+	if err = json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+// readHeader reads the entry up to its value, which is written last.
+// It is enough to know whether the entry is expired without reading the value
+func readHeader(path string) (*entry, error) {
+	f, err := filesystem.Api().Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer util.Ignore(f.Close)
+
+	decoder := json.NewDecoder(f)
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, fmt.Errorf("invalid cache entry %s", path)
+	}
+
+	var e entry
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch token {
+		case "key":
+			err = decoder.Decode(&e.Key)
+		case "created":
+			err = decoder.Decode(&e.Created)
+		case "lifetime":
+			err = decoder.Decode(&e.Lifetime)
+		case "value":
+			return &e, nil
+		default:
+			var skipped json.RawMessage
+			err = decoder.Decode(&skipped)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &e, nil
+}
+
+func writeEntry(path string, e *entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	if err = filesystem.Api().MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	return filesystem.Api().WriteFile(path, data, os.ModePerm)
+}
+
+// file of the cached value
+type file struct {
+	path     string
+	size     int64
+	accessed time.Time
+}
+
+// files returns the files of the namespaces that match the filter, grouped by namespace
+func files(filter string) (map[Namespace][]*file, error) {
+	namespaces := make(map[Namespace][]*file)
+
+	sources, err := filesystem.Api().ReadDir(Root())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return namespaces, nil
+		}
+
+		return nil, err
+	}
+
+	for _, source := range sources {
+		if !source.IsDir() {
+			continue
+		}
+
+		kinds, err := filesystem.Api().ReadDir(filepath.Join(Root(), source.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, kind := range kinds {
+			namespace := Namespace{Source: source.Name(), Kind: Kind(kind.Name())}
+			if !kind.IsDir() || !namespace.matches(filter) {
+				continue
+			}
+
+			entries, err := filesystem.Api().ReadDir(namespace.dir())
+			if err != nil {
+				return nil, err
+			}
+
+			for _, e := range entries {
+				if e.IsDir() {
+					continue
+				}
+
+				namespaces[namespace] = append(namespaces[namespace], &file{
+					path:     filepath.Join(namespace.dir(), e.Name()),
+					size:     e.Size(),
+					accessed: e.ModTime(),
+				})
+			}
+		}
+	}
+
+	return namespaces, nil
+}
+
+// matches checks if the namespace matches the filter.
+// Filter is either empty, the source or the source and the kind
+func (n Namespace) matches(filter string) bool {
+	filter = strings.ToLower(strings.Trim(filter, "/ "))
+	return filter == "" || filter == n.Source || filter == n.String()
+}
+
+// Stat is the usage of the namespace
+type Stat struct {
+	Namespace string        `json:"namespace"`
+	Entries   int           `json:"entries"`
+	Expired   int           `json:"expired"`
+	Size      int64         `json:"size"`
+	Lifetime  time.Duration `json:"lifetime"`
+}
+
+// Stats returns the usage of the namespaces that match the filter, sorted by namespace
+func Stats(filter string) ([]*Stat, error) {
+	namespaces, err := files(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats []*Stat
+	for namespace, files := range namespaces {
+		stat := &Stat{
+			Namespace: namespace.String(),
+			Lifetime:  namespace.lifetime(0),
+		}
+
+		for _, f := range files {
+			stat.Entries++
+			stat.Size += f.size
+
+			lifetime, expired := namespace.expired(f)
+			stat.Lifetime = lifetime
+			if expired {
+				stat.Expired++
+			}
+		}
+
+		stats = append(stats, stat)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Namespace < stats[j].Namespace
+	})
+
+	return stats, nil
+}
+
+// expired checks if the entry of the file is expired, unreadable entries are expired.
+// Lifetime of the entry is returned as well
+func (n Namespace) expired(f *file) (lifetime time.Duration, expired bool) {
+	e, err := readHeader(f.path)
+	if err != nil {
+		return n.lifetime(0), true
+	}
+
+	lifetime = n.lifetime(e.Lifetime)
+	return lifetime, time.Since(e.Created) > lifetime
+}
+
+// Pruned is the result of pruning
+type Pruned struct {
+	Expired int   `json:"expired"`
+	Evicted int   `json:"evicted"`
+	Freed   int64 `json:"freed"`
+}
+
+// Prune removes expired entries, then least recently used ones until the cache fits the size limit
+func Prune() (*Pruned, error) {
+	namespaces, err := files("")
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		pruned = &Pruned{}
+		left   []*file
+		size   int64
+	)
+
+	for namespace, files := range namespaces {
+		for _, f := range files {
+			if _, expired := namespace.expired(f); !expired {
+				left = append(left, f)
+				size += f.size
+				continue
+			}
+
+			if err := filesystem.Api().Remove(f.path); err != nil {
+				return nil, err
+			}
+
+			pruned.Expired++
+			pruned.Freed += f.size
+		}
+	}
+
+	limit := int64(viper.GetInt(key.CacheMaxSize)) * 1024 * 1024
+	if limit <= 0 || size <= limit {
+		return pruned, nil
+	}
+
+	sort.Slice(left, func(i, j int) bool {
+		return left[i].accessed.Before(left[j].accessed)
+	})
+
+	for _, f := range left {
+		if size <= limit {
+			break
+		}
+
+		if err := filesystem.Api().Remove(f.path); err != nil {
+			return nil, err
+		}
+
+		size -= f.size
+		pruned.Evicted++
+		pruned.Freed += f.size
+	}
+
+	return pruned, nil
+}
+
+// Clear removes all entries of the namespaces that match the filter
+func Clear(filter string) error {
+	namespaces, err := files(filter)
+	if err != nil {
+		return err
+	}
+
+	if filter != "" && len(namespaces) == 0 {
+		return fmt.Errorf("namespace %q is not cached", filter)
+	}
+
+	for namespace := range namespaces {
+		if err := filesystem.Api().RemoveAll(namespace.dir()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
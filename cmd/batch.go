@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/metafates/mangal/batch"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/inline"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(batchCmd)
+
+	batchCmd.Flags().Bool("continue-on-error", false, "keep downloading other chapters and entries after a failure")
+	batchCmd.Flags().IntP("concurrency", "n", 0, "number of entries downloaded at the same time, overrides the manifest")
+	batchCmd.Flags().StringP("report", "r", "", "write the report as JSON to the file")
+}
+
+var batchCmd = &cobra.Command{
+	Use:   "batch <file.yaml|file.json>",
+	Short: "Download manga listed in a manifest file",
+	Long: `Download manga listed in a YAML or JSON manifest file.
+
+Each entry is searched by the query or got by the URL and its selected chapters are downloaded
+to the path and in the format of the entry. Sources and their caches are shared between entries.
+The manifest is validated before anything is downloaded.
+
+By default the run stops after the first failure and the unfinished entries are reported as skipped,
+downloader.stop_on_error is not used. Pass --continue-on-error to download everything that can be downloaded.
+
+Manifest example:
+
+concurrency: 2
+entries:
+  - query: chainsaw man
+    source: Mangadex
+    manga: exact
+    chapters: "c1-10"
+    format: cbz
+    path: ~/manga/cbz
+  - name: one piece
+    url: https://mangadex.org/title/a1c7c817-4e59-43b7-9365-09675a149a6f
+    chapters: "latest:5"
+
+Chapter selectors:
+` + inline.SelectorSyntax,
+	Example: `mangal batch manga.yaml --continue-on-error --report report.json`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manifest, err := batch.Load(args[0])
+		handleErr(err)
+
+		report := batch.Run(manifest, &batch.Options{
+			Concurrency:     lo.Must(cmd.Flags().GetInt("concurrency")),
+			ContinueOnError: lo.Must(cmd.Flags().GetBool("continue-on-error")),
+		})
+
+		fmt.Print(report.Summary())
+
+		if path := lo.Must(cmd.Flags().GetString("report")); path != "" {
+			marshalled, err := json.MarshalIndent(report, "", "  ")
+			handleErr(err)
+			handleErr(filesystem.Api().WriteFile(path, marshalled, os.ModePerm))
+		}
+
+		if report.Failed() {
+			os.Exit(1)
+		}
+	},
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/dustin/go-humanize"
+	"github.com/metafates/mangal/cache"
+	"github.com/metafates/mangal/color"
+	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/style"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+}
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage cached search results, chapters, pages and metadata",
+	Long: `Manage cached search results, chapters, pages and metadata.
+Cache is divided into namespaces, e.g. mangadex/chapters or anilist/metadata.
+Use --ns flag with the source name or namespace to manage only a part of it`,
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheStatsCmd)
+
+	cacheStatsCmd.Flags().String("ns", "", "show only namespaces of the source or the namespace")
+	cacheStatsCmd.Flags().BoolP("json", "j", false, "print as json")
+	cacheStatsCmd.SetOut(os.Stdout)
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show cache usage by namespaces",
+	Run: func(cmd *cobra.Command, args []string) {
+		stats, err := cache.Stats(lo.Must(cmd.Flags().GetString("ns")))
+		handleErr(err)
+
+		if lo.Must(cmd.Flags().GetBool("json")) {
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			handleErr(encoder.Encode(stats))
+			return
+		}
+
+		if len(stats) == 0 {
+			cmd.Println("Cache is empty")
+			return
+		}
+
+		var total int64
+		for _, stat := range stats {
+			total += stat.Size
+			cmd.Printf(
+				"%s %s in %s, %d expired, lifetime %s\n",
+				style.Fg(color.Purple)(stat.Namespace),
+				humanize.Bytes(uint64(stat.Size)),
+				style.Fg(color.Yellow)(fmt.Sprint(stat.Entries)+" entries"),
+				stat.Expired,
+				stat.Lifetime,
+			)
+		}
+
+		cmd.Printf("%s %s\n", style.Bold("Total"), humanize.Bytes(uint64(total)))
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cachePruneCmd)
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove expired entries and least recently used ones over the size limit",
+	Run: func(cmd *cobra.Command, args []string) {
+		pruned, err := cache.Prune()
+		handleErr(err)
+
+		fmt.Printf(
+			"%s removed %d expired and %d least recently used entries, freed %s\n",
+			icon.Get(icon.Success),
+			pruned.Expired,
+			pruned.Evicted,
+			humanize.Bytes(uint64(pruned.Freed)),
+		)
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheClearCmd)
+
+	cacheClearCmd.Flags().String("ns", "", "clear only namespaces of the source or the namespace")
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:     "clear",
+	Short:   "Remove cached entries",
+	Example: "mangal cache clear --ns mangadex/chapters",
+	Run: func(cmd *cobra.Command, args []string) {
+		namespace := lo.Must(cmd.Flags().GetString("ns"))
+		handleErr(cache.Clear(namespace))
+
+		if namespace == "" {
+			fmt.Printf("%s cache cleared\n", icon.Get(icon.Success))
+			return
+		}
+
+		fmt.Printf("%s %s cache cleared\n", icon.Get(icon.Success), style.Fg(color.Purple)(namespace))
+	},
+}
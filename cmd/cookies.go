@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/metafates/mangal/color"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/network"
+	"github.com/metafates/mangal/style"
+	"github.com/metafates/mangal/util"
+	"github.com/metafates/mangal/where"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(cookiesCmd)
+}
+
+var cookiesCmd = &cobra.Command{
+	Use:   "cookies",
+	Short: "Manage cookies shared by the sources",
+	Long: `Manage cookies shared by the sources.
+Cookies are used by all sources, including custom ones.
+Import them from the browser to access sites behind JavaScript challenges or logins`,
+}
+
+func init() {
+	cookiesCmd.AddCommand(cookiesImportCmd)
+}
+
+var cookiesImportCmd = &cobra.Command{
+	Use:     "import <cookies.txt>",
+	Short:   "Import cookies in the Netscape format",
+	Long:    `Import cookies in the Netscape format, as exported by browser extensions to cookies.txt`,
+	Example: "mangal cookies import ~/Downloads/cookies.txt",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		file, err := filesystem.Api().Open(args[0])
+		handleErr(err)
+		defer util.Ignore(file.Close)
+
+		cookies, err := network.ParseCookies(file)
+		handleErr(err)
+		handleErr(network.Jar.Import(cookies, true))
+
+		fmt.Printf(
+			"%s imported %d cookies to %s\n",
+			icon.Get(icon.Success),
+			len(cookies),
+			style.Fg(color.Yellow)(where.Cookies()),
+		)
+	},
+}
+
+func init() {
+	cookiesCmd.AddCommand(cookiesListCmd)
+	cookiesListCmd.SetOut(os.Stdout)
+}
+
+var cookiesListCmd = &cobra.Command{
+	Use:   "list <url>",
+	Short: "List cookies sent to the url",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		u, err := url.Parse(args[0])
+		handleErr(err)
+
+		for _, cookie := range network.Jar.Cookies(u) {
+			cmd.Printf("%s=%s\n", style.Fg(color.Purple)(cookie.Name), cookie.Value)
+		}
+	},
+}
+
+func init() {
+	cookiesCmd.AddCommand(cookiesClearCmd)
+}
+
+var cookiesClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove imported cookies",
+	Run: func(cmd *cobra.Command, args []string) {
+		handleErr(network.Jar.Clear())
+		fmt.Printf("%s cookies removed\n", icon.Get(icon.Success))
+	},
+}
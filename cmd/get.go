@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+
+	"github.com/metafates/mangal/converter"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/inline"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/provider"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	rootCmd.AddCommand(getCmd)
+
+	getCmd.Flags().StringP("chapters", "c", "", "chapter selector")
+	getCmd.Flags().BoolP("download", "d", false, "download chapters")
+	getCmd.Flags().BoolP("json", "j", false, "JSON output")
+	getCmd.Flags().BoolP("events", "e", false, "print progress events as newline delimited JSON")
+	getCmd.Flags().BoolP("populate-pages", "p", false, "Populate chapters pages")
+	getCmd.Flags().BoolP("include-anilist-manga", "a", false, "Include anilist manga in the output")
+	getCmd.Flags().StringP("output", "o", "", "output file")
+
+	getCmd.MarkFlagsMutuallyExclusive("download", "json")
+	getCmd.MarkFlagsMutuallyExclusive("events", "json")
+	getCmd.MarkFlagsMutuallyExclusive("include-anilist-manga", "download")
+}
+
+var getCmd = &cobra.Command{
+	Use:   "get <url>",
+	Short: "Get manga by URL without searching",
+	Long: `Get manga by the URL of its page and read, download or print it like the inline mode.
+
+Source is found by the host of the URL. Custom Lua sources declare the hosts they handle
+in the header with "-- @hosts example.com, *.example.org" and define GetManga(mangaURL) function.
+Manga ID can be used instead of the URL with the --source flag, e.g. mangal get -S Mangadex <id>
+
+Chapter selectors:
+` + inline.SelectorSyntax,
+	Example: `mangal get https://mangadex.org/title/a1c7c817-4e59-43b7-9365-09675a149a6f --chapters "latest:5" --download`,
+	Args:    cobra.ExactArgs(1),
+	PreRun: func(cmd *cobra.Command, args []string) {
+		if !lo.Must(cmd.Flags().GetBool("json")) {
+			lo.Must0(cmd.MarkFlagRequired("chapters"))
+		}
+
+		if lo.Must(cmd.Flags().GetBool("populate-pages")) {
+			lo.Must0(cmd.MarkFlagRequired("json"))
+		}
+
+		if _, err := converter.Get(viper.GetString(key.FormatsUse)); err != nil {
+			handleErr(err)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		manga, err := getManga(args[0], cmd.Flags().Changed("source"))
+		handleErr(err)
+
+		output := lo.Must(cmd.Flags().GetString("output"))
+		var writer io.Writer
+		if output != "" {
+			writer, err = filesystem.Api().Create(output)
+			handleErr(err)
+		} else {
+			writer = os.Stdout
+		}
+
+		chapterFilter := mo.None[inline.ChaptersFilter]()
+		if chapterFlag := lo.Must(cmd.Flags().GetString("chapters")); chapterFlag != "" {
+			fn, err := inline.ParseChaptersFilter(chapterFlag)
+			handleErr(err)
+			chapterFilter = mo.Some(fn)
+		}
+
+		options := &inline.Options{
+			Manga:               manga,
+			Download:            lo.Must(cmd.Flags().GetBool("download")),
+			Json:                lo.Must(cmd.Flags().GetBool("json")),
+			Events:              lo.Must(cmd.Flags().GetBool("events")),
+			PopulatePages:       lo.Must(cmd.Flags().GetBool("populate-pages")),
+			IncludeAnilistManga: lo.Must(cmd.Flags().GetBool("include-anilist-manga")),
+			MangaPicker: mo.Some[inline.MangaPicker](func(mangas []*source.Manga) *source.Manga {
+				return mangas[0]
+			}),
+			ChaptersFilter: chapterFilter,
+			Out:            writer,
+		}
+
+		handleErr(inline.Run(options))
+	},
+}
+
+// getManga gets the manga by its URL or ID without searching.
+// Source is found by the host of the URL, unless it is set explicitly.
+// The default source is used for IDs
+func getManga(id string, explicitSource bool) (*source.Manga, error) {
+	var (
+		p   *provider.Provider
+		err error
+	)
+
+	parsed, parseErr := url.Parse(id)
+	isURL := parseErr == nil && parsed.Hostname() != ""
+
+	if isURL && !explicitSource {
+		if p, err = provider.ForURL(id); err != nil {
+			return nil, err
+		}
+	} else {
+		names := viper.GetStringSlice(key.DownloaderDefaultSources)
+		if len(names) == 0 || names[0] == "" {
+			return nil, errors.New("source not set, use --source to get manga by ID")
+		}
+
+		var ok bool
+		if p, ok = provider.Get(names[0]); !ok {
+			return nil, fmt.Errorf("source not found: %s", names[0])
+		}
+	}
+
+	src, err := p.CreateSource()
+	if err != nil {
+		return nil, err
+	}
+
+	getter, ok := src.(source.MangaGetter)
+	if !ok {
+		return nil, fmt.Errorf("source %s can't get manga without searching", p.Name)
+	}
+
+	return getter.GetManga(id)
+}
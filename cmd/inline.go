@@ -7,6 +7,7 @@ import (
 	"github.com/invopop/jsonschema"
 	"github.com/metafates/mangal/anilist"
 	"github.com/metafates/mangal/converter"
+	"github.com/metafates/mangal/event"
 	"github.com/metafates/mangal/filesystem"
 	"github.com/metafates/mangal/inline"
 	"github.com/metafates/mangal/key"
@@ -20,9 +21,6 @@ import (
 	"github.com/spf13/viper"
 	"io"
 	"os"
-	"path/filepath"
-	"reflect"
-	"strings"
 )
 
 func init() {
@@ -33,6 +31,8 @@ func init() {
 	inlineCmd.Flags().StringP("chapters", "c", "", "chapter selector")
 	inlineCmd.Flags().BoolP("download", "d", false, "download chapters")
 	inlineCmd.Flags().BoolP("json", "j", false, "JSON output")
+	inlineCmd.Flags().BoolP("events", "e", false, "print progress events as newline delimited JSON")
+	inlineCmd.Flags().BoolP("group", "g", false, "group the same manga from different sources and pick the most complete one")
 	inlineCmd.Flags().BoolP("populate-pages", "p", false, "Populate chapters pages")
 	inlineCmd.Flags().BoolP("fetch-metadata", "f", false, "Populate manga metadata")
 	inlineCmd.Flags().BoolP("include-anilist-manga", "a", false, "Include anilist manga in the output")
@@ -42,6 +42,7 @@ func init() {
 
 	lo.Must0(inlineCmd.MarkFlagRequired("query"))
 	inlineCmd.MarkFlagsMutuallyExclusive("download", "json")
+	inlineCmd.MarkFlagsMutuallyExclusive("events", "json")
 	inlineCmd.MarkFlagsMutuallyExclusive("include-anilist-manga", "download")
 
 	inlineCmd.RegisterFlagCompletionFunc("query", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -60,14 +61,15 @@ Manga selectors:
   [number] - select manga by index (starting from 0)
 
 Chapter selectors:
-  first - first chapter in the list
-  last - last chapter in the list
-  all - all chapters in the list
-  [number] - select chapter by index (starting from 0)
-  [from]-[to] - select chapters by range
-  @[substring]@ - select chapters by name substring
+` + inline.SelectorSyntax + `
 
-When using the json flag manga selector could be omitted. That way, it will select all mangas`,
+When using the json flag manga selector could be omitted. That way, it will select all mangas
+
+When using the group flag the same manga found in different sources is merged into a single result.
+The source with the most chapters is used and the others are listed in the JSON output.
+
+When using the events flag progress events are printed as newline delimited JSON instead of the downloaded paths.
+Event kinds: progress, page_downloaded, chapter_converted, chapter_downloaded, metadata_fetched, cover_downloaded, skipped, error`,
 
 	Example: "https://github.com/metafates/mangal/wiki/Inline-mode",
 	PreRun: func(cmd *cobra.Command, args []string) {
@@ -138,6 +140,8 @@ When using the json flag manga selector could be omitted. That way, it will sele
 			Sources:             sources,
 			Download:            lo.Must(cmd.Flags().GetBool("download")),
 			Json:                lo.Must(cmd.Flags().GetBool("json")),
+			Events:              lo.Must(cmd.Flags().GetBool("events")),
+			Group:               lo.Must(cmd.Flags().GetBool("group")),
 			Query:               query,
 			PopulatePages:       lo.Must(cmd.Flags().GetBool("populate-pages")),
 			IncludeAnilistManga: lo.Must(cmd.Flags().GetBool("include-anilist-manga")),
@@ -269,29 +273,24 @@ func init() {
 	inlineCmd.AddCommand(inlineSchemaCmd)
 
 	inlineSchemaCmd.Flags().BoolP("anilist", "a", false, "generate anilist search output schema")
+	inlineSchemaCmd.Flags().BoolP("events", "e", false, "generate progress event schema")
+
+	inlineSchemaCmd.MarkFlagsMutuallyExclusive("anilist", "events")
 }
 
 var inlineSchemaCmd = &cobra.Command{
 	Use:   "schema",
 	Short: "Schemas for the inline json outputs",
 	Run: func(cmd *cobra.Command, args []string) {
-		reflector := new(jsonschema.Reflector)
-		reflector.Anonymous = true
-		reflector.Namer = func(t reflect.Type) string {
-			name := t.Name()
-			switch strings.ToLower(name) {
-			case "manga", "chapter", "page", "date", "output":
-				return filepath.Base(t.PkgPath()) + "." + name
-			}
-
-			return name
-		}
+		reflector := inline.NewSchemaReflector()
 
 		var schema *jsonschema.Schema
 
 		switch {
 		case lo.Must(cmd.Flags().GetBool("anilist")):
 			schema = reflector.Reflect([]*anilist.Manga{})
+		case lo.Must(cmd.Flags().GetBool("events")):
+			schema = reflector.Reflect(&event.Event{})
 		default:
 			schema = reflector.Reflect(&inline.Output{})
 		}
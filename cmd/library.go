@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/metafates/mangal/converter"
+	"github.com/metafates/mangal/history"
+	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/layout"
+	"github.com/metafates/mangal/library"
+	"github.com/metafates/mangal/provider"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/util"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(libraryCmd)
+}
+
+var libraryCmd = &cobra.Command{
+	Use:   "library",
+	Short: "Manage downloaded manga",
+}
+
+func init() {
+	libraryCmd.AddCommand(libraryMigrateCmd)
+
+	libraryMigrateCmd.Flags().String("from-layout", "", "layout the chapters were downloaded with, current downloader.layout by default")
+	libraryMigrateCmd.Flags().String("from-name-template", "", "chapter name template the chapters were downloaded with, current downloader.chapter_name_template by default")
+	libraryMigrateCmd.Flags().String("from-format", "", "format the chapters were downloaded in, current formats.use by default")
+	libraryMigrateCmd.Flags().String("from-path", "", "directory the chapters were downloaded to, current downloader.path by default")
+	libraryMigrateCmd.Flags().Bool("dry-run", false, "print the plan without changing anything")
+	libraryMigrateCmd.Flags().Bool("keep", false, "keep the originals of the converted chapters")
+}
+
+var libraryMigrateCmd = &cobra.Command{
+	Use:   "migrate [url...]",
+	Short: "Move and convert downloaded chapters to the current layout and format",
+	Long: `Move and rename downloaded chapters to match the current downloader.layout and convert them to formats.use.
+
+Chapters are looked up in the old layout described by the --from-* flags, the current config is used for the omitted ones.
+Manga are taken from the history unless their URLs are given.
+Page count of each chapter is verified after it is migrated and all finished operations are rolled back if any of them fails.
+Chapters can be converted from plain, zip and cbz formats.`,
+	Example: `mangal library migrate --from-format plain --dry-run
+mangal library migrate --from-layout "{{ sanitize .Manga.Name }}/{{ sanitize .Chapter.FormattedName }}" https://mangadex.org/title/a77742b1-befd-49a4-bff5-1ad4e6b0ef7b`,
+	Run: func(cmd *cobra.Command, args []string) {
+		to, err := library.Current()
+		handleErr(err)
+
+		from, err := fromLayout(cmd, to)
+		handleErr(err)
+
+		var mangas []*source.Manga
+		if len(args) > 0 {
+			for _, arg := range args {
+				manga, err := getManga(arg, cmd.Flags().Changed("source"))
+				handleErr(err)
+				mangas = append(mangas, manga)
+			}
+		} else {
+			mangas, err = historyMangas()
+			handleErr(err)
+		}
+
+		var chapters []*source.Chapter
+		for _, manga := range mangas {
+			mangaChapters, err := manga.Source.ChaptersOf(manga)
+			if err != nil {
+				fmt.Printf("%s %s: %s\n", icon.Get(icon.Fail), manga.Name, err)
+				continue
+			}
+
+			manga.Chapters = mangaChapters
+			chapters = append(chapters, mangaChapters...)
+		}
+
+		plan, err := library.NewPlan(from, to, chapters)
+		handleErr(err)
+
+		fmt.Print(plan.String())
+
+		if lo.Must(cmd.Flags().GetBool("dry-run")) || len(plan.Operations) == 0 {
+			return
+		}
+
+		handleErr(plan.Execute(lo.Must(cmd.Flags().GetBool("keep"))))
+		fmt.Printf("%s %s migrated\n", icon.Get(icon.Success), util.Quantify(len(plan.Operations), "chapter", "chapters"))
+	},
+}
+
+// fromLayout builds the old layout from the flags, the current one is used for the omitted flags
+func fromLayout(cmd *cobra.Command, current *library.Layout) (*library.Layout, error) {
+	from := *current
+
+	if text := lo.Must(cmd.Flags().GetString("from-layout")); text != "" {
+		template, err := layout.Parse(text)
+		if err != nil {
+			return nil, err
+		}
+
+		from.Template = template
+	}
+
+	if nameTemplate := lo.Must(cmd.Flags().GetString("from-name-template")); nameTemplate != "" {
+		from.NameTemplate = nameTemplate
+	}
+
+	if format := lo.Must(cmd.Flags().GetString("from-format")); format != "" {
+		if _, err := converter.Get(format); err != nil {
+			return nil, err
+		}
+
+		from.Format = format
+	}
+
+	if path := lo.Must(cmd.Flags().GetString("from-path")); path != "" {
+		path, err := expandPath(path)
+		if err != nil {
+			return nil, err
+		}
+
+		from.Path = path
+	}
+
+	return &from, nil
+}
+
+// expandPath resolves the home directory and environment variables in the path and makes it absolute like downloader.path
+func expandPath(path string) (string, error) {
+	if home, err := os.UserHomeDir(); err == nil {
+		if path == "~" {
+			path = home
+		} else if strings.HasPrefix(path, fmt.Sprintf("%c%c", '~', os.PathSeparator)) {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+
+	return filepath.Abs(os.ExpandEnv(path))
+}
+
+// historyMangas returns the manga saved in the history
+func historyMangas() ([]*source.Manga, error) {
+	saved, err := history.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	providers := lo.Flatten([][]*provider.Provider{provider.Builtins(), provider.Customs()})
+	sources := make(map[string]source.Source)
+
+	var mangas []*source.Manga
+	for _, chapter := range saved {
+		p, ok := lo.Find(providers, func(p *provider.Provider) bool {
+			return p.ID == chapter.SourceID
+		})
+		if !ok {
+			fmt.Printf("%s %s: source %s not found\n", icon.Get(icon.Fail), chapter.MangaName, chapter.SourceID)
+			continue
+		}
+
+		src, ok := sources[p.ID]
+		if !ok {
+			if src, err = p.CreateSource(); err != nil {
+				return nil, err
+			}
+
+			sources[p.ID] = src
+		}
+
+		mangas = append(mangas, &source.Manga{
+			Name:   chapter.MangaName,
+			URL:    chapter.MangaURL,
+			ID:     chapter.MangaID,
+			Source: src,
+		})
+	}
+
+	return mangas, nil
+}
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/metafates/mangal/color"
+	"github.com/metafates/mangal/db"
+	"github.com/metafates/mangal/downloader"
+	"github.com/metafates/mangal/event"
+	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/model"
+	"github.com/metafates/mangal/provider/mangadex"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/style"
+	"github.com/metafates/mangal/util"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	rootCmd.AddCommand(mangadexCmd)
+}
+
+var mangadexCmd = &cobra.Command{
+	Use:   "mangadex",
+	Short: "Mangadex account features",
+	Long: `Mangadex account features.
+Personal API client is required, create one at https://mangadex.org/settings`,
+}
+
+func init() {
+	mangadexCmd.AddCommand(mangadexLoginCmd)
+}
+
+var mangadexLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Log in to mangadex",
+	Long: `Log in to mangadex with the personal API client.
+Client credentials and the refresh token are saved, the password is never stored`,
+	Run: func(cmd *cobra.Command, args []string) {
+		credentials := []struct {
+			key    string
+			prompt survey.Prompt
+		}{
+			{key.MangadexClientID, &survey.Input{Message: "Mangadex client ID:"}},
+			{key.MangadexClientSecret, &survey.Password{Message: "Mangadex client secret:"}},
+		}
+
+		for _, credential := range credentials {
+			if viper.GetString(credential.key) != "" {
+				continue
+			}
+
+			var response string
+			handleErr(survey.AskOne(credential.prompt, &response, survey.WithValidator(survey.Required)))
+			viper.Set(credential.key, response)
+		}
+
+		var answers struct {
+			Username string
+			Password string
+		}
+
+		handleErr(survey.Ask([]*survey.Question{
+			{Name: "username", Prompt: &survey.Input{Message: "Mangadex username:"}, Validate: survey.Required},
+			{Name: "password", Prompt: &survey.Password{Message: "Mangadex password:"}, Validate: survey.Required},
+		}, &answers))
+
+		handleErr(mangadex.Login(answers.Username, answers.Password))
+
+		fmt.Printf("%s logged in to mangadex as %s\n", icon.Get(icon.Success), style.Fg(color.Yellow)(answers.Username))
+	},
+}
+
+func init() {
+	mangadexCmd.AddCommand(mangadexLogoutCmd)
+}
+
+var mangadexLogoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Log out of mangadex",
+	Run: func(cmd *cobra.Command, args []string) {
+		handleErr(mangadex.Logout())
+
+		fmt.Printf("%s logged out of mangadex\n", icon.Get(icon.Success))
+	},
+}
+
+func init() {
+	mangadexCmd.AddCommand(mangadexFollowsCmd)
+
+	mangadexFollowsCmd.Flags().BoolP("json", "j", false, "print as json")
+	mangadexFollowsCmd.Flags().BoolP("import", "i", false, "import followed manga into the metadata database")
+	mangadexFollowsCmd.SetOut(os.Stdout)
+}
+
+var mangadexFollowsCmd = &cobra.Command{
+	Use:   "follows",
+	Short: "List followed manga",
+	Run: func(cmd *cobra.Command, args []string) {
+		mangas, err := mangadex.New().Follows()
+		handleErr(err)
+
+		if lo.Must(cmd.Flags().GetBool("import")) {
+			handleErr(importMangas(mangas))
+			fmt.Printf("%s imported %d followed manga\n", icon.Get(icon.Success), len(mangas))
+			return
+		}
+
+		if lo.Must(cmd.Flags().GetBool("json")) {
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			handleErr(encoder.Encode(mangas))
+			return
+		}
+
+		for _, manga := range mangas {
+			cmd.Printf("%s %s\n", style.Fg(color.Yellow)(manga.Name), style.Faint(manga.URL))
+		}
+	},
+}
+
+// importMangas saves the metadata of the manga into the database
+func importMangas(mangas []*source.Manga) error {
+	database, err := db.GetDB()
+	if err != nil {
+		return err
+	}
+
+	defer util.Ignore(database.Close)
+
+	for _, manga := range mangas {
+		err := db.SaveMangaMetadata(database, &model.Manga{
+			ID:          manga.ID,
+			Title:       manga.Name,
+			URL:         manga.URL,
+			Description: manga.Metadata.Summary,
+			SourceID:    manga.Source.ID(),
+			SourceName:  manga.Source.Name(),
+			Metadata:    manga.Metadata,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to import %s: %w", manga.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	mangadexCmd.AddCommand(mangadexFeedCmd)
+
+	mangadexFeedCmd.Flags().DurationP("since", "s", 7*24*time.Hour, "show chapters published within this duration")
+	mangadexFeedCmd.Flags().BoolP("download", "d", false, "download new chapters")
+	mangadexFeedCmd.Flags().BoolP("json", "j", false, "print as json")
+	mangadexFeedCmd.SetOut(os.Stdout)
+}
+
+var mangadexFeedCmd = &cobra.Command{
+	Use:   "feed",
+	Short: "List new chapters of followed manga",
+	Long: `List new chapters of followed manga.
+Chapters are filtered by the same language and scanlation group preferences as the chapters list`,
+	Example: "mangal mangadex feed --since 72h --download",
+	Run: func(cmd *cobra.Command, args []string) {
+		since := time.Now().Add(-lo.Must(cmd.Flags().GetDuration("since")))
+
+		mangas, err := mangadex.New().FollowsFeed(since)
+		handleErr(err)
+
+		if lo.Must(cmd.Flags().GetBool("json")) {
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			handleErr(encoder.Encode(mangas))
+			return
+		}
+
+		download := lo.Must(cmd.Flags().GetBool("download"))
+
+		for _, manga := range mangas {
+			cmd.Println(style.Fg(color.Yellow)(manga.Name))
+
+			for _, chapter := range manga.Chapters {
+				if !download {
+					cmd.Printf("  %s\n", chapter.Name)
+					continue
+				}
+
+				path, err := downloader.Download(chapter, event.Discard)
+				if err != nil {
+					log.Error(err)
+					if viper.GetBool(key.DownloaderStopOnError) {
+						handleErr(err)
+					}
+
+					cmd.Printf("  %s %s\n", icon.Get(icon.Fail), chapter.Name)
+					continue
+				}
+
+				cmd.Printf("  %s %s\n", icon.Get(icon.Success), style.Faint(path))
+			}
+		}
+	},
+}
@@ -14,8 +14,10 @@ func init() {
 
 	miniCmd.Flags().BoolP("download", "d", false, "download mode")
 	miniCmd.Flags().BoolP("continue", "c", false, "continue reading")
+	miniCmd.Flags().BoolP("queue", "q", false, "download the selected chapters in the background while reading")
 
 	miniCmd.MarkFlagsMutuallyExclusive("download", "continue")
+	miniCmd.MarkFlagsMutuallyExclusive("download", "queue")
 }
 
 var miniCmd = &cobra.Command{
@@ -32,6 +34,7 @@ Will try to mimic ani-cli.`,
 		options := mini.Options{
 			Download: lo.Must(cmd.Flags().GetBool("download")),
 			Continue: lo.Must(cmd.Flags().GetBool("continue")),
+			Queue:    lo.Must(cmd.Flags().GetBool("queue")),
 		}
 		err := mini.Run(&options)
 
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	cc "github.com/ivanpirog/coloredcobra"
+	"github.com/metafates/mangal/cache"
 	"github.com/metafates/mangal/color"
 	"github.com/metafates/mangal/constant"
 	"github.com/metafates/mangal/config"
@@ -53,6 +54,8 @@ func init() {
 	rootCmd.PersistentFlags().BoolP("write-history", "H", true, "write history of the read chapters")
 	lo.Must0(viper.BindPFlag(key.HistorySaveOnRead, rootCmd.PersistentFlags().Lookup("write-history")))
 
+	rootCmd.PersistentFlags().Bool("no-cache", false, "do not use cached results, fetch fresh ones")
+
 	rootCmd.PersistentFlags().StringSliceP("source", "S", []string{}, "default source to use")
 	lo.Must0(rootCmd.RegisterFlagCompletionFunc("source", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		var sources []string
@@ -90,6 +93,10 @@ func initConfig() {
 		os.Exit(1)
 	}
 
+	if lo.Must(rootCmd.PersistentFlags().GetBool("no-cache")) {
+		cache.Disable()
+	}
+
 	// Initialize database connection
 	_, err := db.GetDB()
 	if err != nil {
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/metafates/mangal/color"
+	"github.com/metafates/mangal/converter"
+	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/server"
+	"github.com/metafates/mangal/style"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"os"
+)
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringP("address", "a", "localhost:6969", "address to listen on")
+	serveCmd.Flags().StringP("socket", "s", "", "unix socket to listen on instead of the address")
+	serveCmd.Flags().IntP("jobs", "j", 1, "number of downloads that can run at the same time")
+
+	serveCmd.MarkFlagsMutuallyExclusive("address", "socket")
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run HTTP API server",
+	Long: `Run HTTP API server for scripting mangal as a service.
+Requests and responses are JSON encoded. Use "mangal serve schema" to get the list of endpoints with their schemas`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		if _, err := converter.Get(viper.GetString(key.FormatsUse)); err != nil {
+			handleErr(err)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		options := &server.Options{
+			Address: lo.Must(cmd.Flags().GetString("address")),
+			Socket:  lo.Must(cmd.Flags().GetString("socket")),
+			Jobs:    lo.Must(cmd.Flags().GetInt("jobs")),
+		}
+
+		listenOn := options.Address
+		if options.Socket != "" {
+			listenOn = options.Socket
+		}
+
+		fmt.Fprintf(os.Stderr, "%s Listening on %s\n", icon.Get(icon.Success), style.Fg(color.Yellow)(listenOn))
+		handleErr(server.Run(options))
+	},
+}
+
+func init() {
+	serveCmd.AddCommand(serveSchemaCmd)
+}
+
+var serveSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Schemas of the API endpoints",
+	Run: func(cmd *cobra.Command, args []string) {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		handleErr(encoder.Encode(server.Schema()))
+	},
+}
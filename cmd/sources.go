@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/metafates/mangal/color"
 	"github.com/metafates/mangal/constant"
@@ -8,6 +10,7 @@ import (
 	"github.com/metafates/mangal/tui"
 	"github.com/metafates/mangal/util"
 	"github.com/spf13/viper"
+	"net/url"
 	"os"
 	"os/user"
 	"path/filepath"
@@ -16,7 +19,10 @@ import (
 
 	"github.com/metafates/mangal/filesystem"
 	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/installer"
 	"github.com/metafates/mangal/provider"
+	"github.com/metafates/mangal/provider/custom"
+	"github.com/metafates/mangal/provider/declarative"
 	"github.com/metafates/mangal/style"
 	"github.com/metafates/mangal/where"
 	"github.com/samber/lo"
@@ -96,7 +102,7 @@ func init() {
 
 		return lo.FilterMap(sources, func(item os.FileInfo, _ int) (string, bool) {
 			name := item.Name()
-			if !strings.HasSuffix(name, provider.CustomProviderExtension) {
+			if !provider.IsCustomSource(name) {
 				return "", false
 			}
 
@@ -111,7 +117,12 @@ var sourcesRemoveCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		for _, name := range lo.Must(cmd.Flags().GetStringArray("name")) {
 			path := filepath.Join(where.Sources(), name+provider.CustomProviderExtension)
+			if p, ok := provider.Get(name); ok && p.IsCustom {
+				path = p.Path
+			}
+
 			handleErr(filesystem.Api().Remove(path))
+			handleErr(installer.Unlock(name))
 			fmt.Printf("%s successfully removed %s\n", icon.Get(icon.Success), style.Fg(color.Yellow)(name))
 		}
 	},
@@ -122,15 +133,93 @@ func init() {
 }
 
 var sourcesInstallCmd = &cobra.Command{
-	Use:   "install",
+	Use:   "install [names...]",
 	Short: "Browse and install custom scrapers",
-	Long: `Browse and install custom scrapers from official GitHub repo.
-https://github.com/metafates/mangal-scrapers`,
+	Long: `Browse and install custom scrapers from the repositories.
+Without names, scrapers are browsed in the TUI.
+Installed scrapers and their dependencies are verified and pinned in the lockfile.
+See installer.repositories config option.`,
+	Example: "  mangal sources install mangasee manganato",
 	Run: func(cmd *cobra.Command, args []string) {
-		handleErr(tui.Run(&tui.Options{Install: true}))
+		if len(args) == 0 {
+			handleErr(tui.Run(&tui.Options{Install: true}))
+			return
+		}
+
+		installed, err := installer.Install(args...)
+		printInstalled(installed, "installed")
+		handleErr(err)
 	},
 }
 
+func init() {
+	sourcesCmd.AddCommand(sourcesUpdateCmd)
+}
+
+var sourcesUpdateCmd = &cobra.Command{
+	Use:   "update [names...]",
+	Short: "Update installed custom scrapers",
+	Long: `Update installed custom scrapers to the latest versions from the repositories.
+Every outdated scraper is updated if no names are given.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		updated, err := installer.Update(args...)
+		printInstalled(updated, "updated")
+		handleErr(err)
+
+		if len(updated) == 0 {
+			fmt.Println("Everything is up to date")
+		}
+	},
+}
+
+func init() {
+	sourcesCmd.AddCommand(sourcesOutdatedCmd)
+
+	sourcesOutdatedCmd.Flags().BoolP("json", "j", false, "print as json")
+	sourcesOutdatedCmd.SetOut(os.Stdout)
+}
+
+var sourcesOutdatedCmd = &cobra.Command{
+	Use:   "outdated",
+	Short: "List installed custom scrapers that have newer versions",
+	Run: func(cmd *cobra.Command, args []string) {
+		outdated, err := installer.CheckOutdated()
+		handleErr(err)
+
+		if lo.Must(cmd.Flags().GetBool("json")) {
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			handleErr(encoder.Encode(outdated))
+			return
+		}
+
+		if len(outdated) == 0 {
+			cmd.Println("Everything is up to date")
+			return
+		}
+
+		for _, o := range outdated {
+			installed := o.Installed
+			if installed == "" {
+				installed = "unversioned"
+			}
+
+			cmd.Printf("%s %s → %s\n", style.Fg(color.Yellow)(o.Name), installed, style.Fg(color.Green)(o.Available))
+		}
+	},
+}
+
+func printInstalled(scrapers []*installer.Scraper, action string) {
+	for _, s := range scrapers {
+		name := s.Name
+		if s.Version != "" {
+			name += "@" + s.Version
+		}
+
+		fmt.Printf("%s successfully %s %s\n", icon.Get(icon.Success), action, style.Fg(color.Yellow)(name))
+	}
+}
+
 func init() {
 	sourcesCmd.AddCommand(sourcesGenCmd)
 
@@ -158,19 +247,29 @@ var sourcesGenCmd = &cobra.Command{
 			}
 		}
 
+		address := lo.Must(cmd.Flags().GetString("url"))
+		parsed, err := url.Parse(address)
+		handleErr(err)
+
 		s := struct {
 			Name            string
 			URL             string
+			Host            string
 			SearchMangaFn   string
 			MangaChaptersFn string
 			ChapterPagesFn  string
+			MangaDetailsFn  string
+			GetMangaFn      string
 			Author          string
 		}{
 			Name:            lo.Must(cmd.Flags().GetString("name")),
-			URL:             lo.Must(cmd.Flags().GetString("url")),
+			URL:             address,
+			Host:            parsed.Hostname(),
 			SearchMangaFn:   constant.SearchMangaFn,
 			MangaChaptersFn: constant.MangaChaptersFn,
 			ChapterPagesFn:  constant.ChapterPagesFn,
+			MangaDetailsFn:  constant.MangaDetailsFn,
+			GetMangaFn:      constant.GetMangaFn,
 			Author:          author,
 		}
 
@@ -195,3 +294,74 @@ var sourcesGenCmd = &cobra.Command{
 		cmd.Println(target)
 	},
 }
+
+func init() {
+	sourcesCmd.AddCommand(sourcesTestCmd)
+
+	sourcesTestCmd.Flags().StringP("query", "q", "", "query to search for")
+	sourcesTestCmd.Flags().IntP("limit", "n", 3, "number of results of each step to show")
+	sourcesTestCmd.Flags().IntP("manga", "m", 0, "index of the found manga to get chapters of")
+	sourcesTestCmd.Flags().IntP("chapter", "c", 0, "index of the chapter to get pages of")
+
+	lo.Must0(sourcesTestCmd.MarkFlagRequired("query"))
+	sourcesTestCmd.SetOut(os.Stdout)
+}
+
+var sourcesTestCmd = &cobra.Command{
+	Use:   "test <file|name>",
+	Short: "Test a lua source",
+	Long: `Test a lua source by running its functions end to end.
+Every returned table is validated and each step is timed.
+Prints a JSON report and exits with non-zero code if the test has failed.`,
+	Example: "  mangal sources test ./example.lua --query \"one piece\"",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := args[0]
+		if exists, _ := filesystem.Api().Exists(path); !exists {
+			if p, ok := provider.Get(path); ok && p.IsCustom {
+				path = p.Path
+			}
+		}
+
+		if filepath.Ext(path) != provider.CustomProviderExtension {
+			handleErr(fmt.Errorf("%s is not a lua source", path))
+		}
+
+		options := custom.TestOptions{
+			Query:   lo.Must(cmd.Flags().GetString("query")),
+			Limit:   lo.Must(cmd.Flags().GetInt("limit")),
+			Manga:   lo.Must(cmd.Flags().GetInt("manga")),
+			Chapter: lo.Must(cmd.Flags().GetInt("chapter")),
+		}
+
+		if options.Manga < 0 || options.Chapter < 0 {
+			handleErr(errors.New("manga and chapter indexes can't be negative"))
+		}
+
+		report := custom.Test(path, options)
+
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		handleErr(encoder.Encode(report))
+
+		if !report.Passed {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	sourcesCmd.AddCommand(sourcesSchemaCmd)
+}
+
+var sourcesSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Schema of the declarative sources",
+	Long: `Print the JSON schema of the declarative sources.
+Definitions are validated against it when they are loaded.
+Editors can use it for completion and validation.`,
+	Example: "  mangal sources schema > schema.json",
+	Run: func(cmd *cobra.Command, args []string) {
+		handleErr(json.NewEncoder(os.Stdout).Encode(declarative.Schema()))
+	},
+}
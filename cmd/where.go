@@ -25,6 +25,7 @@ var wherePaths = []*whereTarget{
 	{"Config", where.Config, "config", mo.Some("c"), false},
 	{"Sources", where.Sources, "sources", mo.Some("s"), false},
 	{"Logs", where.Logs, "logs", mo.Some("l"), false},
+	{"Themes", where.Themes, "themes", mo.None[string](), false},
 	{"Cache", where.Cache, "cache", mo.None[string](), true},
 	{"Temp", where.Temp, "temp", mo.None[string](), true},
 	{"History", where.History, "history", mo.None[string](), true},
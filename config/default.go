@@ -125,7 +125,7 @@ func (f *Field) Env() string {
 
 // defaults contains all default values for the config.
 // It must contain all fields defined in the constant package.
-var defaults = [61]Field{
+var defaults = [92]Field{
 	{
 		key.DownloaderPath,
 		".",
@@ -144,8 +144,12 @@ Available variables:
 {padded-index}   - same as index but padded with leading zeros
 {chapters-count} - total number of chapters
 {chapter}        - name of the chapter
+{number}         - number of the chapter, index is used if unknown
+{padded-number}  - same as number but padded with leading zeros
 {manga}          - name of the manga
 {volume}         - volume of the chapter
+{volume-number}  - number of the volume
+{group}          - scanlation group of the chapter
 {source}         - name of the source`,
 	},
 	{
@@ -164,6 +168,30 @@ Do no turn it off unless you have some issues`,
 		false,
 		`Create a subdirectory for each volume`,
 	},
+	{
+		key.DownloaderLayout,
+		"",
+		`Go template of the chapter path relative to the download path, without the extension
+Segments are separated by "/", segments that render to the empty string are omitted
+and segments before the first one that refers to .Chapter form the manga directory.
+If empty, the layout is built from create_manga_dir, create_volume_dir and chapter_name_template
+Example: {{ first .Manga.Metadata.Staff.Story | default "Unknown" }}/{{ .Manga.Name }}/{{ with .Chapter.Volume }}Vol {{ . }}{{ end }}/{{ pad 4 .Chapter.Number }} {{ clean .Chapter.Name }}
+Available fields:
+.Manga.Name, .Manga.ID, .Manga.URL, .Manga.Source, .Manga.Chapters (count)
+.Manga.Metadata (e.g. .Manga.Metadata.Staff.Story, .Manga.Metadata.StartDate.Year)
+.Chapter.Name, .Chapter.FormattedName (by chapter_name_template), .Chapter.ID, .Chapter.URL
+.Chapter.Index, .Chapter.Number, .Chapter.Volume, .Chapter.VolumeNumber, .Chapter.Group, .Chapter.Date
+Available functions:
+sanitize       - replaces spaces and special symbols with "_", e.g. {{ sanitize .Manga.Name }}
+clean          - replaces only symbols that are not allowed in file names
+pad WIDTH      - pads the integer part of the number with zeros, e.g. {{ pad 3 .Chapter.Number }}
+num            - formats the number without trailing zeros
+first          - first element of the list or empty string
+join SEP       - joins the list with the separator
+default VALUE  - uses the value if the input is empty
+lower, upper, trim, replace OLD NEW
+Metadata is fetched before the path is resolved when downloading if the layout refers to .Manga.Metadata`,
+	},
 	{
 		key.DownloaderReadDownloaded,
 		true,
@@ -186,6 +214,23 @@ Type "mangal sources list" to show available sources`,
 		false,
 		`Stop downloading other chapters on error`,
 	},
+	{
+		key.DownloaderFallback,
+		false,
+		`Look for the same manga in other sources
+to download chapters that are missing or failed to download`,
+	},
+	{
+		key.DownloaderFallbackSources,
+		[]string{},
+		`Sources to fall back to, in order of preference.
+If not set, default sources are used`,
+	},
+	{
+		key.DownloaderWorkers,
+		2,
+		`Number of chapters downloaded at the same time in the background by the TUI`,
+	},
 	{
 		key.DownloaderDownloadCover,
 		true,
@@ -219,7 +264,7 @@ It will also cache the results to not spam the API`,
 	{
 		key.MetadataComicInfoXMLAddDate,
 		true,
-		`Add series release date to each chapter in ComicInfo.xml file`,
+		`Add chapter upload date, if provided by the source, or series release date to each chapter in ComicInfo.xml file`,
 	},
 	{
 		key.MetadataComicInfoXMLAlternativeDate,
@@ -297,10 +342,16 @@ Available options are: emoji, kaomoji, plain, squares, nerd (nerd-font required)
 		true,
 		"Show query suggestions in when searching",
 	},
+	{
+		key.SearchTimeout,
+		20,
+		`How long to wait for each source to respond when searching, in seconds
+Sources that did not respond in time are skipped. Use 0 to wait indefinitely`,
+	},
 	{
 		key.MangadexLanguage,
-		"en",
-		`Preferred language for mangadex
+		[]string{"en"},
+		`Preferred languages for mangadex in the order of priority
 Use "any" to show all languages`,
 	},
 	{
@@ -313,6 +364,98 @@ Use "any" to show all languages`,
 		false,
 		"Show chapters that cannot be downloaded",
 	},
+	{
+		key.MangadexPreferredGroups,
+		[]string{},
+		`Preferred scanlation groups for mangadex in the order of priority, names or ids
+Used to choose between chapters with the same number`,
+	},
+	{
+		key.MangadexBlockedGroups,
+		[]string{},
+		`Scanlation groups for mangadex to hide chapters of, names or ids`,
+	},
+	{
+		key.MangadexDeduplicate,
+		true,
+		`Keep a single chapter for each chapter number on mangadex
+Preferred languages go first, then preferred scanlation groups`,
+	},
+	{
+		key.MangadexDataSaver,
+		false,
+		`Download compressed images from mangadex
+Useful for metered connections`,
+	},
+	{
+		key.MangadexClientID,
+		"",
+		`Client ID of the mangadex personal API client
+Used to access followed manga. Type "mangal mangadex login" to set it up`,
+	},
+	{
+		key.MangadexClientSecret,
+		"",
+		"Client secret of the mangadex personal API client",
+	},
+	{
+		key.MangadexRefreshToken,
+		"",
+		`Refresh token of the mangadex session
+Set by "mangal mangadex login", password is never stored`,
+	},
+	{
+		key.CacheEnabled,
+		true,
+		`Use cached search results, chapters, pages and metadata
+Fresh results are cached anyway. Use --no-cache flag to disable it once`,
+	},
+	{
+		key.CacheTTLSearch,
+		"24h",
+		`How long search results are cached
+Use 0 to disable caching`,
+	},
+	{
+		key.CacheTTLChapters,
+		"24h",
+		"How long chapters lists are cached",
+	},
+	{
+		key.CacheTTLPages,
+		"1h",
+		"How long chapter pages are cached",
+	},
+	{
+		key.CacheTTLMetadata,
+		"240h",
+		"How long manga metadata (e.g. from Anilist) is cached",
+	},
+	{
+		key.CacheTTLOverrides,
+		[]string{},
+		`Cache lifetimes for specific sources or namespaces
+Namespace is the lowercase name of the source, optionally followed by the kind
+Example: ["mangadex/chapters=1h", "mangapill=12h"]`,
+	},
+	{
+		key.CacheMaxSize,
+		256,
+		`Maximum size of the cache in megabytes
+Least recently used entries are removed when it is exceeded. Use 0 for no limit`,
+	},
+	{
+		key.NetworkSolverURL,
+		"",
+		`URL of the FlareSolverr compatible API to solve JavaScript challenges
+Example: http://localhost:8191/v1
+Challenges are not solved if empty`,
+	},
+	{
+		key.NetworkSolverTimeout,
+		60,
+		"How long to wait for the challenge to be solved, in seconds",
+	},
 	{
 		key.InstallerUser,
 		"metafates",
@@ -328,11 +471,53 @@ Use "any" to show all languages`,
 		"main",
 		"Custom scrapers repository branch",
 	},
+	{
+		key.InstallerRepositories,
+		[]string{},
+		`Scraper repositories in the order of priority
+Each one is a URL of the index.json, file:// URL or local directory with the index.json
+GitHub repository from the installer.user, installer.repo and installer.branch is used if empty`,
+	},
 	{
 		key.GenAuthor,
 		"",
 		"Key to use in generated scrapers as author",
 	},
+	{
+		key.LuaTimeout,
+		120,
+		`How long a single call of the Lua source can take, in seconds
+Use 0 to wait indefinitely`,
+	},
+	{
+		key.LuaInstructionLimit,
+		0,
+		`Maximum number of Lua instructions a single call of the Lua source can execute
+Use 0 for no limit`,
+	},
+	{
+		key.LuaCallStackSize,
+		256,
+		"Maximum depth of the Lua call stack",
+	},
+	{
+		key.LuaRegistrySize,
+		5120,
+		`Maximum size of the Lua data stack, in slots
+Limits how many values the stack of the Lua source can hold, it is not a limit of the heap memory`,
+	},
+	{
+		key.LuaEnforcePermissions,
+		false,
+		`Deny everything to the Lua sources without permissions in their header
+Otherwise, such sources are allowed everything`,
+	},
+	{
+		key.LuaSearchPages,
+		1,
+		`Maximum number of search result pages to request from the Lua sources
+Pages are requested until the source returns nothing new`,
+	},
 	{
 		key.LogsWrite,
 		false,
@@ -409,6 +594,21 @@ panic, fatal, error, warn, info, debug, trace`,
 		true,
 		"Show path where chapters were downloaded",
 	},
+	{
+		key.TUITheme,
+		"default",
+		"Name of the built-in theme or the theme file in the themes directory, or the path to the theme file",
+	},
+	{
+		key.TUICoverPreview,
+		true,
+		"Show cover of the manga in the details view",
+	},
+	{
+		key.TUIChaptersSort,
+		"source",
+		"Order of the chapters list: source, number, volume or date",
+	},
 	{
 		key.CliColored,
 		true,
@@ -4,6 +4,8 @@ const (
 	SearchMangaFn   = "SearchManga"
 	MangaChaptersFn = "MangaChapters"
 	ChapterPagesFn  = "ChapterPages"
+	MangaDetailsFn  = "MangaDetails"
+	GetMangaFn      = "GetManga"
 )
 
 const SourceTemplate = `{{ $divider := repeat "-" (plus (max (len .URL) (len .Name) (len .Author) 3) 12) }}{{ $divider }}
@@ -11,11 +13,14 @@ const SourceTemplate = `{{ $divider := repeat "-" (plus (max (len .URL) (len .Na
 -- @url     {{ .URL }}
 -- @author  {{ .Author }} 
 -- @license MIT
+-- @network {{ .Host }}
+-- @hosts   {{ .Host }}
 {{ $divider }}
 
 
----@alias manga { name: string, url: string, author: string|nil, genres: string|nil, summary: string|nil }
----@alias chapter { name: string, url: string, volume: string|nil, manga_summary: string|nil, manga_author: string|nil, manga_genres: string|nil }
+---@alias details { summary: string|nil, cover: string|nil, genres: string|nil, tags: string|nil, authors: string|nil, artists: string|nil, status: string|nil, alt_titles: string[]|nil, language: string|nil, year: number|nil, chapters_count: number|nil }
+---@alias manga { name: string, url: string } | details
+---@alias chapter { name: string, url: string, volume: string|nil, chapter_number: number|nil, volume_number: number|nil, date: string|nil, scanlator: string|nil, manga_summary: string|nil, manga_genres: string|nil, manga_cover: string|nil }
 ---@alias page { url: string, index: number }
 
 
@@ -34,12 +39,29 @@ const SourceTemplate = `{{ $divider := repeat "-" (plus (max (len .URL) (len .Na
 
 --- Searches for manga with given query.
 -- @param query string Query to search for
--- @return manga[] Table of mangas
-function {{ .SearchMangaFn }}(query)
+-- @param page number Page of the results, starting from 1
+-- @return manga[] Table of mangas, empty if there are no more pages
+function {{ .SearchMangaFn }}(query, page)
 	return {}
 end
 
 
+--- Gets the details of a manga. Optional, used when the manga is not found on Anilist.
+-- @param mangaURL string URL of the manga
+-- @return details
+-- function {{ .MangaDetailsFn }}(mangaURL)
+-- 	return {}
+-- end
+
+
+--- Gets the manga by the URL of its page. Optional, used by "mangal get" command.
+-- @param mangaURL string URL of the manga
+-- @return manga
+-- function {{ .GetMangaFn }}(mangaURL)
+-- 	return {}
+-- end
+
+
 --- Gets the list of all manga chapters.
 -- @param mangaURL string URL of the manga
 -- @return chapter[] Table of chapters
@@ -2,25 +2,46 @@ package downloader
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
 
-	"github.com/metafates/mangal/color"
 	"github.com/metafates/mangal/converter"
+	"github.com/metafates/mangal/event"
 	"github.com/metafates/mangal/filesystem"
 	"github.com/metafates/mangal/history"
 	"github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/log"
 	"github.com/metafates/mangal/source"
-	"github.com/metafates/mangal/style"
 	"github.com/spf13/viper"
 )
 
 // Download the chapter using given source.
-func Download(chapter *source.Chapter, progress func(string)) (string, error) {
+func Download(chapter *source.Chapter, handler event.Handler) (string, error) {
+	return DownloadContext(context.Background(), chapter, handler)
+}
+
+// DownloadContext downloads the chapter, the download is stopped when the context is done.
+// Context error is returned in that case.
+// Error event is emitted whenever an error is returned
+func DownloadContext(ctx context.Context, chapter *source.Chapter, handler event.Handler) (string, error) {
+	path, err := download(ctx, chapter, handler)
+	if err != nil {
+		return "", fail(chapter, handler, "download", err)
+	}
+
+	return path, nil
+}
+
+func download(ctx context.Context, chapter *source.Chapter, handler event.Handler) (string, error) {
+	// layout can refer to the metadata, so it is fetched before the path is resolved with the progress reported
+	if l, err := source.Layout(); err == nil {
+		chapter.Manga.PopulateForLayout(l, handler)
+	}
+
 	path, err := chapter.Path(false)
 	if err != nil {
 		return "", fmt.Errorf("failed to get chapter path: %w", err)
@@ -35,19 +56,32 @@ func Download(chapter *source.Chapter, progress func(string)) (string, error) {
 		}
 	} else if chapter.IsDownloaded() {
 		log.Info("chapter already downloaded, skipping")
+		e := chapter.Event(event.Skipped, "Chapter already downloaded")
+		e.Path = path
+		handler.Emit(e)
 		return path, nil
 	}
 
-	progress("Getting pages")
-	pages, err := chapter.Source().PagesOf(chapter)
+	handler.Emit(chapter.Event(event.Progress, "Getting pages"))
+	pages, err := pagesOf(chapter, handler)
 	if err != nil {
 		return "", fmt.Errorf("failed to get pages: %w", err)
 	}
 	log.Info(fmt.Sprintf("found %d pages", len(pages)))
 
-	if err := chapter.DownloadPages(false, progress); err != nil {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	if err := downloadPages(ctx, chapter, false, handler); err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+
 		return "", fmt.Errorf("failed to download pages: %w", err)
 	}
+	// pages could be replaced by the fallback
+	pages = chapter.Pages
 
 	// Run metadata and cover downloads concurrently
 	var wg sync.WaitGroup
@@ -55,7 +89,7 @@ func Download(chapter *source.Chapter, progress func(string)) (string, error) {
 
 	// Populate metadata first
 	if viper.GetBool(key.MetadataFetchAnilist) {
-		if err := chapter.Manga.PopulateMetadata(progress); err != nil {
+		if err := chapter.Manga.PopulateMetadata(handler); err != nil {
 			log.Warn(fmt.Sprintf("failed to populate metadata: %v", err))
 			errChan <- err
 		}
@@ -69,7 +103,7 @@ func Download(chapter *source.Chapter, progress func(string)) (string, error) {
 			errChan <- err
 		} else {
 			jsonPath := filepath.Join(metadataPath, "series.json")
-			progress("Generating series.json")
+			handler.Emit(chapter.Event(event.Progress, "Generating series.json"))
 			
 			seriesJSON := chapter.Manga.SeriesJSON()
 			buf := &bytes.Buffer{}
@@ -93,7 +127,7 @@ func Download(chapter *source.Chapter, progress func(string)) (string, error) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			if err := chapter.Manga.DownloadCover(false, "", progress); err != nil {
+			if err := chapter.Manga.DownloadCover(false, "", handler); err != nil {
 				log.Warn(fmt.Sprintf("failed to download cover: %v", err))
 				errChan <- err
 			}
@@ -114,27 +148,31 @@ func Download(chapter *source.Chapter, progress func(string)) (string, error) {
 		return "", fmt.Errorf("encountered %d errors during metadata/cover operations", len(errs))
 	}
 
-	log.Info("getting " + viper.GetString(key.FormatsUse) + " converter")
-	progress(fmt.Sprintf(
-		"Converting %d pages to %s %s",
-		len(pages),
-		style.Fg(color.Yellow)(viper.GetString(key.FormatsUse)),
-		style.Faint(chapter.SizeHuman()),
-	))
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	log.Info("getting " + chapter.Manga.Format() + " converter")
+	handler.Emit(converting(chapter, len(pages)))
 
-	conv, err := converter.Get(viper.GetString(key.FormatsUse))
+	conv, err := converter.Get(chapter.Manga.Format())
 	if err != nil {
 		log.Error(err)
 		return "", err
 	}
 
-	log.Info("converting " + viper.GetString(key.FormatsUse))
+	log.Info("converting " + chapter.Manga.Format())
 	path, err = conv.Save(chapter)
 	if err != nil {
 		log.Error(err)
 		return "", err
 	}
 
+	converted := chapter.Event(event.ChapterConverted, "Converted to "+chapter.Manga.Format())
+	converted.Pages = len(pages)
+	converted.Path = path
+	handler.Emit(converted)
+
 	if viper.GetBool(key.HistorySaveOnDownload) {
 		go func() {
 			err = history.Save(chapter)
@@ -147,6 +185,29 @@ func Download(chapter *source.Chapter, progress func(string)) (string, error) {
 	}
 
 	log.Info("downloaded without errors")
-	progress("Downloaded")
+	downloaded := chapter.Event(event.ChapterDownloaded, "Downloaded")
+	downloaded.Pages = len(pages)
+	downloaded.Path = path
+	handler.Emit(downloaded)
 	return path, nil
 }
+
+// converting returns the event of the chapter conversion.
+// Message is plain, renderers can build it from the fields to highlight the format
+func converting(chapter *source.Chapter, pages int) *event.Event {
+	format := chapter.Manga.Format()
+	e := chapter.Event(event.Progress, fmt.Sprintf("Converting %d pages to %s (%s)", pages, format, chapter.SizeHuman()))
+	e.Pages = pages
+	e.Bytes = chapter.Size()
+	e.Format = format
+	return e
+}
+
+// fail emits an error event for the chapter and returns the error.
+// Action is what has failed, e.g. download or read
+func fail(chapter *source.Chapter, handler event.Handler, action string, err error) error {
+	e := chapter.Event(event.Error, fmt.Sprintf("Failed to %s %s", action, chapter.Name))
+	e.Error = err.Error()
+	handler.Emit(e)
+	return err
+}
@@ -0,0 +1,160 @@
+package downloader
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/metafates/mangal/color"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/event"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/network"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/style"
+	"github.com/muesli/termenv"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+)
+
+func TestDownloadErrorEvent(t *testing.T) {
+	defer viper.Reset()
+
+	Convey("Given a chapter which path can't be resolved", t, func() {
+		viper.Set(key.DownloaderLayout, "{{ .Manga.Name")
+		chapter := &source.Chapter{Name: "Chapter 1", Manga: &source.Manga{Name: "One"}}
+
+		Convey("When it is downloaded", func() {
+			var events []*event.Event
+			_, err := Download(chapter, func(e *event.Event) {
+				events = append(events, e)
+			})
+
+			Convey("Then the error event should be emitted", func() {
+				So(err, ShouldNotBeNil)
+				So(events, ShouldNotBeEmpty)
+
+				last := events[len(events)-1]
+				So(last.Kind, ShouldEqual, event.Error)
+				So(last.Message, ShouldEqual, "Failed to download Chapter 1")
+				So(last.Error, ShouldEqual, err.Error())
+			})
+		})
+	})
+
+	Convey("Given the conversion event", t, func() {
+		chapter := &source.Chapter{Name: "Chapter 1", Manga: &source.Manga{Name: "One", DownloadFormat: "cbz"}}
+		e := converting(chapter, 10)
+
+		Convey("Then its message should be plain", func() {
+			So(e.Message, ShouldEqual, "Converting 10 pages to cbz (0 B)")
+			So(e.Format, ShouldEqual, "cbz")
+		})
+	})
+}
+
+func TestPageDownloadedEvent(t *testing.T) {
+	defer viper.Reset()
+
+	Convey("Given a chapter with pages", t, func() {
+		viper.Set(key.FormatsUse, constant.FormatPlain)
+		viper.Set(key.DownloaderChapterNameTemplate, "{chapter}")
+
+		// styles are rendered without colors when the output is not a terminal
+		profile := lipgloss.ColorProfile()
+		lipgloss.SetColorProfile(termenv.TrueColor)
+		defer lipgloss.SetColorProfile(profile)
+
+		chapter := &source.Chapter{Name: "Chapter 1", Manga: &source.Manga{Name: "One"}}
+		for i := 1; i <= 2; i++ {
+			chapter.Pages = append(chapter.Pages, &source.Page{
+				Index:     uint16(i),
+				Extension: "jpg",
+				Size:      2048,
+				Contents:  bytes.NewBufferString("page"),
+				Chapter:   chapter,
+			})
+		}
+
+		Convey("When its pages are downloaded", func() {
+			var (
+				mu     sync.Mutex
+				events []*event.Event
+			)
+			err := chapter.DownloadPages(true, func(e *event.Event) {
+				mu.Lock()
+				defer mu.Unlock()
+				events = append(events, e)
+			})
+
+			Convey("Then the messages of the page events should be plain", func() {
+				So(err, ShouldBeNil)
+				So(events, ShouldHaveLength, 2)
+				for _, e := range events {
+					So(e.Kind, ShouldEqual, event.PageDownloaded)
+					So(e.Message, ShouldStartWith, "Downloading Chapter 1: ")
+					So(e.Message, ShouldNotContainSubstring, "\x1b")
+				}
+			})
+		})
+	})
+}
+
+func TestEventStyle(t *testing.T) {
+	Convey("Given events of the chapter named like the size and the format", t, func() {
+		profile := lipgloss.ColorProfile()
+		lipgloss.SetColorProfile(termenv.TrueColor)
+		defer lipgloss.SetColorProfile(profile)
+
+		const name = "2.0 kB pdf special"
+		downloaded := &event.Event{Kind: event.PageDownloaded, ChapterName: name, Page: 1, Pages: 2, Bytes: 2048}
+		converted := &event.Event{Kind: event.Progress, ChapterName: name, Pages: 2, Bytes: 2048, Format: "pdf"}
+
+		Convey("When they are rendered", func() {
+			Convey("Then only the size and the format fields should be highlighted", func() {
+				So(style.Event(downloaded), ShouldEqual, fmt.Sprintf("Downloading %s: %s [1/2]", name, style.Faint("2.0 kB")))
+				So(style.Event(converted), ShouldEqual, fmt.Sprintf("Converting 2 pages to %s (2.0 kB)", style.Fg(color.Yellow)("pdf")))
+			})
+		})
+	})
+}
+
+// countingTransport counts requests and fails them
+type countingTransport struct {
+	requests atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	return nil, errors.New("network is not available")
+}
+
+func TestIsDownloadedWithoutMetadata(t *testing.T) {
+	defer viper.Reset()
+
+	Convey("Given a layout that refers to the metadata", t, func() {
+		viper.Set(key.DownloaderLayout, "{{ .Manga.Metadata.Year }}/{{ .Manga.Name }}/{{ .Chapter.Name }}")
+		viper.Set(key.MetadataFetchAnilist, true)
+
+		transport := &countingTransport{}
+		original := network.Client.Transport
+		network.Client.Transport = transport
+		defer func() { network.Client.Transport = original }()
+
+		chapter := &source.Chapter{Name: "Chapter 1", Manga: &source.Manga{Name: "One"}}
+
+		Convey("When it is checked if the chapter of the manga without metadata is downloaded", func() {
+			downloaded := chapter.IsDownloaded()
+
+			Convey("Then it should not be downloaded and nothing should be requested", func() {
+				So(downloaded, ShouldBeFalse)
+				So(transport.requests.Load(), ShouldEqual, 0)
+			})
+		})
+	})
+}
@@ -0,0 +1,82 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"github.com/metafates/mangal/event"
+	"github.com/metafates/mangal/fallback"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/source"
+)
+
+// pagesOf fetches pages of the chapter.
+// If it fails and fallback is enabled, pages of the same chapter from another source are used
+func pagesOf(chapter *source.Chapter, handler event.Handler) ([]*source.Page, error) {
+	var (
+		pages []*source.Page
+		err   error
+	)
+
+	if alternate, ok := chapter.Alternate().Get(); ok {
+		pages, err = fallback.PagesOf(alternate)
+		chapter.UseAlternate(alternate)
+	} else {
+		pages, err = chapter.Source().PagesOf(chapter)
+	}
+
+	if err == nil && len(pages) > 0 {
+		return pages, nil
+	}
+
+	if err == nil {
+		err = fmt.Errorf("no pages found for %s", chapter.Name)
+	}
+
+	if !canFallback(chapter) {
+		return nil, err
+	}
+
+	log.Warnf("failed to get pages of %s: %s", chapter.Name, err)
+	if fallbackErr := useFallback(chapter, handler); fallbackErr != nil {
+		log.Warn(fallbackErr)
+		return nil, err
+	}
+
+	return chapter.Pages, nil
+}
+
+// downloadPages downloads pages of the chapter.
+// If it fails and fallback is enabled, it is retried with the same chapter from another source
+func downloadPages(ctx context.Context, chapter *source.Chapter, temp bool, handler event.Handler) error {
+	err := chapter.DownloadPagesContext(ctx, temp, handler)
+	if err == nil || ctx.Err() != nil || !canFallback(chapter) {
+		return err
+	}
+
+	log.Warnf("failed to download pages of %s: %s", chapter.Name, err)
+	if fallbackErr := useFallback(chapter, handler); fallbackErr != nil {
+		log.Warn(fallbackErr)
+		return err
+	}
+
+	return chapter.DownloadPagesContext(ctx, temp, handler)
+}
+
+// canFallback checks if the chapter can be downloaded from another source
+func canFallback(chapter *source.Chapter) bool {
+	return fallback.Enabled() && chapter.Alternate().IsAbsent()
+}
+
+// useFallback finds the same chapter in other sources and makes the chapter use it
+func useFallback(chapter *source.Chapter, handler event.Handler) error {
+	handler.Emit(chapter.Event(event.Progress, fmt.Sprintf("Looking for %s in other sources", chapter.Name)))
+
+	alternate, err := fallback.Chapter(chapter)
+	if err != nil {
+		return err
+	}
+
+	chapter.UseAlternate(alternate)
+	handler.Emit(chapter.Event(event.Progress, fmt.Sprintf("Using %s from %s", alternate.Name, alternate.Source().Name())))
+	return nil
+}
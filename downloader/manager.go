@@ -0,0 +1,400 @@
+package downloader
+
+import (
+	"context"
+	"sync"
+
+	"github.com/metafates/mangal/event"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/source"
+)
+
+// JobStatus is the status of the background download
+type JobStatus string
+
+const (
+	JobQueued   JobStatus = "queued"
+	JobActive   JobStatus = "active"
+	JobPaused   JobStatus = "paused"
+	JobDone     JobStatus = "done"
+	JobFailed   JobStatus = "failed"
+	JobCanceled JobStatus = "canceled"
+)
+
+// Job is the chapter downloaded in the background by the Manager
+type Job struct {
+	ID      int
+	Chapter *source.Chapter
+
+	mu      sync.Mutex
+	status  JobStatus
+	page    int
+	pages   int
+	message string
+	path    string
+	err     error
+	cancel  context.CancelFunc
+	// run is increased every time the job is started,
+	// so that a canceled run that is still stopping does not change the retried one
+	run int
+	// resume is closed when the paused job is resumed or canceled
+	resume chan struct{}
+}
+
+// JobState is the snapshot of the job
+type JobState struct {
+	Status  JobStatus
+	Page    int
+	Pages   int
+	Message string
+	Path    string
+	Err     error
+}
+
+// Progress of the pages download from 0 to 1
+func (s JobState) Progress() float64 {
+	if s.Status == JobDone {
+		return 1
+	}
+
+	if s.Pages == 0 {
+		return 0
+	}
+
+	return float64(s.Page) / float64(s.Pages)
+}
+
+// Finished checks if the job will not change unless it is retried
+func (s JobState) Finished() bool {
+	return s.Status == JobDone || s.Status == JobFailed || s.Status == JobCanceled
+}
+
+// State returns the snapshot of the job
+func (j *Job) State() JobState {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return JobState{
+		Status:  j.status,
+		Page:    j.page,
+		Pages:   j.pages,
+		Message: j.message,
+		Path:    j.path,
+		Err:     j.err,
+	}
+}
+
+// Manager downloads queued chapters in the background with a pool of workers.
+// Jobs are started in the order they were queued
+type Manager struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	jobs    []*Job
+	workers int
+	started bool
+	nextID  int
+	updates chan struct{}
+	// download is replaced in tests
+	download func(ctx context.Context, chapter *source.Chapter, handler event.Handler) (string, error)
+}
+
+// NewManager creates a manager with the given number of workers.
+// Workers are started when the first job is queued
+func NewManager(workers int) *Manager {
+	if workers < 1 {
+		workers = 1
+	}
+
+	m := &Manager{
+		workers:  workers,
+		updates:  make(chan struct{}, 1),
+		download: DownloadContext,
+	}
+
+	m.cond = sync.NewCond(&m.mu)
+	return m
+}
+
+// Updates receives a value when any of the jobs has changed.
+// Notifications that were not received in time are merged into one
+func (m *Manager) Updates() <-chan struct{} {
+	return m.updates
+}
+
+func (m *Manager) notify() {
+	select {
+	case m.updates <- struct{}{}:
+	default:
+	}
+}
+
+// Add queues the chapters. Chapters that are already queued or being downloaded are skipped
+func (m *Manager) Add(chapters ...*source.Chapter) []*Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.started {
+		m.started = true
+		for i := 0; i < m.workers; i++ {
+			go m.work()
+		}
+	}
+
+	var added []*Job
+	for _, chapter := range chapters {
+		if m.pending(chapter) {
+			continue
+		}
+
+		m.nextID++
+		job := &Job{ID: m.nextID, Chapter: chapter, status: JobQueued}
+		m.jobs = append(m.jobs, job)
+		added = append(added, job)
+	}
+
+	m.cond.Broadcast()
+	m.notify()
+	return added
+}
+
+// pending checks if the chapter has an unfinished job
+func (m *Manager) pending(chapter *source.Chapter) bool {
+	for _, job := range m.jobs {
+		if job.Chapter == chapter && !job.State().Finished() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Jobs returns all jobs in the order they were queued
+func (m *Manager) Jobs() []*Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]*Job(nil), m.jobs...)
+}
+
+// Unfinished returns the number of jobs that are queued, active or paused
+func (m *Manager) Unfinished() (count int) {
+	for _, job := range m.Jobs() {
+		if !job.State().Finished() {
+			count++
+		}
+	}
+
+	return
+}
+
+// Pause pauses the job. Active job is stopped after the page it is downloading
+func (m *Manager) Pause(job *Job) {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+
+	if job.status != JobQueued && job.status != JobActive {
+		return
+	}
+
+	job.status = JobPaused
+	job.resume = make(chan struct{})
+	m.notify()
+}
+
+// Resume continues the paused job
+func (m *Manager) Resume(job *Job) {
+	job.mu.Lock()
+	if job.status != JobPaused {
+		job.mu.Unlock()
+		return
+	}
+
+	// job that was paused before it was started goes back to the queue
+	if job.cancel != nil {
+		job.status = JobActive
+	} else {
+		job.status = JobQueued
+	}
+
+	close(job.resume)
+	job.resume = nil
+	job.mu.Unlock()
+
+	m.wake()
+}
+
+// Cancel stops the job. Canceled job can be retried
+func (m *Manager) Cancel(job *Job) {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+
+	if job.status == JobDone || job.status == JobFailed || job.status == JobCanceled {
+		return
+	}
+
+	job.status = JobCanceled
+	job.message = "Canceled"
+
+	// the download may still be running, its result is ignored
+	job.run++
+	if job.cancel != nil {
+		job.cancel()
+		job.cancel = nil
+	}
+
+	if job.resume != nil {
+		close(job.resume)
+		job.resume = nil
+	}
+
+	m.notify()
+}
+
+// CancelAll cancels all unfinished jobs
+func (m *Manager) CancelAll() {
+	for _, job := range m.Jobs() {
+		m.Cancel(job)
+	}
+}
+
+// Retry queues the failed or canceled job again
+func (m *Manager) Retry(job *Job) {
+	job.mu.Lock()
+	if job.status != JobFailed && job.status != JobCanceled {
+		job.mu.Unlock()
+		return
+	}
+
+	job.status = JobQueued
+	job.page, job.pages = 0, 0
+	job.message, job.path, job.err = "", "", nil
+	job.mu.Unlock()
+
+	m.wake()
+}
+
+// ClearFinished removes done, failed and canceled jobs
+func (m *Manager) ClearFinished() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var jobs []*Job
+	for _, job := range m.jobs {
+		if !job.State().Finished() {
+			jobs = append(jobs, job)
+		}
+	}
+
+	m.jobs = jobs
+	m.notify()
+}
+
+// wake wakes up the idle workers and notifies about the change
+func (m *Manager) wake() {
+	m.mu.Lock()
+	m.cond.Broadcast()
+	m.mu.Unlock()
+	m.notify()
+}
+
+// work downloads queued jobs one by one
+func (m *Manager) work() {
+	for {
+		job, ctx, run := m.next()
+
+		path, err := m.download(ctx, job.Chapter, m.handler(ctx, job, run))
+		job.finish(run, path, err)
+		m.notify()
+	}
+}
+
+// next waits for the first queued job and marks it active
+func (m *Manager) next() (*Job, context.Context, int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for {
+		for _, job := range m.jobs {
+			job.mu.Lock()
+			if job.status == JobQueued {
+				ctx, cancel := context.WithCancel(context.Background())
+				job.status = JobActive
+				job.cancel = cancel
+				job.run++
+				run := job.run
+				job.mu.Unlock()
+				m.notify()
+				return job, ctx, run
+			}
+			job.mu.Unlock()
+		}
+
+		m.cond.Wait()
+	}
+}
+
+// handler tracks the progress of the job and blocks the download while the job is paused
+func (m *Manager) handler(ctx context.Context, job *Job, run int) event.Handler {
+	return func(e *event.Event) {
+		job.mu.Lock()
+		if job.run != run {
+			job.mu.Unlock()
+			return
+		}
+
+		if e.Kind == event.PageDownloaded {
+			job.page, job.pages = e.Page, e.Pages
+		}
+
+		if job.status != JobCanceled {
+			job.message = e.Message
+		}
+
+		resume := job.resume
+		job.mu.Unlock()
+		m.notify()
+
+		if resume != nil {
+			select {
+			case <-resume:
+			case <-ctx.Done():
+			}
+		}
+	}
+}
+
+// finish sets the result of the given run of the download.
+// Runs that were canceled and retried before they stopped are ignored
+func (j *Job) finish(run int, path string, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.run != run {
+		return
+	}
+
+	if j.cancel != nil {
+		j.cancel()
+		j.cancel = nil
+	}
+
+	if j.resume != nil {
+		close(j.resume)
+		j.resume = nil
+	}
+
+	if j.status == JobCanceled {
+		return
+	}
+
+	if err != nil {
+		log.Error(err)
+		j.status = JobFailed
+		j.err = err
+		j.message = err.Error()
+		return
+	}
+
+	j.status = JobDone
+	j.path = path
+}
@@ -0,0 +1,245 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/metafates/mangal/event"
+	"github.com/metafates/mangal/provider/generic"
+	"github.com/metafates/mangal/source"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// eventually waits for the condition to become true
+func eventually(condition func() bool) bool {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return true
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	return false
+}
+
+func statusOf(job *Job) func(JobStatus) func() bool {
+	return func(status JobStatus) func() bool {
+		return func() bool { return job.State().Status == status }
+	}
+}
+
+func TestManager(t *testing.T) {
+	Convey("Given a manager with one worker", t, func() {
+		manager := NewManager(1)
+		release := make(chan struct{})
+		var failing bool
+
+		manager.download = func(ctx context.Context, chapter *source.Chapter, handler event.Handler) (string, error) {
+			handler.Emit(&event.Event{Kind: event.PageDownloaded, Page: 1, Pages: 2})
+
+			select {
+			case <-release:
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+
+			handler.Emit(&event.Event{Kind: event.PageDownloaded, Page: 2, Pages: 2})
+			if failing {
+				return "", errors.New("failed")
+			}
+
+			return chapter.Name + ".cbz", nil
+		}
+
+		first, second := &source.Chapter{Name: "first"}, &source.Chapter{Name: "second"}
+
+		Convey("When chapters are added", func() {
+			jobs := manager.Add(first, second)
+
+			Convey("Then jobs are started in order", func() {
+				So(jobs, ShouldHaveLength, 2)
+				So(eventually(statusOf(jobs[0])(JobActive)), ShouldBeTrue)
+				So(jobs[1].State().Status, ShouldEqual, JobQueued)
+				So(eventually(func() bool { return jobs[0].State().Progress() == 0.5 }), ShouldBeTrue)
+
+				Convey("And the same chapter is not queued twice", func() {
+					So(manager.Add(first), ShouldBeEmpty)
+					So(manager.Jobs(), ShouldHaveLength, 2)
+				})
+
+				Convey("And all jobs are done when downloads are finished", func() {
+					close(release)
+					So(eventually(statusOf(jobs[1])(JobDone)), ShouldBeTrue)
+					So(jobs[0].State().Path, ShouldEqual, "first.cbz")
+					So(jobs[1].State().Progress(), ShouldEqual, 1)
+					So(manager.Unfinished(), ShouldEqual, 0)
+
+					Convey("And finished jobs can be cleared", func() {
+						manager.ClearFinished()
+						So(manager.Jobs(), ShouldBeEmpty)
+					})
+				})
+
+				Convey("And canceled job stops the download", func() {
+					manager.Cancel(jobs[0])
+					So(jobs[0].State().Status, ShouldEqual, JobCanceled)
+					So(eventually(statusOf(jobs[1])(JobActive)), ShouldBeTrue)
+
+					Convey("And it can be retried", func() {
+						close(release)
+						So(eventually(statusOf(jobs[1])(JobDone)), ShouldBeTrue)
+
+						manager.Retry(jobs[0])
+						So(eventually(statusOf(jobs[0])(JobDone)), ShouldBeTrue)
+					})
+				})
+
+				Convey("And paused job waits until it is resumed", func() {
+					manager.Pause(jobs[0])
+					close(release)
+					So(eventually(func() bool { return jobs[0].State().Page == 2 }), ShouldBeTrue)
+					So(jobs[0].State().Status, ShouldEqual, JobPaused)
+
+					manager.Resume(jobs[0])
+					So(eventually(statusOf(jobs[0])(JobDone)), ShouldBeTrue)
+				})
+			})
+		})
+
+		Convey("When the download fails", func() {
+			failing = true
+			job := manager.Add(first)[0]
+			close(release)
+
+			Convey("Then the job is marked as failed", func() {
+				So(eventually(statusOf(job)(JobFailed)), ShouldBeTrue)
+				So(job.State().Err, ShouldBeError)
+				So(manager.Add(first), ShouldHaveLength, 1)
+			})
+		})
+
+		Reset(func() {
+			manager.CancelAll()
+		})
+	})
+}
+
+func TestManagerCancelRetry(t *testing.T) {
+	Convey("Given a manager with two workers and a download that stops slowly", t, func() {
+		manager := NewManager(2)
+		stopped, release := make(chan struct{}), make(chan struct{})
+		var calls atomic.Int32
+
+		manager.download = func(ctx context.Context, chapter *source.Chapter, handler event.Handler) (string, error) {
+			if calls.Add(1) == 1 {
+				<-stopped
+				handler.Emit(&event.Event{Kind: event.PageDownloaded, Page: 1, Pages: 1})
+				return "", ctx.Err()
+			}
+
+			select {
+			case <-release:
+				return chapter.Name + ".cbz", nil
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		}
+
+		job := manager.Add(&source.Chapter{Name: "chapter"})[0]
+		So(eventually(statusOf(job)(JobActive)), ShouldBeTrue)
+
+		Convey("When the job is canceled and retried before the first run has stopped", func() {
+			manager.Cancel(job)
+			manager.Retry(job)
+			So(eventually(func() bool { return calls.Load() == 2 }), ShouldBeTrue)
+
+			close(stopped)
+			time.Sleep(10 * time.Millisecond)
+
+			Convey("Then the first run should not affect the retried one", func() {
+				So(job.State().Status, ShouldEqual, JobActive)
+				So(job.State().Pages, ShouldEqual, 0)
+
+				close(release)
+				So(eventually(statusOf(job)(JobDone)), ShouldBeTrue)
+				So(job.State().Path, ShouldEqual, "chapter.cbz")
+			})
+		})
+
+		Reset(func() {
+			manager.CancelAll()
+		})
+	})
+}
+
+func TestManagerGenericSource(t *testing.T) {
+	Convey("Given a manager with two workers and chapters of a generic source", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(5 * time.Millisecond)
+			_, _ = fmt.Fprintf(w, `<html><body><img src="%[1]s/1.png"><img src="%[1]s/2.png"></body></html>`, r.URL.Path)
+		}))
+		defer server.Close()
+
+		src := generic.New(&generic.Configuration{
+			Name:        "test",
+			Parallelism: 2,
+			BaseURL:     server.URL,
+			PageExtractor: &generic.Extractor{
+				Selector: "img",
+				URL: func(selection *goquery.Selection) string {
+					return selection.AttrOr("src", "")
+				},
+			},
+		})
+
+		manga := &source.Manga{Name: "manga", URL: server.URL + "/manga", Source: src}
+		var chapters []*source.Chapter
+		for i := 1; i <= 6; i++ {
+			chapters = append(chapters, &source.Chapter{
+				Name:  fmt.Sprintf("Chapter %d", i),
+				URL:   fmt.Sprintf("%s/chapter/%d", server.URL, i),
+				Index: uint16(i),
+				Manga: manga,
+			})
+		}
+
+		manager := NewManager(2)
+		manager.download = func(ctx context.Context, chapter *source.Chapter, handler event.Handler) (string, error) {
+			pages, err := chapter.PagesSource().PagesOf(chapter)
+			if err != nil {
+				return "", err
+			}
+
+			if len(pages) != 2 || pages[1].URL != fmt.Sprintf("/chapter/%d/2.png", chapter.Index) {
+				return "", fmt.Errorf("unexpected pages of %s", chapter.Name)
+			}
+
+			return chapter.Name, nil
+		}
+
+		Convey("When the chapters are downloaded concurrently", func() {
+			jobs := manager.Add(chapters...)
+
+			Convey("Then each job should get the pages of its chapter", func() {
+				for _, job := range jobs {
+					So(eventually(func() bool { return job.State().Status != JobQueued && job.State().Status != JobActive }), ShouldBeTrue)
+					So(job.State().Err, ShouldBeNil)
+					So(job.State().Status, ShouldEqual, JobDone)
+				}
+			})
+		})
+
+		Reset(func() {
+			manager.CancelAll()
+		})
+	})
+}
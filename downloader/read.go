@@ -1,22 +1,31 @@
 package downloader
 
 import (
+	"context"
 	"fmt"
-	"github.com/metafates/mangal/color"
 	"github.com/metafates/mangal/constant"
 	"github.com/metafates/mangal/converter"
+	"github.com/metafates/mangal/event"
 	"github.com/metafates/mangal/history"
 	"github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/log"
 	"github.com/metafates/mangal/open"
 	"github.com/metafates/mangal/source"
-	"github.com/metafates/mangal/style"
 	"github.com/spf13/viper"
 )
 
 // Read the chapter by downloading it with the given source
 // and opening it with the configured reader.
-func Read(chapter *source.Chapter, progress func(string)) error {
+// Error event is emitted whenever an error is returned
+func Read(chapter *source.Chapter, handler event.Handler) error {
+	if err := read(chapter, handler); err != nil {
+		return fail(chapter, handler, "read", err)
+	}
+
+	return nil
+}
+
+func read(chapter *source.Chapter, handler event.Handler) error {
 	if viper.GetBool(key.ReaderReadInBrowser) {
 		return open.StartWith(
 			chapter.URL,
@@ -27,56 +36,68 @@ func Read(chapter *source.Chapter, progress func(string)) error {
 	if viper.GetBool(key.DownloaderReadDownloaded) && chapter.IsDownloaded() {
 		path, err := chapter.Path(false)
 		if err == nil {
-			return openRead(path, chapter, progress)
+			return openRead(path, chapter, handler)
 		}
 	}
 
 	log.Infof("downloading %s for reading. Provider is %s", chapter.Name, chapter.Source().ID())
 	log.Infof("getting pages of %s", chapter.Name)
-	progress("Getting pages")
-	pages, err := chapter.Source().PagesOf(chapter)
+	handler.Emit(chapter.Event(event.Progress, "Getting pages"))
+	pages, err := pagesOf(chapter, handler)
 	if err != nil {
 		log.Error(err)
 		return err
 	}
 
-	err = chapter.DownloadPages(true, progress)
+	err = downloadPages(context.Background(), chapter, true, handler)
 	if err != nil {
 		log.Error(err)
 		return err
 	}
+	// pages could be replaced by the fallback
+	pages = chapter.Pages
 
-	log.Info("getting " + viper.GetString(key.FormatsUse) + " converter")
-	conv, err := converter.Get(viper.GetString(key.FormatsUse))
+	log.Info("getting " + chapter.Manga.Format() + " converter")
+	conv, err := converter.Get(chapter.Manga.Format())
 	if err != nil {
 		log.Error(err)
 		return err
 	}
 
-	log.Info("converting " + viper.GetString(key.FormatsUse))
-	progress(fmt.Sprintf(
-		"Converting %d pages to %s %s",
-		len(pages),
-		style.Fg(color.Yellow)(viper.GetString(key.FormatsUse)),
-		style.Faint(chapter.SizeHuman())),
-	)
+	log.Info("converting " + chapter.Manga.Format())
+	handler.Emit(converting(chapter, len(pages)))
 	path, err := conv.SaveTemp(chapter)
 	if err != nil {
 		log.Error(err)
 		return err
 	}
 
-	err = openRead(path, chapter, progress)
+	converted := chapter.Event(event.ChapterConverted, "Converted to "+chapter.Manga.Format())
+	converted.Pages = len(pages)
+	converted.Path = path
+	handler.Emit(converted)
+
+	err = openRead(path, chapter, handler)
 	if err != nil {
 		log.Error(err)
 		return err
 	}
 
-	progress("Done")
+	handler.Emit(chapter.Event(event.Progress, "Done"))
+	return nil
+}
+
+// Open the chapter that is already downloaded to the path with the configured reader.
+// Error event is emitted if it fails
+func Open(path string, chapter *source.Chapter, handler event.Handler) error {
+	if err := openRead(path, chapter, handler); err != nil {
+		return fail(chapter, handler, "open", err)
+	}
+
 	return nil
 }
 
-func openRead(path string, chapter *source.Chapter, progress func(string)) error {
+func openRead(path string, chapter *source.Chapter, handler event.Handler) error {
 	if viper.GetBool(key.HistorySaveOnRead) {
 		go func() {
 			err := history.Save(chapter)
@@ -93,7 +114,7 @@ func openRead(path string, chapter *source.Chapter, progress func(string)) error
 		err    error
 	)
 
-	switch viper.GetString(key.FormatsUse) {
+	switch chapter.Manga.Format() {
 	case constant.FormatPDF:
 		reader = viper.GetString(key.ReaderPDF)
 	case constant.FormatCBZ:
@@ -106,10 +127,10 @@ func openRead(path string, chapter *source.Chapter, progress func(string)) error
 
 	if reader != "" {
 		log.Info("opening with " + reader)
-		progress(fmt.Sprintf("Opening %s", reader))
+		handler.Emit(chapter.Event(event.Progress, fmt.Sprintf("Opening %s", reader)))
 	} else {
 		log.Info("no reader specified. opening with default")
-		progress("Opening")
+		handler.Emit(chapter.Event(event.Progress, "Opening"))
 	}
 
 	err = open.RunWith(path, reader)
@@ -0,0 +1,82 @@
+// Package event defines structured progress events that are emitted
+// while downloading and reading chapters.
+package event
+
+import "time"
+
+// Kind of the event
+type Kind string
+
+const (
+	// Progress is a generic status update, e.g. "Getting pages"
+	Progress Kind = "progress"
+	// PageDownloaded is emitted after each downloaded page
+	PageDownloaded Kind = "page_downloaded"
+	// ChapterConverted is emitted when the chapter is converted to the output format
+	ChapterConverted Kind = "chapter_converted"
+	// ChapterDownloaded is emitted when the chapter download is finished
+	ChapterDownloaded Kind = "chapter_downloaded"
+	// MetadataFetched is emitted when the manga metadata is populated
+	MetadataFetched Kind = "metadata_fetched"
+	// CoverDownloaded is emitted when the manga cover is saved
+	CoverDownloaded Kind = "cover_downloaded"
+	// Skipped is emitted when the chapter is already downloaded
+	Skipped Kind = "skipped"
+	// Error is emitted when something has failed
+	Error Kind = "error"
+)
+
+// Event is a single progress event
+type Event struct {
+	// Kind of the event
+	Kind Kind `json:"kind" jsonschema:"enum=progress,enum=page_downloaded,enum=chapter_converted,enum=chapter_downloaded,enum=metadata_fetched,enum=cover_downloaded,enum=skipped,enum=error,description=Kind of the event"`
+	// Time when the event has happened
+	Time time.Time `json:"time" jsonschema:"description=Time when the event has happened"`
+	// Message is a human-readable description of the event
+	Message string `json:"message" jsonschema:"description=Human-readable description of the event"`
+	// MangaID is an ID of the manga in the source
+	MangaID string `json:"manga_id,omitempty" jsonschema:"description=ID of the manga in the source"`
+	// MangaName is a name of the manga
+	MangaName string `json:"manga_name,omitempty" jsonschema:"description=Name of the manga"`
+	// ChapterID is an ID of the chapter in the source
+	ChapterID string `json:"chapter_id,omitempty" jsonschema:"description=ID of the chapter in the source"`
+	// ChapterName is a name of the chapter
+	ChapterName string `json:"chapter_name,omitempty" jsonschema:"description=Name of the chapter"`
+	// Page is a number of downloaded pages so far
+	Page int `json:"page,omitempty" jsonschema:"description=Number of downloaded pages so far"`
+	// Pages is a total number of pages in the chapter
+	Pages int `json:"pages,omitempty" jsonschema:"description=Total number of pages in the chapter"`
+	// Bytes is a number of bytes downloaded so far
+	Bytes uint64 `json:"bytes,omitempty" jsonschema:"description=Number of bytes downloaded so far"`
+	// Path of the saved file
+	Path string `json:"path,omitempty" jsonschema:"description=Path of the saved file"`
+	// Format that the chapter is converted to
+	Format string `json:"format,omitempty" jsonschema:"description=Format that the chapter is converted to"`
+	// Error message. Set only for error events
+	Error string `json:"error,omitempty" jsonschema:"description=Error message. Set only for error events"`
+}
+
+// String returns the message of the event
+func (e *Event) String() string {
+	return e.Message
+}
+
+// Handler receives emitted events. It must be safe for concurrent use
+type Handler func(*Event)
+
+// Discard is a handler that ignores all events
+func Discard(*Event) {}
+
+// Emit sets the event time and passes it to the handler.
+// Nil handler is allowed
+func (h Handler) Emit(e *Event) {
+	if h == nil {
+		return
+	}
+
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	h(e)
+}
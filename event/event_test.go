@@ -0,0 +1,36 @@
+package event
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHandler(t *testing.T) {
+	Convey("Given a handler", t, func() {
+		var received []*Event
+		handler := Handler(func(e *Event) {
+			received = append(received, e)
+		})
+
+		Convey("When emitting an event", func() {
+			handler.Emit(&Event{Kind: PageDownloaded, Page: 1, Pages: 2})
+
+			Convey("Then it should be received with the time set", func() {
+				So(received, ShouldHaveLength, 1)
+				So(received[0].Kind, ShouldEqual, PageDownloaded)
+				So(received[0].Time.IsZero(), ShouldBeFalse)
+			})
+		})
+	})
+
+	Convey("Given a nil handler", t, func() {
+		var handler Handler
+
+		Convey("When emitting an event", func() {
+			Convey("Then it should not panic", func() {
+				So(func() { handler.Emit(&Event{Kind: Progress}) }, ShouldNotPanic)
+			})
+		})
+	})
+}
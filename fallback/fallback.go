@@ -0,0 +1,256 @@
+// Package fallback finds the same manga in other sources
+// to download chapters that are missing or broken in the original one.
+package fallback
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/numbering"
+	"github.com/metafates/mangal/provider"
+	"github.com/metafates/mangal/search"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	"github.com/spf13/viper"
+	"sort"
+	"sync"
+)
+
+var (
+	// mu guards the maps below, it is not held while the sources are used
+	mu sync.Mutex
+	// loaded sources by provider name
+	loaded = make(map[string]source.Source)
+	// alternates found for mangas, by source id and url of the manga
+	alternates = make(map[string]*lookup)
+)
+
+// lookup of the alternates of the manga, shared by the concurrent calls for the same manga
+type lookup struct {
+	once  sync.Once
+	found []*source.Manga
+}
+
+// Enabled returns true if fallback is enabled in the config
+func Enabled() bool {
+	return viper.GetBool(key.DownloaderFallback)
+}
+
+// sources returns sources to fall back to except the given one
+func sources(except source.Source) []source.Source {
+	mu.Lock()
+	defer mu.Unlock()
+
+	names := viper.GetStringSlice(key.DownloaderFallbackSources)
+	if len(names) == 0 {
+		names = viper.GetStringSlice(key.DownloaderDefaultSources)
+	}
+
+	var result []source.Source
+	for _, name := range names {
+		if except != nil && name == except.Name() {
+			continue
+		}
+
+		src, ok := loaded[name]
+		if !ok {
+			p, found := provider.Get(name)
+			if !found {
+				log.Warnf("fallback source not found: %s", name)
+				continue
+			}
+
+			var err error
+			src, err = p.CreateSource()
+			if err != nil {
+				log.Warnf("failed to load fallback source %s: %s", name, err)
+				continue
+			}
+
+			loaded[name] = src
+		}
+
+		if except != nil && src.ID() == except.ID() {
+			continue
+		}
+
+		result = append(result, src)
+	}
+
+	return result
+}
+
+// Find returns the same manga from other sources.
+// Results are cached for the lifetime of the program.
+func Find(manga *source.Manga) []*source.Manga {
+	cacheKey := manga.URL
+	if manga.Source != nil {
+		cacheKey = manga.Source.ID() + " " + cacheKey
+	}
+
+	mu.Lock()
+	l, ok := alternates[cacheKey]
+	if !ok {
+		l = &lookup{}
+		alternates[cacheKey] = l
+	}
+	mu.Unlock()
+
+	l.once.Do(func() {
+		for _, src := range sources(manga.Source) {
+			candidates, err := src.Search(manga.Name)
+			if err != nil {
+				log.Warnf("fallback search on %s failed: %s", src.Name(), err)
+				continue
+			}
+
+			if closest, ok := search.Closest(manga, candidates); ok {
+				log.Infof("found %s on %s as %s", manga.Name, src.Name(), closest.Name)
+				l.found = append(l.found, closest)
+			}
+		}
+	})
+
+	return l.found
+}
+
+// sameChapter returns the chapter with the same number as the given one
+func sameChapter(chapter *source.Chapter, chapters []*source.Chapter) (*source.Chapter, bool) {
+	number, ok := chapter.ParsedNumber()
+	if !ok {
+		return nil, false
+	}
+
+	for _, c := range chapters {
+		if n, ok := c.ParsedNumber(); ok && n == number {
+			return c, true
+		}
+	}
+
+	return nil, false
+}
+
+// Chapter finds the same chapter in other sources, aligned by the chapter number,
+// and fetches its pages. The first alternate that has pages is returned.
+func Chapter(chapter *source.Chapter) (*source.Chapter, error) {
+	for _, manga := range Find(chapter.Manga) {
+		chapters, err := manga.Source.ChaptersOf(manga)
+		if err != nil {
+			log.Warn(err)
+			continue
+		}
+
+		alternate, ok := sameChapter(chapter, chapters)
+		if !ok {
+			continue
+		}
+
+		pages, err := manga.Source.PagesOf(alternate)
+		if err != nil || len(pages) == 0 {
+			log.Warnf("%s from %s has no pages: %v", alternate.Name, manga.Source.Name(), err)
+			continue
+		}
+
+		return alternate, nil
+	}
+
+	return nil, fmt.Errorf("%s was not found in other sources", chapter.Name)
+}
+
+// PagesOf fetches pages of the chapter that belongs to a fallback source
+func PagesOf(chapter *source.Chapter) ([]*source.Page, error) {
+	return chapter.Source().PagesOf(chapter)
+}
+
+// Complete adds chapters that are missing in the given list of the manga chapters
+// but present in other sources. Chapters are aligned by their numbers.
+// Added chapters belong to the given manga and download pages from their alternates.
+func Complete(manga *source.Manga, chapters []*source.Chapter) []*source.Chapter {
+	present := make(map[numbering.Chapter]struct{})
+	for _, chapter := range chapters {
+		if n, ok := chapter.ParsedNumber(); ok {
+			present[n] = struct{}{}
+		}
+	}
+
+	var missing []*source.Chapter
+	for _, alternate := range Find(manga) {
+		altChapters, err := alternate.Source.ChaptersOf(alternate)
+		if err != nil {
+			log.Warn(err)
+			continue
+		}
+
+		for _, altChapter := range altChapters {
+			n, ok := altChapter.ParsedNumber()
+			if !ok {
+				continue
+			}
+
+			if _, ok := present[n]; ok {
+				continue
+			}
+
+			present[n] = struct{}{}
+			chapter := &source.Chapter{
+				Name:         altChapter.Name,
+				URL:          altChapter.URL,
+				ID:           altChapter.ID,
+				Number:       &n.Number,
+				Volume:       altChapter.Volume,
+				VolumeNumber: altChapter.VolumeNumber,
+				Manga:        manga,
+			}
+			chapter.UseAlternate(altChapter)
+			missing = append(missing, chapter)
+		}
+	}
+
+	if len(missing) == 0 {
+		return chapters
+	}
+
+	log.Infof("found %d chapters of %s missing in %s", len(missing), manga.Name, manga.Source.Name())
+
+	all := merge(chapters, missing)
+	manga.Chapters = all
+	return all
+}
+
+// merge sorts the present and the missing chapters by their numbers, chapters without numbers go last.
+// Indices of the present chapters are kept as is, since they are used in the file names.
+// Missing chapters get the indices after the largest present one, in the merged order
+func merge(chapters, missing []*source.Chapter) []*source.Chapter {
+	all := make([]*source.Chapter, 0, len(chapters)+len(missing))
+	all = append(all, chapters...)
+	all = append(all, missing...)
+	sort.SliceStable(all, func(i, j int) bool {
+		a, aok := all[i].ParsedNumber()
+		b, bok := all[j].ParsedNumber()
+		if aok != bok {
+			return aok
+		}
+
+		return aok && a.Less(b)
+	})
+
+	var last uint16
+	for _, chapter := range chapters {
+		if chapter.Index > last {
+			last = chapter.Index
+		}
+	}
+
+	added := lo.SliceToMap(missing, func(chapter *source.Chapter) (*source.Chapter, struct{}) {
+		return chapter, struct{}{}
+	})
+
+	for _, chapter := range all {
+		if _, ok := added[chapter]; ok {
+			last++
+			chapter.Index = last
+		}
+	}
+
+	return all
+}
@@ -0,0 +1,44 @@
+package fallback
+
+import (
+	"testing"
+
+	"github.com/metafates/mangal/source"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMerge(t *testing.T) {
+	Convey("Given present chapters and the missing ones from another source", t, func() {
+		chapters := []*source.Chapter{
+			{Name: "Chapter 1", Index: 1},
+			{Name: "Extra", Index: 2},
+			{Name: "Chapter 3", Index: 3},
+		}
+
+		missing := []*source.Chapter{
+			{Name: "Chapter 2.5"},
+			{Name: "Chapter 2"},
+		}
+
+		Convey("When merging them", func() {
+			all := merge(chapters, missing)
+
+			Convey("Then chapters should be ordered by numbers with the unnumbered last", func() {
+				names := make([]string, len(all))
+				for i, chapter := range all {
+					names[i] = chapter.Name
+				}
+
+				So(names, ShouldResemble, []string{"Chapter 1", "Chapter 2", "Chapter 2.5", "Chapter 3", "Extra"})
+			})
+
+			Convey("Then missing chapters should get unique indices after the present ones", func() {
+				So(all[0].Index, ShouldEqual, 1)
+				So(all[1].Index, ShouldEqual, 4)
+				So(all[2].Index, ShouldEqual, 5)
+				So(all[3].Index, ShouldEqual, 3)
+				So(all[4].Index, ShouldEqual, 2)
+			})
+		})
+	})
+}
@@ -15,10 +15,17 @@ type SavedChapter struct {
 	ID                 string `json:"id"`
 	Index              int    `json:"index"`
 	MangaID            string `json:"manga_id"`
+	// Read are the URLs of all read chapters of the manga
+	Read []string `json:"read,omitempty"`
 }
 
 func (c *SavedChapter) encode() string {
-	return fmt.Sprintf("%s (%s)", c.MangaName, c.SourceID)
+	return encode(c.MangaName, c.SourceID)
+}
+
+// encode returns the key of the manga in the history file
+func encode(mangaName, sourceID string) string {
+	return fmt.Sprintf("%s (%s)", mangaName, sourceID)
 }
 
 func (c *SavedChapter) String() string {
@@ -1,6 +1,9 @@
 package history
 
 import (
+	"maps"
+	"sync"
+
 	"github.com/metafates/gache"
 	"github.com/metafates/mangal/filesystem"
 	"github.com/metafates/mangal/integration"
@@ -18,6 +21,10 @@ var cacher = gache.New[map[string]*SavedChapter](
 	},
 )
 
+// mu guards read-modify-write of the history and visits files,
+// chapters are saved concurrently by the download workers and the reader
+var mu sync.Mutex
+
 // Get returns all chapters from the history file
 func Get() (chapters map[string]*SavedChapter, err error) {
 	cached, expired, err := cacher.Get()
@@ -45,12 +52,27 @@ func Save(chapter *source.Chapter) error {
 		}()
 	}
 
+	mu.Lock()
+	defer mu.Unlock()
+
 	saved, err := Get()
 	if err != nil {
 		return err
 	}
 
+	// the map is shared with the other callers of Get, so it's not mutated in place
+	saved = maps.Clone(saved)
+
 	savedChapter := newSavedChapter(chapter)
+	savedChapter.Read = []string{chapter.URL}
+	if previous, ok := saved[savedChapter.encode()]; ok {
+		for _, url := range previous.Read {
+			if url != chapter.URL {
+				savedChapter.Read = append(savedChapter.Read, url)
+			}
+		}
+	}
+
 	saved[savedChapter.encode()] = savedChapter
 
 	return cacher.Set(saved)
@@ -58,11 +80,15 @@ func Save(chapter *source.Chapter) error {
 
 // Remove removes the chapter from the history file
 func Remove(chapter *SavedChapter) error {
+	mu.Lock()
+	defer mu.Unlock()
+
 	saved, err := Get()
 	if err != nil {
 		return err
 	}
 
+	saved = maps.Clone(saved)
 	delete(saved, chapter.encode())
 
 	return cacher.Set(saved)
@@ -5,6 +5,7 @@ import (
 	"github.com/metafates/mangal/filesystem"
 	"github.com/metafates/mangal/source"
 	. "github.com/smartystreets/goconvey/convey"
+	"sync"
 	"testing"
 )
 
@@ -68,3 +69,111 @@ func TestHistory(t *testing.T) {
 		})
 	})
 }
+
+func TestProgress(t *testing.T) {
+	Convey("Given the chapters of a manga", t, func() {
+		manga := source.Manga{Name: "progress", Source: testSource{}}
+		chapters := make([]*source.Chapter, 5)
+		for i := range chapters {
+			chapters[i] = &source.Chapter{
+				Name:  fmt.Sprintf("Chapter %d", i+1),
+				URL:   fmt.Sprintf("https://example.com/%d", i+1),
+				Index: uint16(i + 1),
+				Manga: &manga,
+			}
+		}
+		manga.Chapters = chapters
+
+		Convey("When nothing was read", func() {
+			progress, err := ProgressOf(&manga)
+			So(err, ShouldBeNil)
+
+			Convey("Then all chapters should be unread", func() {
+				So(progress.Unread(chapters), ShouldResemble, chapters)
+				So(progress.FromLastRead(chapters), ShouldResemble, chapters)
+			})
+		})
+
+		Convey("When some chapters were read", func() {
+			So(Save(chapters[0]), ShouldBeNil)
+			So(Save(chapters[2]), ShouldBeNil)
+
+			progress, err := ProgressOf(&manga)
+			So(err, ShouldBeNil)
+
+			Convey("Then only they should be read", func() {
+				So(progress.IsRead(chapters[0]), ShouldBeTrue)
+				So(progress.IsRead(chapters[1]), ShouldBeFalse)
+				So(progress.Unread(chapters), ShouldResemble, []*source.Chapter{chapters[1], chapters[3], chapters[4]})
+			})
+
+			Convey("Then chapters from the last read one should be selected", func() {
+				So(progress.FromLastRead(chapters), ShouldResemble, chapters[2:])
+			})
+		})
+	})
+}
+
+func TestRecordVisit(t *testing.T) {
+	Convey("Given a manga", t, func() {
+		manga := source.Manga{Name: "visited", Source: testSource{}}
+		chapter := func(n int) *source.Chapter {
+			return &source.Chapter{Name: fmt.Sprintf("Chapter %d", n), URL: fmt.Sprintf("https://example.com/%d", n), Manga: &manga}
+		}
+
+		Convey("When it is visited for the first time", func() {
+			fresh, err := RecordVisit(&manga, []*source.Chapter{chapter(1), chapter(2)})
+
+			Convey("Then nothing should be new", func() {
+				So(err, ShouldBeNil)
+				So(fresh, ShouldBeEmpty)
+
+				Convey("And the chapters added later should be new", func() {
+					third := chapter(3)
+					fresh, err = RecordVisit(&manga, []*source.Chapter{chapter(1), chapter(2), third})
+					So(err, ShouldBeNil)
+					So(fresh, ShouldResemble, []*source.Chapter{third})
+				})
+			})
+		})
+	})
+}
+
+func TestSaveConcurrently(t *testing.T) {
+	Convey("Given the chapters of a manga", t, func() {
+		manga := source.Manga{Name: "concurrent", Source: testSource{}}
+		chapters := make([]*source.Chapter, 20)
+		for i := range chapters {
+			chapters[i] = &source.Chapter{
+				Name:  fmt.Sprintf("Chapter %d", i+1),
+				URL:   fmt.Sprintf("https://example.com/concurrent/%d", i+1),
+				Index: uint16(i + 1),
+				Manga: &manga,
+			}
+		}
+		manga.Chapters = chapters
+
+		Convey("When they are saved concurrently", func() {
+			var wg sync.WaitGroup
+			errs := make([]error, len(chapters))
+			for i, chapter := range chapters {
+				wg.Add(1)
+				go func(i int, chapter *source.Chapter) {
+					defer wg.Done()
+					errs[i] = Save(chapter)
+				}(i, chapter)
+			}
+			wg.Wait()
+
+			Convey("Then every chapter should be read", func() {
+				for _, err := range errs {
+					So(err, ShouldBeNil)
+				}
+
+				progress, err := ProgressOf(&manga)
+				So(err, ShouldBeNil)
+				So(progress.Unread(chapters), ShouldBeEmpty)
+			})
+		})
+	})
+}
@@ -0,0 +1,79 @@
+package history
+
+import (
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+)
+
+// Progress is the reading progress of the manga
+type Progress struct {
+	// Last is the last read chapter
+	Last mo.Option[*SavedChapter]
+
+	read map[string]struct{}
+}
+
+// ProgressOf returns the reading progress of the manga
+func ProgressOf(manga *source.Manga) (*Progress, error) {
+	saved, err := Get()
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &Progress{read: make(map[string]struct{})}
+
+	last, ok := saved[encode(manga.Name, manga.Source.ID())]
+	if !ok {
+		return progress, nil
+	}
+
+	progress.Last = mo.Some(last)
+	for _, url := range last.Read {
+		progress.read[url] = struct{}{}
+	}
+
+	return progress, nil
+}
+
+// IsRead checks if the chapter was read.
+// History saved by the older versions has only the last read chapter,
+// chapters before it are considered read in that case
+func (p *Progress) IsRead(chapter *source.Chapter) bool {
+	if _, ok := p.read[chapter.URL]; ok {
+		return true
+	}
+
+	last, ok := p.Last.Get()
+	if !ok || len(last.Read) > 0 {
+		return false
+	}
+
+	return chapter.URL == last.URL || int(chapter.Index) < last.Index
+}
+
+// Unread returns the chapters that were not read
+func (p *Progress) Unread(chapters []*source.Chapter) []*source.Chapter {
+	return lo.Filter(chapters, func(chapter *source.Chapter, _ int) bool {
+		return !p.IsRead(chapter)
+	})
+}
+
+// FromLastRead returns the chapters starting from the last read one.
+// All chapters are returned if the last read chapter is not among them
+func (p *Progress) FromLastRead(chapters []*source.Chapter) []*source.Chapter {
+	last, ok := p.Last.Get()
+	if !ok {
+		return chapters
+	}
+
+	_, index, found := lo.FindIndexOf(chapters, func(chapter *source.Chapter) bool {
+		return chapter.URL == last.URL
+	})
+
+	if !found {
+		return chapters
+	}
+
+	return chapters[index:]
+}
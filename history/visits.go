@@ -0,0 +1,68 @@
+package history
+
+import (
+	"maps"
+	"time"
+
+	"github.com/metafates/gache"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/where"
+	"github.com/samber/lo"
+)
+
+// Visit is the list of chapters seen on the last visit of the manga
+type Visit struct {
+	// Chapters are the URLs of the chapters
+	Chapters []string `json:"chapters"`
+	// Time of the visit
+	Time time.Time `json:"time"`
+}
+
+var visitsCacher = gache.New[map[string]*Visit](
+	&gache.Options{
+		Path:       where.Visits(),
+		FileSystem: &filesystem.GacheFs{},
+	},
+)
+
+// RecordVisit saves the chapters of the manga and returns the ones that were not there on the previous visit.
+// Nothing is new on the first visit
+func RecordVisit(manga *source.Manga, chapters []*source.Chapter) ([]*source.Chapter, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	visits, expired, err := visitsCacher.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	if expired || visits == nil {
+		visits = make(map[string]*Visit)
+	} else {
+		visits = maps.Clone(visits)
+	}
+
+	key := encode(manga.Name, manga.Source.ID())
+
+	var fresh []*source.Chapter
+	if previous, ok := visits[key]; ok {
+		seen := lo.SliceToMap(previous.Chapters, func(url string) (string, struct{}) {
+			return url, struct{}{}
+		})
+
+		fresh = lo.Filter(chapters, func(chapter *source.Chapter, _ int) bool {
+			_, ok := seen[chapter.URL]
+			return !ok
+		})
+	}
+
+	visits[key] = &Visit{
+		Chapters: lo.Map(chapters, func(chapter *source.Chapter, _ int) string {
+			return chapter.URL
+		}),
+		Time: time.Now(),
+	}
+
+	return fresh, visitsCacher.Set(visits)
+}
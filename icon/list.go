@@ -18,6 +18,8 @@ const (
 	Progress
 	Search
 	Link
+	Read
+	New
 )
 
 var icons = map[Icon]*iconDef{
@@ -91,4 +93,18 @@ var icons = map[Icon]*iconDef{
 		kaomoji: style.Fg(color.Blue)("⌐■-■"),
 		squares: style.Fg(color.Blue)("◪"),
 	},
+	Read: {
+		emoji:   "👀",
+		nerd:    style.Faint("\uF06E"),
+		plain:   style.Faint("R"),
+		kaomoji: style.Faint("(⊙_⊙)"),
+		squares: style.Faint("◫"),
+	},
+	New: {
+		emoji:   "🆕",
+		nerd:    style.Fg(color.Yellow)("\uF005"),
+		plain:   style.New().Bold(true).Foreground(color.Yellow).Render("N"),
+		kaomoji: style.Fg(color.Yellow)("☆ﾟ"),
+		squares: style.Fg(color.Yellow)("◆"),
+	},
 }
@@ -0,0 +1,25 @@
+package inline
+
+import (
+	"encoding/json"
+	"github.com/metafates/mangal/event"
+	"github.com/metafates/mangal/log"
+	"io"
+	"sync"
+)
+
+// eventsWriter returns a handler that writes each event
+// as a single line of JSON (NDJSON) to the given writer
+func eventsWriter(out io.Writer) event.Handler {
+	var mu sync.Mutex
+	encoder := json.NewEncoder(out)
+
+	return func(e *event.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if err := encoder.Encode(e); err != nil {
+			log.Warn(err)
+		}
+	}
+}
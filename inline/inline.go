@@ -2,8 +2,11 @@ package inline
 
 import (
 	"github.com/metafates/mangal/downloader"
+	"github.com/metafates/mangal/event"
+	"github.com/metafates/mangal/fallback"
 	"github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/search"
 	"github.com/metafates/mangal/source"
 	"github.com/spf13/viper"
 	"os"
@@ -14,20 +17,15 @@ func Run(options *Options) (err error) {
 		options.Out = os.Stdout
 	}
 
-	var mangas []*source.Manga
-	for _, src := range options.Sources {
-		m, err := src.Search(options.Query)
-		if err != nil {
-			return err
-		}
-
-		mangas = append(mangas, m...)
+	mangas, err := find(options)
+	if err != nil {
+		return err
 	}
 
 	if options.MangaPicker.IsAbsent() && options.ChaptersFilter.IsAbsent() {
 		if viper.GetBool(key.MetadataFetchAnilist) {
 			for _, manga := range mangas {
-				_ = manga.PopulateMetadata(func(string) {})
+				_ = manga.PopulateMetadata(event.Discard)
 			}
 		}
 
@@ -95,6 +93,10 @@ func Run(options *Options) (err error) {
 		return err
 	}
 
+	if fallback.Enabled() {
+		chapters = fallback.Complete(manga, chapters)
+	}
+
 	if options.ChaptersFilter.IsPresent() {
 		chapters, err = options.ChaptersFilter.MustGet()(chapters)
 		if err != nil {
@@ -116,9 +118,14 @@ func Run(options *Options) (err error) {
 		return err
 	}
 
+	handler := event.Handler(event.Discard)
+	if options.Events {
+		handler = eventsWriter(options.Out)
+	}
+
 	for _, chapter := range chapters {
 		if options.Download {
-			path, err := downloader.Download(chapter, func(string) {})
+			path, err := downloader.Download(chapter, handler)
 			if err != nil {
 				if viper.GetBool(key.DownloaderStopOnError) {
 					return err
@@ -127,12 +134,17 @@ func Run(options *Options) (err error) {
 				continue
 			}
 
+			// paths are already included in the events
+			if options.Events {
+				continue
+			}
+
 			_, err = options.Out.Write([]byte(path + "\n"))
 			if err != nil {
 				log.Warn(err)
 			}
 		} else {
-			err := downloader.Read(chapter, func(string) {})
+			err := downloader.Read(chapter, handler)
 			if err != nil {
 				return err
 			}
@@ -141,3 +153,31 @@ func Run(options *Options) (err error) {
 
 	return nil
 }
+
+// find searches the mangas by the query, the manga of the options is used without searching if set
+func find(options *Options) ([]*source.Manga, error) {
+	if options.Manga != nil {
+		return []*source.Manga{options.Manga}, nil
+	}
+
+	results := search.Sources(options.Sources, options.Query, search.Timeout())
+	mangas, err := search.Mangas(results)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.Group {
+		groups := search.Merge(results)
+		search.CountChapters(groups, search.Timeout())
+
+		// pick the most complete entry of each group
+		mangas = make([]*source.Manga, len(groups))
+		options.groups = make(map[*source.Manga]*search.Group, len(groups))
+		for i, group := range groups {
+			mangas[i] = group.Best().Manga
+			options.groups[mangas[i]] = group
+		}
+	}
+
+	return mangas, nil
+}
@@ -3,6 +3,7 @@ package inline
 import (
 	"encoding/json"
 	"github.com/metafates/mangal/anilist"
+	"github.com/metafates/mangal/event"
 	"github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/source"
 	"github.com/spf13/viper"
@@ -15,6 +16,20 @@ type Manga struct {
 	Mangal *source.Manga `json:"mangal" jsonschema:"description=Mangal variant of the manga"`
 	// Anilist is the closest anilist match to mangal manga
 	Anilist *anilist.Manga `json:"anilist" jsonschema:"description=Anilist is the closest anilist match to mangal manga"`
+	// Sources that carry the same manga. Set only when results are grouped
+	Sources []*SourceEntry `json:"sources,omitempty" jsonschema:"description=Sources that carry the same manga. Set only when results are grouped"`
+}
+
+// SourceEntry is the same manga found in another source
+type SourceEntry struct {
+	// Source that carries the manga
+	Source string `json:"source" jsonschema:"description=Source that carries the manga"`
+	// URL of the manga in the source
+	URL string `json:"url" jsonschema:"description=URL of the manga in the source"`
+	// Chapters is the number of chapters in the source
+	Chapters int `json:"chapters" jsonschema:"description=Number of chapters in the source"`
+	// Error is set if chapters could not be counted
+	Error string `json:"error,omitempty" jsonschema:"description=Set if chapters could not be counted"`
 }
 
 type Output struct {
@@ -35,6 +50,17 @@ func asJson(manga []*source.Manga, options *Options) (marshalled []byte, err err
 			Anilist: al,
 			Source:  manga.Source.Name(),
 		}
+
+		if group, ok := options.groups[manga]; ok {
+			for _, entry := range group.Entries {
+				m[i].Sources = append(m[i].Sources, &SourceEntry{
+					Source:   entry.Source,
+					URL:      entry.Manga.URL,
+					Chapters: entry.Chapters,
+					Error:    entry.Error,
+				})
+			}
+		}
 	}
 
 	return json.Marshal(&Output{
@@ -80,7 +106,7 @@ func prepareManga(manga *source.Manga, options *Options) error {
 	}
 
 	if viper.GetBool(key.MetadataFetchAnilist) {
-		_ = manga.PopulateMetadata(func(string) {})
+		_ = manga.PopulateMetadata(event.Discard)
 	}
 
 	return nil
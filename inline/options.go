@@ -2,14 +2,13 @@ package inline
 
 import (
 	"fmt"
+	"github.com/metafates/mangal/search"
 	"github.com/metafates/mangal/source"
 	"github.com/metafates/mangal/util"
-	"github.com/samber/lo"
 	"github.com/samber/mo"
 	"io"
 	"regexp"
 	"strconv"
-	"strings"
 )
 
 type (
@@ -23,10 +22,17 @@ type Options struct {
 	IncludeAnilistManga bool
 	Download            bool
 	Json                bool
+	Events              bool
+	Group               bool
 	PopulatePages       bool
 	Query               string
-	MangaPicker         mo.Option[MangaPicker]
-	ChaptersFilter      mo.Option[ChaptersFilter]
+	// Manga is used instead of searching by the query if set
+	Manga          *source.Manga
+	MangaPicker    mo.Option[MangaPicker]
+	ChaptersFilter mo.Option[ChaptersFilter]
+
+	// groups of the found mangas, set if Group is true
+	groups map[*source.Manga]*search.Group
 }
 
 func ParseMangaPicker(query, description string) (MangaPicker, error) {
@@ -43,6 +49,14 @@ func ParseMangaPicker(query, description string) (MangaPicker, error) {
 		return nil, fmt.Errorf("invalid manga picker pattern: %s", description)
 	}
 
+	var index int
+	if description != first && description != last && description != exact {
+		var err error
+		if index, err = strconv.Atoi(description); err != nil {
+			return nil, fmt.Errorf("invalid manga picker pattern: %s: index is too large", description)
+		}
+	}
+
 	return func(mangas []*source.Manga) *source.Manga {
 		if len(mangas) == 0 {
 			return nil
@@ -62,66 +76,7 @@ func ParseMangaPicker(query, description string) (MangaPicker, error) {
 
 			return nil
 		default:
-			index := lo.Must(strconv.ParseUint(description, 10, 16))
-			return mangas[util.Min(index, uint64(len(mangas)-1))]
-		}
-	}, nil
-}
-
-func ParseChaptersFilter(description string) (ChaptersFilter, error) {
-	const (
-		first = "first"
-		last  = "last"
-		all   = "all"
-		from  = "From"
-		to    = "To"
-		sub   = "Sub"
-	)
-
-	pattern := fmt.Sprintf(`^(%s|%s|%s|(?P<%s>\d+)(-(?P<%s>\d+))?|@(?P<%s>.+)@)$`, first, last, all, from, to, sub)
-	mangaPickerRegex := regexp.MustCompile(pattern)
-
-	if !mangaPickerRegex.MatchString(description) {
-		return nil, fmt.Errorf("invalid chapter filter pattern: %s", description)
-	}
-
-	return func(chapters []*source.Chapter) ([]*source.Chapter, error) {
-		if len(chapters) == 0 {
-			return chapters, nil
-		}
-
-		switch description {
-		case first:
-			return chapters[0:1], nil
-		case last:
-			return chapters[len(chapters)-1:], nil
-		case all:
-			return chapters, nil
-		default:
-			groups := util.ReGroups(mangaPickerRegex, description)
-
-			if sub, ok := groups[sub]; ok && sub != "" {
-				return lo.Filter(chapters, func(a *source.Chapter, _ int) bool {
-					return strings.Contains(a.Name, sub)
-				}), nil
-			}
-
-			from := lo.Must(strconv.ParseUint(groups[from], 10, 16))
-			from = util.Min(from, uint64(len(chapters)))
-
-			n := groups[to]
-			if n == "" {
-				return []*source.Chapter{chapters[from]}, nil
-			}
-
-			to := lo.Must(strconv.ParseUint(n, 10, 16))
-			to = util.Min(to, uint64(len(chapters)))
-
-			if from > to {
-				from, to = to, from
-			}
-
-			return chapters[from : to+1], nil
+			return mangas[util.Min(index, len(mangas)-1)]
 		}
 	}, nil
 }
@@ -0,0 +1,27 @@
+package inline
+
+import (
+	"github.com/invopop/jsonschema"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// NewSchemaReflector returns a jsonschema reflector that is used
+// to generate schemas for every JSON output of the mangal.
+// Types that share the same name across packages are prefixed with the package name.
+func NewSchemaReflector() *jsonschema.Reflector {
+	reflector := new(jsonschema.Reflector)
+	reflector.Anonymous = true
+	reflector.Namer = func(t reflect.Type) string {
+		name := t.Name()
+		switch strings.ToLower(name) {
+		case "manga", "chapter", "page", "date", "output", "provider", "event":
+			return filepath.Base(t.PkgPath()) + "." + name
+		}
+
+		return name
+	}
+
+	return reflector
+}
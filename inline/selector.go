@@ -0,0 +1,295 @@
+package inline
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/metafates/mangal/history"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+)
+
+// SelectorSyntax describes the chapters selector
+const SelectorSyntax = `Selector is a comma separated list of terms, chapters matching any of them are selected.
+Terms prefixed with ! exclude chapters instead, all chapters are selected if there are only exclusions.
+
+  first, last, all - first, last or all chapters in the list
+  [n], [from]-[to], [from]- - chapters by positions in the list, starting from 0
+  c[n], c[from]-[to], c[from]- - chapters by their numbers, e.g. c12, c10.5, c100-
+  v[n], v[from]-[to], v[from]- - chapters of the volumes, e.g. v3, v3-5
+  latest:[n] - last n chapters in the list
+  unread - chapters that were not read
+  new - chapters that were added since the last time they were selected with new
+  not-downloaded - chapters that are not downloaded
+  from-last-read - chapters starting from the last read one
+  @[substring]@ - chapters with the substring in the name
+  /[regex]/ - chapters with the name matching the regular expression
+
+Example: "v1-3,c30-,!@Extra@"`
+
+// requirement is the state that must be loaded before the term is matched
+type requirement int
+
+const (
+	requiresProgress requirement = 1 << iota
+	requiresVisit
+)
+
+// term is a single term of the chapters selector
+type term struct {
+	negated  bool
+	requires requirement
+	match    func(s *selection, index int) bool
+}
+
+// selection is the state of the chapters that terms are matched against
+type selection struct {
+	chapters     []*source.Chapter
+	progress     *history.Progress
+	fromLastRead map[*source.Chapter]struct{}
+	fresh        map[*source.Chapter]struct{}
+}
+
+var (
+	positionsTerm = regexp.MustCompile(`^(\d+)(?:(-)(\d+)?)?$`)
+	numbersTerm   = regexp.MustCompile(`^([cv])(\d+(?:\.\d+)?)(?:(-)(\d+(?:\.\d+)?)?)?$`)
+	latestTerm    = regexp.MustCompile(`^latest:(.*)$`)
+)
+
+// ParseChaptersFilter parses the chapters selector, see SelectorSyntax
+func ParseChaptersFilter(description string) (ChaptersFilter, error) {
+	var terms []*term
+
+	for i, text := range strings.Split(description, ",") {
+		t, err := parseTerm(strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("invalid chapters selector %q: term %d: %w", description, i+1, err)
+		}
+
+		terms = append(terms, t)
+	}
+
+	return func(chapters []*source.Chapter) ([]*source.Chapter, error) {
+		if len(chapters) == 0 {
+			return chapters, nil
+		}
+
+		s, err := newSelection(chapters, terms)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.filter(terms), nil
+	}, nil
+}
+
+// parseTerm parses a single term of the selector
+func parseTerm(text string) (*term, error) {
+	if text == "" {
+		return nil, errors.New("empty term")
+	}
+
+	if strings.HasPrefix(text, "!") {
+		t, err := parseTerm(strings.TrimPrefix(text, "!"))
+		if err != nil {
+			return nil, err
+		}
+
+		if t.negated {
+			return nil, fmt.Errorf("%q: double negation", text)
+		}
+
+		t.negated = true
+		return t, nil
+	}
+
+	switch text {
+	case "first":
+		return &term{match: func(_ *selection, index int) bool { return index == 0 }}, nil
+	case "last":
+		return &term{match: func(s *selection, index int) bool { return index == len(s.chapters)-1 }}, nil
+	case "all":
+		return &term{match: func(*selection, int) bool { return true }}, nil
+	case "unread":
+		return &term{
+			requires: requiresProgress,
+			match: func(s *selection, index int) bool {
+				return !s.progress.IsRead(s.chapters[index])
+			},
+		}, nil
+	case "from-last-read":
+		return &term{
+			requires: requiresProgress,
+			match: func(s *selection, index int) bool {
+				_, ok := s.fromLastRead[s.chapters[index]]
+				return ok
+			},
+		}, nil
+	case "new":
+		return &term{
+			requires: requiresVisit,
+			match: func(s *selection, index int) bool {
+				_, ok := s.fresh[s.chapters[index]]
+				return ok
+			},
+		}, nil
+	case "not-downloaded":
+		return &term{match: func(s *selection, index int) bool {
+			return !s.chapters[index].IsDownloaded()
+		}}, nil
+	}
+
+	switch {
+	case len(text) >= 2 && strings.HasPrefix(text, "@") && strings.HasSuffix(text, "@"):
+		substring := text[1 : len(text)-1]
+		if substring == "" {
+			return nil, fmt.Errorf("%q: empty substring", text)
+		}
+
+		return &term{match: func(s *selection, index int) bool {
+			return strings.Contains(s.chapters[index].Name, substring)
+		}}, nil
+	case len(text) >= 2 && strings.HasPrefix(text, "/") && strings.HasSuffix(text, "/"):
+		re, err := regexp.Compile(text[1 : len(text)-1])
+		if err != nil {
+			return nil, fmt.Errorf("%q: invalid regular expression: %w", text, err)
+		}
+
+		return &term{match: func(s *selection, index int) bool {
+			return re.MatchString(s.chapters[index].Name)
+		}}, nil
+	case latestTerm.MatchString(text):
+		count, err := strconv.Atoi(latestTerm.FindStringSubmatch(text)[1])
+		if err != nil || count <= 0 {
+			return nil, fmt.Errorf("%q: expected a positive number of chapters after latest:", text)
+		}
+
+		return &term{match: func(s *selection, index int) bool {
+			return index >= len(s.chapters)-count
+		}}, nil
+	case positionsTerm.MatchString(text):
+		groups := positionsTerm.FindStringSubmatch(text)
+
+		from, err := strconv.Atoi(groups[1])
+		if err != nil {
+			return nil, fmt.Errorf("%q: position is too large", text)
+		}
+
+		to := from
+		if groups[2] != "" {
+			to = math.MaxInt
+		}
+
+		if groups[3] != "" {
+			if to, err = strconv.Atoi(groups[3]); err != nil {
+				return nil, fmt.Errorf("%q: position is too large", text)
+			}
+		}
+
+		if from > to {
+			return nil, fmt.Errorf("%q: range start is greater than its end", text)
+		}
+
+		return &term{match: func(_ *selection, index int) bool {
+			return from <= index && index <= to
+		}}, nil
+	case numbersTerm.MatchString(text):
+		groups := numbersTerm.FindStringSubmatch(text)
+
+		from, _ := strconv.ParseFloat(groups[2], 64)
+		to := from
+		if groups[3] != "" {
+			to = math.Inf(1)
+		}
+
+		if groups[4] != "" {
+			to, _ = strconv.ParseFloat(groups[4], 64)
+		}
+
+		if from > to {
+			return nil, fmt.Errorf("%q: range start is greater than its end", text)
+		}
+
+		number := func(chapter *source.Chapter) (float64, bool) {
+			parsed, ok := chapter.ParsedNumber()
+			return parsed.Number, ok
+		}
+
+		if groups[1] == "v" {
+			number = (*source.Chapter).ParsedVolume
+		}
+
+		return &term{match: func(s *selection, index int) bool {
+			n, ok := number(s.chapters[index])
+			return ok && from <= n && n <= to
+		}}, nil
+	default:
+		return nil, fmt.Errorf("unknown term %q", text)
+	}
+}
+
+// newSelection loads the state required by the terms
+func newSelection(chapters []*source.Chapter, terms []*term) (*selection, error) {
+	s := &selection{chapters: chapters}
+
+	var requires requirement
+	for _, t := range terms {
+		requires |= t.requires
+	}
+
+	manga := chapters[0].Manga
+
+	if requires&requiresProgress != 0 {
+		progress, err := history.ProgressOf(manga)
+		if err != nil {
+			return nil, err
+		}
+
+		s.progress = progress
+		s.fromLastRead = lo.SliceToMap(progress.FromLastRead(chapters), func(chapter *source.Chapter) (*source.Chapter, struct{}) {
+			return chapter, struct{}{}
+		})
+	}
+
+	if requires&requiresVisit != 0 {
+		fresh, err := history.RecordVisit(manga, chapters)
+		if err != nil {
+			return nil, err
+		}
+
+		s.fresh = lo.SliceToMap(fresh, func(chapter *source.Chapter) (*source.Chapter, struct{}) {
+			return chapter, struct{}{}
+		})
+	}
+
+	return s, nil
+}
+
+// filter returns the chapters matching any of the terms and none of the negated ones, in the list order
+func (s *selection) filter(terms []*term) []*source.Chapter {
+	included := lo.Filter(terms, func(t *term, _ int) bool { return !t.negated })
+	excluded := lo.Filter(terms, func(t *term, _ int) bool { return t.negated })
+
+	matches := func(terms []*term, index int) bool {
+		return lo.SomeBy(terms, func(t *term) bool { return t.match(s, index) })
+	}
+
+	selected := make([]*source.Chapter, 0)
+	for i, chapter := range s.chapters {
+		if len(included) > 0 && !matches(included, i) {
+			continue
+		}
+
+		if matches(excluded, i) {
+			continue
+		}
+
+		selected = append(selected, chapter)
+	}
+
+	return selected
+}
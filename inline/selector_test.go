@@ -0,0 +1,214 @@
+package inline
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/history"
+	"github.com/metafates/mangal/source"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type testSource struct{}
+
+func (testSource) Name() string {
+	return "test"
+}
+
+func (testSource) Search(_ string) ([]*source.Manga, error) {
+	panic("")
+}
+
+func (testSource) ChaptersOf(_ *source.Manga) ([]*source.Chapter, error) {
+	panic("")
+}
+
+func (testSource) PagesOf(_ *source.Chapter) ([]*source.Page, error) {
+	panic("")
+}
+
+func (testSource) ID() string {
+	return "test source"
+}
+
+func init() {
+	filesystem.SetMemMapFs()
+}
+
+// testChapters returns chapters 1-3 of volume 1, extra chapter 3.5 and chapters 4-6 of volume 2
+func testChapters(mangaName string) []*source.Chapter {
+	manga := &source.Manga{Name: mangaName, Source: testSource{}}
+
+	var chapters []*source.Chapter
+	add := func(name, volume string) {
+		chapters = append(chapters, &source.Chapter{
+			Name:   name,
+			Volume: volume,
+			URL:    fmt.Sprintf("https://example.com/%d", len(chapters)),
+			Index:  uint16(len(chapters) + 1),
+			Manga:  manga,
+		})
+	}
+
+	for i := 1; i <= 3; i++ {
+		add(fmt.Sprintf("Chapter %d", i), "Vol. 1")
+	}
+
+	add("Chapter 3.5 Extra", "Vol. 1")
+
+	for i := 4; i <= 6; i++ {
+		add(fmt.Sprintf("Chapter %d", i), "Vol. 2")
+	}
+
+	manga.Chapters = chapters
+	return chapters
+}
+
+func names(chapters []*source.Chapter) []string {
+	result := make([]string, len(chapters))
+	for i, chapter := range chapters {
+		result[i] = chapter.Name
+	}
+
+	return result
+}
+
+func TestParseChaptersFilter(t *testing.T) {
+	Convey("Given the selectors", t, func() {
+		cases := []struct {
+			selector string
+			expected []string
+		}{
+			{"first", []string{"Chapter 1"}},
+			{"last", []string{"Chapter 6"}},
+			{"all", []string{"Chapter 1", "Chapter 2", "Chapter 3", "Chapter 3.5 Extra", "Chapter 4", "Chapter 5", "Chapter 6"}},
+			{"0", []string{"Chapter 1"}},
+			{"1-2", []string{"Chapter 2", "Chapter 3"}},
+			{"5-", []string{"Chapter 5", "Chapter 6"}},
+			{"100", []string{}},
+			{"c3", []string{"Chapter 3"}},
+			{"c3-4", []string{"Chapter 3", "Chapter 3.5 Extra", "Chapter 4"}},
+			{"c3.5", []string{"Chapter 3.5 Extra"}},
+			{"c5-", []string{"Chapter 5", "Chapter 6"}},
+			{"v2", []string{"Chapter 4", "Chapter 5", "Chapter 6"}},
+			{"v1-2", []string{"Chapter 1", "Chapter 2", "Chapter 3", "Chapter 3.5 Extra", "Chapter 4", "Chapter 5", "Chapter 6"}},
+			{"v3", []string{}},
+			{"latest:2", []string{"Chapter 5", "Chapter 6"}},
+			{"latest:100", []string{"Chapter 1", "Chapter 2", "Chapter 3", "Chapter 3.5 Extra", "Chapter 4", "Chapter 5", "Chapter 6"}},
+			{"@Extra@", []string{"Chapter 3.5 Extra"}},
+			{`/^Chapter [15]$/`, []string{"Chapter 1", "Chapter 5"}},
+			{"first, last", []string{"Chapter 1", "Chapter 6"}},
+			{"c6,c1", []string{"Chapter 1", "Chapter 6"}},
+			{"v1,!@Extra@", []string{"Chapter 1", "Chapter 2", "Chapter 3"}},
+			{"!v1", []string{"Chapter 4", "Chapter 5", "Chapter 6"}},
+			{"!v1,!last", []string{"Chapter 4", "Chapter 5"}},
+		}
+
+		for _, c := range cases {
+			c := c
+			Convey(fmt.Sprintf("When %q is applied", c.selector), func() {
+				filter, err := ParseChaptersFilter(c.selector)
+				So(err, ShouldBeNil)
+
+				selected, err := filter(testChapters("selectors"))
+				So(err, ShouldBeNil)
+
+				Convey("Then the expected chapters should be selected", func() {
+					So(names(selected), ShouldResemble, c.expected)
+				})
+			})
+		}
+	})
+
+	Convey("Given the invalid selectors", t, func() {
+		cases := []struct {
+			selector string
+			message  string
+		}{
+			{"", "term 1: empty term"},
+			{"first,,last", "term 2: empty term"},
+			{"chapter 1", `unknown term "chapter 1"`},
+			{"3-1", `"3-1": range start is greater than its end`},
+			{"c5-3", `"c5-3": range start is greater than its end`},
+			{"v3-a", `unknown term "v3-a"`},
+			{"latest:0", `"latest:0": expected a positive number of chapters after latest:`},
+			{"latest:many", `"latest:many": expected a positive number of chapters after latest:`},
+			{"/[/", `"/[/": invalid regular expression`},
+			{"@@", `"@@": empty substring`},
+			{"!!first", `"!!first": double negation`},
+			{"99999999999999999999", "position is too large"},
+		}
+
+		for _, c := range cases {
+			c := c
+			Convey(fmt.Sprintf("When %q is parsed", c.selector), func() {
+				_, err := ParseChaptersFilter(c.selector)
+
+				Convey("Then the error should describe the problem", func() {
+					So(err, ShouldNotBeNil)
+					So(err.Error(), ShouldContainSubstring, c.message)
+				})
+			})
+		}
+	})
+
+	Convey("Given the read chapters", t, func() {
+		chapters := testChapters("progress")
+		So(history.Save(chapters[0]), ShouldBeNil)
+		So(history.Save(chapters[2]), ShouldBeNil)
+
+		cases := []struct {
+			selector string
+			expected []string
+		}{
+			{"unread", []string{"Chapter 2", "Chapter 3.5 Extra", "Chapter 4", "Chapter 5", "Chapter 6"}},
+			{"from-last-read", []string{"Chapter 3", "Chapter 3.5 Extra", "Chapter 4", "Chapter 5", "Chapter 6"}},
+			{"unread,!v2", []string{"Chapter 2", "Chapter 3.5 Extra"}},
+		}
+
+		for _, c := range cases {
+			c := c
+			Convey(fmt.Sprintf("When %q is applied", c.selector), func() {
+				filter, err := ParseChaptersFilter(c.selector)
+				So(err, ShouldBeNil)
+
+				selected, err := filter(chapters)
+				So(err, ShouldBeNil)
+
+				Convey("Then the expected chapters should be selected", func() {
+					So(names(selected), ShouldResemble, c.expected)
+				})
+			})
+		}
+	})
+
+	Convey("Given the chapters selected with new", t, func() {
+		filter, err := ParseChaptersFilter("new")
+		So(err, ShouldBeNil)
+
+		chapters := testChapters("visits")
+		selected, err := filter(chapters[:5])
+		So(err, ShouldBeNil)
+		So(selected, ShouldBeEmpty)
+
+		Convey("When more chapters are released", func() {
+			selected, err = filter(chapters)
+			So(err, ShouldBeNil)
+
+			Convey("Then only they should be new", func() {
+				So(names(selected), ShouldResemble, []string{"Chapter 5", "Chapter 6"})
+			})
+		})
+	})
+}
+
+func TestParseMangaPicker(t *testing.T) {
+	Convey("Given the manga picker with a too large index", t, func() {
+		_, err := ParseMangaPicker("query", "99999999999999999999")
+
+		Convey("Then error should be returned instead of panic", func() {
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -1,7 +1,10 @@
 package installer
 
 import (
+	"errors"
+	"fmt"
 	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
 	"github.com/metafates/mangal/util"
 	"github.com/samber/lo"
 	"github.com/spf13/viper"
@@ -10,9 +13,72 @@ import (
 
 var collector *githubFilesCollector
 
-// Scrapers gets available scrapers from GitHub repo.
+// Repositories returns the locations of the scraper repositories in the order of priority.
+// The GitHub repo from the installer config is used if none are set
+func Repositories() []string {
+	repositories := viper.GetStringSlice(key.InstallerRepositories)
+	if len(repositories) == 0 {
+		return []string{defaultRepository()}
+	}
+
+	return repositories
+}
+
+// defaultRepository is the index in the GitHub repo from the installer config
+func defaultRepository() string {
+	return fmt.Sprintf(
+		"https://raw.githubusercontent.com/%s/%s/%s/%s",
+		viper.GetString(key.InstallerUser),
+		viper.GetString(key.InstallerRepo),
+		viper.GetString(key.InstallerBranch),
+		IndexFilename,
+	)
+}
+
+// Scrapers gets available scrapers from the repositories.
+// If several repositories have the scraper with the same name, the first one is used.
 // See https://github.com/metafates/mangal-scrapers
 func Scrapers() ([]*Scraper, error) {
+	var (
+		scrapers = make([]*Scraper, 0)
+		names    = make(map[string]struct{})
+		errs     []error
+	)
+
+	for _, repository := range Repositories() {
+		found, err := repositoryScrapers(repository)
+
+		// GitHub repo without index, list its files instead
+		if errors.Is(err, errNotFound) && repository == defaultRepository() {
+			found, err = githubScrapers()
+		}
+
+		if err != nil {
+			log.Warnf("failed to list scrapers of %s: %s", repository, err)
+			errs = append(errs, fmt.Errorf("%s: %w", repository, err))
+			continue
+		}
+
+		for _, scraper := range found {
+			if _, ok := names[scraper.Name]; ok {
+				continue
+			}
+
+			names[scraper.Name] = struct{}{}
+			scrapers = append(scrapers, scraper)
+		}
+	}
+
+	if len(scrapers) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return scrapers, nil
+}
+
+// githubScrapers lists every lua file of the GitHub repo.
+// Such scrapers have no versions and checksums
+func githubScrapers() ([]*Scraper, error) {
 	if collector == nil {
 		setupCollector()
 	}
@@ -28,8 +94,11 @@ func Scrapers() ([]*Scraper, error) {
 		}
 
 		return &Scraper{
-			Name: util.FileStem(filepath.Base(f.Path)),
-			URL:  f.Url,
+			Name:       util.FileStem(filepath.Base(f.Path)),
+			URL:        f.Url,
+			Repository: defaultRepository(),
+			Extension:  ".lua",
+			github:     true,
 		}, true
 	}), nil
 }
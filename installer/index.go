@@ -0,0 +1,146 @@
+package installer
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/metafates/mangal/provider"
+	"github.com/metafates/mangal/version"
+	"path/filepath"
+	"strings"
+)
+
+// IndexFilename is the name of the index file in the repository directory
+const IndexFilename = "index.json"
+
+// Index is the manifest of the scrapers repository:
+//
+//	{
+//	  "scrapers": [
+//	    {
+//	      "name": "example",
+//	      "version": "1.0.0",
+//	      "file": "scrapers/example.lua",
+//	      "sha256": "…",
+//	      "min_mangal_version": "4.0.0",
+//	      "description": "Example scraper",
+//	      "dependencies": ["common"]
+//	    }
+//	  ]
+//	}
+type Index struct {
+	Scrapers []*Entry `json:"scrapers"`
+}
+
+// Entry is a scraper listed in the index
+type Entry struct {
+	// Name of the scraper, used as the installed file name
+	Name string `json:"name"`
+	// Version of the scraper in major.minor.patch format
+	Version string `json:"version"`
+	// File of the scraper relative to the index, or absolute URL
+	File string `json:"file"`
+	// SHA256 checksum of the file in hex
+	SHA256 string `json:"sha256"`
+	// MinVersion is the minimal version of mangal that the scraper works with
+	MinVersion string `json:"min_mangal_version,omitempty"`
+	// Description of the scraper
+	Description string `json:"description,omitempty"`
+	// Dependencies are the names of scrapers from the same index that must be installed with this one
+	Dependencies []string `json:"dependencies,omitempty"`
+}
+
+// parseIndex parses the index and validates its entries
+func parseIndex(data []byte) (*Index, error) {
+	var index Index
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, err
+	}
+
+	var (
+		errs  []error
+		names = make(map[string]struct{}, len(index.Scrapers))
+	)
+
+	for i, entry := range index.Scrapers {
+		if err := entry.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("scrapers[%d]: %w", i, err))
+			continue
+		}
+
+		if _, ok := names[entry.Name]; ok {
+			errs = append(errs, fmt.Errorf("scrapers[%d]: %s is listed more than once", i, entry.Name))
+		}
+
+		names[entry.Name] = struct{}{}
+	}
+
+	for i, entry := range index.Scrapers {
+		for _, dependency := range entry.Dependencies {
+			if _, ok := names[dependency]; !ok {
+				errs = append(errs, fmt.Errorf("scrapers[%d]: dependency %s is not in the index", i, dependency))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return &index, nil
+}
+
+func (e *Entry) validate() error {
+	if e.Name == "" {
+		return errors.New("name is required")
+	}
+
+	if strings.ContainsAny(e.Name, `/\`) {
+		return fmt.Errorf("name %q must not contain path separators", e.Name)
+	}
+
+	if _, err := version.Compare(e.Version, e.Version); err != nil {
+		return fmt.Errorf("%s: invalid version %q, expected major.minor.patch", e.Name, e.Version)
+	}
+
+	if e.MinVersion != "" {
+		if _, err := version.Compare(e.MinVersion, e.MinVersion); err != nil {
+			return fmt.Errorf("%s: invalid min_mangal_version %q, expected major.minor.patch", e.Name, e.MinVersion)
+		}
+	}
+
+	if e.File == "" {
+		return fmt.Errorf("%s: file is required", e.Name)
+	}
+
+	if !provider.IsCustomSource(e.File) {
+		return fmt.Errorf("%s: file %s is not a lua or declarative scraper", e.Name, e.File)
+	}
+
+	if checksum, err := hex.DecodeString(e.SHA256); err != nil || len(checksum) != 32 {
+		return fmt.Errorf("%s: sha256 must be 64 hex characters", e.Name)
+	}
+
+	return nil
+}
+
+// scraper converts the entry to the scraper, file location is resolved relative to the index
+func (e *Entry) scraper(repository, index string) (*Scraper, error) {
+	location, err := resolve(index, e.File)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Scraper{
+		Name:         e.Name,
+		URL:          location,
+		Description:  e.Description,
+		Version:      e.Version,
+		Checksum:     strings.ToLower(e.SHA256),
+		MinVersion:   e.MinVersion,
+		Dependencies: e.Dependencies,
+		Repository:   repository,
+		Extension:    filepath.Ext(e.File),
+	}, nil
+}
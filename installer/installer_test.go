@@ -0,0 +1,220 @@
+package installer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/where"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func checksum(contents string) string {
+	sum := sha256.Sum256([]byte(contents))
+	return hex.EncodeToString(sum[:])
+}
+
+func writeRepository(t *testing.T, dir string, entries []*Entry, files map[string]string) {
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		So(os.MkdirAll(filepath.Dir(path), os.ModePerm), ShouldBeNil)
+		So(os.WriteFile(path, []byte(contents), os.ModePerm), ShouldBeNil)
+	}
+
+	data, err := json.Marshal(Index{Scrapers: entries})
+	So(err, ShouldBeNil)
+	So(os.WriteFile(filepath.Join(dir, IndexFilename), data, os.ModePerm), ShouldBeNil)
+}
+
+func TestInstaller(t *testing.T) {
+	defer viper.Reset()
+	t.Setenv(where.EnvConfigPath, t.TempDir())
+
+	const (
+		common    = "-- common\n"
+		example   = "-- example v1\n"
+		exampleV2 = "-- example v2\n"
+	)
+
+	Convey("Given a local repository", t, func() {
+		repository := t.TempDir()
+		viper.Set(key.InstallerRepositories, []string{"file://" + filepath.ToSlash(repository)})
+
+		writeRepository(t, repository, []*Entry{
+			{Name: "common", Version: "1.0.0", File: "scrapers/common.lua", SHA256: checksum(common)},
+			{Name: "example", Version: "1.0.0", File: "scrapers/example.lua", SHA256: checksum(example), Dependencies: []string{"common"}},
+			{Name: "future", Version: "1.0.0", File: "scrapers/future.lua", SHA256: checksum(example), MinVersion: "99.0.0"},
+			{Name: "tampered", Version: "1.0.0", File: "scrapers/tampered.lua", SHA256: checksum("something else")},
+		}, map[string]string{
+			"scrapers/common.lua":   common,
+			"scrapers/example.lua":  example,
+			"scrapers/future.lua":   example,
+			"scrapers/tampered.lua": example,
+		})
+
+		Convey("When installing a scraper", func() {
+			installed, err := Install("example")
+
+			Convey("Then it should be installed with dependencies", func() {
+				So(err, ShouldBeNil)
+				So(installed, ShouldHaveLength, 2)
+				So(installed[0].Name, ShouldEqual, "common")
+				So(installed[1].Name, ShouldEqual, "example")
+
+				contents, err := os.ReadFile(filepath.Join(where.Sources(), "example.lua"))
+				So(err, ShouldBeNil)
+				So(string(contents), ShouldEqual, example)
+			})
+
+			Convey("Then it should be pinned in the lockfile", func() {
+				lock, err := ReadLock()
+				So(err, ShouldBeNil)
+				So(lock["example"].Version, ShouldEqual, "1.0.0")
+				So(lock["example"].SHA256, ShouldEqual, checksum(example))
+				So(lock["common"].File, ShouldEqual, "common.lua")
+			})
+
+			Convey("And a newer version is released", func() {
+				writeRepository(t, repository, []*Entry{
+					{Name: "common", Version: "1.0.0", File: "scrapers/common.lua", SHA256: checksum(common)},
+					{Name: "example", Version: "1.1.0", File: "scrapers/example.lua", SHA256: checksum(exampleV2), Dependencies: []string{"common"}},
+				}, map[string]string{"scrapers/example.lua": exampleV2})
+
+				outdated, err := CheckOutdated()
+
+				Convey("Then it should be outdated", func() {
+					So(err, ShouldBeNil)
+					So(outdated, ShouldHaveLength, 1)
+					So(*outdated[0], ShouldResemble, Outdated{
+						Name:       "example",
+						Installed:  "1.0.0",
+						Available:  "1.1.0",
+						Repository: "file://" + filepath.ToSlash(repository),
+					})
+				})
+
+				Convey("Then it should be updated", func() {
+					updated, err := Update()
+					So(err, ShouldBeNil)
+					So(updated, ShouldHaveLength, 1)
+					So(updated[0].Version, ShouldEqual, "1.1.0")
+
+					lock, err := ReadLock()
+					So(err, ShouldBeNil)
+					So(lock["example"].Version, ShouldEqual, "1.1.0")
+
+					outdated, err := CheckOutdated()
+					So(err, ShouldBeNil)
+					So(outdated, ShouldBeEmpty)
+				})
+			})
+
+			Convey("And it is removed", func() {
+				So(Unlock("example"), ShouldBeNil)
+
+				Convey("Then it should not be in the lockfile", func() {
+					lock, err := ReadLock()
+					So(err, ShouldBeNil)
+					So(lock, ShouldNotContainKey, "example")
+					So(lock, ShouldContainKey, "common")
+				})
+			})
+		})
+
+		Convey("When installing a scraper of the listing", func() {
+			scrapers, err := Scrapers()
+			So(err, ShouldBeNil)
+
+			example, ok := lo.Find(scrapers, func(scraper *Scraper) bool {
+				return scraper.Name == "example"
+			})
+			So(ok, ShouldBeTrue)
+
+			Convey("Then its dependencies should be resolved from the listing", func() {
+				So(example.Install(scrapers), ShouldBeNil)
+
+				lock, err := ReadLock()
+				So(err, ShouldBeNil)
+				So(lock, ShouldContainKey, "example")
+				So(lock, ShouldContainKey, "common")
+			})
+		})
+
+		Convey("When installing a scraper with wrong checksum", func() {
+			_, err := Install("tampered")
+
+			Convey("Then error should be returned", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "checksum mismatch")
+			})
+		})
+
+		Convey("When installing a scraper for newer mangal", func() {
+			_, err := Install("future")
+
+			Convey("Then error should be returned", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "requires mangal 99.0.0 or newer")
+			})
+		})
+
+		Convey("When installing unknown scraper", func() {
+			_, err := Install("unknown")
+
+			Convey("Then error should be returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+
+	Convey("Given several repositories with the same scraper", t, func() {
+		first, second := t.TempDir(), t.TempDir()
+		viper.Set(key.InstallerRepositories, []string{first, second})
+
+		writeRepository(t, first, []*Entry{
+			{Name: "example", Version: "1.0.0", File: "example.lua", SHA256: checksum(example)},
+		}, map[string]string{"example.lua": example})
+		writeRepository(t, second, []*Entry{
+			{Name: "example", Version: "2.0.0", File: "example.lua", SHA256: checksum(exampleV2)},
+			{Name: "other", Version: "1.0.0", File: "other.lua", SHA256: checksum(exampleV2)},
+		}, map[string]string{"example.lua": exampleV2, "other.lua": exampleV2})
+
+		Convey("When listing scrapers", func() {
+			scrapers, err := Scrapers()
+
+			Convey("Then the first repository should take priority", func() {
+				So(err, ShouldBeNil)
+				So(scrapers, ShouldHaveLength, 2)
+				So(scrapers[0].Version, ShouldEqual, "1.0.0")
+				So(scrapers[0].Repository, ShouldEqual, first)
+				So(scrapers[1].Name, ShouldEqual, "other")
+			})
+		})
+	})
+}
+
+func TestParseIndex(t *testing.T) {
+	Convey("Given an invalid index", t, func() {
+		data := []byte(`{"scrapers": [
+			{"name": "a", "version": "1", "file": "a.lua", "sha256": "00"},
+			{"name": "b", "version": "1.0.0", "file": "b.txt", "sha256": "` + checksum("") + `"},
+			{"name": "c", "version": "1.0.0", "file": "c.lua", "sha256": "` + checksum("") + `", "dependencies": ["d"]}
+		]}`)
+
+		Convey("When parsing it", func() {
+			_, err := parseIndex(data)
+
+			Convey("Then every problem should be reported", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, `invalid version "1"`)
+				So(err.Error(), ShouldContainSubstring, "b.txt is not a lua or declarative scraper")
+				So(err.Error(), ShouldContainSubstring, "dependency d is not in the index")
+			})
+		})
+	})
+}
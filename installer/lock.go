@@ -0,0 +1,77 @@
+package installer
+
+import (
+	"encoding/json"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/where"
+	"os"
+	"path/filepath"
+)
+
+// LockFilename is the name of the lockfile in the sources directory.
+// It has no json extension, so it is not mistaken for a declarative scraper
+const LockFilename = "sources.lock"
+
+// Locked is the installed scraper pinned in the lockfile
+type Locked struct {
+	// Version of the installed scraper, empty if the repository has no index
+	Version string `json:"version"`
+	// SHA256 checksum of the installed file
+	SHA256 string `json:"sha256"`
+	// Repository that the scraper was installed from
+	Repository string `json:"repository"`
+	// File is the name of the installed file
+	File string `json:"file"`
+}
+
+// Lock is the lockfile with installed scrapers by their names
+type Lock map[string]*Locked
+
+// LockPath returns the path of the lockfile
+func LockPath() string {
+	return filepath.Join(where.Sources(), LockFilename)
+}
+
+// ReadLock reads the lockfile, empty lock is returned if there is none
+func ReadLock() (Lock, error) {
+	data, err := filesystem.Api().ReadFile(LockPath())
+	if os.IsNotExist(err) {
+		return make(Lock), nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	lock := make(Lock)
+	if err = json.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+
+	return lock, nil
+}
+
+// Save writes the lockfile
+func (l Lock) Save() error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return filesystem.Api().WriteFile(LockPath(), data, os.ModePerm)
+}
+
+// Unlock removes the scraper from the lockfile
+func Unlock(name string) error {
+	lock, err := ReadLock()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := lock[name]; !ok {
+		return nil
+	}
+
+	delete(lock, name)
+	return lock.Save()
+}
@@ -0,0 +1,220 @@
+package installer
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/version"
+	"github.com/samber/lo"
+	"sort"
+)
+
+// Outdated is an installed scraper that has a newer version in the repositories
+type Outdated struct {
+	Name       string `json:"name"`
+	Installed  string `json:"installed"`
+	Available  string `json:"available"`
+	Repository string `json:"repository"`
+}
+
+// Install installs scrapers by their names with dependencies and pins them in the lockfile.
+// Returns installed scrapers, dependencies first
+func Install(names ...string) ([]*Scraper, error) {
+	scrapers, err := Scrapers()
+	if err != nil {
+		return nil, err
+	}
+
+	return install(names, scrapers)
+}
+
+func install(names []string, scrapers []*Scraper) ([]*Scraper, error) {
+	if scrapers == nil {
+		var err error
+		if scrapers, err = Scrapers(); err != nil {
+			return nil, err
+		}
+	}
+
+	plan, err := resolveDependencies(names, scrapers)
+	if err != nil {
+		return nil, err
+	}
+
+	lock, err := ReadLock()
+	if err != nil {
+		return nil, err
+	}
+
+	requested := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		requested[name] = struct{}{}
+	}
+
+	installed := make([]*Scraper, 0, len(plan))
+	for _, scraper := range plan {
+		// dependencies that are already installed are kept as is
+		if _, ok := requested[scraper.Name]; !ok {
+			if locked, ok := lock[scraper.Name]; ok && locked.Version == scraper.Version {
+				continue
+			}
+		}
+
+		if err = scraper.install(lock); err != nil {
+			break
+		}
+
+		installed = append(installed, scraper)
+	}
+
+	if saveErr := lock.Save(); saveErr != nil && err == nil {
+		err = saveErr
+	}
+
+	return installed, err
+}
+
+// resolveDependencies returns scrapers to install in order, dependencies first
+func resolveDependencies(names []string, scrapers []*Scraper) ([]*Scraper, error) {
+	byName := make(map[string]*Scraper, len(scrapers))
+	for _, scraper := range scrapers {
+		byName[scraper.Name] = scraper
+	}
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+
+	var (
+		plan  []*Scraper
+		state = make(map[string]int)
+		visit func(name, dependent string) error
+	)
+
+	visit = func(name, dependent string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("dependency cycle: %s depends on %s", dependent, name)
+		case visited:
+			return nil
+		}
+
+		scraper, ok := byName[name]
+		if !ok {
+			if dependent != "" {
+				return fmt.Errorf("dependency %s of %s is not found", name, dependent)
+			}
+
+			return fmt.Errorf("scraper %s is not found", name)
+		}
+
+		state[name] = visiting
+		for _, dependency := range scraper.Dependencies {
+			if err := visit(dependency, name); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+
+		plan = append(plan, scraper)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	return plan, nil
+}
+
+// CheckOutdated returns installed scrapers that have newer versions, sorted by name.
+// Scrapers installed without version are outdated when their checksum differs
+func CheckOutdated() ([]*Outdated, error) {
+	lock, err := ReadLock()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(lock) == 0 {
+		return []*Outdated{}, nil
+	}
+
+	scrapers, err := Scrapers()
+	if err != nil {
+		return nil, err
+	}
+
+	return outdatedOf(lock, scrapers), nil
+}
+
+// outdatedOf returns the locked scrapers that have newer versions in the listing, sorted by name
+func outdatedOf(lock Lock, scrapers []*Scraper) []*Outdated {
+	updates := make([]*Outdated, 0)
+	for _, scraper := range scrapers {
+		locked, ok := lock[scraper.Name]
+		if !ok {
+			continue
+		}
+
+		var outdated bool
+		if locked.Version == "" || scraper.Version == "" {
+			outdated = scraper.Checksum != "" && scraper.Checksum != locked.SHA256
+		} else {
+			comp, err := version.Compare(scraper.Version, locked.Version)
+			outdated = err == nil && comp > 0
+		}
+
+		if outdated {
+			updates = append(updates, &Outdated{
+				Name:       scraper.Name,
+				Installed:  locked.Version,
+				Available:  scraper.Version,
+				Repository: scraper.Repository,
+			})
+		}
+	}
+
+	sort.Slice(updates, func(i, j int) bool {
+		return updates[i].Name < updates[j].Name
+	})
+
+	return updates
+}
+
+// Update updates installed scrapers by their names, or every outdated scraper if none are given.
+// Returns updated scrapers
+func Update(names ...string) ([]*Scraper, error) {
+	lock, err := ReadLock()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		if _, ok := lock[name]; !ok {
+			return nil, fmt.Errorf("scraper %s is not installed", name)
+		}
+	}
+
+	if len(lock) == 0 {
+		return []*Scraper{}, nil
+	}
+
+	scrapers, err := Scrapers()
+	if err != nil {
+		return nil, err
+	}
+
+	outdated := make([]string, 0)
+	for _, update := range outdatedOf(lock, scrapers) {
+		if len(names) == 0 || lo.Contains(names, update.Name) {
+			outdated = append(outdated, update.Name)
+		}
+	}
+
+	if len(outdated) == 0 {
+		return []*Scraper{}, nil
+	}
+
+	return install(outdated, scrapers)
+}
@@ -0,0 +1,143 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/util"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var errNotFound = errors.New("not found")
+
+// isRemote checks if the location is http(s) URL
+func isRemote(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// localPath converts file:// URL to the path, other locations are returned as is
+func localPath(location string) (string, error) {
+	if !strings.HasPrefix(location, "file://") {
+		return location, nil
+	}
+
+	parsed, err := url.Parse(location)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.FromSlash(parsed.Path), nil
+}
+
+// fetch reads the file from the http(s) URL, file:// URL or local path
+func fetch(location string) ([]byte, error) {
+	if !isRemote(location) {
+		path, err := localPath(location)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := filesystem.Api().ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%s: %w", path, errNotFound)
+		}
+
+		return data, err
+	}
+
+	res, err := http.Get(location)
+	if err != nil {
+		return nil, err
+	}
+
+	defer util.Ignore(res.Body.Close)
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%s: %w", location, errNotFound)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get %s: %s", location, res.Status)
+	}
+
+	return io.ReadAll(res.Body)
+}
+
+// indexLocation returns the location of the index file of the repository.
+// Repository can be the index itself or a local directory with the index
+func indexLocation(repository string) (string, error) {
+	if isRemote(repository) {
+		return repository, nil
+	}
+
+	path, err := localPath(repository)
+	if err != nil {
+		return "", err
+	}
+
+	if isDir, _ := filesystem.Api().IsDir(path); isDir {
+		return filepath.Join(path, IndexFilename), nil
+	}
+
+	return path, nil
+}
+
+// resolve resolves the file location relative to the index location
+func resolve(index, file string) (string, error) {
+	if isRemote(file) || strings.HasPrefix(file, "file://") {
+		return file, nil
+	}
+
+	if !isRemote(index) {
+		if filepath.IsAbs(file) {
+			return file, nil
+		}
+
+		return filepath.Join(filepath.Dir(index), filepath.FromSlash(file)), nil
+	}
+
+	base, err := url.Parse(index)
+	if err != nil {
+		return "", err
+	}
+
+	ref, err := url.Parse(file)
+	if err != nil {
+		return "", err
+	}
+
+	return base.ResolveReference(ref).String(), nil
+}
+
+// repositoryScrapers lists scrapers from the index of the repository
+func repositoryScrapers(repository string) ([]*Scraper, error) {
+	location, err := indexLocation(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := fetch(location)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := parseIndex(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid index %s: %w", location, err)
+	}
+
+	scrapers := make([]*Scraper, len(index.Scrapers))
+	for i, entry := range index.Scrapers {
+		scrapers[i], err = entry.scraper(repository, location)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return scrapers, nil
+}
@@ -1,17 +1,17 @@
 package installer
 
 import (
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
-	"os"
-	"path/filepath"
-
+	"github.com/metafates/mangal/constant"
 	"github.com/metafates/mangal/filesystem"
-	"github.com/metafates/mangal/util"
+	"github.com/metafates/mangal/version"
 	"github.com/metafates/mangal/where"
+	"os"
+	"path/filepath"
 )
 
 type Scraper struct {
@@ -19,17 +19,45 @@ type Scraper struct {
 	URL         string
 	Description string
 	Contents    string
+	// Version of the scraper, empty if the repository has no index
+	Version string
+	// Checksum is the expected SHA256 of the contents in hex
+	Checksum string
+	// MinVersion is the minimal version of mangal that the scraper works with
+	MinVersion string
+	// Dependencies are the names of scrapers that must be installed with this one
+	Dependencies []string
+	// Repository that the scraper is listed in
+	Repository string
+	// Extension of the scraper file
+	Extension string
+
+	// github is true if the scraper is listed from the GitHub repo without index
+	github bool
 }
 
 func (s *Scraper) Path() string {
-	filename := fmt.Sprintf("%s.lua", s.Name)
-	return filepath.Join(where.Sources(), filename)
+	extension := s.Extension
+	if extension == "" {
+		extension = ".lua"
+	}
+
+	return filepath.Join(where.Sources(), s.Name+extension)
 }
 
 func (s *Scraper) GithubURL() string {
 	return fmt.Sprintf("https://github.com/%s/%s/blob/%s/scrapers/%s.lua", collector.user, collector.repo, collector.branch, s.Name)
 }
 
+// Location returns the location of the scraper file to open in browser
+func (s *Scraper) Location() string {
+	if s.github {
+		return s.GithubURL()
+	}
+
+	return s.URL
+}
+
 func (s *Scraper) download() error {
 	if s.Contents != "" {
 		return nil
@@ -39,21 +67,14 @@ func (s *Scraper) download() error {
 		return fmt.Errorf("url must be set")
 	}
 
-	res, err := http.Get(s.URL)
+	b, err := fetch(s.URL)
 	if err != nil {
 		return err
 	}
 
-	defer util.Ignore(res.Body.Close)
-
-	if res.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to get %s: %s", s.URL, res.Status)
-	}
-
-	var b []byte
-	b, err = io.ReadAll(res.Body)
-	if err != nil {
-		return err
+	if !s.github {
+		s.Contents = string(b)
+		return nil
 	}
 
 	var info = struct {
@@ -81,12 +102,50 @@ func (s *Scraper) download() error {
 	return nil
 }
 
-func (s *Scraper) Install() error {
+// checksum returns SHA256 of the contents in hex
+func (s *Scraper) checksum() string {
+	sum := sha256.Sum256([]byte(s.Contents))
+	return hex.EncodeToString(sum[:])
+}
+
+// Install installs the scraper with its dependencies and pins them in the lockfile.
+// Scrapers is the listing the scraper comes from, dependencies are resolved from it.
+// The listing is fetched if it is nil
+func (s *Scraper) Install(scrapers []*Scraper) error {
+	_, err := install([]string{s.Name}, scrapers)
+	return err
+}
+
+// install downloads the scraper, verifies its checksum and writes it to the sources directory.
+// The lock is updated, but not saved
+func (s *Scraper) install(lock Lock) error {
+	if s.MinVersion != "" {
+		if comp, err := version.Compare(constant.Version, s.MinVersion); err == nil && comp < 0 {
+			return fmt.Errorf("%s requires mangal %s or newer, you're on %s", s.Name, s.MinVersion, constant.Version)
+		}
+	}
+
 	err := s.download()
+	if err != nil {
+		return err
+	}
+
+	checksum := s.checksum()
+	if s.Checksum != "" && s.Checksum != checksum {
+		return fmt.Errorf("%s: checksum mismatch, expected %s, got %s", s.Name, s.Checksum, checksum)
+	}
 
+	err = filesystem.Api().WriteFile(s.Path(), []byte(s.Contents), os.ModePerm)
 	if err != nil {
 		return err
 	}
 
-	return filesystem.Api().WriteFile(s.Path(), []byte(s.Contents), os.ModePerm)
+	lock[s.Name] = &Locked{
+		Version:    s.Version,
+		SHA256:     checksum,
+		Repository: s.Repository,
+		File:       filepath.Base(s.Path()),
+	}
+
+	return nil
 }
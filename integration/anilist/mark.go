@@ -34,12 +34,17 @@ func (a *Anilist) MarkRead(chapter *source.Chapter) error {
 		return err
 	}
 
+	progress := int(chapter.Index)
+	if number, ok := chapter.ParsedNumber(); ok {
+		progress = int(number.Number)
+	}
+
 	// prepare body
 	body := map[string]interface{}{
 		"query": markReadQuery,
 		"variables": map[string]interface{}{
 			"ID":       manga.ID,
-			"progress": chapter.Index,
+			"progress": progress,
 		},
 	}
 
@@ -3,7 +3,7 @@ package key
 // DefinedFieldsCount is the number of fields defined in this package.
 // You have to manually update this number when you add a new field
 // to check later if every field has a defined default value
-const DefinedFieldsCount = 55
+const DefinedFieldsCount = 86
 
 const (
 	DownloaderPath                = "downloader.path"
@@ -11,11 +11,15 @@ const (
 	DownloaderAsync               = "downloader.async"
 	DownloaderCreateMangaDir      = "downloader.create_manga_dir"
 	DownloaderCreateVolumeDir     = "downloader.create_volume_dir"
+	DownloaderLayout              = "downloader.layout"
 	DownloaderDefaultSources      = "downloader.default_sources"
 	DownloaderStopOnError         = "downloader.stop_on_error"
 	DownloaderDownloadCover       = "downloader.download_cover"
 	DownloaderRedownloadExisting  = "downloader.redownload_existing"
 	DownloaderReadDownloaded      = "downloader.read_downloaded"
+	DownloaderFallback            = "downloader.fallback"
+	DownloaderFallbackSources     = "downloader.fallback_sources"
+	DownloaderWorkers             = "downloader.workers"
 )
 
 const (
@@ -50,6 +54,7 @@ const (
 
 const (
 	SearchShowQuerySuggestions = "search.show_query_suggestions"
+	SearchTimeout              = "search.timeout"
 )
 
 const (
@@ -64,6 +69,28 @@ const (
 	MangadexLanguage                = "mangadex.language"
 	MangadexNSFW                    = "mangadex.nsfw"
 	MangadexShowUnavailableChapters = "mangadex.show_unavailable_chapters"
+	MangadexPreferredGroups         = "mangadex.preferred_groups"
+	MangadexBlockedGroups           = "mangadex.blocked_groups"
+	MangadexDeduplicate             = "mangadex.deduplicate"
+	MangadexDataSaver               = "mangadex.data_saver"
+	MangadexClientID                = "mangadex.client_id"
+	MangadexClientSecret            = "mangadex.client_secret"
+	MangadexRefreshToken            = "mangadex.refresh_token"
+)
+
+const (
+	CacheEnabled      = "cache.enabled"
+	CacheTTLSearch    = "cache.ttl_search"
+	CacheTTLChapters  = "cache.ttl_chapters"
+	CacheTTLPages     = "cache.ttl_pages"
+	CacheTTLMetadata  = "cache.ttl_metadata"
+	CacheTTLOverrides = "cache.ttl_overrides"
+	CacheMaxSize      = "cache.max_size"
+)
+
+const (
+	NetworkSolverURL     = "network.solver_url"
+	NetworkSolverTimeout = "network.solver_timeout"
 )
 
 const (
@@ -81,18 +108,35 @@ const (
 	TUIShowURLs           = "tui.show_urls"
 	TUIShowDownloadedPath = "tui.show_downloaded_path"
 	TUIReverseChapters    = "tui.reverse_chapters"
+	TUITheme              = "tui.theme"
+	TUICoverPreview       = "tui.cover_preview"
+	TUIChaptersSort       = "tui.chapters_sort"
 )
 
+// TUIKeys is the config section of the remapped TUI keys.
+// It has no default value, so it is not counted as a field
+const TUIKeys = "tui.keys"
+
 const (
-	InstallerUser   = "installer.user"
-	InstallerRepo   = "installer.repo"
-	InstallerBranch = "installer.branch"
+	InstallerUser         = "installer.user"
+	InstallerRepo         = "installer.repo"
+	InstallerBranch       = "installer.branch"
+	InstallerRepositories = "installer.repositories"
 )
 
 const (
 	GenAuthor = "gen.author"
 )
 
+const (
+	LuaTimeout            = "lua.timeout"
+	LuaInstructionLimit   = "lua.instruction_limit"
+	LuaCallStackSize      = "lua.call_stack_size"
+	LuaRegistrySize       = "lua.registry_size"
+	LuaEnforcePermissions = "lua.enforce_permissions"
+	LuaSearchPages        = "lua.search_pages"
+)
+
 const (
 	LogsWrite = "logs.write"
 	LogsLevel = "logs.level"
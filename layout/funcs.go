@@ -0,0 +1,104 @@
+package layout
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/metafates/mangal/numbering"
+	"github.com/metafates/mangal/util"
+)
+
+// Funcs are the functions available in the layouts
+var Funcs = template.FuncMap{
+	"sanitize": util.SanitizeFilename,
+	"clean":    clean,
+	"pad":      pad,
+	"num":      num,
+	"first":    first,
+	"join":     join,
+	"default":  defaultValue,
+	"lower":    strings.ToLower,
+	"upper":    strings.ToUpper,
+	"trim":     strings.TrimSpace,
+	"replace":  replace,
+}
+
+var forbidden = regexp.MustCompile(`[<>:"/\\|?*\x00-\x1f]`)
+
+// clean keeps the name readable unlike sanitize, only symbols that are not allowed by file systems are replaced
+func clean(s string) string {
+	return strings.Trim(forbidden.ReplaceAllString(s, "_"), " .")
+}
+
+// pad pads the number with zeros, strings are padded as they are
+func pad(width int, value any) (string, error) {
+	if s, ok := value.(string); ok {
+		if number, ok := numbering.ParseVolume(s); ok {
+			return numbering.Pad(number, width), nil
+		}
+
+		return util.PadZero(s, width), nil
+	}
+
+	number, err := toFloat(value)
+	if err != nil {
+		return "", err
+	}
+
+	return numbering.Pad(number, width), nil
+}
+
+func num(value any) (string, error) {
+	number, err := toFloat(value)
+	if err != nil {
+		return "", err
+	}
+
+	return numbering.Format(number), nil
+}
+
+func toFloat(value any) (float64, error) {
+	v := reflect.ValueOf(value)
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	default:
+		return 0, fmt.Errorf("%v is not a number", value)
+	}
+}
+
+func first(list []string) string {
+	if len(list) == 0 {
+		return ""
+	}
+
+	return list[0]
+}
+
+func join(sep string, list []string) string {
+	return strings.Join(list, sep)
+}
+
+func defaultValue(fallback, value any) any {
+	if value == nil {
+		return fallback
+	}
+
+	if reflect.ValueOf(value).IsZero() {
+		return fallback
+	}
+
+	return value
+}
+
+func replace(old, new, s string) string {
+	return strings.ReplaceAll(s, old, new)
+}
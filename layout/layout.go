@@ -0,0 +1,238 @@
+// Package layout renders paths of the downloaded chapters from text/template layouts
+package layout
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// Layout is a parsed path template, e.g. "{{ .Manga.Name }}/Vol {{ .Chapter.Volume }}/{{ .Chapter.Name }}".
+// Each segment separated by "/" is a template of its own, so segments that render to the empty string are omitted
+type Layout struct {
+	text     string
+	segments []*segment
+}
+
+type segment struct {
+	template *template.Template
+	// chapter is true if the segment depends on the chapter
+	chapter bool
+}
+
+// Parse parses the layout.
+// Segments before the first one that refers to .Chapter form the manga directory,
+// the rest is the path of the chapter inside it
+func Parse(text string) (*Layout, error) {
+	parts, err := split(text)
+	if err != nil {
+		return nil, err
+	}
+
+	layout := &Layout{text: text}
+
+	var chapter bool
+	for i, part := range parts {
+		if strings.TrimSpace(part) == "" {
+			return nil, fmt.Errorf("layout %q has an empty segment", text)
+		}
+
+		tmpl, err := template.New(fmt.Sprintf("segment %d", i+1)).
+			Funcs(Funcs).
+			Option("missingkey=error").
+			Parse(part)
+		if err != nil {
+			return nil, err
+		}
+
+		chapter = chapter || uses(tmpl.Tree.Root, false, "Chapter")
+		layout.segments = append(layout.segments, &segment{template: tmpl, chapter: chapter})
+	}
+
+	if !chapter {
+		return nil, fmt.Errorf("layout %q doesn't refer to .Chapter, all chapters would have the same path", text)
+	}
+
+	return layout, nil
+}
+
+// String returns the text of the layout
+func (l *Layout) String() string {
+	return l.text
+}
+
+// Uses checks if the layout refers to the field, e.g. Uses("Manga", "Metadata")
+func (l *Layout) Uses(fields ...string) bool {
+	for _, s := range l.segments {
+		if uses(s.template.Tree.Root, false, fields...) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Render renders the manga directory and the chapter path relative to it.
+// Path separators produced by the values are replaced, so a value can't escape its segment
+func (l *Layout) Render(data any) (manga, chapter string, err error) {
+	var mangaParts, chapterParts []string
+
+	for _, s := range l.segments {
+		var sb strings.Builder
+		if err = s.template.Execute(&sb, data); err != nil {
+			return "", "", err
+		}
+
+		rendered := strings.TrimSpace(separators.Replace(sb.String()))
+		switch rendered {
+		case "":
+			continue
+		case ".", "..":
+			return "", "", fmt.Errorf("layout segment rendered to %q", rendered)
+		}
+
+		if s.chapter {
+			chapterParts = append(chapterParts, rendered)
+		} else {
+			mangaParts = append(mangaParts, rendered)
+		}
+	}
+
+	if len(chapterParts) == 0 {
+		return "", "", errors.New("layout rendered an empty chapter path")
+	}
+
+	return filepath.Join(mangaParts...), filepath.Join(chapterParts...), nil
+}
+
+// RenderManga renders the manga directory only, so the chapter fields are not used
+func (l *Layout) RenderManga(data any) (string, error) {
+	var parts []string
+
+	for _, s := range l.segments {
+		if s.chapter {
+			break
+		}
+
+		var sb strings.Builder
+		if err := s.template.Execute(&sb, data); err != nil {
+			return "", err
+		}
+
+		if rendered := strings.TrimSpace(separators.Replace(sb.String())); rendered != "" {
+			parts = append(parts, rendered)
+		}
+	}
+
+	return filepath.Join(parts...), nil
+}
+
+var separators = strings.NewReplacer("/", "_", "\\", "_")
+
+// split splits the layout by "/" outside of the template actions
+func split(text string) ([]string, error) {
+	var (
+		parts []string
+		start int
+		depth int
+	)
+
+	for i := 0; i < len(text); i++ {
+		switch {
+		case strings.HasPrefix(text[i:], "{{"):
+			depth++
+			i++
+		case strings.HasPrefix(text[i:], "}}"):
+			if depth == 0 {
+				return nil, fmt.Errorf("unexpected \"}}\" in layout %q", text)
+			}
+
+			depth--
+			i++
+		case text[i] == '/' && depth == 0:
+			parts = append(parts, text[start:i])
+			start = i + 1
+		}
+	}
+
+	if depth != 0 {
+		return nil, fmt.Errorf("unclosed action in layout %q", text)
+	}
+
+	return append(parts, text[start:]), nil
+}
+
+// uses walks the template tree and checks if it refers to the fields chain.
+// Dot inside with and range is rebound, so only the fields of the root data are checked.
+// Plain dot of the root data is assumed to use everything
+func uses(node parse.Node, rebound bool, fields ...string) bool {
+	matches := func(idents []string) bool {
+		if len(idents) < len(fields) {
+			return false
+		}
+
+		for i, field := range fields {
+			if idents[i] != field {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return false
+		}
+
+		for _, child := range n.Nodes {
+			if uses(child, rebound, fields...) {
+				return true
+			}
+		}
+	case *parse.ActionNode:
+		return uses(n.Pipe, rebound, fields...)
+	case *parse.PipeNode:
+		if n == nil {
+			return false
+		}
+
+		for _, cmd := range n.Cmds {
+			if uses(cmd, rebound, fields...) {
+				return true
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if uses(arg, rebound, fields...) {
+				return true
+			}
+		}
+	case *parse.IfNode:
+		return uses(n.Pipe, rebound, fields...) ||
+			uses(n.List, rebound, fields...) ||
+			uses(n.ElseList, rebound, fields...)
+	case *parse.WithNode:
+		return uses(n.Pipe, rebound, fields...) ||
+			uses(n.List, true, fields...) ||
+			uses(n.ElseList, rebound, fields...)
+	case *parse.RangeNode:
+		return uses(n.Pipe, rebound, fields...) ||
+			uses(n.List, true, fields...) ||
+			uses(n.ElseList, rebound, fields...)
+	case *parse.ChainNode:
+		return uses(n.Node, rebound, fields...)
+	case *parse.FieldNode:
+		return !rebound && matches(n.Ident)
+	case *parse.VariableNode:
+		return len(n.Ident) > 1 && n.Ident[0] == "$" && matches(n.Ident[1:])
+	case *parse.DotNode:
+		return !rebound
+	}
+
+	return false
+}
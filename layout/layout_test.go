@@ -0,0 +1,104 @@
+package layout
+
+import (
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type testManga struct {
+	Name  string
+	Staff []string
+}
+
+type testChapter struct {
+	Name   string
+	Number float64
+	Volume string
+}
+
+type testData struct {
+	Manga   testManga
+	Chapter testChapter
+}
+
+func TestParse(t *testing.T) {
+	Convey("Given a layout with manga and chapter segments", t, func() {
+		l, err := Parse(`{{ first .Manga.Staff | default "Unknown" }}/{{ .Manga.Name }}/{{ with .Chapter.Volume }}Vol {{ . }}{{ end }}/{{ pad 3 .Chapter.Number }} {{ clean .Chapter.Name }}`)
+		So(err, ShouldBeNil)
+
+		Convey("When it is rendered", func() {
+			manga, chapter, err := l.Render(testData{
+				Manga:   testManga{Name: "Fate/Zero", Staff: []string{"Gen Urobuchi"}},
+				Chapter: testChapter{Name: "What? Why: \"now\"", Number: 12.5, Volume: "2"},
+			})
+
+			Convey("Then manga and chapter paths should be split", func() {
+				So(err, ShouldBeNil)
+				So(manga, ShouldEqual, filepath.Join("Gen Urobuchi", "Fate_Zero"))
+				So(chapter, ShouldEqual, filepath.Join("Vol 2", "012.5 What_ Why_ _now_"))
+			})
+		})
+
+		Convey("When the conditional segment is empty", func() {
+			manga, chapter, err := l.Render(testData{
+				Manga:   testManga{Name: "One"},
+				Chapter: testChapter{Name: "Start", Number: 1},
+			})
+
+			Convey("Then it should be omitted", func() {
+				So(err, ShouldBeNil)
+				So(manga, ShouldEqual, filepath.Join("Unknown", "One"))
+				So(chapter, ShouldEqual, "001 Start")
+			})
+		})
+
+		Convey("When only the manga directory is rendered", func() {
+			manga, err := l.RenderManga(testData{Manga: testManga{Name: "One"}})
+
+			Convey("Then chapter segments should be skipped", func() {
+				So(err, ShouldBeNil)
+				So(manga, ShouldEqual, filepath.Join("Unknown", "One"))
+			})
+		})
+
+		Convey("Then used fields should be reported", func() {
+			So(l.Uses("Manga", "Staff"), ShouldBeTrue)
+			So(l.Uses("Manga", "Metadata"), ShouldBeFalse)
+		})
+	})
+
+	Convey("Given a layout with slashes inside the action", t, func() {
+		l, err := Parse(`{{ .Manga.Name }}/{{ replace "/" " - " .Chapter.Name }}`)
+		So(err, ShouldBeNil)
+
+		Convey("Then it should be split outside of the actions only", func() {
+			_, chapter, err := l.Render(testData{Manga: testManga{Name: "One"}, Chapter: testChapter{Name: "a/b"}})
+			So(err, ShouldBeNil)
+			So(chapter, ShouldEqual, "a - b")
+		})
+	})
+
+	Convey("Given invalid layouts", t, func() {
+		for _, text := range []string{
+			`{{ .Manga.Name }}`,
+			`{{ .Manga.Name }}//{{ .Chapter.Name }}`,
+			`{{ .Manga.Name }}/{{ .Chapter.Name`,
+			`{{ unknown .Chapter.Name }}`,
+		} {
+			_, err := Parse(text)
+			So(err, ShouldNotBeNil)
+		}
+	})
+
+	Convey("Given a layout that renders dots", t, func() {
+		l, err := Parse(`{{ .Manga.Name }}/{{ .Chapter.Name }}`)
+		So(err, ShouldBeNil)
+
+		Convey("Then rendering should fail", func() {
+			_, _, err := l.Render(testData{Manga: testManga{Name: ".."}, Chapter: testChapter{Name: "One"}})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
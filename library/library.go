@@ -0,0 +1,89 @@
+// Package library finds downloaded chapters and migrates them between layouts and formats
+package library
+
+import (
+	"path/filepath"
+
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/layout"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/where"
+	"github.com/spf13/viper"
+)
+
+// Layout describes where and in what format the chapters are stored
+type Layout struct {
+	// Path is the downloads directory
+	Path string
+	// Template of the chapter paths, see downloader.layout
+	Template *layout.Layout
+	// NameTemplate is used for .Chapter.FormattedName, see downloader.chapter_name_template
+	NameTemplate string
+	// Format of the chapters
+	Format string
+}
+
+// Current returns the layout of the current config
+func Current() (*Layout, error) {
+	template, err := source.Layout()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Layout{
+		Path:         where.Downloads(),
+		Template:     template,
+		NameTemplate: viper.GetString(key.DownloaderChapterNameTemplate),
+		Format:       viper.GetString(key.FormatsUse),
+	}, nil
+}
+
+// PathOf returns the path of the chapter in the layout.
+// Metadata of the manga is fetched first if the template refers to it, the same way as for the downloads
+func (l *Layout) PathOf(chapter *source.Chapter) (string, error) {
+	chapter.Manga.PopulateForLayout(l.Template, nil)
+
+	manga, relative, err := chapter.RenderPath(l.Template, l.NameTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(l.Path, manga, relative)
+	if l.Format != constant.FormatPlain {
+		path += "." + l.Format
+	}
+
+	return path, nil
+}
+
+// Downloaded is the chapter found in the layout
+type Downloaded struct {
+	Chapter *source.Chapter
+	// Path of the chapter file or directory
+	Path string
+}
+
+// Scan finds the chapters that are downloaded in the layout
+func (l *Layout) Scan(chapters []*source.Chapter) ([]*Downloaded, error) {
+	var downloaded []*Downloaded
+
+	for _, chapter := range chapters {
+		path, err := l.PathOf(chapter)
+		if err != nil {
+			return nil, err
+		}
+
+		exists, err := filesystem.Api().Exists(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if exists {
+			downloaded = append(downloaded, &Downloaded{Chapter: chapter, Path: path})
+		}
+	}
+
+	return downloaded, nil
+}
@@ -0,0 +1,139 @@
+package library
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/layout"
+	"github.com/metafates/mangal/source"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+)
+
+func testChapters() []*source.Chapter {
+	manga := &source.Manga{Name: "One"}
+	chapters := []*source.Chapter{
+		{Name: "Chapter 1", Index: 1, Volume: "1", Manga: manga},
+		{Name: "Chapter 2", Index: 2, Volume: "2", Manga: manga},
+	}
+	manga.Chapters = chapters
+
+	return chapters
+}
+
+// writePages creates a plain chapter with the given number of pages
+func writePages(dir string, count int) {
+	So(filesystem.Api().MkdirAll(dir, os.ModePerm), ShouldBeNil)
+
+	for i := 1; i <= count; i++ {
+		path := filepath.Join(dir, filepath.Base(dir)+string(rune('a'+i))+".jpg")
+		So(filesystem.Api().WriteFile(path, []byte{byte(i)}, os.ModePerm), ShouldBeNil)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	defer viper.Reset()
+
+	Convey("Given plain chapters downloaded in the old layout", t, func() {
+		filesystem.SetMemMapFs()
+
+		from := &Layout{
+			Path:         "/downloads",
+			Template:     mustParse(`{{ sanitize .Manga.Name }}/{{ sanitize .Chapter.FormattedName }}`),
+			NameTemplate: "{chapter}",
+			Format:       constant.FormatPlain,
+		}
+
+		writePages("/downloads/One/Chapter_1", 2)
+		writePages("/downloads/One/Chapter_2", 1)
+
+		viper.Set(key.DownloaderPath, "/library")
+		viper.Set(key.DownloaderLayout, `{{ .Manga.Name }}/Vol {{ .Chapter.Volume }}/{{ .Chapter.Name }}`)
+
+		Convey("When the target layout has the same format", func() {
+			viper.Set(key.FormatsUse, constant.FormatPlain)
+			to, err := Current()
+			So(err, ShouldBeNil)
+
+			plan, err := NewPlan(from, to, testChapters())
+			So(err, ShouldBeNil)
+
+			Convey("Then chapters should be moved", func() {
+				So(plan.Operations, ShouldHaveLength, 2)
+				So(plan.Operations[0].Action, ShouldEqual, ActionMove)
+				So(plan.String(), ShouldContainSubstring, "2 chapters to migrate, 0 skipped")
+
+				So(plan.Execute(false), ShouldBeNil)
+
+				count, err := pageCount("/library/One/Vol 1/Chapter 1", constant.FormatPlain)
+				So(err, ShouldBeNil)
+				So(count, ShouldEqual, 2)
+
+				exists, _ := filesystem.Api().Exists("/downloads/One")
+				So(exists, ShouldBeFalse)
+			})
+		})
+
+		Convey("When the target layout has another format", func() {
+			viper.Set(key.FormatsUse, constant.FormatCBZ)
+			to, err := Current()
+			So(err, ShouldBeNil)
+
+			plan, err := NewPlan(from, to, testChapters())
+			So(err, ShouldBeNil)
+			So(plan.Operations, ShouldHaveLength, 2)
+			So(plan.Operations[0].String(), ShouldStartWith, "convert plain to cbz")
+
+			Convey("Then chapters should be converted and originals removed", func() {
+				So(plan.Execute(false), ShouldBeNil)
+
+				count, err := pageCount("/library/One/Vol 1/Chapter 1.cbz", constant.FormatCBZ)
+				So(err, ShouldBeNil)
+				So(count, ShouldEqual, 2)
+
+				exists, _ := filesystem.Api().Exists("/downloads/One/Chapter_1")
+				So(exists, ShouldBeFalse)
+			})
+
+			Convey("Then a failed operation should roll back the others", func() {
+				So(filesystem.Api().RemoveAll("/downloads/One/Chapter_2"), ShouldBeNil)
+
+				So(plan.Execute(false), ShouldNotBeNil)
+
+				exists, _ := filesystem.Api().Exists("/library/One/Vol 1/Chapter 1.cbz")
+				So(exists, ShouldBeFalse)
+
+				count, err := pageCount("/downloads/One/Chapter_1", constant.FormatPlain)
+				So(err, ShouldBeNil)
+				So(count, ShouldEqual, 2)
+			})
+		})
+
+		Convey("When the target already exists", func() {
+			viper.Set(key.FormatsUse, constant.FormatPlain)
+			writePages("/library/One/Vol 2/Chapter 2", 1)
+
+			to, err := Current()
+			So(err, ShouldBeNil)
+
+			plan, err := NewPlan(from, to, testChapters())
+			So(err, ShouldBeNil)
+
+			Convey("Then the chapter should be skipped", func() {
+				So(plan.Operations, ShouldHaveLength, 1)
+				So(plan.Skipped, ShouldHaveLength, 1)
+				So(plan.Skipped[0].Reason, ShouldContainSubstring, "already exists")
+			})
+		})
+	})
+}
+
+func mustParse(text string) *layout.Layout {
+	l, err := layout.Parse(text)
+	So(err, ShouldBeNil)
+	return l
+}
@@ -0,0 +1,183 @@
+package library
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/metafates/mangal/converter"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/log"
+	"github.com/samber/lo"
+)
+
+// Execute runs the operations of the plan.
+// Page count of each chapter is verified after it is migrated and all finished operations are rolled back if any of them fails.
+// Originals of the converted chapters are removed only after every operation has succeeded, unless keep is set
+func (p *Plan) Execute(keep bool) error {
+	var undos []func() error
+
+	for _, operation := range p.Operations {
+		log.Info(operation.String())
+
+		undo, err := p.execute(operation)
+		if err != nil {
+			err = fmt.Errorf("%s: %w", operation.Chapter.Name, err)
+			if rollbackErr := rollback(undos); rollbackErr != nil {
+				return errors.Join(err, fmt.Errorf("rollback failed: %w", rollbackErr))
+			}
+
+			return err
+		}
+
+		undos = append(undos, undo)
+	}
+
+	for _, operation := range p.Operations {
+		if operation.Action == ActionConvert && keep {
+			continue
+		}
+
+		if operation.Action == ActionConvert {
+			if err := filesystem.Api().RemoveAll(operation.From); err != nil {
+				log.Warn(err)
+				continue
+			}
+		}
+
+		removeEmptyParents(operation.From, p.From.Path)
+	}
+
+	return nil
+}
+
+func (p *Plan) execute(operation *Operation) (undo func() error, err error) {
+	switch operation.Action {
+	case ActionMove:
+		return move(operation)
+	case ActionConvert:
+		return p.convert(operation)
+	default:
+		return nil, fmt.Errorf("unknown action %q", operation.Action)
+	}
+}
+
+// move renames the chapter and checks that no pages were lost
+func move(operation *Operation) (undo func() error, err error) {
+	before, err := pageCount(operation.From, operation.FromFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = filesystem.Api().MkdirAll(filepath.Dir(operation.To), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	if err = filesystem.Api().Rename(operation.From, operation.To); err != nil {
+		return nil, err
+	}
+
+	undo = func() error {
+		if err := filesystem.Api().MkdirAll(filepath.Dir(operation.From), os.ModePerm); err != nil {
+			return err
+		}
+
+		return filesystem.Api().Rename(operation.To, operation.From)
+	}
+
+	if err = verify(operation.To, operation.ToFormat, before); err != nil {
+		return nil, errors.Join(err, undo())
+	}
+
+	return undo, nil
+}
+
+// convert reads the pages of the chapter and saves them with the converter of the target format.
+// The original is kept until the whole plan succeeds
+func (p *Plan) convert(operation *Operation) (undo func() error, err error) {
+	chapter := operation.Chapter
+
+	pages, err := readPages(chapter, operation.From, operation.FromFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	conv, err := converter.Get(operation.ToFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	chapter.Pages = pages
+	chapter.Manga.DownloadPath = p.To.Path
+	chapter.Manga.DownloadFormat = operation.ToFormat
+
+	path, err := conv.Save(chapter)
+	if path != "" {
+		undo = func() error {
+			return filesystem.Api().RemoveAll(path)
+		}
+	}
+
+	if err != nil {
+		if undo != nil {
+			err = errors.Join(err, undo())
+		}
+
+		return nil, err
+	}
+
+	if path != operation.To {
+		return nil, errors.Join(
+			fmt.Errorf("chapter was saved to %s instead of %s, check that the target layout is the current config", path, operation.To),
+			undo(),
+		)
+	}
+
+	if err = verify(operation.To, operation.ToFormat, len(pages)); err != nil {
+		return nil, errors.Join(err, undo())
+	}
+
+	return undo, nil
+}
+
+// verify checks that the migrated chapter has the expected number of pages
+func verify(path, format string, expected int) error {
+	count, err := pageCount(path, format)
+	if err != nil {
+		return fmt.Errorf("failed to verify %s: %w", path, err)
+	}
+
+	if count != expected {
+		return fmt.Errorf("%s has %d pages instead of %d", path, count, expected)
+	}
+
+	return nil
+}
+
+// rollback undoes the operations in the reverse order
+func rollback(undos []func() error) error {
+	var errs []error
+
+	for _, undo := range lo.Reverse(undos) {
+		if err := undo(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// removeEmptyParents removes the directories left empty after the chapter was moved, up to the root
+func removeEmptyParents(path, root string) {
+	for dir := filepath.Dir(path); dir != root && len(dir) > len(root); dir = filepath.Dir(dir) {
+		empty, err := filesystem.Api().IsEmpty(dir)
+		if err != nil || !empty {
+			return
+		}
+
+		if err = filesystem.Api().Remove(dir); err != nil {
+			return
+		}
+	}
+}
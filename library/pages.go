@@ -0,0 +1,174 @@
+package library
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/util"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/samber/lo"
+)
+
+var imageExtensions = []string{".jpg", ".jpeg", ".png", ".gif", ".webp", ".bmp", ".avif"}
+
+func isImage(name string) bool {
+	return lo.Contains(imageExtensions, strings.ToLower(filepath.Ext(name)))
+}
+
+// readable checks if pages can be read from the chapters of the format to convert them
+func readable(format string) bool {
+	switch format {
+	case constant.FormatPlain, constant.FormatZIP, constant.FormatCBZ:
+		return true
+	default:
+		return false
+	}
+}
+
+// pageCount counts the pages of the downloaded chapter
+func pageCount(path, format string) (int, error) {
+	switch format {
+	case constant.FormatPlain:
+		entries, err := filesystem.Api().ReadDir(path)
+		if err != nil {
+			return 0, err
+		}
+
+		return lo.CountBy(entries, func(entry os.FileInfo) bool {
+			return !entry.IsDir() && isImage(entry.Name())
+		}), nil
+	case constant.FormatZIP, constant.FormatCBZ:
+		var count int
+		err := walkArchive(path, func(file *zip.File) error {
+			if !file.FileInfo().IsDir() && isImage(file.Name) {
+				count++
+			}
+
+			return nil
+		})
+
+		return count, err
+	case constant.FormatPDF:
+		file, err := filesystem.Api().Open(path)
+		if err != nil {
+			return 0, err
+		}
+
+		defer util.Ignore(file.Close)
+		return api.PageCount(file, model.NewDefaultConfiguration())
+	default:
+		return 0, fmt.Errorf("counting pages of %s chapters is not supported", format)
+	}
+}
+
+// readPages reads the pages of the downloaded chapter in the order of their file names
+func readPages(chapter *source.Chapter, path, format string) ([]*source.Page, error) {
+	type file struct {
+		name     string
+		contents []byte
+	}
+
+	var files []file
+
+	switch format {
+	case constant.FormatPlain:
+		entries, err := filesystem.Api().ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || !isImage(entry.Name()) {
+				continue
+			}
+
+			contents, err := filesystem.Api().ReadFile(filepath.Join(path, entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+
+			files = append(files, file{name: entry.Name(), contents: contents})
+		}
+	case constant.FormatZIP, constant.FormatCBZ:
+		err := walkArchive(path, func(f *zip.File) error {
+			if f.FileInfo().IsDir() || !isImage(f.Name) {
+				return nil
+			}
+
+			reader, err := f.Open()
+			if err != nil {
+				return err
+			}
+
+			defer util.Ignore(reader.Close)
+
+			contents, err := io.ReadAll(reader)
+			if err != nil {
+				return err
+			}
+
+			files = append(files, file{name: f.Name, contents: contents})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("reading pages of %s chapters is not supported", format)
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].name < files[j].name
+	})
+
+	pages := make([]*source.Page, len(files))
+	for i, f := range files {
+		pages[i] = &source.Page{
+			Index:     uint16(i + 1),
+			Extension: filepath.Ext(f.name),
+			Size:      uint64(len(f.contents)),
+			Contents:  bytes.NewBuffer(f.contents),
+			Chapter:   chapter,
+		}
+	}
+
+	return pages, nil
+}
+
+// walkArchive calls fn for each file of the zip archive
+func walkArchive(path string, fn func(*zip.File) error) error {
+	file, err := filesystem.Api().Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer util.Ignore(file.Close)
+
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	reader, err := zip.NewReader(file, stat.Size())
+	if err != nil {
+		return err
+	}
+
+	for _, f := range reader.File {
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package library
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/metafates/mangal/converter"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/util"
+)
+
+// Action of the operation
+type Action string
+
+const (
+	// ActionMove moves the chapter to the new path
+	ActionMove Action = "move"
+	// ActionConvert converts the chapter to the new format, the original is removed after the whole plan succeeds
+	ActionConvert Action = "convert"
+)
+
+// Operation migrates a single chapter
+type Operation struct {
+	Chapter *source.Chapter
+	Action  Action
+	// From is the current path of the chapter
+	From string
+	// To is the path of the chapter in the target layout
+	To         string
+	FromFormat string
+	ToFormat   string
+}
+
+// String describes the operation
+func (o *Operation) String() string {
+	if o.Action == ActionConvert {
+		return fmt.Sprintf("convert %s to %s: %s -> %s", o.FromFormat, o.ToFormat, o.From, o.To)
+	}
+
+	return fmt.Sprintf("move %s -> %s", o.From, o.To)
+}
+
+// Skip is the downloaded chapter that can't be migrated
+type Skip struct {
+	Chapter *source.Chapter
+	Path    string
+	Reason  string
+}
+
+// Plan is the list of operations that migrate the downloaded chapters from one layout to another
+type Plan struct {
+	From       *Layout
+	To         *Layout
+	Operations []*Operation
+	Skipped    []*Skip
+}
+
+// NewPlan finds the chapters downloaded in the from layout and plans their migration to the target layout.
+// Chapters that are already in place are ignored
+func NewPlan(from, to *Layout, chapters []*source.Chapter) (*Plan, error) {
+	if from.Format != to.Format {
+		if _, err := converter.Get(to.Format); err != nil {
+			return nil, err
+		}
+	}
+
+	downloaded, err := from.Scan(chapters)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{From: from, To: to}
+	targets := make(map[string]*source.Chapter)
+
+	for _, d := range downloaded {
+		target, err := to.PathOf(d.Chapter)
+		if err != nil {
+			return nil, err
+		}
+
+		skip := func(format string, args ...any) {
+			plan.Skipped = append(plan.Skipped, &Skip{
+				Chapter: d.Chapter,
+				Path:    d.Path,
+				Reason:  fmt.Sprintf(format, args...),
+			})
+		}
+
+		if target == d.Path && from.Format == to.Format {
+			continue
+		}
+
+		if other, ok := targets[target]; ok {
+			skip("%s would be moved to the same path %s", other.Name, target)
+			continue
+		}
+
+		if exists, err := filesystem.Api().Exists(target); err != nil {
+			return nil, err
+		} else if exists {
+			skip("%s already exists", target)
+			continue
+		}
+
+		operation := &Operation{
+			Chapter:    d.Chapter,
+			Action:     ActionMove,
+			From:       d.Path,
+			To:         target,
+			FromFormat: from.Format,
+			ToFormat:   to.Format,
+		}
+
+		if from.Format != to.Format {
+			if !readable(from.Format) {
+				skip("%s chapters can't be converted", from.Format)
+				continue
+			}
+
+			operation.Action = ActionConvert
+		}
+
+		targets[target] = d.Chapter
+		plan.Operations = append(plan.Operations, operation)
+	}
+
+	return plan, nil
+}
+
+// String returns the human-readable plan
+func (p *Plan) String() string {
+	var sb strings.Builder
+
+	for _, operation := range p.Operations {
+		sb.WriteString(operation.String())
+		sb.WriteString("\n")
+	}
+
+	for _, skip := range p.Skipped {
+		sb.WriteString(fmt.Sprintf("skip %s: %s\n", skip.Path, skip.Reason))
+	}
+
+	sb.WriteString(fmt.Sprintf(
+		"%s to migrate, %d skipped\n",
+		util.Quantify(len(p.Operations), "chapter", "chapters"),
+		len(p.Skipped),
+	))
+
+	return sb.String()
+}
@@ -5,12 +5,13 @@ import "github.com/samber/lo"
 type bind lo.Tuple2[string, string]
 
 var (
-	quit   = &bind{A: "q", B: "quit"}
-	prev   = &bind{A: "p", B: "previous"}
-	next   = &bind{A: "n", B: "next"}
-	reread = &bind{A: "r", B: "reread"}
-	back   = &bind{A: "b", B: "back"}
-	search = &bind{A: "s", B: "search"}
+	quit     = &bind{A: "q", B: "quit"}
+	prev     = &bind{A: "p", B: "previous"}
+	next     = &bind{A: "n", B: "next"}
+	reread   = &bind{A: "r", B: "reread"}
+	download = &bind{A: "d", B: "download this"}
+	back     = &bind{A: "b", B: "back"}
+	search   = &bind{A: "s", B: "search"}
 )
 
 func (b *bind) eq(other *bind) bool {
@@ -27,6 +28,8 @@ func parseBind(b string) (*bind, bool) {
 		return next, true
 	case reread.A:
 		return reread, true
+	case download.A:
+		return download, true
 	case back.A:
 		return back, true
 	case search.A:
@@ -2,6 +2,7 @@ package mini
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"github.com/metafates/mangal/color"
 	"github.com/metafates/mangal/style"
@@ -20,7 +21,20 @@ func (o input) asInt() (n int64, ok bool) {
 	return n, ok
 }
 
+var errInvalidChoice = errors.New("Invalid choice entered")
+
 func getInput(validator func(string) bool) (*input, error) {
+	return getValidInput(func(s string) error {
+		if !validator(s) {
+			return errInvalidChoice
+		}
+
+		return nil
+	})
+}
+
+// getValidInput reads the input until it passes the validation, validation errors are shown to the user
+func getValidInput(validate func(string) error) (*input, error) {
 	fmt.Print(style.Fg(color.Purple)("> "))
 	reader := bufio.NewReader(os.Stdin)
 	in, err := reader.ReadString('\n')
@@ -34,9 +48,9 @@ func getInput(validator func(string) bool) (*input, error) {
 		in = "1"
 	}
 
-	if !validator(in) {
-		fmt.Println(style.Fg(color.Red)("Invalid choice entered"))
-		return getInput(validator)
+	if err := validate(in); err != nil {
+		fmt.Println(style.Fg(color.Red)(err.Error()))
+		return getValidInput(validate)
 	}
 
 	return &input{value: in}, nil
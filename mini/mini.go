@@ -2,6 +2,7 @@ package mini
 
 import (
 	"errors"
+	"github.com/metafates/mangal/downloader"
 	"github.com/metafates/mangal/source"
 	"github.com/metafates/mangal/util"
 	"github.com/samber/lo"
@@ -15,6 +16,8 @@ var (
 type Options struct {
 	Download bool
 	Continue bool
+	// Queue downloads the selected chapters in the background while they are read
+	Queue bool
 }
 
 type mini struct {
@@ -24,6 +27,11 @@ type mini struct {
 	statesHistory util.Stack[state]
 
 	download bool
+	queue    bool
+
+	// downloads are the chapters queued in the queue mode
+	downloads *downloader.Manager
+	queued    map[*source.Chapter]*downloader.Job
 
 	selectedSource source.Source
 
@@ -34,6 +42,8 @@ type mini struct {
 	query            string
 	selectedManga    *source.Manga
 	selectedChapters []*source.Chapter
+	// readFrom is the index of the selected chapter to start reading from
+	readFrom int
 }
 
 func newMini() *mini {
@@ -42,6 +52,7 @@ func newMini() *mini {
 		cachedMangas:   make(map[string][]*source.Manga),
 		cachedChapters: make(map[string][]*source.Chapter),
 		cachedPages:    make(map[string][]*source.Page),
+		queued:         make(map[*source.Chapter]*downloader.Job),
 	}
 }
 
@@ -74,6 +85,10 @@ func Run(options *Options) error {
 		return errors.New("cannot download and continue")
 	}
 
+	if options.Queue && options.Download {
+		return errors.New("cannot download and queue")
+	}
+
 	m := newMini()
 	m.state = sourceSelectState
 	if options.Continue {
@@ -81,16 +96,15 @@ func Run(options *Options) error {
 	}
 
 	m.download = options.Download
+	m.queue = options.Queue
 
 	if w, h, err := util.TerminalSize(); err == nil {
 		m.width, m.height = w, h
 		truncateAt = w
 	}
 
-	var err error
-
 	for {
-		if m.handleState() != nil {
+		if err := m.handleState(); err != nil {
 			return err
 		}
 	}
@@ -113,6 +127,7 @@ func (m *mini) handleState() error {
 	case chaptersDownloadState:
 		return m.handleChaptersDownloadState()
 	case quitState:
+		m.finishDownloads()
 		os.Exit(0)
 	}
 
@@ -0,0 +1,68 @@
+package mini
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/metafates/mangal/downloader"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/util"
+	"github.com/spf13/viper"
+)
+
+// enqueue downloads the chapters in the background, chapters that are already queued are skipped
+func (m *mini) enqueue(chapters []*source.Chapter) {
+	if m.downloads == nil {
+		m.downloads = downloader.NewManager(viper.GetInt(key.DownloaderWorkers))
+	}
+
+	for _, job := range m.downloads.Add(chapters...) {
+		m.queued[job.Chapter] = job
+	}
+}
+
+// waitFor waits until the queued chapter is downloaded and returns its path
+func (m *mini) waitFor(job *downloader.Job) (string, error) {
+	erase := func() {}
+	defer func() { erase() }()
+
+	for {
+		state := job.State()
+		switch state.Status {
+		case downloader.JobDone:
+			return state.Path, nil
+		case downloader.JobFailed:
+			return "", state.Err
+		case downloader.JobCanceled:
+			return "", errors.New("download was canceled")
+		}
+
+		erase()
+		erase = progress(fmt.Sprintf("Downloading %s %.0f%%", job.Chapter.Name, state.Progress()*100))
+
+		<-m.downloads.Updates()
+	}
+}
+
+// finishDownloads waits for the queued chapters that are not downloaded yet
+func (m *mini) finishDownloads() {
+	if m.downloads == nil {
+		return
+	}
+
+	erase := func() {}
+	defer func() { erase() }()
+
+	for {
+		left := m.downloads.Unfinished()
+		if left == 0 {
+			return
+		}
+
+		erase()
+		erase = progress(fmt.Sprintf("Finishing downloads, %s left..", util.Quantify(left, "chapter", "chapters")))
+
+		<-m.downloads.Updates()
+	}
+}
@@ -0,0 +1,65 @@
+package mini
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var chaptersRange = regexp.MustCompile(`^(\d+)(?:\s*(-|\s)\s*(\d+)?)?$`)
+
+// parseChaptersInput parses the chapters entered by the user into 0-based indexes in the list order.
+// Input is a comma separated list of 1-based numbers, ranges "3-5" or "3 5" and open-ended ranges "12-"
+func parseChaptersInput(in string, total int) ([]int, error) {
+	selected := make(map[int]struct{})
+
+	for _, part := range strings.Split(in, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, errors.New("empty chapter in the list")
+		}
+
+		groups := chaptersRange.FindStringSubmatch(part)
+		if groups == nil {
+			return nil, fmt.Errorf("%q is not a chapter or a range", part)
+		}
+
+		from, err := strconv.Atoi(groups[1])
+		if err != nil {
+			return nil, fmt.Errorf("%q: chapter is too large", part)
+		}
+
+		to := from
+		switch {
+		case groups[3] != "":
+			if to, err = strconv.Atoi(groups[3]); err != nil {
+				return nil, fmt.Errorf("%q: chapter is too large", part)
+			}
+		case groups[2] == "-":
+			to = total
+		}
+
+		if from > to {
+			return nil, fmt.Errorf("%q: range start is greater than its end", part)
+		}
+
+		if from < 1 || to > total {
+			return nil, fmt.Errorf("%q: chapters are numbered from 1 to %d", part, total)
+		}
+
+		for i := from; i <= to; i++ {
+			selected[i-1] = struct{}{}
+		}
+	}
+
+	indexes := make([]int, 0, len(selected))
+	for i := range selected {
+		indexes = append(indexes, i)
+	}
+
+	sort.Ints(indexes)
+	return indexes, nil
+}
@@ -0,0 +1,65 @@
+package mini
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseChaptersInput(t *testing.T) {
+	Convey("Given the list of 20 chapters", t, func() {
+		cases := []struct {
+			input    string
+			expected []int
+		}{
+			{"3", []int{2}},
+			{"3 5", []int{2, 3, 4}},
+			{"3-5", []int{2, 3, 4}},
+			{"5-5", []int{4}},
+			{"5 5", []int{4}},
+			{"18-", []int{17, 18, 19}},
+			{"1, 3,5-6", []int{0, 2, 4, 5}},
+			{"19-,1,2-3,20", []int{0, 1, 2, 18, 19}},
+		}
+
+		for _, c := range cases {
+			c := c
+			Convey(fmt.Sprintf("When %q is entered", c.input), func() {
+				indexes, err := parseChaptersInput(c.input, 20)
+
+				Convey("Then the chapters should be selected in the list order", func() {
+					So(err, ShouldBeNil)
+					So(indexes, ShouldResemble, c.expected)
+				})
+			})
+		}
+	})
+
+	Convey("Given the invalid input", t, func() {
+		cases := []struct {
+			input   string
+			message string
+		}{
+			{"0", "numbered from 1 to 20"},
+			{"21", "numbered from 1 to 20"},
+			{"15-25", "numbered from 1 to 20"},
+			{"5-3", "range start is greater than its end"},
+			{"1,,2", "empty chapter"},
+			{"first", "is not a chapter or a range"},
+			{"99999999999999999999", "chapter is too large"},
+		}
+
+		for _, c := range cases {
+			c := c
+			Convey(fmt.Sprintf("When %q is entered", c.input), func() {
+				_, err := parseChaptersInput(c.input, 20)
+
+				Convey("Then the error should describe the problem", func() {
+					So(err, ShouldNotBeNil)
+					So(err.Error(), ShouldContainSubstring, c.message)
+				})
+			})
+		}
+	})
+}
@@ -3,17 +3,17 @@ package mini
 import (
 	"fmt"
 	"github.com/metafates/mangal/downloader"
+	"github.com/metafates/mangal/event"
+	"github.com/metafates/mangal/fallback"
 	"github.com/metafates/mangal/history"
 	"github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/provider"
 	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/style"
 	"github.com/metafates/mangal/util"
 	"github.com/samber/lo"
 	"github.com/spf13/viper"
 	"golang.org/x/exp/slices"
-	"regexp"
-	"strconv"
-	"strings"
 )
 
 type state int
@@ -138,6 +138,9 @@ func (m *mini) handleChapterSelectState() error {
 
 	erase := progress("Searching Chapters..")
 	m.cachedChapters[m.selectedManga.URL], err = m.selectedSource.ChaptersOf(m.selectedManga)
+	if err == nil && fallback.Enabled() {
+		m.cachedChapters[m.selectedManga.URL] = fallback.Complete(m.selectedManga, m.cachedChapters[m.selectedManga.URL])
+	}
 	erase()
 	if err != nil {
 		return err
@@ -152,63 +155,36 @@ func (m *mini) handleChapterSelectState() error {
 		return nil
 	}
 
-	title(fmt.Sprintf("To specify a range, use: start_number end_number (Chapters: 1-%d)", len(chapters)))
-	oneChapterInput := regexp.MustCompile(`^\d+$`)
-	rangeInput := regexp.MustCompile(`^\d+ \d+$`)
-	in, err := getInput(func(s string) bool {
-		var err error
-
-		switch {
-		case rangeInput.MatchString(s):
-			var a, b int64
-			{
-				l := strings.Split(s, " ")
-				a, err = strconv.ParseInt(l[0], 10, 16)
-				if err != nil {
-					return false
-				}
-
-				b, err = strconv.ParseInt(l[1], 10, 16)
-				if err != nil {
-					return false
-				}
-			}
-
-			return a < b && 0 < a && int(a) < len(chapters) && int(b) <= len(chapters)
-		case oneChapterInput.MatchString(s):
-			var a int64
-			a, err = strconv.ParseInt(s, 10, 16)
-			if err != nil {
-				return false
-			}
-
-			return 0 < a && int(a) <= len(chapters)
-		default:
-			return s == "q"
+	title(fmt.Sprintf("Chapters: 1-%d. Enter a number, a range like 3-5 or 12- or a comma separated list of them", len(chapters)))
+	in, err := getValidInput(func(s string) error {
+		if s == "q" {
+			return nil
 		}
+
+		_, err := parseChaptersInput(s, len(chapters))
+		return err
 	})
 
 	if err != nil {
 		return err
 	}
 
-	switch {
-	case rangeInput.MatchString(in.value):
-		nums := strings.Split(in.value, " ")
-		from := lo.Must(strconv.ParseInt(nums[0], 10, 16))
-		to := lo.Must(strconv.ParseInt(nums[1], 10, 16))
-
-		for i := from - 1; i < to; i++ {
-			m.selectedChapters = append(m.selectedChapters, chapters[i])
-		}
-	case oneChapterInput.MatchString(in.value):
-		num := lo.Must(strconv.ParseInt(in.value, 10, 16))
-		m.selectedChapters = append(m.selectedChapters, chapters[num-1])
-	case in.value == "q":
+	if in.value == "q" {
 		m.newState(quitState)
 		return nil
 	}
 
+	indexes, err := parseChaptersInput(in.value, len(chapters))
+	if err != nil {
+		return err
+	}
+
+	m.selectedChapters = make([]*source.Chapter, len(indexes))
+	for i, index := range indexes {
+		m.selectedChapters[i] = chapters[index]
+	}
+	m.readFrom = 0
+
 	if m.download {
 		m.newState(chaptersDownloadState)
 	} else {
@@ -219,97 +195,106 @@ func (m *mini) handleChapterSelectState() error {
 }
 
 func (m *mini) handleChapterReadState() error {
-	type controls struct {
-		next chan struct{}
-		prev chan struct{}
-		stop chan struct{}
-		err  chan error
+	if m.queue {
+		m.enqueue(m.selectedChapters)
 	}
 
-	var (
-		err      error
-		readLoop func(*source.Chapter, *controls, bool, bool)
-	)
-
-	readLoop = func(chapter *source.Chapter, c *controls, hasPrev, hasNext bool) {
-		util.ClearScreen()
-		var erase = func() {}
+	i := m.readFrom
 
-		err = downloader.Read(chapter, func(s string) {
-			erase()
-			erase = progress(s)
-		})
+	for {
+		chapter := m.selectedChapters[i]
 
-		if err != nil {
-			c.err <- err
-			return
+		util.ClearScreen()
+		if err := m.read(chapter); err != nil {
+			return err
 		}
 
-		erase()
-
 		title(fmt.Sprintf("Currently reading %s", chapter.Name))
 
 		var options []*bind
-		if hasPrev {
+		if i > 0 {
 			options = append(options, prev)
 		}
-		if hasNext {
+		if i+1 < len(m.selectedChapters) {
 			options = append(options, next)
 		}
 
-		options = append(options, reread, back, search)
+		options = append(options, reread)
+		if _, ok := m.queued[chapter]; !ok {
+			options = append(options, download)
+		}
+		options = append(options, back, search)
 
 		b, _, err := menu([]fmt.Stringer{}, options...)
+		for err == nil && b == download {
+			if err := m.downloadChapter(chapter); err != nil {
+				fail(err.Error())
+			}
+
+			b, _, err = menu([]fmt.Stringer{}, options...)
+		}
+
 		if err != nil {
-			c.err <- err
-			return
+			return err
 		}
 
 		switch b {
+		case prev:
+			i--
 		case next:
-			c.next <- struct{}{}
+			i++
 		case reread:
-			readLoop(chapter, c, hasPrev, hasNext)
+			// the same chapter is read again
 		case back:
 			m.previousState()
-			c.stop <- struct{}{}
+			return nil
 		case search:
 			m.newState(mangasSearchState)
-			c.stop <- struct{}{}
+			return nil
 		case quit:
 			m.newState(quitState)
-			c.stop <- struct{}{}
+			return nil
 		}
 	}
+}
 
-	c := &controls{
-		next: make(chan struct{}),
-		prev: make(chan struct{}),
-		stop: make(chan struct{}),
-		err:  make(chan error),
+// read opens the chapter with the reader, chapters queued in the queue mode are read when they are downloaded
+func (m *mini) read(chapter *source.Chapter) error {
+	var erase = func() {}
+	defer func() { erase() }()
+
+	handler := func(e *event.Event) {
+		erase()
+		erase = progress(style.Event(e))
 	}
 
-	var i int
+	if job, ok := m.queued[chapter]; ok && !viper.GetBool(key.ReaderReadInBrowser) {
+		path, err := m.waitFor(job)
+		if err == nil {
+			return downloader.Open(path, chapter, handler)
+		}
 
-	for {
-		var (
-			hasPrev = i > 0
-			hasNext = i+1 < len(m.selectedChapters)
-		)
+		fail(fmt.Sprintf("Failed to download %s in the background: %s", chapter.Name, err))
+	}
 
-		go readLoop(m.selectedChapters[i], c, hasPrev, hasNext)
+	return downloader.Read(chapter, handler)
+}
 
-		select {
-		case <-c.next:
-			i++
-		case <-c.prev:
-			i--
-		case <-c.stop:
-			return nil
-		case err := <-c.err:
-			return err
-		}
+// downloadChapter downloads the chapter that is being read
+func (m *mini) downloadChapter(chapter *source.Chapter) error {
+	var erase = func() {}
+	path, err := downloader.Download(chapter, func(e *event.Event) {
+		erase()
+		erase = progress(style.Event(e))
+	})
+	erase()
+
+	if err != nil {
+		return err
 	}
+
+	title(fmt.Sprintf("Downloaded to %s", path))
+	return nil
 }
 
 func (m *mini) handleChaptersDownloadState() error {
@@ -324,9 +309,9 @@ func (m *mini) handleChaptersDownloadState() error {
 
 		title(fmt.Sprintf("Currently downloading %s %s (%s)", chapter.Manga.Name, chapter.Name, m.selectedSource.Name()))
 
-		_, err := downloader.Download(chapter, func(s string) {
+		_, err := downloader.Download(chapter, func(e *event.Event) {
 			erase()
-			erase = progress(s)
+			erase = progress(style.Event(e))
 		})
 
 		erase()
@@ -418,8 +403,27 @@ func (m *mini) handleHistorySelectState() error {
 		return err
 	}
 
+	manga.Chapters = chaps
 	m.cachedChapters[manga.URL] = chaps
-	m.selectedChapters = chaps[c.Index-1:]
+
+	// chapters are found by the URL of the last read one, its index may change when new chapters are released
+	readingProgress, err := history.ProgressOf(manga)
+	if err != nil {
+		return err
+	}
+
+	m.selectedChapters = readingProgress.FromLastRead(chaps)
+	if len(m.selectedChapters) == 0 {
+		fail("No chapters found")
+		m.newState(quitState)
+		return nil
+	}
+
+	// continue with the chapter after the last read one, it can be reread with the previous control
+	m.readFrom = 0
+	if len(m.selectedChapters) > 1 && readingProgress.IsRead(m.selectedChapters[0]) {
+		m.readFrom = 1
+	}
 
 	m.newState(chapterReadState)
 	return nil
@@ -73,6 +73,8 @@ type MangaMetadata struct {
 	UpdatedAt int `json:"updatedAt"`
 	// PublicationRun is the publication run of the manga (e.g. "1 2023 - 4 2024")
 	PublicationRun string `json:"publicationRun"`
+	// Language of the manga as ISO 639 code (e.g. "en")
+	Language string `json:"language,omitempty"`
 }
 
 // SeriesJSON represents metadata in series.json format
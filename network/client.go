@@ -16,7 +16,11 @@ func init() {
 	transport.ExpectContinueTimeout = 30 * time.Second
 }
 
+// Transport is shared by all sources, JavaScript challenges are solved by the external solver
+var Transport = WithChallenges(transport)
+
 var Client = &http.Client{
 	Timeout:   time.Minute,
-	Transport: transport,
+	Transport: Transport,
+	Jar:       Jar,
 }
@@ -0,0 +1,257 @@
+package network
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/where"
+)
+
+// httpOnlyPrefix marks http only cookies in the Netscape format
+const httpOnlyPrefix = "#HttpOnly_"
+
+// Jar is the cookie jar shared by all sources.
+// Cookies imported from the Netscape file are loaded on first use
+var Jar = &cookieJar{}
+
+type cookieJar struct {
+	once sync.Once
+	mu   sync.Mutex
+	jar  *cookiejar.Jar
+	// stored are the cookies that are saved to the cookies file
+	stored map[string]*http.Cookie
+}
+
+func (j *cookieJar) load() {
+	j.once.Do(func() {
+		j.jar, _ = cookiejar.New(nil)
+		j.stored = make(map[string]*http.Cookie)
+
+		file, err := filesystem.Api().Open(where.Cookies())
+		if err != nil {
+			return
+		}
+
+		defer file.Close()
+
+		cookies, err := ParseCookies(file)
+		if err != nil {
+			return
+		}
+
+		j.add(cookies, false)
+	})
+}
+
+// SetCookies implements http.CookieJar
+func (j *cookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.load()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.jar.SetCookies(u, cookies)
+}
+
+// Cookies implements http.CookieJar
+func (j *cookieJar) Cookies(u *url.URL) []*http.Cookie {
+	j.load()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.jar.Cookies(u)
+}
+
+// Import adds the cookies to the jar.
+// If persist is true, they are also saved to the cookies file
+func (j *cookieJar) Import(cookies []*http.Cookie, persist bool) error {
+	j.load()
+	j.add(cookies, persist)
+
+	if !persist {
+		return nil
+	}
+
+	return j.save()
+}
+
+// Clear removes all cookies, including the saved ones.
+// Solved challenges are forgotten too, since their cookies are removed
+func (j *cookieJar) Clear() error {
+	j.load()
+	forgetSolutions()
+
+	j.mu.Lock()
+	j.jar, _ = cookiejar.New(nil)
+	j.stored = make(map[string]*http.Cookie)
+	j.mu.Unlock()
+
+	err := filesystem.Api().Remove(where.Cookies())
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	return err
+}
+
+func (j *cookieJar) add(cookies []*http.Cookie, persist bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, cookie := range cookies {
+		set := cookie
+		// host only cookies must not have the domain attribute
+		if !strings.HasPrefix(cookie.Domain, ".") {
+			copied := *cookie
+			copied.Domain = ""
+			set = &copied
+		}
+
+		j.jar.SetCookies(cookieURL(cookie), []*http.Cookie{set})
+
+		if persist {
+			j.stored[cookie.Domain+cookie.Path+"\t"+cookie.Name] = cookie
+		}
+	}
+}
+
+// save writes the stored cookies to the cookies file
+func (j *cookieJar) save() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	keys := make([]string, 0, len(j.stored))
+	for k, cookie := range j.stored {
+		if cookie.Expires.IsZero() || cookie.Expires.After(time.Now()) {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("# Netscape HTTP Cookie File\n")
+	for _, k := range keys {
+		b.WriteString(formatCookie(j.stored[k]))
+		b.WriteString("\n")
+	}
+
+	return filesystem.Api().WriteFile(where.Cookies(), []byte(b.String()), os.ModePerm)
+}
+
+// cookieURL is the URL that the cookie is set for
+func cookieURL(cookie *http.Cookie) *url.URL {
+	u := &url.URL{
+		Scheme: "http",
+		Host:   strings.TrimPrefix(cookie.Domain, "."),
+		Path:   cookie.Path,
+	}
+
+	if cookie.Secure {
+		u.Scheme = "https"
+	}
+
+	return u
+}
+
+// ParseCookies parses cookies in the Netscape format, as exported by browsers to cookies.txt.
+// Domain of the cookies that include subdomains starts with a dot, expired cookies are skipped
+func ParseCookies(r io.Reader) ([]*http.Cookie, error) {
+	var (
+		cookies []*http.Cookie
+		scanner = bufio.NewScanner(r)
+		line    int
+	)
+
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+
+		httpOnly := strings.HasPrefix(text, httpOnlyPrefix)
+		if httpOnly {
+			text = strings.TrimPrefix(text, httpOnlyPrefix)
+		}
+
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("line %d: expected 7 tab separated fields, got %d", line, len(fields))
+		}
+
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid expiration time %q", line, fields[4])
+		}
+
+		cookie := &http.Cookie{
+			Domain:   fields[0],
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			Name:     fields[5],
+			Value:    fields[6],
+			HttpOnly: httpOnly,
+		}
+
+		// domain of the cookies that include subdomains starts with a dot
+		if strings.EqualFold(fields[1], "TRUE") {
+			cookie.Domain = "." + strings.TrimPrefix(cookie.Domain, ".")
+		} else {
+			cookie.Domain = strings.TrimPrefix(cookie.Domain, ".")
+		}
+
+		if expires > 0 {
+			cookie.Expires = time.Unix(expires, 0)
+			if cookie.Expires.Before(time.Now()) {
+				continue
+			}
+		}
+
+		cookies = append(cookies, cookie)
+	}
+
+	return cookies, scanner.Err()
+}
+
+// formatCookie formats the cookie as a line of the Netscape format
+func formatCookie(cookie *http.Cookie) string {
+	flag := func(b bool) string {
+		if b {
+			return "TRUE"
+		}
+
+		return "FALSE"
+	}
+
+	var expires int64
+	if !cookie.Expires.IsZero() {
+		expires = cookie.Expires.Unix()
+	}
+
+	domain := cookie.Domain
+	if cookie.HttpOnly {
+		domain = httpOnlyPrefix + domain
+	}
+
+	return strings.Join([]string{
+		domain,
+		flag(strings.HasPrefix(cookie.Domain, ".")),
+		cookie.Path,
+		flag(cookie.Secure),
+		strconv.FormatInt(expires, 10),
+		cookie.Name,
+		cookie.Value,
+	}, "\t")
+}
@@ -0,0 +1,73 @@
+package network
+
+import (
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/metafates/mangal/where"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const testCookies = `# Netscape HTTP Cookie File
+.example.com	TRUE	/	TRUE	4102444800	session	abc
+#HttpOnly_img.example.com	FALSE	/	FALSE	0	token	xyz
+.example.com	TRUE	/	FALSE	946684800	expired	old
+`
+
+func TestCookies(t *testing.T) {
+	t.Setenv(where.EnvConfigPath, t.TempDir())
+
+	Convey("Given cookies exported by the browser", t, func() {
+		So(Jar.Clear(), ShouldBeNil)
+
+		Convey("When parsing them", func() {
+			cookies, err := ParseCookies(strings.NewReader(testCookies))
+
+			Convey("Then expired cookies should be skipped", func() {
+				So(err, ShouldBeNil)
+				So(cookies, ShouldHaveLength, 2)
+				So(cookies[0].Domain, ShouldEqual, ".example.com")
+				So(cookies[1].Domain, ShouldEqual, "img.example.com")
+				So(cookies[1].HttpOnly, ShouldBeTrue)
+			})
+
+			Convey("And importing them", func() {
+				So(Jar.Import(cookies, true), ShouldBeNil)
+
+				Convey("Then subdomain cookies should be sent to subdomains", func() {
+					names := func(address string) []string {
+						var names []string
+						for _, cookie := range Jar.Cookies(&url.URL{Scheme: "https", Host: address, Path: "/"}) {
+							names = append(names, cookie.Name)
+						}
+
+						return names
+					}
+
+					So(names("www.example.com"), ShouldResemble, []string{"session"})
+					So(names("img.example.com"), ShouldContain, "token")
+					So(names("a.img.example.com"), ShouldNotContain, "token")
+				})
+
+				Convey("Then they should be saved in the same format", func() {
+					saved, err := os.ReadFile(where.Cookies())
+					So(err, ShouldBeNil)
+
+					lines := strings.Split(strings.TrimSpace(string(saved)), "\n")
+					So(lines[1:], ShouldResemble, strings.Split(testCookies, "\n")[1:3])
+				})
+			})
+		})
+
+		Convey("When parsing invalid cookies", func() {
+			_, err := ParseCookies(strings.NewReader("example.com\tTRUE\t/"))
+
+			Convey("Then error should be returned", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "line 1")
+			})
+		})
+	})
+}
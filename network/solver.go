@@ -0,0 +1,240 @@
+package network
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/spf13/viper"
+)
+
+// solvedRecently is how long the solution of the host is reused
+// instead of solving the challenge again
+const solvedRecently = time.Minute
+
+// challengeMarkers are parts of the pages that protection services show instead of the content
+var challengeMarkers = [][]byte{
+	[]byte("challenge-platform"),
+	[]byte("cf-chl"),
+	[]byte("<title>Just a moment...</title>"),
+	[]byte("DDoS-Guard"),
+}
+
+// solver of the JavaScript challenges.
+// Cookies given by the solver are bound to its user agent,
+// so it is used for all the following requests to the host
+var solver struct {
+	mu       sync.Mutex
+	solvedAt map[string]time.Time
+	agents   sync.Map
+}
+
+// challengeTransport retries requests blocked by JavaScript challenges
+// with the cookies and user agent given by the external solver
+type challengeTransport struct {
+	base http.RoundTripper
+}
+
+// WithChallenges wraps the transport so that JavaScript challenges are solved by the external solver.
+// Does nothing if the transport is already wrapped
+func WithChallenges(base http.RoundTripper) http.RoundTripper {
+	if _, ok := base.(*challengeTransport); ok {
+		return base
+	}
+
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &challengeTransport{base: base}
+}
+
+func (t *challengeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if agent, ok := solver.agents.Load(req.URL.Hostname()); ok {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", agent.(string))
+	}
+
+	res, err := t.base.RoundTrip(req)
+	if err != nil || viper.GetString(key.NetworkSolverURL) == "" || !isChallenge(res) {
+		return res, err
+	}
+
+	// request body was already sent
+	if req.Body != nil && req.GetBody == nil {
+		return res, nil
+	}
+
+	if err := solve(req.URL); err != nil {
+		log.Warn("failed to solve challenge for " + req.URL.Hostname() + ": " + err.Error())
+		return res, nil
+	}
+
+	_, _ = io.Copy(io.Discard, res.Body)
+	_ = res.Body.Close()
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+
+	if agent, ok := solver.agents.Load(req.URL.Hostname()); ok {
+		retry.Header.Set("User-Agent", agent.(string))
+	}
+
+	// the client has set cookies before the challenge was solved
+	var cookies []string
+	for _, cookie := range Jar.Cookies(req.URL) {
+		cookies = append(cookies, cookie.Name+"="+cookie.Value)
+	}
+
+	retry.Header.Set("Cookie", strings.Join(cookies, "; "))
+	return t.base.RoundTrip(retry)
+}
+
+// isChallenge checks if the response is a JavaScript challenge page.
+// Body of the response is preserved
+func isChallenge(res *http.Response) bool {
+	if res.StatusCode != http.StatusForbidden && res.StatusCode != http.StatusServiceUnavailable {
+		return false
+	}
+
+	if res.Header.Get("cf-mitigated") == "challenge" {
+		return true
+	}
+
+	peek, _ := io.ReadAll(io.LimitReader(res.Body, 64*1024))
+	res.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(peek), res.Body), res.Body}
+
+	for _, marker := range challengeMarkers {
+		if bytes.Contains(peek, marker) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// forgetSolutions makes the challenges to be solved again
+func forgetSolutions() {
+	solver.mu.Lock()
+	defer solver.mu.Unlock()
+
+	solver.solvedAt = nil
+	solver.agents.Range(func(host, _ any) bool {
+		solver.agents.Delete(host)
+		return true
+	})
+}
+
+// solution of the FlareSolverr compatible API
+type solution struct {
+	Status   string `json:"status"`
+	Message  string `json:"message"`
+	Solution struct {
+		URL     string `json:"url"`
+		Cookies []struct {
+			Name     string  `json:"name"`
+			Value    string  `json:"value"`
+			Domain   string  `json:"domain"`
+			Path     string  `json:"path"`
+			Expires  float64 `json:"expires"`
+			HttpOnly bool    `json:"httpOnly"`
+			Secure   bool    `json:"secure"`
+		} `json:"cookies"`
+		UserAgent string `json:"userAgent"`
+	} `json:"solution"`
+}
+
+// solve asks the external solver to pass the challenge of the page.
+// Cookies are added to the jar, user agent is remembered for the host
+func solve(u *url.URL) error {
+	solver.mu.Lock()
+	defer solver.mu.Unlock()
+
+	if solver.solvedAt == nil {
+		solver.solvedAt = make(map[string]time.Time)
+	}
+
+	// solved by another request while this one was waiting
+	if time.Since(solver.solvedAt[u.Hostname()]) < solvedRecently {
+		return nil
+	}
+
+	timeout := time.Duration(viper.GetInt(key.NetworkSolverTimeout)) * time.Second
+
+	payload, err := json.Marshal(map[string]any{
+		"cmd":        "request.get",
+		"url":        u.String(),
+		"maxTimeout": timeout.Milliseconds(),
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info("solving challenge for " + u.Hostname())
+
+	// solver is not called through the shared client, its requests are not challenged
+	client := &http.Client{Timeout: timeout + 10*time.Second}
+	res, err := client.Post(viper.GetString(key.NetworkSolverURL), "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	var s solution
+	if err = json.NewDecoder(res.Body).Decode(&s); err != nil {
+		return fmt.Errorf("solver: %s", res.Status)
+	}
+
+	if s.Status != "ok" {
+		return fmt.Errorf("solver: %s", s.Message)
+	}
+
+	var cookies []*http.Cookie
+	for _, c := range s.Solution.Cookies {
+		cookie := &http.Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			HttpOnly: c.HttpOnly,
+			Secure:   c.Secure,
+		}
+
+		if cookie.Domain == "" {
+			cookie.Domain = u.Hostname()
+		}
+
+		if c.Expires > 0 {
+			cookie.Expires = time.Unix(int64(c.Expires), 0)
+		}
+
+		cookies = append(cookies, cookie)
+	}
+
+	if err = Jar.Import(cookies, false); err != nil {
+		return err
+	}
+
+	if s.Solution.UserAgent != "" {
+		solver.agents.Store(u.Hostname(), s.Solution.UserAgent)
+	}
+
+	solver.solvedAt[u.Hostname()] = time.Now()
+	return nil
+}
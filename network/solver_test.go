@@ -0,0 +1,98 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/where"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+)
+
+func TestSolver(t *testing.T) {
+	defer viper.Reset()
+	t.Setenv(where.EnvConfigPath, t.TempDir())
+
+	Convey("Given a site behind JavaScript challenge", t, func() {
+		So(Jar.Clear(), ShouldBeNil)
+
+		site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cookie, err := r.Cookie("cf_clearance")
+			if err != nil || cookie.Value != "passed" || r.UserAgent() != "solver-agent" {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_, _ = fmt.Fprint(w, "<html><title>Just a moment...</title></html>")
+				return
+			}
+
+			_, _ = fmt.Fprint(w, "content")
+		}))
+		defer site.Close()
+
+		var solved int
+		solverServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var request struct {
+				Cmd string `json:"cmd"`
+				URL string `json:"url"`
+			}
+
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Cmd != "request.get" {
+				_, _ = fmt.Fprint(w, `{"status": "error", "message": "bad request"}`)
+				return
+			}
+
+			solved++
+			host, _ := url.Parse(request.URL)
+			_, _ = fmt.Fprintf(w, `{"status": "ok", "solution": {
+				"url": %q,
+				"cookies": [{"name": "cf_clearance", "value": "passed", "domain": %q, "path": "/"}],
+				"userAgent": "solver-agent"
+			}}`, request.URL, host.Hostname())
+		}))
+		defer solverServer.Close()
+
+		get := func() (int, string) {
+			res, err := Client.Get(site.URL + "/manga")
+			So(err, ShouldBeNil)
+			defer res.Body.Close()
+
+			body, err := io.ReadAll(res.Body)
+			So(err, ShouldBeNil)
+			return res.StatusCode, string(body)
+		}
+
+		Convey("When solver is not configured", func() {
+			viper.Set(key.NetworkSolverURL, "")
+			status, body := get()
+
+			Convey("Then challenge page should be returned as is", func() {
+				So(status, ShouldEqual, http.StatusServiceUnavailable)
+				So(body, ShouldContainSubstring, "Just a moment...")
+			})
+		})
+
+		Convey("When solver is configured", func() {
+			viper.Set(key.NetworkSolverURL, solverServer.URL)
+			viper.Set(key.NetworkSolverTimeout, 5)
+
+			status, body := get()
+
+			Convey("Then the request should be retried with the solution", func() {
+				So(status, ShouldEqual, http.StatusOK)
+				So(body, ShouldEqual, "content")
+			})
+
+			Convey("Then the solution should be reused by the following requests", func() {
+				status, body = get()
+				So(status, ShouldEqual, http.StatusOK)
+				So(body, ShouldEqual, "content")
+				So(solved, ShouldEqual, 1)
+			})
+		})
+	})
+}
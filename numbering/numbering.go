@@ -0,0 +1,107 @@
+// Package numbering parses chapter and volume numbers from their names.
+package numbering
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// chapterRegex matches explicitly marked chapter numbers, e.g. "Chapter 10.5" or "Ch. 3"
+	chapterRegex = regexp.MustCompile(`(?i)(?:^|[^a-z])(?:chapter|chap|ch|episode|ep|#)\.?\s*(\d+(?:[.,]\d+)?)`)
+	// volumeRegex matches explicitly marked volume numbers, e.g. "Vol.2" or "Volume 3"
+	volumeRegex = regexp.MustCompile(`(?i)(?:^|[^a-z])(?:volume|vol|v)\.?\s*(\d+(?:[.,]\d+)?)`)
+	// extraRegex matches names of the extra chapters
+	extraRegex = regexp.MustCompile(`(?i)\b(?:extra|omake|special|bonus|side\s*story)\b`)
+	// numberRegex matches any number
+	numberRegex = regexp.MustCompile(`\d+(?:[.,]\d+)?`)
+)
+
+// Chapter is a number of the chapter
+type Chapter struct {
+	// Number of the chapter
+	Number float64
+	// Extra is true for extra chapters, e.g. "Extra", "Omake" or "Special".
+	// Numbered extras are placed after the chapter with the same number
+	Extra bool
+}
+
+// Less reports whether the chapter goes before the other one
+func (c Chapter) Less(other Chapter) bool {
+	if c.Number != other.Number {
+		return c.Number < other.Number
+	}
+
+	return !c.Extra && other.Extra
+}
+
+// String returns the formatted chapter number
+func (c Chapter) String() string {
+	return Format(c.Number)
+}
+
+func parseFloat(s string) (float64, bool) {
+	number, err := strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+	return number, err == nil
+}
+
+// ParseChapter parses the chapter number from its name.
+// Explicitly marked numbers, such as "Chapter 10.5", are preferred.
+// Otherwise, the first number that is not a volume number is used.
+// It returns false if there is no number, but Extra is still set for the unnumbered extras
+func ParseChapter(name string) (Chapter, bool) {
+	chapter := Chapter{Extra: extraRegex.MatchString(name)}
+
+	if groups := chapterRegex.FindStringSubmatch(name); groups != nil {
+		number, ok := parseFloat(groups[1])
+		chapter.Number = number
+		return chapter, ok
+	}
+
+	withoutVolume := volumeRegex.ReplaceAllString(name, " ")
+	if match := numberRegex.FindString(withoutVolume); match != "" {
+		number, ok := parseFloat(match)
+		chapter.Number = number
+		return chapter, ok
+	}
+
+	return chapter, false
+}
+
+// ParseVolume parses the volume number, e.g. from "Vol.2", "Volume 3" or just "3".
+// It can also be used with the chapter names that include volumes, such as "Vol.2 Ch.10"
+func ParseVolume(name string) (float64, bool) {
+	if groups := volumeRegex.FindStringSubmatch(name); groups != nil {
+		return parseFloat(groups[1])
+	}
+
+	// plain number, e.g. the volume field of the chapter
+	if strings.TrimSpace(name) == numberRegex.FindString(name) && name != "" {
+		return parseFloat(strings.TrimSpace(name))
+	}
+
+	return 0, false
+}
+
+// Format formats the number without trailing zeros, e.g. "10" or "10.5"
+func Format(number float64) string {
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
+
+// Pad formats the number with the integer part padded with zeros to the given width, e.g. "0010.5"
+func Pad(number float64, width int) string {
+	formatted := Format(number)
+
+	integer, fraction, found := strings.Cut(formatted, ".")
+	if len(integer) < width {
+		integer = strings.Repeat("0", width-len(integer)) + integer
+	}
+
+	if found {
+		return fmt.Sprintf("%s.%s", integer, fraction)
+	}
+
+	return integer
+}
@@ -0,0 +1,100 @@
+package numbering
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseChapter(t *testing.T) {
+	Convey("Given chapter names", t, func() {
+		cases := []struct {
+			name   string
+			number float64
+			extra  bool
+		}{
+			{"Chapter 12", 12, false},
+			{"Vol.2 Ch. 10.5: Title", 10.5, false},
+			{"ch.7", 7, false},
+			{"#3 Beginning", 3, false},
+			{"45", 45, false},
+			{"Volume 3 - 21", 21, false},
+			{"Chapter 10 Extra", 10, true},
+			{"Episode 4,5", 4.5, false},
+		}
+
+		for _, c := range cases {
+			c := c
+			Convey("When parsing "+c.name, func() {
+				chapter, ok := ParseChapter(c.name)
+				Convey("Then the number should be parsed", func() {
+					So(ok, ShouldBeTrue)
+					So(chapter.Number, ShouldEqual, c.number)
+					So(chapter.Extra, ShouldEqual, c.extra)
+				})
+			})
+		}
+
+		Convey("When parsing an unnumbered extra", func() {
+			chapter, ok := ParseChapter("Extra: Beach Episode")
+			Convey("Then it should be marked as extra without a number", func() {
+				So(ok, ShouldBeFalse)
+				So(chapter.Extra, ShouldBeTrue)
+			})
+		})
+	})
+}
+
+func TestParseVolume(t *testing.T) {
+	Convey("Given volume names", t, func() {
+		cases := map[string]float64{
+			"Vol.10":         10,
+			"Volume 2":       2,
+			"3":              3,
+			"Vol.2 Ch.10":    2,
+			"v4 Chapter 100": 4,
+		}
+
+		for name, want := range cases {
+			name, want := name, want
+			Convey("When parsing "+name, func() {
+				got, ok := ParseVolume(name)
+				Convey("Then the number should be parsed", func() {
+					So(ok, ShouldBeTrue)
+					So(got, ShouldEqual, want)
+				})
+			})
+		}
+
+		Convey("When there is no volume", func() {
+			_, ok := ParseVolume("Chapter 1")
+			Convey("Then it should not be parsed", func() {
+				So(ok, ShouldBeFalse)
+			})
+		})
+	})
+}
+
+func TestOrder(t *testing.T) {
+	Convey("Given chapters with the same number", t, func() {
+		regular := Chapter{Number: 10}
+		extra := Chapter{Number: 10, Extra: true}
+
+		Convey("Then the extra should go after the regular one", func() {
+			So(regular.Less(extra), ShouldBeTrue)
+			So(extra.Less(regular), ShouldBeFalse)
+			So(regular.Less(Chapter{Number: 10.5}), ShouldBeTrue)
+		})
+	})
+}
+
+func TestFormat(t *testing.T) {
+	Convey("Given numbers", t, func() {
+		Convey("Then they should be formatted without trailing zeros", func() {
+			So(Format(10), ShouldEqual, "10")
+			So(Format(10.5), ShouldEqual, "10.5")
+			So(Pad(10.5, 4), ShouldEqual, "0010.5")
+			So(Pad(7, 4), ShouldEqual, "0007")
+		})
+	})
+}
@@ -8,47 +8,58 @@ import (
 )
 
 func (s *luaSource) ChaptersOf(manga *source.Manga) ([]*source.Chapter, error) {
-	if chapters := s.cache.chapters.Get(manga.URL); chapters.IsPresent() {
-		c := chapters.MustGet()
-		for _, chapter := range c {
-			chapter.Manga = manga
+	var result []*source.Chapter
+
+	err := s.worker.do(func() error {
+		if chapters := s.cache.chapters.Get(manga.URL); chapters.IsPresent() {
+			c := chapters.MustGet()
+			for _, chapter := range c {
+				chapter.Manga = manga
+			}
+
+			result = c
+			return nil
 		}
 
-		return c, nil
-	}
+		val, err := s.call(constant.MangaChaptersFn, lua.LTTable, lua.LString(manga.URL))
 
-	_, err := s.call(constant.MangaChaptersFn, lua.LTTable, lua.LString(manga.URL))
+		if err != nil {
+			return err
+		}
 
-	if err != nil {
-		return nil, err
-	}
+		table := val.(*lua.LTable)
+		chapters := make([]*source.Chapter, 0)
 
-	table := s.state.CheckTable(-1)
-	chapters := make([]*source.Chapter, 0)
+		table.ForEach(func(k lua.LValue, v lua.LValue) {
+			if k.Type() != lua.LTNumber {
+				s.state.RaiseError(constant.MangaChaptersFn + " was expected to return a table with numbers as keys, got " + k.Type().String() + " as a key")
+			}
 
-	table.ForEach(func(k lua.LValue, v lua.LValue) {
-		if k.Type() != lua.LTNumber {
-			s.state.RaiseError(constant.MangaChaptersFn + " was expected to return a table with numbers as keys, got " + k.Type().String() + " as a key")
-		}
+			if v.Type() != lua.LTTable {
+				s.state.RaiseError(constant.MangaChaptersFn + " was expected to return a table with tables as values, got " + v.Type().String() + " as a value")
+			}
 
-		if v.Type() != lua.LTTable {
-			s.state.RaiseError(constant.MangaChaptersFn + " was expected to return a table with tables as values, got " + v.Type().String() + " as a value")
-		}
+			index, err := strconv.ParseUint(k.String(), 10, 16)
+			if err != nil {
+				s.state.RaiseError(constant.MangaChaptersFn + " was expected to return a table with unsigned integers as keys. " + err.Error())
+			}
 
-		index, err := strconv.ParseUint(k.String(), 10, 16)
-		if err != nil {
-			s.state.RaiseError(constant.MangaChaptersFn + " was expected to return a table with unsigned integers as keys. " + err.Error())
-		}
+			chapter, err := chapterFromTable(v.(*lua.LTable), manga, uint16(index))
 
-		chapter, err := chapterFromTable(v.(*lua.LTable), manga, uint16(index))
+			if err != nil {
+				s.state.RaiseError(err.Error())
+			}
 
-		if err != nil {
-			s.state.RaiseError(err.Error())
-		}
+			chapters = append(chapters, chapter)
+		})
+
+		// sort by the parsed numbers, so that templates and metadata use the same order as other sources
+		source.SortChapters(chapters)
 
-		chapters = append(chapters, chapter)
+		_ = s.cache.chapters.Set(manga.URL, chapters)
+		result = chapters
+		return nil
 	})
 
-	_ = s.cache.chapters.Set(manga.URL, chapters)
-	return chapters, nil
+	return result, err
 }
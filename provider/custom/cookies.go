@@ -0,0 +1,115 @@
+package custom
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/metafates/mangal/network"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// cookiesLoader returns the loader of the cookies module.
+// Cookies are shared with the http client and other sources,
+// only the hosts allowed by the permissions can be accessed
+func cookiesLoader(permissions Permissions) lua.LGFunction {
+	checkURL := func(state *lua.LState, n int) *url.URL {
+		u, err := url.Parse(state.CheckString(n))
+		if err != nil || u.Hostname() == "" {
+			state.ArgError(n, "absolute url expected")
+			return nil
+		}
+
+		if !permissions.AllowsHost(u.Hostname()) {
+			state.RaiseError("network access to %q is not permitted, add it to `-- @network` in the script header", u.Hostname())
+			return nil
+		}
+
+		return u
+	}
+
+	set := func(state *lua.LState) int {
+		u := checkURL(state, 1)
+		cookie := &http.Cookie{
+			Name:   state.CheckString(2),
+			Value:  state.CheckString(3),
+			Domain: u.Hostname(),
+			Path:   "/",
+			Secure: u.Scheme == "https",
+		}
+
+		var persist bool
+		if options := state.OptTable(4, nil); options != nil {
+			if path := options.RawGetString("path"); path != lua.LNil {
+				cookie.Path = path.String()
+			}
+
+			// cookie is sent to the subdomains too
+			if lua.LVAsBool(options.RawGetString("subdomains")) {
+				cookie.Domain = "." + cookie.Domain
+			}
+
+			if expires, ok := options.RawGetString("expires").(lua.LNumber); ok {
+				cookie.Expires = time.Unix(int64(expires), 0)
+			}
+
+			cookie.HttpOnly = lua.LVAsBool(options.RawGetString("http_only"))
+			persist = lua.LVAsBool(options.RawGetString("persist"))
+		}
+
+		if err := network.Jar.Import([]*http.Cookie{cookie}, persist); err != nil {
+			state.RaiseError(err.Error())
+		}
+
+		return 0
+	}
+
+	get := func(state *lua.LState) int {
+		u := checkURL(state, 1)
+
+		table := state.NewTable()
+		for _, cookie := range network.Jar.Cookies(u) {
+			table.RawSetString(cookie.Name, lua.LString(cookie.Value))
+		}
+
+		state.Push(table)
+		return 1
+	}
+
+	// parse adds cookies in the Netscape format
+	parse := func(state *lua.LState) int {
+		cookies, err := network.ParseCookies(strings.NewReader(state.CheckString(1)))
+		if err != nil {
+			state.RaiseError("invalid cookies: %s", err)
+			return 0
+		}
+
+		for _, cookie := range cookies {
+			host := strings.TrimPrefix(cookie.Domain, ".")
+			if !permissions.AllowsHost(host) {
+				state.RaiseError("network access to %q is not permitted, add it to `-- @network` in the script header", host)
+				return 0
+			}
+		}
+
+		if err = network.Jar.Import(cookies, lua.LVAsBool(state.Get(2))); err != nil {
+			state.RaiseError(err.Error())
+			return 0
+		}
+
+		state.Push(lua.LNumber(len(cookies)))
+		return 1
+	}
+
+	return func(state *lua.LState) int {
+		module := state.SetFuncs(state.NewTable(), map[string]lua.LGFunction{
+			"set":    set,
+			"get":    get,
+			"import": parse,
+		})
+
+		state.Push(module)
+		return 1
+	}
+}
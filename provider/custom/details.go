@@ -0,0 +1,30 @@
+package custom
+
+import (
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/source"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// MangaDetails fills metadata of the manga with the table returned by the optional MangaDetails function.
+// source.ErrNoDetails is returned if the function is not defined
+func (s *luaSource) MangaDetails(manga *source.Manga) error {
+	return s.worker.do(func() error {
+		if !s.defines(constant.MangaDetailsFn) {
+			return source.ErrNoDetails
+		}
+
+		val, err := s.call(constant.MangaDetailsFn, lua.LTTable, lua.LString(manga.URL))
+		if err != nil {
+			return err
+		}
+
+		return translate(val.(*lua.LTable), metadataMappings(&manga.Metadata))
+	})
+}
+
+// defines checks if the global function is defined.
+// It must be called from the worker goroutine
+func (s *luaSource) defines(fn string) bool {
+	return s.state.GetGlobal(fn).Type() == lua.LTFunction
+}
@@ -0,0 +1,179 @@
+package custom
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/source"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const detailsScript = `-- @network
+
+function SearchManga(query, page)
+	if page > 2 then
+		return {}
+	end
+
+	return { { name = query .. " " .. page, url = "https://example.com/" .. query .. "/" .. page } }
+end
+
+function MangaDetails(url)
+	return {
+		summary = "Pirates",
+		authors = "Eiichiro Oda",
+		status = "Ongoing",
+		alt_titles = { "OP", "Wan Pīsu" },
+		language = "EN",
+		year = 1997,
+	}
+end
+
+function MangaChapters(url)
+	return { { name = "Chapter 1", url = url .. "/1", date = "2020-01-02", scanlator = "Team" } }
+end
+
+function ChapterPages(url)
+	return {}
+end
+`
+
+func loadDetailsSource(t *testing.T, script string) *luaSource {
+	path := filepath.Join(t.TempDir(), fmt.Sprintf("details_%d.lua", time.Now().UnixNano()))
+	So(os.WriteFile(path, []byte(script), os.ModePerm), ShouldBeNil)
+
+	src, err := LoadSource(path, true)
+	So(err, ShouldBeNil)
+
+	return src.(*luaSource)
+}
+
+const unsortedChaptersScript = `-- @network
+
+function SearchManga(query)
+	return {}
+end
+
+function MangaChapters(url)
+	return {
+		{ name = "Chapter 10", url = url .. "/10" },
+		{ name = "Extra", url = url .. "/extra" },
+		{ name = "Chapter 2", url = url .. "/2" },
+		{ name = "Special", url = url .. "/special", chapter_number = 1.5 },
+	}
+end
+
+function ChapterPages(url)
+	return {}
+end
+`
+
+func TestLuaSourceChaptersOrder(t *testing.T) {
+	Convey("Given a lua source that lists chapters out of order", t, func() {
+		src := loadDetailsSource(t, unsortedChaptersScript)
+		defer src.close()
+
+		Convey("When getting chapters", func() {
+			manga := &source.Manga{URL: fmt.Sprintf("https://example.com/%d", time.Now().UnixNano())}
+			chapters, err := src.ChaptersOf(manga)
+
+			Convey("Then they should be sorted by the parsed numbers and reindexed", func() {
+				So(err, ShouldBeNil)
+				So(chapters, ShouldHaveLength, 4)
+
+				for i, name := range []string{"Special", "Chapter 2", "Chapter 10", "Extra"} {
+					So(chapters[i].Name, ShouldEqual, name)
+					So(chapters[i].Index, ShouldEqual, i+1)
+				}
+			})
+		})
+	})
+}
+
+func TestLuaSourceDetails(t *testing.T) {
+	defer viper.Reset()
+
+	Convey("Given a lua source with paginated search", t, func() {
+		viper.Set(key.LuaSearchPages, 5)
+		src := loadDetailsSource(t, detailsScript)
+		defer src.close()
+
+		Convey("When searching", func() {
+			mangas, err := src.Search(fmt.Sprint(time.Now().UnixNano()))
+
+			Convey("Then every page should be requested until nothing is returned", func() {
+				So(err, ShouldBeNil)
+				So(mangas, ShouldHaveLength, 2)
+				So(mangas[0].Index, ShouldEqual, 1)
+				So(mangas[1].Index, ShouldEqual, 2)
+			})
+		})
+
+		Convey("When getting manga details", func() {
+			manga := &source.Manga{URL: "https://example.com/x"}
+			err := src.MangaDetails(manga)
+
+			Convey("Then metadata should be filled", func() {
+				So(err, ShouldBeNil)
+				So(manga.Metadata.Summary, ShouldEqual, "Pirates")
+				So(manga.Metadata.Staff.Story, ShouldResemble, []string{"Eiichiro Oda"})
+				So(manga.Metadata.Status, ShouldEqual, "RELEASING")
+				So(manga.Metadata.Synonyms, ShouldResemble, []string{"OP", "Wan Pīsu"})
+				So(manga.Metadata.Language, ShouldEqual, "en")
+				So(manga.Metadata.StartDate.Year, ShouldEqual, 1997)
+			})
+		})
+
+		Convey("When getting chapters", func() {
+			chapters, err := src.ChaptersOf(&source.Manga{URL: fmt.Sprintf("https://example.com/%d", time.Now().UnixNano())})
+
+			Convey("Then chapter date and group should be set", func() {
+				So(err, ShouldBeNil)
+				So(chapters, ShouldHaveLength, 1)
+				So(chapters[0].Date, ShouldNotBeNil)
+				So(chapters[0].Date.Format("2006-01-02"), ShouldEqual, "2020-01-02")
+				So(chapters[0].Group, ShouldEqual, "Team")
+				So(chapters[0].ComicInfo().Translator, ShouldEqual, "Team")
+			})
+		})
+	})
+
+	Convey("Given a lua source that ignores the page", t, func() {
+		viper.Set(key.LuaSearchPages, 5)
+		src := loadDetailsSource(t, `-- @network
+function SearchManga(query)
+	return { { name = query, url = "https://example.com/" .. query } }
+end
+
+function MangaChapters(url)
+	return {}
+end
+
+function ChapterPages(url)
+	return {}
+end
+`)
+		defer src.close()
+
+		Convey("When searching", func() {
+			mangas, err := src.Search(fmt.Sprint(time.Now().UnixNano()))
+
+			Convey("Then duplicate results should stop the search", func() {
+				So(err, ShouldBeNil)
+				So(mangas, ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When getting manga details", func() {
+			err := src.MangaDetails(&source.Manga{})
+
+			Convey("Then no details error should be returned", func() {
+				So(err, ShouldEqual, source.ErrNoDetails)
+			})
+		})
+	})
+}
@@ -0,0 +1,300 @@
+package custom
+
+import (
+	"encoding/json"
+	"fmt"
+	luajson "github.com/metafates/mangal-lua-libs/json"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/model"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/util"
+	lua "github.com/yuin/gopher-lua"
+	"sort"
+	"time"
+)
+
+// TestOptions are options of the source test
+type TestOptions struct {
+	// Query to search for
+	Query string
+	// Limit of the results to include in the report
+	Limit int
+	// Manga is the index of the found manga to get chapters of
+	Manga int
+	// Chapter is the index of the chapter to get pages of
+	Chapter int
+}
+
+// FieldError is an error of the table returned by the source function
+type FieldError struct {
+	// Key of the table in the returned list, empty if a single table is returned
+	Key string `json:"key"`
+	// Field of the table, empty if the table itself is invalid
+	Field string `json:"field,omitempty"`
+	// Error message
+	Error string `json:"error"`
+}
+
+// TestStep is a result of a single source function call
+type TestStep struct {
+	// Function that was called
+	Function string `json:"function"`
+	// Input of the function
+	Input string `json:"input"`
+	// Took is the duration of the call in milliseconds
+	Took int64 `json:"took_ms"`
+	// Count of the returned tables
+	Count int `json:"count"`
+	// Results are the first returned tables
+	Results []json.RawMessage `json:"results"`
+	// FieldErrors are the errors of the returned tables
+	FieldErrors []*FieldError `json:"field_errors,omitempty"`
+	// Error of the call
+	Error string `json:"error,omitempty"`
+}
+
+// TestReport is a report of the source test
+type TestReport struct {
+	// Source name
+	Source string `json:"source"`
+	// Path to the source file
+	Path string `json:"path"`
+	// Passed is true if every step succeeded without field errors
+	Passed bool `json:"passed"`
+	// Load is the duration of loading the source in milliseconds
+	Load int64 `json:"load_ms"`
+	// Error of loading the source
+	Error string `json:"error,omitempty"`
+	// Steps of the test
+	Steps []*TestStep `json:"steps"`
+}
+
+func (r *TestReport) failed() bool {
+	if r.Error != "" {
+		return true
+	}
+
+	for _, step := range r.Steps {
+		if step.Error != "" || len(step.FieldErrors) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Test runs SearchManga, MangaDetails (if defined), MangaChapters and ChapterPages functions of the source end to end.
+// Every returned table is validated against the fields that mangal expects.
+// The test stops at the first step that fails or returns nothing
+func Test(path string, options TestOptions) *TestReport {
+	report := &TestReport{
+		Source: util.FileStem(path),
+		Path:   path,
+		Steps:  make([]*TestStep, 0),
+	}
+
+	defer func() {
+		report.Passed = !report.failed()
+	}()
+
+	start := time.Now()
+	loaded, err := LoadSource(path, true)
+	report.Load = time.Since(start).Milliseconds()
+
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+
+	s := loaded.(*luaSource)
+	defer s.close()
+
+	var (
+		manga   = &source.Manga{}
+		chapter = &source.Chapter{Manga: manga}
+		mangas  []*source.Manga
+	)
+
+	step := s.test(constant.SearchMangaFn, options.Query, options, func(table *lua.LTable) map[string]error {
+		found := &source.Manga{}
+		errs := translateAll(table, mangaMappings(found))
+		if len(errs) == 0 {
+			mangas = append(mangas, found)
+		}
+		return errs
+	}, lua.LNumber(1))
+	report.Steps = append(report.Steps, step)
+
+	if step.Error != "" {
+		return report
+	}
+
+	if options.Manga < 0 || options.Manga >= len(mangas) {
+		step.Error = fmt.Sprintf("manga #%d is not found, %d valid mangas returned", options.Manga, len(mangas))
+		return report
+	}
+	manga.URL = mangas[options.Manga].URL
+
+	var defined bool
+	_ = s.worker.do(func() error {
+		defined = s.defines(constant.MangaDetailsFn)
+		return nil
+	})
+
+	if defined {
+		step = s.testDetails(manga.URL, options)
+		report.Steps = append(report.Steps, step)
+
+		if step.Error != "" {
+			return report
+		}
+	}
+
+	var chapters []*source.Chapter
+	step = s.test(constant.MangaChaptersFn, manga.URL, options, func(table *lua.LTable) map[string]error {
+		found := &source.Chapter{Manga: manga}
+		errs := translateAll(table, chapterMappings(found, manga))
+		if len(errs) == 0 {
+			chapters = append(chapters, found)
+		}
+		return errs
+	})
+	report.Steps = append(report.Steps, step)
+
+	if step.Error != "" {
+		return report
+	}
+
+	if options.Chapter < 0 || options.Chapter >= len(chapters) {
+		step.Error = fmt.Sprintf("chapter #%d is not found, %d valid chapters returned", options.Chapter, len(chapters))
+		return report
+	}
+	chapter.URL = chapters[options.Chapter].URL
+
+	step = s.test(constant.ChapterPagesFn, chapter.URL, options, func(table *lua.LTable) map[string]error {
+		return translateAll(table, pageMappings(&source.Page{Chapter: chapter}))
+	})
+	report.Steps = append(report.Steps, step)
+
+	return report
+}
+
+// test calls the function with the input and extra arguments and validates every returned table
+func (s *luaSource) test(fn, input string, options TestOptions, validate func(*lua.LTable) map[string]error, args ...lua.LValue) *TestStep {
+	step := &TestStep{
+		Function: fn,
+		Input:    input,
+		Results:  make([]json.RawMessage, 0),
+	}
+
+	start := time.Now()
+	err := s.worker.do(func() error {
+		val, err := s.call(fn, lua.LTTable, append([]lua.LValue{lua.LString(input)}, args...)...)
+		step.Took = time.Since(start).Milliseconds()
+
+		if err != nil {
+			return err
+		}
+
+		val.(*lua.LTable).ForEach(func(k lua.LValue, v lua.LValue) {
+			step.Count++
+
+			if k.Type() != lua.LTNumber {
+				step.FieldErrors = append(step.FieldErrors, &FieldError{
+					Key:   k.String(),
+					Error: "key must be a number, got " + k.Type().String(),
+				})
+			}
+
+			table, ok := v.(*lua.LTable)
+			if !ok {
+				step.FieldErrors = append(step.FieldErrors, &FieldError{
+					Key:   k.String(),
+					Error: "value must be a table, got " + v.Type().String(),
+				})
+				return
+			}
+
+			if len(step.Results) < options.Limit {
+				step.Results = append(step.Results, encode(table))
+			}
+
+			step.FieldErrors = append(step.FieldErrors, fieldErrors(k.String(), validate(table))...)
+		})
+
+		return nil
+	})
+
+	if err != nil {
+		step.Error = err.Error()
+	} else if step.Count == 0 {
+		step.Error = "nothing returned"
+	}
+
+	return step
+}
+
+// testDetails calls the MangaDetails function and validates the returned table
+func (s *luaSource) testDetails(url string, options TestOptions) *TestStep {
+	step := &TestStep{
+		Function: constant.MangaDetailsFn,
+		Input:    url,
+		Results:  make([]json.RawMessage, 0),
+	}
+
+	start := time.Now()
+	err := s.worker.do(func() error {
+		val, err := s.call(constant.MangaDetailsFn, lua.LTTable, lua.LString(url))
+		step.Took = time.Since(start).Milliseconds()
+
+		if err != nil {
+			return err
+		}
+
+		table := val.(*lua.LTable)
+		step.Count = 1
+
+		if options.Limit > 0 {
+			step.Results = append(step.Results, encode(table))
+		}
+
+		step.FieldErrors = fieldErrors("", translateAll(table, metadataMappings(&model.MangaMetadata{})))
+		return nil
+	})
+
+	if err != nil {
+		step.Error = err.Error()
+	}
+
+	return step
+}
+
+// fieldErrors converts errors by field names to the field errors of the table, sorted by field
+func fieldErrors(key string, errs map[string]error) []*FieldError {
+	fields := make([]string, 0, len(errs))
+	for field := range errs {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	result := make([]*FieldError, 0, len(fields))
+	for _, field := range fields {
+		result = append(result, &FieldError{
+			Key:   key,
+			Field: field,
+			Error: errs[field].Error(),
+		})
+	}
+
+	return result
+}
+
+// encode encodes the table to JSON, tables that can't be encoded are replaced with the error
+func encode(table *lua.LTable) json.RawMessage {
+	data, err := luajson.ValueEncode(table)
+	if err != nil {
+		data, _ = json.Marshal(map[string]string{"error": err.Error()})
+	}
+
+	return data
+}
@@ -0,0 +1,102 @@
+package custom
+
+import (
+	"encoding/json"
+	. "github.com/smartystreets/goconvey/convey"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const harnessScript = `-- @network
+
+function SearchManga(query)
+	return {
+		{ name = query, url = "https://example.com/manga/1" },
+		{ name = "Broken", url = "https://example.com/manga/2", cover = 42, covr = "typo" },
+	}
+end
+
+function MangaDetails(url)
+	return { authors = "Oda", status = "airing", alt_titles = { "OP" } }
+end
+
+function MangaChapters(url)
+	return { { name = "Chapter 1", url = url .. "/1", chapter_number = 1, date = "2020-01-02" } }
+end
+
+function ChapterPages(url)
+	return { { url = url .. "/1.png", index = 1 }, { url = url .. "/2.png" } }
+end
+`
+
+func TestTest(t *testing.T) {
+	Convey("Given a lua source", t, func() {
+		path := filepath.Join(t.TempDir(), "harness.lua")
+		So(os.WriteFile(path, []byte(harnessScript), os.ModePerm), ShouldBeNil)
+
+		Convey("When testing it", func() {
+			report := Test(path, TestOptions{Query: "one piece", Limit: 1})
+
+			Convey("Then every step should be run", func() {
+				So(report.Error, ShouldBeEmpty)
+				So(report.Steps, ShouldHaveLength, 4)
+				So(report.Steps[1].Function, ShouldEqual, "MangaDetails")
+				So(report.Steps[1].Input, ShouldEqual, "https://example.com/manga/1")
+				So(report.Steps[2].Input, ShouldEqual, "https://example.com/manga/1")
+				So(report.Steps[3].Input, ShouldEqual, "https://example.com/manga/1/1")
+			})
+
+			Convey("Then results should be limited", func() {
+				search := report.Steps[0]
+				So(search.Count, ShouldEqual, 2)
+				So(search.Results, ShouldHaveLength, 1)
+
+				var manga map[string]any
+				So(json.Unmarshal(search.Results[0], &manga), ShouldBeNil)
+				So(manga["name"], ShouldEqual, "one piece")
+			})
+
+			Convey("Then field errors should be reported", func() {
+				So(report.Passed, ShouldBeFalse)
+				So(report.Steps[0].FieldErrors, ShouldResemble, []*FieldError{
+					{Key: "2", Field: "cover", Error: `field of "cover" must be of type string`},
+					{Key: "2", Field: "covr", Error: `field "covr" is unknown`},
+				})
+				So(report.Steps[1].FieldErrors, ShouldResemble, []*FieldError{
+					{Field: "status", Error: `unknown status "airing", expected one of ongoing, completed, hiatus, cancelled, unreleased`},
+				})
+				So(report.Steps[2].FieldErrors, ShouldBeEmpty)
+				So(report.Steps[3].FieldErrors, ShouldResemble, []*FieldError{
+					{Key: "2", Field: "index", Error: `field of "index" is required`},
+				})
+			})
+		})
+
+		Convey("When testing it with negative indexes", func() {
+			mangaReport := Test(path, TestOptions{Query: "one piece", Manga: -1})
+			chapterReport := Test(path, TestOptions{Query: "one piece", Chapter: -1})
+
+			Convey("Then the steps should fail instead of panicking", func() {
+				So(mangaReport.Steps, ShouldHaveLength, 1)
+				So(mangaReport.Steps[0].Error, ShouldContainSubstring, "manga #-1 is not found")
+				So(chapterReport.Steps[len(chapterReport.Steps)-1].Error, ShouldContainSubstring, "chapter #-1 is not found")
+			})
+		})
+	})
+
+	Convey("Given a source that fails to load", t, func() {
+		path := filepath.Join(t.TempDir(), "broken.lua")
+		So(os.WriteFile(path, []byte("function SearchManga("), os.ModePerm), ShouldBeNil)
+
+		Convey("When testing it", func() {
+			report := Test(path, TestOptions{Query: "x"})
+
+			Convey("Then load error should be reported", func() {
+				So(report.Passed, ShouldBeFalse)
+				So(report.Error, ShouldNotBeEmpty)
+				So(report.Steps, ShouldBeEmpty)
+			})
+		})
+	})
+}
@@ -0,0 +1,44 @@
+package custom
+
+import (
+	"io"
+
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/util"
+)
+
+// ParseHosts parses the hosts of the manga pages that the source handles from the script header.
+// They are used to find the source by the manga URL:
+//
+//	-- @hosts example.com, *.example.org
+func ParseHosts(r io.Reader) ([]string, error) {
+	var hosts []string
+
+	err := parseHeader(r, func(tag, value string) error {
+		if tag != "@hosts" {
+			return nil
+		}
+
+		parsed, err := parseHosts(tag, value)
+		if err != nil {
+			return err
+		}
+
+		hosts = append(hosts, parsed...)
+		return nil
+	})
+
+	return hosts, err
+}
+
+// ReadHosts reads the handled hosts from the header of the script file
+func ReadHosts(path string) ([]string, error) {
+	file, err := filesystem.Api().Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer util.Ignore(file.Close)
+
+	return ParseHosts(file)
+}
@@ -0,0 +1,103 @@
+package custom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/metafates/mangal/key"
+	"github.com/spf13/viper"
+	"sync/atomic"
+	"time"
+)
+
+// ErrInstructionLimit is returned when the Lua source executes too many instructions in a single call
+var ErrInstructionLimit = errors.New("instruction limit exceeded")
+
+// limits of the Lua source execution
+type limits struct {
+	// Timeout of a single call. 0 means no timeout
+	Timeout time.Duration
+	// Instructions is the maximum number of instructions of a single call. 0 means no limit
+	Instructions int64
+	// CallStackSize is the maximum depth of the call stack
+	CallStackSize int
+	// RegistrySize is the maximum size of the data stack
+	RegistrySize int
+}
+
+func limitsFromConfig() limits {
+	return limits{
+		Timeout:       time.Duration(viper.GetInt(key.LuaTimeout)) * time.Second,
+		Instructions:  viper.GetInt64(key.LuaInstructionLimit),
+		CallStackSize: viper.GetInt(key.LuaCallStackSize),
+		RegistrySize:  viper.GetInt(key.LuaRegistrySize),
+	}
+}
+
+// context returns a context for a single call of the Lua source, it is done when the parent is done
+func (l limits) context(parent context.Context) (context.Context, context.CancelFunc) {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+
+	if l.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(parent, l.Timeout)
+	} else {
+		ctx, cancel = context.WithCancel(parent)
+	}
+
+	if l.Instructions > 0 {
+		ctx = newBudget(ctx, l.Instructions)
+	}
+
+	return ctx, cancel
+}
+
+// explain replaces errors caused by the exceeded limits with more descriptive ones
+func (l limits) explain(fn string, ctx context.Context, err error) error {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("%s timed out after %s", fn, l.Timeout)
+	case errors.Is(ctx.Err(), ErrInstructionLimit):
+		return fmt.Errorf("%s exceeded the limit of %d instructions", fn, l.Instructions)
+	default:
+		return err
+	}
+}
+
+var closed = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
+
+// budget is a context that is done after the given number of instructions.
+// Lua VM checks whether the context is done before executing each instruction,
+// so every call of Done is counted as an instruction
+type budget struct {
+	context.Context
+	left atomic.Int64
+}
+
+func newBudget(parent context.Context, instructions int64) *budget {
+	b := &budget{Context: parent}
+	b.left.Store(instructions)
+	return b
+}
+
+func (b *budget) Done() <-chan struct{} {
+	if b.left.Add(-1) < 0 {
+		return closed
+	}
+
+	return b.Context.Done()
+}
+
+func (b *budget) Err() error {
+	if b.left.Load() < 0 {
+		return ErrInstructionLimit
+	}
+
+	return b.Context.Err()
+}
@@ -1,11 +1,14 @@
 package custom
 
 import (
+	"context"
 	"fmt"
-	libs "github.com/metafates/mangal-lua-libs"
 	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
 	"github.com/metafates/mangal/source"
 	"github.com/metafates/mangal/util"
+	"github.com/spf13/viper"
 	lua "github.com/yuin/gopher-lua"
 	"github.com/yuin/gopher-lua/parse"
 )
@@ -14,36 +17,60 @@ func IDfromName(name string) string {
 	return name + " custom"
 }
 
+// LoadSource loads the Lua source from the file.
+// The source runs on its own worker goroutine with modules allowed
+// by the permissions declared in its header
 func LoadSource(path string, validate bool) (source.Source, error) {
 	proto, err := Compile(path)
 	if err != nil {
 		return nil, err
 	}
 
-	state := lua.NewState()
-	libs.Preload(state)
-
-	lfunc := state.NewFunctionFromProto(proto)
-	state.Push(lfunc)
-	err = state.PCall(0, lua.MultRet, nil)
+	permissions, err := ReadPermissions(path)
 	if err != nil {
 		return nil, err
 	}
 
 	name := util.FileStem(path)
 
-	if validate {
-		for _, fn := range mustHave {
-			defined := state.GetGlobal(fn)
+	if !permissions.Declared && !viper.GetBool(key.LuaEnforcePermissions) {
+		log.Warnf("source %s doesn't declare permissions, allowing everything", name)
+		permissions = AllPermissions()
+	}
+
+	limits := limitsFromConfig()
+	luaSource := newLuaSource(name, newState(permissions, limits), limits)
+
+	err = luaSource.worker.do(func() error {
+		state := luaSource.state
+
+		ctx, cancel := limits.context(context.Background())
+		defer cancel()
+
+		state.SetContext(ctx)
+		defer state.RemoveContext()
 
-			if defined.Type() != lua.LTFunction {
-				return nil, fmt.Errorf("required function %s is not defined in the luaSource %s", fn, name)
+		lfunc := state.NewFunctionFromProto(proto)
+		state.Push(lfunc)
+		if err := state.PCall(0, lua.MultRet, nil); err != nil {
+			return limits.explain(name, ctx, err)
+		}
+
+		if validate {
+			for _, fn := range mustHave {
+				defined := state.GetGlobal(fn)
+
+				if defined.Type() != lua.LTFunction {
+					return fmt.Errorf("required function %s is not defined in the luaSource %s", fn, name)
+				}
 			}
 		}
-	}
 
-	luaSource, err := newLuaSource(name, state)
+		return nil
+	})
+
 	if err != nil {
+		luaSource.close()
 		return nil, err
 	}
 
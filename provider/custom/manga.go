@@ -0,0 +1,35 @@
+package custom
+
+import (
+	"fmt"
+
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/source"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// GetManga returns the manga by its URL with the optional GetManga function
+func (s *luaSource) GetManga(id string) (*source.Manga, error) {
+	var manga *source.Manga
+
+	err := s.worker.do(func() error {
+		if !s.defines(constant.GetMangaFn) {
+			return fmt.Errorf("source %s can't get manga by URL, %s function is not defined", s.name, constant.GetMangaFn)
+		}
+
+		val, err := s.call(constant.GetMangaFn, lua.LTTable, lua.LString(id))
+		if err != nil {
+			return err
+		}
+
+		manga, err = mangaFromTable(val.(*lua.LTable), 0)
+		if err != nil {
+			return fmt.Errorf("%s: %w", constant.GetMangaFn, err)
+		}
+
+		manga.Source = s
+		return nil
+	})
+
+	return manga, err
+}
@@ -7,32 +7,39 @@ import (
 )
 
 func (s *luaSource) PagesOf(chapter *source.Chapter) ([]*source.Page, error) {
-	_, err := s.call(constant.ChapterPagesFn, lua.LTTable, lua.LString(chapter.URL))
+	var result []*source.Page
 
-	if err != nil {
-		return nil, err
-	}
+	err := s.worker.do(func() error {
+		val, err := s.call(constant.ChapterPagesFn, lua.LTTable, lua.LString(chapter.URL))
 
-	table := s.state.CheckTable(-1)
-	pages := make([]*source.Page, 0)
-
-	table.ForEach(func(k lua.LValue, v lua.LValue) {
-		if k.Type() != lua.LTNumber {
-			s.state.RaiseError(constant.ChapterPagesFn + " was expected to return a table with numbers as keys, got " + k.Type().String() + " as a key")
+		if err != nil {
+			return err
 		}
 
-		if v.Type() != lua.LTTable {
-			s.state.RaiseError(constant.ChapterPagesFn + " was expected to return a table with tables as values, got " + v.Type().String() + " as a value")
-		}
+		table := val.(*lua.LTable)
+		pages := make([]*source.Page, 0)
 
-		page, err := pageFromTable(v.(*lua.LTable), chapter)
+		table.ForEach(func(k lua.LValue, v lua.LValue) {
+			if k.Type() != lua.LTNumber {
+				s.state.RaiseError(constant.ChapterPagesFn + " was expected to return a table with numbers as keys, got " + k.Type().String() + " as a key")
+			}
 
-		if err != nil {
-			s.state.RaiseError(err.Error())
-		}
+			if v.Type() != lua.LTTable {
+				s.state.RaiseError(constant.ChapterPagesFn + " was expected to return a table with tables as values, got " + v.Type().String() + " as a value")
+			}
+
+			page, err := pageFromTable(v.(*lua.LTable), chapter)
+
+			if err != nil {
+				s.state.RaiseError(err.Error())
+			}
+
+			pages = append(pages, page)
+		})
 
-		pages = append(pages, page)
+		result = pages
+		return nil
 	})
 
-	return pages, nil
+	return result, err
 }
@@ -0,0 +1,165 @@
+package custom
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/util"
+	"io"
+	"strings"
+)
+
+// Permissions of the Lua source.
+// They are declared in the script header, next to the name and author:
+//
+//	-- @network    example.com, *.example.org
+//	-- @filesystem
+//	-- @headless
+type Permissions struct {
+	// Declared is true if any permission is declared in the header
+	Declared bool
+	// Network allows http modules
+	Network bool
+	// Hosts that http requests can be made to, e.g. "example.com" or "*.example.com".
+	// Any host is allowed if empty
+	Hosts []string
+	// Filesystem allows reading and writing files, running commands and environment variables
+	Filesystem bool
+	// Headless allows headless browser
+	Headless bool
+}
+
+// AllPermissions allows everything
+func AllPermissions() Permissions {
+	return Permissions{
+		Network:    true,
+		Filesystem: true,
+		Headless:   true,
+	}
+}
+
+// ParsePermissions parses permissions from the header of the script.
+// Header is the first block of comments of the script
+func ParsePermissions(r io.Reader) (Permissions, error) {
+	var permissions Permissions
+
+	err := parseHeader(r, func(tag, value string) error {
+		switch tag {
+		case "@network":
+			permissions.Declared = true
+			permissions.Network = true
+
+			hosts, err := parseHosts(tag, value)
+			if err != nil {
+				return err
+			}
+
+			permissions.Hosts = append(permissions.Hosts, hosts...)
+		case "@filesystem":
+			permissions.Declared = true
+			permissions.Filesystem = true
+		case "@headless":
+			permissions.Declared = true
+			permissions.Headless = true
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return Permissions{}, err
+	}
+
+	return permissions, nil
+}
+
+// parseHeader calls the handler for each tag of the script header, e.g. "@network example.com".
+// Header is the first block of comments of the script
+func parseHeader(r io.Reader, handle func(tag, value string) error) error {
+	var started bool
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" && !started {
+			continue
+		}
+
+		if !strings.HasPrefix(line, "--") {
+			break
+		}
+
+		started = true
+
+		line = strings.TrimSpace(strings.TrimLeft(line, "-"))
+		if !strings.HasPrefix(line, "@") {
+			continue
+		}
+
+		tag, value, _ := strings.Cut(line, " ")
+		if err := handle(tag, strings.TrimSpace(value)); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// parseHosts parses the list of hosts separated by commas or spaces
+func parseHosts(tag, value string) ([]string, error) {
+	hosts := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+
+	for i, host := range hosts {
+		host = strings.ToLower(host)
+		if strings.ContainsAny(host, "/:") {
+			return nil, fmt.Errorf("%s: invalid host %q, expected something like example.com or *.example.com", tag, host)
+		}
+
+		hosts[i] = host
+	}
+
+	return hosts, nil
+}
+
+// ReadPermissions reads permissions from the header of the script file
+func ReadPermissions(path string) (Permissions, error) {
+	file, err := filesystem.Api().Open(path)
+	if err != nil {
+		return Permissions{}, err
+	}
+
+	defer util.Ignore(file.Close)
+
+	return ParsePermissions(file)
+}
+
+// AllowsHost checks if http requests can be made to the host
+func (p Permissions) AllowsHost(host string) bool {
+	if !p.Network {
+		return false
+	}
+
+	if len(p.Hosts) == 0 {
+		return true
+	}
+
+	return MatchHost(p.Hosts, host)
+}
+
+// MatchHost checks if the host matches any of the patterns, e.g. "example.com", "*.example.com" or "*"
+func MatchHost(patterns []string, host string) bool {
+	host = strings.ToLower(host)
+	for _, pattern := range patterns {
+		if pattern == "*" || pattern == host {
+			return true
+		}
+
+		if strings.HasPrefix(pattern, "*.") && strings.HasSuffix(host, pattern[1:]) {
+			return true
+		}
+	}
+
+	return false
+}
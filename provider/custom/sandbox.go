@@ -0,0 +1,339 @@
+package custom
+
+import (
+	"context"
+	"fmt"
+	"github.com/metafates/mangal-lua-libs/base64"
+	"github.com/metafates/mangal-lua-libs/crypto"
+	"github.com/metafates/mangal-lua-libs/filepath"
+	"github.com/metafates/mangal-lua-libs/goos"
+	"github.com/metafates/mangal-lua-libs/headless"
+	"github.com/metafates/mangal-lua-libs/html"
+	httplib "github.com/metafates/mangal-lua-libs/http"
+	httpclient "github.com/metafates/mangal-lua-libs/http/client"
+	httputil "github.com/metafates/mangal-lua-libs/http/util"
+	"github.com/metafates/mangal-lua-libs/humanize"
+	"github.com/metafates/mangal-lua-libs/inspect"
+	"github.com/metafates/mangal-lua-libs/ioutil"
+	"github.com/metafates/mangal-lua-libs/json"
+	"github.com/metafates/mangal-lua-libs/log"
+	"github.com/metafates/mangal-lua-libs/regexp"
+	"github.com/metafates/mangal-lua-libs/runtime"
+	"github.com/metafates/mangal-lua-libs/shellescape"
+	"github.com/metafates/mangal-lua-libs/stats"
+	"github.com/metafates/mangal-lua-libs/storage"
+	"github.com/metafates/mangal-lua-libs/strings"
+	"github.com/metafates/mangal-lua-libs/template"
+	luatime "github.com/metafates/mangal-lua-libs/time"
+	"github.com/metafates/mangal-lua-libs/xmlpath"
+	"github.com/metafates/mangal-lua-libs/yaml"
+	"github.com/metafates/mangal/network"
+	lua "github.com/yuin/gopher-lua"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// unrestricted modules don't access the network or filesystem
+var unrestricted = []func(*lua.LState){
+	yaml.Preload,
+	html.Preload,
+	xmlpath.Preload,
+	luatime.Preload,
+	strings.Preload,
+	stats.Preload,
+	shellescape.Preload,
+	runtime.Preload,
+	regexp.Preload,
+	json.Preload,
+	inspect.Preload,
+	humanize.Preload,
+	crypto.Preload,
+	base64.Preload,
+	httputil.Preload,
+}
+
+// filesystemModules are the modules that require filesystem permission
+var filesystemModules = map[string]lua.LGFunction{
+	"ioutil":  ioutil.Loader,
+	"goos":    goos.Loader,
+	"storage": storage.Loader,
+	"log":     log.Loader,
+}
+
+// partialModule is the module with some functions that access the filesystem
+type partialModule struct {
+	loader lua.LGFunction
+	// functions returns the table that contains the filesystem functions after the module is loaded
+	functions func(state *lua.LState) lua.LValue
+	// filesystem are the names of the functions that require filesystem permission
+	filesystem []string
+}
+
+// partialModules are available without filesystem permission, except for their filesystem functions
+var partialModules = map[string]partialModule{
+	// template.render_file reads any file
+	"template": {
+		loader: template.Loader,
+		functions: func(state *lua.LState) lua.LValue {
+			return state.GetField(state.GetTypeMetatable("template_ud"), "__index")
+		},
+		filesystem: []string{"render_file"},
+	},
+	// filepath.glob lists the filesystem
+	"filepath": {
+		loader: filepath.Loader,
+		functions: func(state *lua.LState) lua.LValue {
+			return state.Get(-1)
+		},
+		filesystem: []string{"glob"},
+	},
+}
+
+// osFilesystemFunctions are the functions of the os library that require filesystem permission
+var osFilesystemFunctions = []string{"execute", "remove", "rename", "tmpname", "getenv", "setenv"}
+
+// newState creates a new Lua state for the source.
+// Only modules allowed by the permissions are preloaded,
+// the others raise an error explaining which permission is missing when required
+func newState(permissions Permissions, limits limits) *lua.LState {
+	options := lua.Options{
+		SkipOpenLibs:  true,
+		CallStackSize: limits.CallStackSize,
+		RegistrySize:  lua.RegistrySize,
+	}
+
+	if limits.RegistrySize > 0 {
+		if limits.RegistrySize < options.RegistrySize {
+			options.RegistrySize = limits.RegistrySize
+		}
+
+		options.RegistryMaxSize = limits.RegistrySize
+	}
+
+	state := lua.NewState(options)
+	openLibs(state, permissions)
+
+	for _, preload := range unrestricted {
+		preload(state)
+	}
+
+	for name, loader := range filesystemModules {
+		if permissions.Filesystem {
+			state.PreloadModule(name, loader)
+		} else {
+			state.PreloadModule(name, denied(name, "filesystem"))
+		}
+	}
+
+	for name, module := range partialModules {
+		if permissions.Filesystem {
+			state.PreloadModule(name, module.loader)
+		} else {
+			state.PreloadModule(name, withoutFilesystem(name, module))
+		}
+	}
+
+	if permissions.Network {
+		state.PreloadModule("http", guardHTTP(httplib.Loader, permissions))
+		state.PreloadModule("http_client", guardHTTP(httpclient.Loader, permissions))
+		state.PreloadModule("cookies", cookiesLoader(permissions))
+	} else {
+		state.PreloadModule("http", denied("http", "network"))
+		state.PreloadModule("http_client", denied("http_client", "network"))
+		state.PreloadModule("cookies", denied("cookies", "network"))
+	}
+
+	if permissions.Headless {
+		headless.Preload(state)
+	} else {
+		state.PreloadModule("headless", denied("headless", "headless"))
+	}
+
+	return state
+}
+
+// openLibs opens the standard libraries.
+// Debug library is reduced to traceback, the rest of it reaches past the permission wrappers.
+// Without filesystem permission, io library and functions that access files are not available
+func openLibs(state *lua.LState, permissions Permissions) {
+	libs := []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.LoadLibName, lua.OpenPackage},
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.OsLibName, lua.OpenOs},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+		{lua.DebugLibName, lua.OpenDebug},
+		{lua.ChannelLibName, lua.OpenChannel},
+		{lua.CoroutineLibName, lua.OpenCoroutine},
+	}
+
+	if permissions.Filesystem {
+		libs = append(libs, struct {
+			name string
+			open lua.LGFunction
+		}{lua.IoLibName, lua.OpenIo})
+	}
+
+	for _, lib := range libs {
+		state.Push(state.NewFunction(lib.open))
+		state.Push(lua.LString(lib.name))
+		state.Call(1, 0)
+	}
+
+	restrictDebug(state)
+
+	os := state.GetGlobal(lua.OsLibName).(*lua.LTable)
+	// exiting would close mangal itself
+	os.RawSetString("exit", lua.LNil)
+
+	if permissions.Filesystem {
+		return
+	}
+
+	for _, fn := range osFilesystemFunctions {
+		os.RawSetString(fn, lua.LNil)
+	}
+
+	state.SetGlobal("dofile", lua.LNil)
+	state.SetGlobal("loadfile", lua.LNil)
+
+	// disable loading modules from files
+	pkg := state.GetGlobal(lua.LoadLibName).(*lua.LTable)
+	pkg.RawSetString("path", lua.LString(""))
+	pkg.RawSetString("cpath", lua.LString(""))
+}
+
+// restrictDebug replaces the opened debug library with the one that has only the traceback function,
+// both for the global and for require("debug")
+func restrictDebug(state *lua.LState) {
+	full := state.GetGlobal(lua.DebugLibName).(*lua.LTable)
+
+	debug := state.NewTable()
+	debug.RawSetString("traceback", full.RawGetString("traceback"))
+
+	state.SetGlobal(lua.DebugLibName, debug)
+	loaded := state.GetField(state.Get(lua.RegistryIndex), "_LOADED").(*lua.LTable)
+	loaded.RawSetString(lua.DebugLibName, debug)
+}
+
+// denied returns a module loader that raises an error about the missing permission
+func denied(name, permission string) lua.LGFunction {
+	return func(state *lua.LState) int {
+		state.RaiseError(
+			"%q requires %s permission, declare it in the script header with `-- @%s`",
+			name,
+			permission,
+			permission,
+		)
+		return 0
+	}
+}
+
+// withoutFilesystem loads the module with its filesystem functions replaced by the ones raising the permission error
+func withoutFilesystem(name string, module partialModule) lua.LGFunction {
+	return func(state *lua.LState) int {
+		n := module.loader(state)
+
+		if functions, ok := module.functions(state).(*lua.LTable); ok {
+			for _, function := range module.filesystem {
+				functions.RawSetString(function, state.NewFunction(denied(name+"."+function, "filesystem")))
+			}
+		}
+
+		return n
+	}
+}
+
+// guardHTTP wraps the http module loader so that requests are checked against the permissions
+// and bound to the context of the current call
+func guardHTTP(loader lua.LGFunction, permissions Permissions) lua.LGFunction {
+	return func(state *lua.LState) int {
+		n := loader(state)
+
+		index, ok := state.GetField(state.GetTypeMetatable("http_client_ud"), "__index").(*lua.LTable)
+		if ok {
+			index.RawSetString("do_request", state.NewFunction(doRequest(permissions)))
+		}
+
+		if module, ok := state.Get(-1).(*lua.LTable); ok && !permissions.Filesystem {
+			module.RawSetString("file_request", state.NewFunction(func(state *lua.LState) int {
+				state.RaiseError("uploading files requires filesystem permission, declare it in the script header with `-- @filesystem`")
+				return 0
+			}))
+		}
+
+		return n
+	}
+}
+
+// doRequest returns http client do_request function that checks the request host
+func doRequest(permissions Permissions) lua.LGFunction {
+	checkHost := func(u *url.URL) error {
+		if !permissions.AllowsHost(u.Hostname()) {
+			return fmt.Errorf("network access to %q is not permitted, add it to `-- @network` in the script header", u.Hostname())
+		}
+
+		return nil
+	}
+
+	return func(state *lua.LState) int {
+		client, ok := state.CheckUserData(1).Value.(*httpclient.LuaClient)
+		if !ok {
+			state.ArgError(1, "http client expected")
+			return 0
+		}
+
+		// request type is not exported, but it embeds *http.Request
+		request, ok := state.CheckUserData(2).Value.(interface {
+			WithContext(context.Context) *http.Request
+		})
+		if !ok {
+			state.ArgError(2, "http request expected")
+			return 0
+		}
+
+		if err := checkHost(request.WithContext(context.Background()).URL); err != nil {
+			state.RaiseError(err.Error())
+			return 0
+		}
+
+		// share cookies and solved challenges with other sources
+		client.Jar = network.Jar
+		client.Transport = network.WithChallenges(client.Transport)
+
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return fmt.Errorf("stopped after 10 redirects")
+			}
+
+			return checkHost(req.URL)
+		}
+
+		// requests must not outlive the call timeout
+		if ctx := state.Context(); ctx != nil {
+			if deadline, ok := ctx.Deadline(); ok {
+				timeout := client.Timeout
+				defer func() {
+					client.Timeout = timeout
+				}()
+
+				left := time.Until(deadline)
+				if left <= 0 {
+					state.RaiseError(context.DeadlineExceeded.Error())
+					return 0
+				}
+
+				if timeout == 0 || left < timeout {
+					client.Timeout = left
+				}
+			}
+		}
+
+		return httpclient.DoRequest(state)
+	}
+}
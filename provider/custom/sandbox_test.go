@@ -0,0 +1,294 @@
+package custom
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/source"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testFunctions = `
+function SearchManga(query)
+	return {}
+end
+
+function MangaChapters(url)
+	return {}
+end
+`
+
+func loadTestSource(t *testing.T, header, pages string) (source.Source, error) {
+	path := filepath.Join(t.TempDir(), "sandbox.lua")
+	script := header + "\n" + testFunctions + "\nfunction ChapterPages(url)\n" + pages + "\nend\n"
+	So(os.WriteFile(path, []byte(script), os.ModePerm), ShouldBeNil)
+
+	return LoadSource(path, true)
+}
+
+func TestParsePermissions(t *testing.T) {
+	Convey("Given a script header with permissions", t, func() {
+		header := `--------------------
+-- @name    example
+-- @network example.com, *.example.org cdn.example.net
+-- @headless
+--------------------
+
+-- @filesystem is not in the header
+local x = 1
+`
+
+		Convey("When parsing it", func() {
+			permissions, err := ParsePermissions(strings.NewReader(header))
+
+			Convey("Then permissions from the header should be parsed", func() {
+				So(err, ShouldBeNil)
+				So(permissions.Declared, ShouldBeTrue)
+				So(permissions.Network, ShouldBeTrue)
+				So(permissions.Headless, ShouldBeTrue)
+				So(permissions.Filesystem, ShouldBeFalse)
+				So(permissions.Hosts, ShouldResemble, []string{"example.com", "*.example.org", "cdn.example.net"})
+			})
+
+			Convey("Then hosts should be matched", func() {
+				So(permissions.AllowsHost("example.com"), ShouldBeTrue)
+				So(permissions.AllowsHost("img.example.org"), ShouldBeTrue)
+				So(permissions.AllowsHost("example.org"), ShouldBeFalse)
+				So(permissions.AllowsHost("evil.com"), ShouldBeFalse)
+			})
+		})
+	})
+
+	Convey("Given a header with invalid host", t, func() {
+		Convey("When parsing it", func() {
+			_, err := ParsePermissions(strings.NewReader("-- @network https://example.com"))
+
+			Convey("Then error should be returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+
+	Convey("Given a header without permissions", t, func() {
+		Convey("When parsing it", func() {
+			permissions, err := ParsePermissions(strings.NewReader("-- @name example\nlocal x = 1"))
+
+			Convey("Then nothing should be declared", func() {
+				So(err, ShouldBeNil)
+				So(permissions.Declared, ShouldBeFalse)
+				So(permissions.AllowsHost("example.com"), ShouldBeFalse)
+			})
+		})
+	})
+}
+
+func TestParseHosts(t *testing.T) {
+	Convey("Given a script header with handled hosts", t, func() {
+		header := `-- @name    example
+-- @network example.com, cdn.example.net
+-- @hosts   example.com, *.example.org
+local x = 1
+`
+
+		Convey("When parsing it", func() {
+			hosts, err := ParseHosts(strings.NewReader(header))
+
+			Convey("Then only the handled hosts should be parsed", func() {
+				So(err, ShouldBeNil)
+				So(hosts, ShouldResemble, []string{"example.com", "*.example.org"})
+				So(MatchHost(hosts, "www.example.org"), ShouldBeTrue)
+				So(MatchHost(hosts, "cdn.example.net"), ShouldBeFalse)
+			})
+		})
+	})
+}
+
+func TestSandbox(t *testing.T) {
+	defer viper.Reset()
+
+	Convey("Given a source without network permission", t, func() {
+		src, err := loadTestSource(t, "-- @headless", `
+			local http = require("http")
+			return {}
+		`)
+		So(err, ShouldBeNil)
+
+		Convey("When it requires http module", func() {
+			_, err := src.PagesOf(&source.Chapter{URL: "https://example.com"})
+
+			Convey("Then error should mention the missing permission", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "requires network permission")
+			})
+		})
+	})
+
+	Convey("Given a source without filesystem permission", t, func() {
+		src, err := loadTestSource(t, "-- @network", `
+			if io ~= nil or os.execute ~= nil or dofile ~= nil then
+				error("filesystem is accessible")
+			end
+			return {}
+		`)
+		So(err, ShouldBeNil)
+
+		Convey("When it is called", func() {
+			_, err := src.PagesOf(&source.Chapter{URL: "https://example.com"})
+
+			Convey("Then filesystem functions should not be available", func() {
+				So(err, ShouldBeNil)
+			})
+		})
+
+		for _, call := range []string{
+			`require("filepath").glob("*")`,
+			`require("template").choose("mustache"):render_file("/etc/passwd", {})`,
+		} {
+			Convey("When it calls "+call, func() {
+				src, err := loadTestSource(t, "-- @network", call+"\nreturn {}")
+				So(err, ShouldBeNil)
+
+				_, err = src.PagesOf(&source.Chapter{URL: "https://example.com"})
+
+				Convey("Then error should mention the missing permission", func() {
+					So(err, ShouldNotBeNil)
+					So(err.Error(), ShouldContainSubstring, "requires filesystem permission")
+				})
+			})
+		}
+
+		Convey("When it uses the other functions of the partially restricted modules", func() {
+			src, err := loadTestSource(t, "-- @network", `
+				if require("filepath").join("a", "b") == nil then error("join is not available") end
+				local out = require("template").choose("mustache"):render("{{x}}", {x = "y"})
+				if out ~= "y" then error("render is not available") end
+				return {}
+			`)
+			So(err, ShouldBeNil)
+
+			_, err = src.PagesOf(&source.Chapter{URL: "https://example.com"})
+
+			Convey("Then they should work", func() {
+				So(err, ShouldBeNil)
+			})
+		})
+	})
+
+	Convey("Given a source that requests a host not in the manifest", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprint(w, "ok")
+		}))
+		defer server.Close()
+
+		src, err := loadTestSource(t, "-- @network example.com", `
+			local http = require("http")
+			local client = http.client()
+			local response, err = client:do_request(http.request("GET", url))
+			return {}
+		`)
+		So(err, ShouldBeNil)
+
+		Convey("When it is called", func() {
+			_, err := src.PagesOf(&source.Chapter{URL: server.URL})
+
+			Convey("Then request should be denied", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "is not permitted")
+			})
+		})
+	})
+
+	Convey("Given a source that uses the debug library", t, func() {
+		src, err := loadTestSource(t, "-- @network", `
+			for _, lib in ipairs({ debug, require("debug") }) do
+				if lib.getregistry ~= nil or lib.setupvalue ~= nil or lib.setmetatable ~= nil then
+					error("debug library is not restricted")
+				end
+				if type(lib.traceback()) ~= "string" then error("traceback is not available") end
+			end
+			return {}
+		`)
+		So(err, ShouldBeNil)
+
+		Convey("When it is called", func() {
+			_, err := src.PagesOf(&source.Chapter{URL: "https://example.com"})
+
+			Convey("Then only traceback should be available", func() {
+				So(err, ShouldBeNil)
+			})
+		})
+	})
+
+	Convey("Given a source with an infinite loop", t, func() {
+		Convey("When instruction limit is set", func() {
+			viper.Set(key.LuaInstructionLimit, 10_000)
+			defer viper.Set(key.LuaInstructionLimit, 0)
+
+			src, err := loadTestSource(t, "-- @network", "while true do end")
+			So(err, ShouldBeNil)
+
+			_, err = src.PagesOf(&source.Chapter{})
+
+			Convey("Then the call should be stopped", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "exceeded the limit of 10000 instructions")
+			})
+		})
+
+		Convey("When timeout is set", func() {
+			viper.Set(key.LuaTimeout, 1)
+			defer viper.Set(key.LuaTimeout, 0)
+
+			src, err := loadTestSource(t, "-- @network", "while true do end")
+			So(err, ShouldBeNil)
+
+			_, err = src.PagesOf(&source.Chapter{})
+
+			Convey("Then the call should time out", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "timed out")
+			})
+		})
+	})
+
+	Convey("Given a source", t, func() {
+		src, err := loadTestSource(t, "-- @network", `return { { url = url .. "/1.png", index = 1 } }`)
+		So(err, ShouldBeNil)
+
+		Convey("When it is called from many goroutines", func() {
+			var (
+				wg     sync.WaitGroup
+				mu     sync.Mutex
+				failed int
+			)
+
+			for i := 0; i < 32; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+
+					chapter := &source.Chapter{URL: fmt.Sprintf("https://example.com/%d", i)}
+					pages, err := src.PagesOf(chapter)
+					if err != nil || len(pages) != 1 || pages[0].URL != chapter.URL+"/1.png" {
+						mu.Lock()
+						failed++
+						mu.Unlock()
+					}
+				}(i)
+			}
+
+			wg.Wait()
+
+			Convey("Then every call should succeed", func() {
+				So(failed, ShouldEqual, 0)
+			})
+		})
+	})
+}
@@ -1,29 +1,95 @@
 package custom
 
 import (
+	"context"
+	"fmt"
 	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/source"
+	"github.com/spf13/viper"
 	lua "github.com/yuin/gopher-lua"
 	"strconv"
 )
 
 func (s *luaSource) Search(query string) ([]*source.Manga, error) {
-	if mangas := s.cache.mangas.Get(query); mangas.IsPresent() {
-		m := mangas.MustGet()
-		for _, manga := range m {
-			manga.Source = s
-		}
+	return s.SearchContext(context.Background(), query)
+}
+
+// SearchContext searches for mangas and stops the search function when the context is done
+func (s *luaSource) SearchContext(ctx context.Context, query string) ([]*source.Manga, error) {
+	var result []*source.Manga
+
+	pages := viper.GetInt(key.LuaSearchPages)
+	if pages < 1 {
+		pages = 1
+	}
 
-		return m, nil
+	cacheKey := query
+	if pages > 1 {
+		cacheKey = fmt.Sprintf("%s#%d", query, pages)
 	}
 
-	_, err := s.call(constant.SearchMangaFn, lua.LTTable, lua.LString(query))
+	err := s.worker.do(func() error {
+		// the context may be done while the search was waiting for the worker
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if mangas := s.cache.mangas.Get(cacheKey); mangas.IsPresent() {
+			m := mangas.MustGet()
+			for _, manga := range m {
+				manga.Source = s
+			}
+
+			result = m
+			return nil
+		}
+
+		mangas := make([]*source.Manga, 0)
+		seen := make(map[string]struct{})
+
+		// sources that ignore the page return the same results again, which stops the search
+		for page := 1; page <= pages; page++ {
+			found, err := s.searchPage(ctx, query, page, len(mangas))
+			if err != nil {
+				return err
+			}
+
+			fresh := 0
+			for _, manga := range found {
+				if _, ok := seen[manga.URL]; ok {
+					continue
+				}
+
+				seen[manga.URL] = struct{}{}
+				mangas = append(mangas, manga)
+				fresh++
+			}
+
+			if fresh == 0 {
+				break
+			}
+		}
+
+		_ = s.cache.mangas.Set(cacheKey, mangas)
+		result = mangas
+		return nil
+	})
+
+	return result, err
+}
+
+// searchPage calls the search function for a single page of results.
+// Indexes of the found mangas start after the offset.
+// It must be called from the worker goroutine
+func (s *luaSource) searchPage(ctx context.Context, query string, page, offset int) ([]*source.Manga, error) {
+	val, err := s.callContext(ctx, constant.SearchMangaFn, lua.LTTable, lua.LString(query), lua.LNumber(page))
 
 	if err != nil {
 		return nil, err
 	}
 
-	table := s.state.CheckTable(-1)
+	table := val.(*lua.LTable)
 	mangas := make([]*source.Manga, 0)
 
 	table.ForEach(func(k lua.LValue, v lua.LValue) {
@@ -40,7 +106,7 @@ func (s *luaSource) Search(query string) ([]*source.Manga, error) {
 			s.state.RaiseError(constant.SearchMangaFn + " was expected to return a table with unsigned integers as keys. " + err.Error())
 		}
 
-		manga, err := mangaFromTable(v.(*lua.LTable), uint16(index))
+		manga, err := mangaFromTable(v.(*lua.LTable), uint16(offset)+uint16(index))
 
 		if err != nil {
 			s.state.RaiseError(err.Error())
@@ -50,6 +116,5 @@ func (s *luaSource) Search(query string) ([]*source.Manga, error) {
 		mangas = append(mangas, manga)
 	})
 
-	_ = s.cache.mangas.Set(query, mangas)
 	return mangas, nil
 }
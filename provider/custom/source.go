@@ -1,17 +1,21 @@
 package custom
 
 import (
+	"context"
 	"fmt"
+	"github.com/metafates/mangal/cache"
 	"github.com/metafates/mangal/source"
 	lua "github.com/yuin/gopher-lua"
 )
 
 type luaSource struct {
-	name  string
-	state *lua.LState
-	cache struct {
-		mangas   *cacher[[]*source.Manga]
-		chapters *cacher[[]*source.Chapter]
+	name   string
+	state  *lua.LState
+	worker *worker
+	limits limits
+	cache  struct {
+		mangas   *cache.Cache[[]*source.Manga]
+		chapters *cache.Cache[[]*source.Chapter]
 	}
 }
 
@@ -19,23 +23,34 @@ func (s *luaSource) Name() string {
 	return s.name
 }
 
-func newLuaSource(name string, state *lua.LState) (*luaSource, error) {
+func newLuaSource(name string, state *lua.LState, limits limits) *luaSource {
 	s := &luaSource{
-		name:  name,
-		state: state,
+		name:   name,
+		state:  state,
+		worker: newWorker(),
+		limits: limits,
 	}
 
-	cacheName := func(cacheFor string) string {
-		return fmt.Sprintf("%s_%s", s.ID(), cacheFor)
-	}
-
-	s.cache.mangas = newCacher[[]*source.Manga](cacheName("mangas"))
-	s.cache.chapters = newCacher[[]*source.Chapter](cacheName("chapters"))
+	s.cache.mangas = cache.New[[]*source.Manga](name, cache.Search)
+	s.cache.chapters = cache.New[[]*source.Chapter](name, cache.Chapters)
 
-	return s, nil
+	return s
 }
 
+// call calls the global function with the execution limits of the source.
+// It must be called from the worker goroutine
 func (s *luaSource) call(fn string, ret lua.LValueType, args ...lua.LValue) (lua.LValue, error) {
+	return s.callContext(context.Background(), fn, ret, args...)
+}
+
+// callContext is like call, but the function is stopped when the parent context is done
+func (s *luaSource) callContext(parent context.Context, fn string, ret lua.LValueType, args ...lua.LValue) (lua.LValue, error) {
+	ctx, cancel := s.limits.context(parent)
+	defer cancel()
+
+	s.state.SetContext(ctx)
+	defer s.state.RemoveContext()
+
 	err := s.state.CallByParam(lua.P{
 		Fn:      s.state.GetGlobal(fn),
 		NRet:    1,
@@ -43,18 +58,32 @@ func (s *luaSource) call(fn string, ret lua.LValueType, args ...lua.LValue) (lua
 	}, args...)
 
 	if err != nil {
-		return nil, err
+		if parent.Err() != nil {
+			return nil, parent.Err()
+		}
+
+		return nil, s.limits.explain(fn, ctx, err)
 	}
 
 	val := s.state.Get(-1)
+	s.state.Pop(1)
 
 	if val.Type() != ret {
-		s.state.RaiseError(fn + " was expected to return a " + ret.String() + ", got " + val.Type().String())
+		return nil, fmt.Errorf("%s was expected to return a %s, got %s", fn, ret, val.Type())
 	}
 
 	return val, nil
 }
 
+// close stops the worker and closes the Lua state
+func (s *luaSource) close() {
+	_ = s.worker.do(func() error {
+		s.state.Close()
+		return nil
+	})
+	s.worker.stop()
+}
+
 func (s *luaSource) ID() string {
 	return IDfromName(s.name)
 }
@@ -2,6 +2,7 @@ package custom
 
 import (
 	"fmt"
+	"github.com/metafates/mangal/model"
 	"github.com/metafates/mangal/source"
 	"github.com/samber/lo"
 	lua "github.com/yuin/gopher-lua"
@@ -9,6 +10,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type mapping lo.Tuple4[lua.LValueType, bool, func(string) error, string]
@@ -16,34 +18,101 @@ type mapping lo.Tuple4[lua.LValueType, bool, func(string) error, string]
 func translate(
 	table *lua.LTable,
 	mappings map[string]mapping,
-) (err error) {
+) error {
 	for field, t := range mappings {
-		var (
-			type_    = t.A
-			required = t.B
-			handle   = t.C
-			default_ = t.D
-		)
-
-		val := table.RawGetString(field)
-		if val.Type() == lua.LTNil {
-			if required {
-				err = fmt.Errorf(`field of "%s" is required`, field)
-			} else {
-				err = handle(default_)
-			}
-		} else if val.Type() != type_ {
-			err = fmt.Errorf(`field of "%s" must be of type %s`, field, type_)
-		} else {
-			err = handle(val.String())
+		if err := translateField(table, field, t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// translateAll applies every mapping to the table and returns errors by field names.
+// Fields of the table that are not in the mappings are reported as unknown
+func translateAll(
+	table *lua.LTable,
+	mappings map[string]mapping,
+) map[string]error {
+	errs := make(map[string]error)
+
+	for field, t := range mappings {
+		if err := translateField(table, field, t); err != nil {
+			errs[field] = err
+		}
+	}
+
+	table.ForEach(func(k lua.LValue, _ lua.LValue) {
+		if _, ok := mappings[k.String()]; !ok {
+			errs[k.String()] = fmt.Errorf(`field "%s" is unknown`, k.String())
 		}
+	})
+
+	return errs
+}
+
+func translateField(table *lua.LTable, field string, t mapping) error {
+	var (
+		type_    = t.A
+		required = t.B
+		handle   = t.C
+		default_ = t.D
+	)
 
+	val := table.RawGetString(field)
+	if val.Type() == lua.LTNil {
+		if required {
+			return fmt.Errorf(`field of "%s" is required`, field)
+		}
+
+		return handle(default_)
+	}
+
+	if val.Type() != type_ {
+		return fmt.Errorf(`field of "%s" must be of type %s`, field, type_)
+	}
+
+	// lists of strings are passed to the handler line by line
+	if table, ok := val.(*lua.LTable); ok {
+		lines, err := stringList(table)
 		if err != nil {
-			return
+			return fmt.Errorf(`field of "%s" %s`, field, err)
 		}
+
+		return handle(strings.Join(lines, "\n"))
 	}
 
-	return
+	return handle(val.String())
+}
+
+// stringList converts the table to a list of strings
+func stringList(table *lua.LTable) ([]string, error) {
+	lines := make([]string, 0, table.Len())
+	for i := 1; i <= table.Len(); i++ {
+		value := table.RawGetInt(i)
+		if value.Type() != lua.LTString {
+			return nil, fmt.Errorf("must be a list of strings, got %s at %d", value.Type(), i)
+		}
+
+		lines = append(lines, strings.ReplaceAll(value.String(), "\n", " "))
+	}
+
+	return lines, nil
+}
+
+// parseNumber parses an optional number field, empty value is skipped
+func parseNumber(value string, to **float64) error {
+	if value == "" {
+		return nil
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return err
+	}
+
+	*to = &number
+	return nil
 }
 
 func mangaFromTable(table *lua.LTable, index uint16) (manga *source.Manga, err error) {
@@ -52,10 +121,23 @@ func mangaFromTable(table *lua.LTable, index uint16) (manga *source.Manga, err e
 		Chapters: []*source.Chapter{},
 	}
 
-	mappings := map[string]mapping{
-		"name":    {A: lua.LTString, B: true, C: func(v string) error { manga.Name = v; return nil }},
-		"url":     {A: lua.LTString, B: true, C: func(v string) error { manga.URL = v; return nil }},
-		"summary": {A: lua.LTString, B: false, C: func(v string) error { manga.Metadata.Summary = v; return nil }},
+	err = translate(table, mangaMappings(manga))
+	return
+}
+
+func mangaMappings(manga *source.Manga) map[string]mapping {
+	mappings := metadataMappings(&manga.Metadata)
+	mappings["name"] = mapping{A: lua.LTString, B: true, C: func(v string) error { manga.Name = v; return nil }}
+	mappings["url"] = mapping{A: lua.LTString, B: true, C: func(v string) error { manga.URL = v; return nil }}
+
+	return mappings
+}
+
+// metadataMappings are the fields of the manga details.
+// They can be returned along with search results or by the MangaDetails function
+func metadataMappings(metadata *model.MangaMetadata) map[string]mapping {
+	return map[string]mapping{
+		"summary": {A: lua.LTString, B: false, C: func(v string) error { metadata.Summary = v; return nil }},
 		"cover": {A: lua.LTString, B: false, C: func(v string) error {
 			if v == "" {
 				return nil
@@ -66,19 +148,111 @@ func mangaFromTable(table *lua.LTable, index uint16) (manga *source.Manga, err e
 				return err
 			}
 
-			manga.Metadata.Cover.ExtraLarge = v
+			metadata.Cover.ExtraLarge = v
+			return nil
+		}},
+		"genres":     {A: lua.LTString, B: false, C: func(v string) error { parseList(v, &metadata.Genres); return nil }},
+		"tags":       {A: lua.LTString, B: false, C: func(v string) error { parseList(v, &metadata.Tags); return nil }},
+		"authors":    {A: lua.LTString, B: false, C: func(v string) error { parseList(v, &metadata.Staff.Story); return nil }},
+		"artists":    {A: lua.LTString, B: false, C: func(v string) error { parseList(v, &metadata.Staff.Art); return nil }},
+		"alt_titles": {A: lua.LTTable, B: false, C: func(v string) error { parseLines(v, &metadata.Synonyms); return nil }},
+		"language":   {A: lua.LTString, B: false, C: func(v string) error { metadata.Language = strings.ToLower(v); return nil }},
+		"status": {A: lua.LTString, B: false, C: func(v string) error {
+			if v == "" {
+				return nil
+			}
+
+			status, ok := statuses[strings.ToLower(v)]
+			if !ok {
+				return fmt.Errorf("unknown status %q, expected one of ongoing, completed, hiatus, cancelled, unreleased", v)
+			}
+
+			metadata.Status = status
 			return nil
 		}},
-		"genres": {A: lua.LTString, B: false, C: func(v string) error {
-			manga.Metadata.Genres = lo.Map(strings.Split(v, ","), func(genre string, _ int) string {
-				return strings.TrimSpace(genre)
-			})
+		"year": {A: lua.LTNumber, B: false, C: func(v string) error {
+			if v == "" {
+				return nil
+			}
+
+			year, err := strconv.Atoi(v)
+			if err != nil {
+				return err
+			}
+
+			metadata.StartDate.Year = year
+			return nil
+		}},
+		"chapters_count": {A: lua.LTNumber, B: false, C: func(v string) error {
+			if v == "" {
+				return nil
+			}
+
+			count, err := strconv.Atoi(v)
+			if err != nil {
+				return err
+			}
+
+			metadata.Chapters = count
 			return nil
 		}},
 	}
+}
 
-	err = translate(table, mappings)
-	return
+// statuses maps the status names accepted from the sources to the Anilist ones
+var statuses = map[string]string{
+	"ongoing":          "RELEASING",
+	"releasing":        "RELEASING",
+	"completed":        "FINISHED",
+	"finished":         "FINISHED",
+	"hiatus":           "HIATUS",
+	"cancelled":        "CANCELLED",
+	"canceled":         "CANCELLED",
+	"unreleased":       "NOT_YET_RELEASED",
+	"not_yet_released": "NOT_YET_RELEASED",
+}
+
+// parseList parses a comma separated list, empty value is skipped
+func parseList(value string, to *[]string) {
+	if value == "" {
+		return
+	}
+
+	*to = lo.Map(strings.Split(value, ","), func(item string, _ int) string {
+		return strings.TrimSpace(item)
+	})
+}
+
+// parseLines parses a list of strings passed by the table mapping, empty value is skipped
+func parseLines(value string, to *[]string) {
+	if value == "" {
+		return
+	}
+
+	*to = strings.Split(value, "\n")
+}
+
+// dateLayouts are the accepted layouts of the dates returned by the sources
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// parseDate parses an optional date field, empty value is skipped
+func parseDate(value string, to **time.Time) error {
+	if value == "" {
+		return nil
+	}
+
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			*to = &date
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid date %q, expected YYYY-MM-DD or RFC 3339", value)
 }
 
 func chapterFromTable(table *lua.LTable, manga *source.Manga, index uint16) (chapter *source.Chapter, err error) {
@@ -88,17 +262,28 @@ func chapterFromTable(table *lua.LTable, manga *source.Manga, index uint16) (cha
 		Pages: []*source.Page{},
 	}
 
-	mappings := map[string]mapping{
-		"name":          {A: lua.LTString, B: true, C: func(v string) error { chapter.Name = v; return nil }},
-		"url":           {A: lua.LTString, B: true, C: func(v string) error { chapter.URL = v; return nil }},
-		"volume":        {A: lua.LTString, B: false, C: func(v string) error { chapter.Volume = v; return nil }},
-		"manga_summary": {A: lua.LTString, B: false, C: func(v string) error { manga.Metadata.Summary = v; return nil }},
-		"manga_genres": {A: lua.LTString, B: false, C: func(v string) error {
-			manga.Metadata.Genres = lo.Map(strings.Split(v, ","), func(genre string, _ int) string {
-				return strings.TrimSpace(genre)
-			})
-			return nil
+	err = translate(table, chapterMappings(chapter, manga))
+	manga.Chapters = append(manga.Chapters, chapter)
+	return
+}
+
+func chapterMappings(chapter *source.Chapter, manga *source.Manga) map[string]mapping {
+	return map[string]mapping{
+		"name":   {A: lua.LTString, B: true, C: func(v string) error { chapter.Name = v; return nil }},
+		"url":    {A: lua.LTString, B: true, C: func(v string) error { chapter.URL = v; return nil }},
+		"volume": {A: lua.LTString, B: false, C: func(v string) error { chapter.Volume = v; return nil }},
+		"chapter_number": {A: lua.LTNumber, B: false, C: func(v string) error {
+			return parseNumber(v, &chapter.Number)
+		}},
+		"volume_number": {A: lua.LTNumber, B: false, C: func(v string) error {
+			return parseNumber(v, &chapter.VolumeNumber)
 		}},
+		"date": {A: lua.LTString, B: false, C: func(v string) error {
+			return parseDate(v, &chapter.Date)
+		}},
+		"scanlator":     {A: lua.LTString, B: false, C: func(v string) error { chapter.Group = v; return nil }},
+		"manga_summary": {A: lua.LTString, B: false, C: func(v string) error { manga.Metadata.Summary = v; return nil }},
+		"manga_genres":  {A: lua.LTString, B: false, C: func(v string) error { parseList(v, &manga.Metadata.Genres); return nil }},
 		"manga_cover": {A: lua.LTString, B: false, C: func(v string) error {
 			if v == "" {
 				return nil
@@ -112,10 +297,6 @@ func chapterFromTable(table *lua.LTable, manga *source.Manga, index uint16) (cha
 			return nil
 		}},
 	}
-
-	err = translate(table, mappings)
-	manga.Chapters = append(manga.Chapters, chapter)
-	return
 }
 
 func pageFromTable(table *lua.LTable, chapter *source.Chapter) (page *source.Page, err error) {
@@ -123,7 +304,18 @@ func pageFromTable(table *lua.LTable, chapter *source.Chapter) (page *source.Pag
 		Chapter: chapter,
 	}
 
-	mappings := map[string]mapping{
+	err = translate(table, pageMappings(page))
+	if err != nil {
+		return
+	}
+
+	page.Extension = filepath.Ext(page.URL)
+	chapter.Pages = append(chapter.Pages, page)
+	return
+}
+
+func pageMappings(page *source.Page) map[string]mapping {
+	return map[string]mapping{
 		"url": {A: lua.LTString, B: true, C: func(v string) error { page.URL = v; return nil }},
 		"index": {A: lua.LTNumber, B: true, C: func(v string) error {
 			num, err := strconv.ParseUint(v, 10, 16)
@@ -135,13 +327,4 @@ func pageFromTable(table *lua.LTable, chapter *source.Chapter) (page *source.Pag
 			return nil
 		}},
 	}
-
-	err = translate(table, mappings)
-	if err != nil {
-		return
-	}
-
-	page.Extension = filepath.Ext(page.URL)
-	chapter.Pages = append(chapter.Pages, page)
-	return
 }
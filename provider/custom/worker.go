@@ -0,0 +1,55 @@
+package custom
+
+import (
+	"fmt"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// queueSize is the number of requests that can wait for the worker without blocking
+const queueSize = 16
+
+// worker runs requests to the Lua state on a single goroutine,
+// since the Lua state is not safe for concurrent use.
+// Requests are executed one by one in the order they were queued
+type worker struct {
+	requests chan func()
+}
+
+func newWorker() *worker {
+	w := &worker{requests: make(chan func(), queueSize)}
+	go w.run()
+	return w
+}
+
+func (w *worker) run() {
+	for request := range w.requests {
+		request()
+	}
+}
+
+// do queues the function and waits for it to finish.
+// Panics, such as Lua errors raised outside of protected calls, are returned as errors
+func (w *worker) do(fn func() error) error {
+	done := make(chan error, 1)
+
+	w.requests <- func() {
+		defer func() {
+			if r := recover(); r != nil {
+				if apiErr, ok := r.(*lua.ApiError); ok {
+					done <- apiErr
+				} else {
+					done <- fmt.Errorf("%v", r)
+				}
+			}
+		}()
+
+		done <- fn()
+	}
+
+	return <-done
+}
+
+// stop stops the worker after the queued requests are done
+func (w *worker) stop() {
+	close(w.requests)
+}
@@ -0,0 +1,142 @@
+package declarative
+
+import (
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+	"github.com/metafates/mangal/provider/generic"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+func compileSelector(selector string) (cascadia.Selector, error) {
+	return cascadia.Compile(selector)
+}
+
+// Compile validates the definition and compiles it into the generic scraper configuration
+func (d *Definition) Compile(name string) (*generic.Configuration, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+
+	var delay time.Duration
+	if d.Delay != "" {
+		// already validated
+		delay, _ = time.ParseDuration(d.Delay)
+	}
+
+	return &generic.Configuration{
+		Name:              name,
+		Custom:            true,
+		Delay:             delay,
+		Parallelism:       d.Parallelism,
+		ReverseChapters:   d.ReverseChapters,
+		BaseURL:           d.BaseURL,
+		Hosts:             d.Hosts,
+		MangaName:         d.MangaName.compile(),
+		GenerateSearchURL: d.Search.compile(),
+		MangaExtractor:    d.Manga.compile(),
+		ChapterExtractor:  d.Chapters.compile(),
+		PageExtractor:     d.Pages.compile(),
+	}, nil
+}
+
+func (s *Search) compile() func(string) string {
+	return func(query string) string {
+		query = strings.TrimSpace(query)
+
+		if s.Lowercase {
+			query = strings.ToLower(query)
+		}
+
+		if s.Spaces != "" {
+			query = strings.ReplaceAll(query, " ", s.Spaces)
+		}
+
+		return strings.ReplaceAll(s.URL, "{query}", url.QueryEscape(query))
+	}
+}
+
+func (e *Extractor) compile() *generic.Extractor {
+	extractor := &generic.Extractor{
+		Selector: e.Selector,
+		Name:     e.Name.compile(),
+		URL:      e.URL.compile(),
+		Volume:   e.Volume.compile(),
+		Cover:    e.Cover.compile(),
+	}
+
+	if e.Pagination != nil {
+		extractor.Pagination = &generic.Pagination{
+			NextPage: e.Pagination.Next.compile(),
+			Limit:    e.Pagination.Limit,
+		}
+	}
+
+	return extractor
+}
+
+// compile returns a function that extracts the field value from the element.
+// Undefined fields always extract an empty string
+func (f *Field) compile() func(*goquery.Selection) string {
+	if f == nil {
+		return func(*goquery.Selection) string {
+			return ""
+		}
+	}
+
+	var (
+		re      *regexp.Regexp
+		replace = f.Replace
+	)
+
+	if f.Regex != "" {
+		// already validated
+		re = regexp.MustCompile(f.Regex)
+
+		if replace == "" {
+			if re.NumSubexp() > 0 {
+				replace = "$1"
+			} else {
+				replace = "$0"
+			}
+		}
+	}
+
+	return func(selection *goquery.Selection) string {
+		if f.Selector != "" {
+			selection = selection.Find(f.Selector)
+		}
+
+		selection = selection.First()
+
+		var value string
+		if len(f.Attribute) == 0 {
+			value = selection.Text()
+		} else {
+			for _, attribute := range f.Attribute {
+				if value = strings.TrimSpace(selection.AttrOr(attribute, "")); value != "" {
+					break
+				}
+			}
+		}
+
+		value = strings.TrimSpace(value)
+
+		if re != nil {
+			match := re.FindStringSubmatchIndex(value)
+			if match == nil {
+				return ""
+			}
+
+			value = strings.TrimSpace(string(re.ExpandString(nil, replace, value, match)))
+		}
+
+		if f.Template != "" && value != "" {
+			value = strings.ReplaceAll(f.Template, "{value}", value)
+		}
+
+		return value
+	}
+}
@@ -0,0 +1,389 @@
+package declarative
+
+import (
+	"fmt"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/metafates/mangal/provider/generic"
+	"github.com/metafates/mangal/source"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const definitionYAML = `
+base_url: %[1]s
+delay: 10ms
+parallelism: 2
+reverse_chapters: true
+search:
+  url: %[1]s/search?q={query}
+  lowercase: true
+  spaces: _
+manga:
+  selector: .manga
+  name:
+    selector: a
+  url:
+    selector: a
+    attribute: href
+  cover:
+    selector: img
+    attribute: [data-src, src]
+  pagination:
+    next:
+      selector: a.next
+      attribute: href
+chapters:
+  selector: .chapter
+  name:
+    selector: a
+    regex: '^(?:Vol\.\d+\s+)?(.+)$'
+  url:
+    selector: a
+    attribute: href
+  volume:
+    selector: a
+    regex: '^(Vol\.\d+)'
+manga_name:
+  selector: h1
+pages:
+  selector: img
+  url:
+    attribute: src
+    template: "%[1]s{value}"
+`
+
+func newTestServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "":
+			_, _ = fmt.Fprint(w, `<html><body>
+<div class="manga"><a href="/manga/one">One</a><img src="/one.png"></div>
+<a class="next" href="/search?q=x&page=2">Next</a>
+</body></html>`)
+		default:
+			_, _ = fmt.Fprint(w, `<html><body>
+<div class="manga"><a href="/manga/two">Two</a><img data-src="/two.png" src="/placeholder.png"></div>
+</body></html>`)
+		}
+	})
+	mux.HandleFunc("/manga/one", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<html><head><title>One - Example</title></head><body>
+<h1> One </h1>
+<div class="chapter"><a href="/chapter/2">Vol.1 Chapter 2</a></div>
+<div class="chapter"><a href="/chapter/1">Vol.1 Chapter 1</a></div>
+</body></html>`)
+	})
+	mux.HandleFunc("/chapter/1", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<html><body><img src="/1.png"><img src="/2.png"></body></html>`)
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestParse(t *testing.T) {
+	Convey("Given a yaml definition", t, func() {
+		data := []byte(fmt.Sprintf(definitionYAML, "https://example.com"))
+
+		Convey("When parsing it", func() {
+			definition, err := Parse(data, ".yaml")
+
+			Convey("Then it should be valid", func() {
+				So(err, ShouldBeNil)
+				So(definition.Validate(), ShouldBeNil)
+				So(definition.Manga.Cover.Attribute, ShouldResemble, Strings{"data-src", "src"})
+				So(definition.Manga.URL.Attribute, ShouldResemble, Strings{"href"})
+			})
+		})
+	})
+
+	Convey("Given a json definition", t, func() {
+		data := []byte(`{"base_url": "https://example.com", "pages": {"selector": "img", "url": {"attribute": "src"}}}`)
+
+		Convey("When parsing it", func() {
+			definition, err := Parse(data, ".json")
+
+			Convey("Then it should be parsed", func() {
+				So(err, ShouldBeNil)
+				So(definition.Pages.URL.Attribute, ShouldResemble, Strings{"src"})
+			})
+		})
+	})
+
+	Convey("Given a definition with unknown field", t, func() {
+		data := []byte("base_url: https://example.com\nselectr: .manga\n")
+
+		Convey("When parsing it", func() {
+			_, err := Parse(data, ".yml")
+
+			Convey("Then error should mention the field", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "selectr")
+			})
+		})
+	})
+
+	Convey("Given an unsupported format", t, func() {
+		Convey("When parsing it", func() {
+			_, err := Parse([]byte(""), ".toml")
+
+			Convey("Then error should be returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
+
+func TestValidate(t *testing.T) {
+	Convey("Given an invalid definition", t, func() {
+		definition := &Definition{
+			BaseURL: "example.com",
+			Delay:   "fast",
+			Search:  Search{URL: "https://example.com/search"},
+			Manga: Extractor{
+				Selector: ".manga",
+				Name:     &Field{Regex: "("},
+				Volume:   &Field{},
+			},
+			Chapters: Extractor{
+				Selector: "[[",
+				Name:     &Field{Replace: "$1"},
+				URL:      &Field{Template: "https://example.com/"},
+			},
+			Pages: Extractor{
+				Selector:   "img",
+				URL:        &Field{Attribute: Strings{"src"}},
+				Pagination: &Pagination{},
+			},
+		}
+
+		Convey("When validating it", func() {
+			err := definition.Validate()
+
+			Convey("Then all errors should be reported with paths", func() {
+				So(err, ShouldNotBeNil)
+
+				for _, path := range []string{
+					"base_url:",
+					"delay:",
+					"search.url: must contain {query}",
+					"manga.name.regex:",
+					"manga.url: is required",
+					"manga.volume: is not supported",
+					"chapters.selector:",
+					"chapters.name.replace: requires regex",
+					"chapters.url.template:",
+					"pages.pagination.next: is required",
+				} {
+					So(err.Error(), ShouldContainSubstring, path)
+				}
+			})
+		})
+	})
+}
+
+func TestValidateSchema(t *testing.T) {
+	Convey("Given a valid yaml definition", t, func() {
+		data := []byte(fmt.Sprintf(definitionYAML, "https://example.com"))
+
+		Convey("When validating it against the schema", func() {
+			err := ValidateSchema(data, ".yaml")
+
+			Convey("Then no errors should be reported", func() {
+				So(err, ShouldBeNil)
+			})
+		})
+	})
+
+	Convey("Given a json definition that refers to the schema", t, func() {
+		data := []byte(`{
+	"$schema": "./schema.json",
+	"base_url": "https://example.com",
+	"parallelism": 4,
+	"search": {"url": "https://example.com/search?q={query}"},
+	"manga": {"selector": ".manga", "name": {}, "url": {"attribute": ["data-href", "href"]}},
+	"chapters": {"selector": ".chapter", "name": {}, "url": {"attribute": "href"}},
+	"pages": {"selector": "img", "url": {"attribute": "src"}, "pagination": {"next": {}, "limit": 10}}
+}`)
+
+		Convey("When validating it against the schema", func() {
+			err := ValidateSchema(data, ".json")
+
+			Convey("Then no errors should be reported", func() {
+				So(err, ShouldBeNil)
+			})
+		})
+	})
+
+	Convey("Given a definition that doesn't match the schema", t, func() {
+		data := []byte(`
+base_url: ""
+hosts: [example.com, 1]
+parallelism: 300
+reverse_chapters: yes please
+search:
+  url: https://example.com/search
+manga:
+  selectr: .manga
+  name:
+    attribute: {src: true}
+chapters: .chapter
+pages:
+  selector: img
+  pagination:
+    limit: -1
+`)
+
+		Convey("When validating it against the schema", func() {
+			err := ValidateSchema(data, ".yaml")
+
+			Convey("Then all errors should be reported with paths", func() {
+				So(err, ShouldNotBeNil)
+
+				for _, path := range []string{
+					"base_url: must not be empty",
+					"hosts[1]: expected string, got number",
+					"parallelism: must be at most 255",
+					"reverse_chapters: expected boolean, got string",
+					"search.url: must match",
+					"manga.selector: is required",
+					"manga.selectr: unknown field",
+					"manga.name.attribute: expected string or list of strings",
+					"chapters: expected object, got string",
+					"pages.pagination.next: is required",
+					"pages.pagination.limit: must be at least 0",
+				} {
+					So(err.Error(), ShouldContainSubstring, path)
+				}
+			})
+		})
+	})
+
+	Convey("Given an empty definition", t, func() {
+		Convey("When validating it against the schema", func() {
+			err := ValidateSchema([]byte(""), ".yml")
+
+			Convey("Then error should be returned", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "definition: expected object")
+			})
+		})
+	})
+}
+
+func TestField(t *testing.T) {
+	Convey("Given an html element", t, func() {
+		document, err := goquery.NewDocumentFromReader(strings.NewReader(`<div>
+<a href="/manga/42" title="Title">  Vol.2 Chapter 10.5  </a>
+<img data-src="" src="/cover.png">
+</div>`))
+		So(err, ShouldBeNil)
+		selection := document.Find("div")
+
+		Convey("When extracting text", func() {
+			value := (&Field{Selector: "a"}).compile()(selection)
+
+			Convey("Then it should be trimmed", func() {
+				So(value, ShouldEqual, "Vol.2 Chapter 10.5")
+			})
+		})
+
+		Convey("When extracting the first non-empty attribute", func() {
+			value := (&Field{Selector: "img", Attribute: Strings{"data-src", "src"}}).compile()(selection)
+
+			Convey("Then it should be found", func() {
+				So(value, ShouldEqual, "/cover.png")
+			})
+		})
+
+		Convey("When extracting with regex and template", func() {
+			value := (&Field{
+				Selector:  "a",
+				Attribute: Strings{"href"},
+				Regex:     `/manga/(\d+)`,
+				Template:  "https://example.com/{value}",
+			}).compile()(selection)
+
+			Convey("Then the first group should be used in the template", func() {
+				So(value, ShouldEqual, "https://example.com/42")
+			})
+		})
+
+		Convey("When regex doesn't match", func() {
+			value := (&Field{Selector: "a", Regex: `Volume \d+`}).compile()(selection)
+
+			Convey("Then value should be empty", func() {
+				So(value, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When field is not defined", func() {
+			var field *Field
+
+			Convey("Then value should be empty", func() {
+				So(field.compile()(selection), ShouldBeEmpty)
+			})
+		})
+	})
+}
+
+func TestCompile(t *testing.T) {
+	Convey("Given a definition of the local server", t, func() {
+		server := newTestServer()
+		defer server.Close()
+
+		definition, err := Parse([]byte(fmt.Sprintf(definitionYAML, server.URL)), ".yaml")
+		So(err, ShouldBeNil)
+
+		Convey("When compiling it", func() {
+			conf, err := definition.Compile("test")
+			So(err, ShouldBeNil)
+
+			Convey("Then search url should be generated from the query", func() {
+				So(conf.GenerateSearchURL(" One Piece "), ShouldEqual, server.URL+"/search?q=one_piece")
+				So(conf.ID(), ShouldEqual, "test custom")
+			})
+
+			Convey("And the scraper is used", func() {
+				scraper := generic.New(conf)
+				mangas, err := scraper.Search("x")
+
+				Convey("Then mangas from all pages should be found", func() {
+					So(err, ShouldBeNil)
+					So(mangas, ShouldHaveLength, 2)
+					So(mangas[0].Name, ShouldEqual, "One")
+					So(mangas[0].URL, ShouldEqual, server.URL+"/manga/one")
+					So(mangas[1].Metadata.Cover.ExtraLarge, ShouldEqual, "/two.png")
+
+					Convey("And chapters should be extracted", func() {
+						chapters, err := scraper.ChaptersOf(mangas[0])
+						So(err, ShouldBeNil)
+						So(chapters, ShouldHaveLength, 2)
+						So(chapters[0].Name, ShouldEqual, "Chapter 1")
+						So(chapters[0].Volume, ShouldEqual, "Vol.1")
+
+						Convey("And pages should be extracted", func() {
+							pages, err := scraper.PagesOf(chapters[0])
+							So(err, ShouldBeNil)
+							So(pages, ShouldHaveLength, 2)
+							So(pages[1].URL, ShouldEqual, server.URL+"/2.png")
+						})
+					})
+				})
+			})
+
+			Convey("And the manga is got by its path without searching", func() {
+				manga, err := generic.New(conf).(source.MangaGetter).GetManga("/manga/one")
+
+				Convey("Then its name should be extracted from the page", func() {
+					So(err, ShouldBeNil)
+					So(manga.Name, ShouldEqual, "One")
+					So(manga.URL, ShouldEqual, server.URL+"/manga/one")
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,127 @@
+// Package declarative loads scrapers defined in YAML or JSON files
+// and compiles them into the generic scraper configurations.
+package declarative
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+)
+
+// Extensions of the definition files
+var Extensions = []string{".yaml", ".yml", ".json"}
+
+// Definition is a declarative scraper definition
+type Definition struct {
+	// Schema is the URL or path of the JSON schema of the definition, used by editors
+	Schema string `yaml:"$schema" json:"$schema" jsonschema:"description=URL or path of this schema for the editors"`
+	// BaseURL of the source
+	BaseURL string `yaml:"base_url" json:"base_url" jsonschema:"required,minLength=1,description=Base URL of the source"`
+	// Hosts of the manga pages handled by the source, host of the base url is used if empty
+	Hosts []string `yaml:"hosts" json:"hosts" jsonschema:"description=Hosts of the manga pages handled by the source. Host of the base URL is used if empty"`
+	// Delay between requests, e.g. "50ms" or "1s"
+	Delay string `yaml:"delay" json:"delay" jsonschema:"description=Delay between requests e.g. 50ms or 1s"`
+	// Parallelism of the scraper
+	Parallelism uint8 `yaml:"parallelism" json:"parallelism" jsonschema:"minimum=0,maximum=255,description=Parallelism of the scraper"`
+	// ReverseChapters if true, chapters will be shown in reverse order
+	ReverseChapters bool `yaml:"reverse_chapters" json:"reverse_chapters" jsonschema:"description=Show chapters in reverse order"`
+
+	// Search defines how to make the search URL
+	Search Search `yaml:"search" json:"search" jsonschema:"required,description=How to make the search URL"`
+
+	// MangaName extracts the manga name from its page, used to get the manga by URL.
+	// og:title meta or title is used if not defined
+	MangaName *Field `yaml:"manga_name" json:"manga_name" jsonschema:"description=Name of the manga on its page. og:title meta or title is used if not defined"`
+
+	// Manga extractor of the search results
+	Manga Extractor `yaml:"manga" json:"manga" jsonschema:"required,description=Extractor of the search results. Requires name and url"`
+	// Chapters extractor of the manga page
+	Chapters Extractor `yaml:"chapters" json:"chapters" jsonschema:"required,description=Extractor of the chapters on the manga page. Requires name and url"`
+	// Pages extractor of the chapter page
+	Pages Extractor `yaml:"pages" json:"pages" jsonschema:"required,description=Extractor of the pages on the chapter page. Requires url"`
+}
+
+// Search defines how to make the search URL from the query
+type Search struct {
+	// URL template of the search. "{query}" is replaced with the escaped query
+	URL string `yaml:"url" json:"url" jsonschema:"required,pattern=[{]query[}],description=URL template of the search. {query} is replaced with the escaped query"`
+	// Spaces replace spaces of the query with the given string before escaping, e.g. "_" or "+"
+	Spaces string `yaml:"spaces" json:"spaces" jsonschema:"description=Replace spaces of the query with the given string before escaping"`
+	// Lowercase the query
+	Lowercase bool `yaml:"lowercase" json:"lowercase" jsonschema:"description=Lowercase the query"`
+}
+
+// Extractor finds elements by selector and extracts data from them
+type Extractor struct {
+	// Selector CSS selector of the elements
+	Selector string `yaml:"selector" json:"selector" jsonschema:"required,minLength=1,description=CSS selector of the elements"`
+	// Name of the manga or chapter
+	Name *Field `yaml:"name" json:"name" jsonschema:"description=Name of the manga or chapter"`
+	// URL of the manga, chapter or page
+	URL *Field `yaml:"url" json:"url" jsonschema:"description=URL of the manga or chapter or page"`
+	// Volume of the chapter. Chapters only
+	Volume *Field `yaml:"volume" json:"volume" jsonschema:"description=Volume of the chapter. Chapters only"`
+	// Cover of the manga. Manga only
+	Cover *Field `yaml:"cover" json:"cover" jsonschema:"description=Cover of the manga. Manga only"`
+	// Pagination defines how to find the next page
+	Pagination *Pagination `yaml:"pagination" json:"pagination" jsonschema:"description=How to find the next page"`
+}
+
+// Field extracts a single value from the element
+type Field struct {
+	// Selector CSS selector relative to the element. The element itself is used if empty
+	Selector string `yaml:"selector" json:"selector" jsonschema:"description=CSS selector relative to the element. The element itself is used if empty"`
+	// Attribute to extract, the text is used if empty.
+	// Can be a list of attributes, the first non-empty one is used
+	Attribute Strings `yaml:"attribute" json:"attribute" jsonschema:"description=Attribute or list of attributes to extract. The first non-empty one is used and the text if empty"`
+	// Regex to apply to the value. Value is empty if it doesn't match
+	Regex string `yaml:"regex" json:"regex" jsonschema:"description=Regex to apply to the value"`
+	// Replace template of the regex match, e.g. "$2".
+	// The first group is used by default, or the whole match if there are no groups
+	Replace string `yaml:"replace" json:"replace" jsonschema:"description=Replace template of the regex match e.g. $2"`
+	// Template of the result, "{value}" is replaced with the extracted value.
+	// E.g. "https://example.com/manga/{value}"
+	Template string `yaml:"template" json:"template" jsonschema:"description=Template of the result. {value} is replaced with the extracted value"`
+}
+
+// Pagination defines how to find the next page of the results
+type Pagination struct {
+	// Next page URL, extracted from the whole document
+	Next *Field `yaml:"next" json:"next" jsonschema:"required,description=Next page URL extracted from the whole document"`
+	// Limit of the pages to visit. 0 means no limit
+	Limit uint `yaml:"limit" json:"limit" jsonschema:"minimum=0,description=Limit of the pages to visit. 0 means no limit"`
+}
+
+// Strings is a list of strings that can also be defined as a single string
+type Strings []string
+
+func (s *Strings) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*s = Strings{node.Value}
+		return nil
+	}
+
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return fmt.Errorf("line %d: expected string or list of strings", node.Line)
+	}
+
+	*s = list
+	return nil
+}
+
+func (s *Strings) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*s = Strings{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("expected string or list of strings")
+	}
+
+	*s = list
+	return nil
+}
@@ -0,0 +1,78 @@
+package declarative
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/provider/generic"
+	"github.com/metafates/mangal/util"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+	"path/filepath"
+	"strings"
+)
+
+// IsDefinition checks if the file is a definition by its extension
+func IsDefinition(path string) bool {
+	return lo.Contains(Extensions, strings.ToLower(filepath.Ext(path)))
+}
+
+// Parse parses the definition, ext is the extension of the file it was read from.
+// Unknown fields are treated as errors, so typos are not ignored silently
+func Parse(data []byte, ext string) (*Definition, error) {
+	var definition Definition
+
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&definition); err != nil {
+			return nil, err
+		}
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&definition); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported definition format %q, expected one of %s", ext, strings.Join(Extensions, ", "))
+	}
+
+	return &definition, nil
+}
+
+// Load reads the definition file, validates it against the Schema, parses and validates it
+func Load(path string) (*Definition, error) {
+	data, err := filesystem.Api().ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var definition *Definition
+	if err = ValidateSchema(data, filepath.Ext(path)); err == nil {
+		definition, err = Parse(data, filepath.Ext(path))
+	}
+
+	if err == nil {
+		err = definition.Validate()
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+
+	return definition, nil
+}
+
+// LoadConfiguration loads the definition file and compiles it.
+// The name of the scraper is the name of the file without extension
+func LoadConfiguration(path string) (*generic.Configuration, error) {
+	definition, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return definition.Compile(util.FileStem(path))
+}
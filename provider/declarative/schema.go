@@ -0,0 +1,263 @@
+package declarative
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/invopop/jsonschema"
+	"gopkg.in/yaml.v3"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Schema returns the JSON schema of the definition generated from its structs.
+// Definitions are validated against it before they are parsed
+var Schema = sync.OnceValue(func() *jsonschema.Schema {
+	reflector := new(jsonschema.Reflector)
+	reflector.RequiredFromJSONSchemaTags = true
+
+	return reflector.Reflect(&Definition{})
+})
+
+// JSONSchema of the strings is either a single string or a list of them
+func (Strings) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		OneOf: []*jsonschema.Schema{
+			{Type: "string"},
+			{Type: "array", Items: &jsonschema.Schema{Type: "string"}},
+		},
+	}
+}
+
+// ValidateSchema checks the raw definition against the Schema,
+// ext is the extension of the file it was read from.
+// All found errors are returned at once
+func ValidateSchema(data []byte, ext string) error {
+	var value any
+
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &value); err != nil {
+			return err
+		}
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported definition format %q, expected one of %s", ext, strings.Join(Extensions, ", "))
+	}
+
+	var v validator
+	validateValue(&v, Schema(), "", Schema(), value)
+
+	return errors.Join(v.errors...)
+}
+
+// validateValue checks the value against the subset of the JSON schema the reflector generates
+func validateValue(v *validator, root *jsonschema.Schema, path string, schema *jsonschema.Schema, value any) {
+	if schema.Ref != "" {
+		definition, ok := root.Definitions[strings.TrimPrefix(schema.Ref, "#/$defs/")]
+		if !ok {
+			v.add(pathOrRoot(path), "unknown schema reference %s", schema.Ref)
+			return
+		}
+
+		schema = definition
+	}
+
+	if len(schema.OneOf) > 0 {
+		var matched int
+		for _, alternative := range schema.OneOf {
+			var scratch validator
+			validateValue(&scratch, root, path, alternative, value)
+			if len(scratch.errors) == 0 {
+				matched++
+			}
+		}
+
+		if matched != 1 {
+			alternatives := make([]string, len(schema.OneOf))
+			for i, alternative := range schema.OneOf {
+				alternatives[i] = describeSchema(alternative)
+			}
+
+			v.add(pathOrRoot(path), "expected %s", strings.Join(alternatives, " or "))
+		}
+
+		return
+	}
+
+	if schema.Type != "" && !hasType(value, schema.Type) {
+		v.add(pathOrRoot(path), "expected %s, got %s", describeSchema(schema), typeOf(value))
+		return
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		for _, name := range schema.Required {
+			if _, ok := value[name]; !ok {
+				v.add(joinPath(path, name), "is required")
+			}
+		}
+
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+
+		for _, name := range names {
+			var property *jsonschema.Schema
+			if schema.Properties != nil {
+				property, _ = schema.Properties.Get(name)
+			}
+
+			switch {
+			case property != nil:
+				validateValue(v, root, joinPath(path, name), property, value[name])
+			case schema.AdditionalProperties == jsonschema.FalseSchema:
+				v.add(joinPath(path, name), "unknown field")
+			}
+		}
+	case []any:
+		if schema.Items == nil {
+			return
+		}
+
+		for i, item := range value {
+			validateValue(v, root, fmt.Sprintf("%s[%d]", pathOrRoot(path), i), schema.Items, item)
+		}
+	case string:
+		if schema.MinLength != nil && uint64(len(value)) < *schema.MinLength {
+			if *schema.MinLength == 1 {
+				v.add(pathOrRoot(path), "must not be empty")
+			} else {
+				v.add(pathOrRoot(path), "must be at least %d characters long", *schema.MinLength)
+			}
+		}
+
+		if schema.Pattern != "" {
+			if pattern, err := regexp.Compile(schema.Pattern); err == nil && !pattern.MatchString(value) {
+				v.add(pathOrRoot(path), "must match %s", schema.Pattern)
+			}
+		}
+	default:
+		number, ok := toNumber(value)
+		if !ok {
+			return
+		}
+
+		if minimum, err := schema.Minimum.Float64(); schema.Minimum != "" && err == nil && number < minimum {
+			v.add(pathOrRoot(path), "must be at least %s", schema.Minimum)
+		}
+
+		if maximum, err := schema.Maximum.Float64(); schema.Maximum != "" && err == nil && number > maximum {
+			v.add(pathOrRoot(path), "must be at most %s", schema.Maximum)
+		}
+	}
+}
+
+func hasType(value any, typ string) bool {
+	switch typ {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	case "number":
+		_, ok := toNumber(value)
+		return ok
+	case "integer":
+		number, ok := toNumber(value)
+		return ok && number == math.Trunc(number)
+	default:
+		return true
+	}
+}
+
+func toNumber(value any) (float64, bool) {
+	switch value := value.(type) {
+	case int:
+		return float64(value), true
+	case int64:
+		return float64(value), true
+	case uint64:
+		return float64(value), true
+	case float64:
+		return value, true
+	case json.Number:
+		number, err := strconv.ParseFloat(value.String(), 64)
+		return number, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func typeOf(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "list"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	default:
+		if _, ok := toNumber(value); ok {
+			return "number"
+		}
+
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func describeSchema(schema *jsonschema.Schema) string {
+	switch schema.Type {
+	case "array":
+		if schema.Items != nil && schema.Items.Type != "" {
+			return "list of " + schema.Items.Type + "s"
+		}
+
+		return "list"
+	case "":
+		return "value"
+	default:
+		return schema.Type
+	}
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "definition"
+	}
+
+	return path
+}
@@ -0,0 +1,147 @@
+package declarative
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// validator collects errors of the definition with paths to the invalid fields
+type validator struct {
+	errors []error
+}
+
+func (v *validator) add(path, format string, args ...any) {
+	v.errors = append(v.errors, fmt.Errorf("%s: %s", path, fmt.Sprintf(format, args...)))
+}
+
+// Validate checks that the definition is complete and can be compiled.
+// All found errors are returned at once
+func (d *Definition) Validate() error {
+	var v validator
+
+	validateURL(&v, "base_url", d.BaseURL)
+
+	for i, host := range d.Hosts {
+		if host == "" || strings.ContainsAny(host, "/:") {
+			v.add(fmt.Sprintf("hosts[%d]", i), "invalid host %q, expected something like example.com or *.example.com", host)
+		}
+	}
+
+	if d.MangaName != nil {
+		d.MangaName.validate(&v, "manga_name")
+	}
+
+	if d.Delay != "" {
+		if delay, err := time.ParseDuration(d.Delay); err != nil {
+			v.add("delay", "invalid duration %q, expected something like \"50ms\" or \"1s\"", d.Delay)
+		} else if delay < 0 {
+			v.add("delay", "must not be negative")
+		}
+	}
+
+	switch {
+	case d.Search.URL == "":
+		v.add("search.url", "is required")
+	case !strings.Contains(d.Search.URL, "{query}"):
+		v.add("search.url", "must contain {query} placeholder")
+	default:
+		validateURL(&v, "search.url", strings.ReplaceAll(d.Search.URL, "{query}", "query"))
+	}
+
+	d.Manga.validate(&v, "manga", "name", "url", "cover")
+	d.Chapters.validate(&v, "chapters", "name", "url", "volume")
+	d.Pages.validate(&v, "pages", "url")
+
+	return errors.Join(v.errors...)
+}
+
+func validateURL(v *validator, path, address string) {
+	if address == "" {
+		v.add(path, "is required")
+		return
+	}
+
+	parsed, err := url.Parse(address)
+	if err != nil {
+		v.add(path, "invalid url: %s", err)
+		return
+	}
+
+	if parsed.Scheme != "http" && parsed.Scheme != "https" || parsed.Host == "" {
+		v.add(path, "must be an absolute http(s) url, got %q", address)
+	}
+}
+
+// validate checks the extractor. The first field in the list is required,
+// others are optional and fields not in the list are not allowed
+func (e *Extractor) validate(v *validator, path string, fields ...string) {
+	if e.Selector == "" {
+		v.add(path+".selector", "is required")
+	} else if _, err := compileSelector(e.Selector); err != nil {
+		v.add(path+".selector", "invalid selector %q: %s", e.Selector, err)
+	}
+
+	all := map[string]*Field{
+		"name":   e.Name,
+		"url":    e.URL,
+		"volume": e.Volume,
+		"cover":  e.Cover,
+	}
+
+	allowed := make(map[string]bool)
+	for _, name := range fields {
+		allowed[name] = true
+	}
+
+	for _, name := range []string{"name", "url", "volume", "cover"} {
+		field := all[name]
+		fieldPath := path + "." + name
+
+		switch {
+		case field == nil && (name == "name" || name == "url") && allowed[name]:
+			v.add(fieldPath, "is required")
+		case field != nil && !allowed[name]:
+			v.add(fieldPath, "is not supported here")
+		case field != nil:
+			field.validate(v, fieldPath)
+		}
+	}
+
+	if e.Pagination != nil {
+		if e.Pagination.Next == nil {
+			v.add(path+".pagination.next", "is required")
+		} else {
+			e.Pagination.Next.validate(v, path+".pagination.next")
+		}
+	}
+}
+
+func (f *Field) validate(v *validator, path string) {
+	if f.Selector != "" {
+		if _, err := compileSelector(f.Selector); err != nil {
+			v.add(path+".selector", "invalid selector %q: %s", f.Selector, err)
+		}
+	}
+
+	for _, attribute := range f.Attribute {
+		if strings.TrimSpace(attribute) == "" {
+			v.add(path+".attribute", "must not be empty")
+		}
+	}
+
+	if f.Regex != "" {
+		if _, err := regexp.Compile(f.Regex); err != nil {
+			v.add(path+".regex", "invalid regex: %s", err)
+		}
+	} else if f.Replace != "" {
+		v.add(path+".replace", "requires regex")
+	}
+
+	if f.Template != "" && !strings.Contains(f.Template, "{value}") {
+		v.add(path+".template", "must contain {value} placeholder")
+	}
+}
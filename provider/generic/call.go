@@ -0,0 +1,94 @@
+package generic
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// call collects the results of a single request and the requests of its next pages.
+// Each call waits only for its own requests, so concurrent calls don't mix their results
+type call[T any] struct {
+	mu      sync.Mutex
+	pending sync.WaitGroup
+	results []T
+	err     error
+}
+
+// callOf returns the call that the request belongs to
+func callOf[T any](r *colly.Request) *call[T] {
+	c, _ := r.Ctx.GetAny("call").(*call[T])
+	return c
+}
+
+// add appends the results and returns the number of results before them
+func (c *call[T]) add(results ...T) (offset int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	offset = len(c.results)
+	c.results = append(c.results, results...)
+	return
+}
+
+// request makes the request of the call and waits until it and all of its next pages are finished.
+// Error is returned only if nothing was collected
+func (c *call[T]) request(collector *colly.Collector, url string, ctx *colly.Context) ([]T, error) {
+	return c.requestContext(context.Background(), collector, url, ctx)
+}
+
+// requestContext is like request, but it stops waiting when the context is done.
+// Next pages are not visited after that, the pending requests are left to finish in the background
+func (c *call[T]) requestContext(parent context.Context, collector *colly.Collector, url string, ctx *colly.Context) ([]T, error) {
+	ctx.Put("call", c)
+	ctx.Put("context", parent)
+
+	c.pending.Add(1)
+	if err := collector.Request("GET", url, nil, ctx, nil); err != nil {
+		return nil, err
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		c.pending.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+	case <-parent.Done():
+		return nil, parent.Err()
+	}
+
+	if len(c.results) == 0 && c.err != nil {
+		return nil, c.err
+	}
+
+	return c.results, nil
+}
+
+// stopped checks if the context of the call that the request belongs to is done
+func stopped(r *colly.Request) bool {
+	ctx, ok := r.Ctx.GetAny("context").(context.Context)
+	return ok && ctx.Err() != nil
+}
+
+// track marks the requests of the calls as finished
+func track[T any](collector *colly.Collector) {
+	collector.OnScraped(func(r *colly.Response) {
+		if c := callOf[T](r.Request); c != nil {
+			c.pending.Done()
+		}
+	})
+
+	collector.OnError(func(r *colly.Response, err error) {
+		if c := callOf[T](r.Request); c != nil {
+			c.mu.Lock()
+			c.err = err
+			c.mu.Unlock()
+
+			c.pending.Done()
+		}
+	})
+}
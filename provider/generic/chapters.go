@@ -3,28 +3,39 @@ package generic
 import (
 	"github.com/gocolly/colly/v2"
 	"github.com/metafates/mangal/source"
-	"net/http"
 )
 
 // ChaptersOf given source.Manga
 func (s *Scraper) ChaptersOf(manga *source.Manga) ([]*source.Chapter, error) {
-	if chapters, ok := s.chapters[manga.URL]; ok {
+	s.mu.Lock()
+	chapters, ok := s.chapters[manga.URL]
+	s.mu.Unlock()
+
+	if ok {
 		return chapters, nil
 	}
 
+	if cached, ok := s.cache.chapters.Get(manga.URL).Get(); ok {
+		for _, chapter := range cached {
+			chapter.Manga = manga
+		}
+
+		s.mu.Lock()
+		manga.Chapters = cached
+		s.chapters[manga.URL] = cached
+		s.mu.Unlock()
+		return cached, nil
+	}
+
 	ctx := colly.NewContext()
 	ctx.Put("manga", manga)
-	err := s.chaptersCollector.Request(http.MethodGet, manga.URL, nil, ctx, nil)
-
+	chapters, err := (&call[*source.Chapter]{}).request(s.chaptersCollector, manga.URL, ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	s.chaptersCollector.Wait()
-
 	if s.config.ReverseChapters {
 		// reverse chapters
-		chapters := s.chapters[manga.URL]
 		reversed := make([]*source.Chapter, len(chapters))
 		for i, chapter := range chapters {
 			reversed[len(chapters)-i-1] = chapter
@@ -32,8 +43,20 @@ func (s *Scraper) ChaptersOf(manga *source.Manga) ([]*source.Chapter, error) {
 			chapter.Index++
 		}
 
-		s.chapters[manga.URL] = reversed
+		chapters = reversed
+	}
+
+	// sort by the parsed numbers, chapters without them keep the order of the page
+	source.SortChapters(chapters)
+
+	s.mu.Lock()
+	manga.Chapters = chapters
+	s.chapters[manga.URL] = chapters
+	s.mu.Unlock()
+
+	if len(chapters) > 0 {
+		_ = s.cache.chapters.Set(manga.URL, chapters)
 	}
 
-	return s.chapters[manga.URL], nil
+	return chapters, nil
 }
@@ -2,6 +2,7 @@ package generic
 
 import (
 	"github.com/PuerkitoBio/goquery"
+	"github.com/metafates/mangal/provider/custom"
 	"time"
 )
 
@@ -17,12 +18,25 @@ type Extractor struct {
 	Volume func(*goquery.Selection) string
 	// Cover function to get cover from element found by selector. Used by manga extractor
 	Cover func(*goquery.Selection) string
+	// Pagination is responsible for following the next pages. Optional
+	Pagination *Pagination
+}
+
+// Pagination is responsible for finding the next page of the results
+type Pagination struct {
+	// NextPage function to get URL of the next page from the whole document.
+	// Empty string means there are no more pages
+	NextPage func(*goquery.Selection) string
+	// Limit of the pages to visit, including the first one. 0 means no limit
+	Limit uint
 }
 
 // Configuration is a generic scraper configuration that defines behavior of the scraper
 type Configuration struct {
 	// Name of the scraper
 	Name string
+	// Custom is true for the scrapers defined by user rather than built-in ones
+	Custom bool
 	// Delay between requests
 	Delay time.Duration
 	// Parallelism of the scraper
@@ -33,10 +47,17 @@ type Configuration struct {
 
 	// BaseURL of the source
 	BaseURL string
+	// Hosts of the manga pages that the source handles, e.g. "example.com" or "*.example.com".
+	// Host of the BaseURL is used if empty
+	Hosts []string
 	// GenerateSearchURL function to create search URL from the query.
 	// E.g. "one piece" -> "https://manganelo.com/search/story/one%20piece"
 	GenerateSearchURL func(query string) string
 
+	// MangaName function to get the manga name from the whole document of the manga page.
+	// Used to get the manga by URL without searching. Optional, og:title meta or title is used by default
+	MangaName func(*goquery.Selection) string
+
 	// MangaExtractor is responsible for finding manga elements and extracting required data from them
 	MangaExtractor,
 	// ChapterExtractor is responsible for finding chapter elements and extracting required data from them
@@ -46,5 +67,9 @@ type Configuration struct {
 }
 
 func (c *Configuration) ID() string {
+	if c.Custom {
+		return custom.IDfromName(c.Name)
+	}
+
 	return c.Name + " built-in"
 }
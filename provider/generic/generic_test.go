@@ -0,0 +1,198 @@
+package generic
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/metafates/mangal/source"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// newTestServer serves mangas with two pages of chapters each and chapters with two pages of images each
+func newTestServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/manga/", func(w http.ResponseWriter, r *http.Request) {
+		// make the requests of different calls overlap
+		time.Sleep(5 * time.Millisecond)
+
+		if r.URL.Query().Get("page") == "" {
+			_, _ = fmt.Fprintf(w, `<html><body>
+<div class="chapter"><a href="/chapter%[1]s-1">Chapter 1</a></div>
+<a class="next" href="%[1]s?page=2">Next</a>
+</body></html>`, r.URL.Path)
+			return
+		}
+
+		_, _ = fmt.Fprintf(w, `<html><body>
+<div class="chapter"><a href="/chapter%[1]s-2">Chapter 2</a></div>
+</body></html>`, r.URL.Path)
+	})
+	mux.HandleFunc("/chapter/", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+
+		if r.URL.Query().Get("page") == "" {
+			_, _ = fmt.Fprintf(w, `<html><body>
+<img src="%[1]s/1.png"><img src="%[1]s/2.png">
+<a class="next" href="%[1]s?page=2">Next</a>
+</body></html>`, r.URL.Path)
+			return
+		}
+
+		_, _ = fmt.Fprintf(w, `<html><body><img src="%s/3.png"></body></html>`, r.URL.Path)
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func testConfiguration(baseURL string) *Configuration {
+	attr := func(name string) func(*goquery.Selection) string {
+		return func(selection *goquery.Selection) string {
+			return selection.AttrOr(name, "")
+		}
+	}
+
+	next := &Pagination{
+		NextPage: func(document *goquery.Selection) string {
+			return document.Find("a.next").AttrOr("href", "")
+		},
+	}
+
+	return &Configuration{
+		Name:              "test",
+		Parallelism:       4,
+		BaseURL:           baseURL,
+		GenerateSearchURL: func(query string) string { return baseURL + "/search?q=" + query },
+		ChapterExtractor: &Extractor{
+			Selector:   ".chapter",
+			Name:       func(selection *goquery.Selection) string { return selection.Find("a").Text() },
+			URL:        func(selection *goquery.Selection) string { return selection.Find("a").AttrOr("href", "") },
+			Volume:     func(*goquery.Selection) string { return "" },
+			Pagination: next,
+		},
+		PageExtractor: &Extractor{
+			Selector:   "img",
+			Name:       attr("src"),
+			URL:        attr("src"),
+			Pagination: next,
+		},
+	}
+}
+
+func TestScraperConcurrency(t *testing.T) {
+	Convey("Given a generic scraper of the local server", t, func() {
+		server := newTestServer()
+		defer server.Close()
+
+		scraper := New(testConfiguration(server.URL))
+
+		mangas := make([]*source.Manga, 4)
+		for i := range mangas {
+			mangas[i] = &source.Manga{
+				Name:   fmt.Sprintf("Manga %d", i),
+				URL:    fmt.Sprintf("%s/manga/%d", server.URL, i),
+				Source: scraper,
+			}
+		}
+
+		Convey("When chapters and pages are requested from several goroutines", func() {
+			var (
+				wg       sync.WaitGroup
+				mu       sync.Mutex
+				errs     []error
+				chapters = make(map[*source.Manga][]*source.Chapter)
+				pages    = make(map[*source.Chapter][]*source.Page)
+			)
+
+			for _, manga := range mangas {
+				// each manga is requested twice to share the results between the calls
+				for i := 0; i < 2; i++ {
+					wg.Add(1)
+					go func(manga *source.Manga) {
+						defer wg.Done()
+
+						mangaChapters, err := scraper.ChaptersOf(manga)
+						if err != nil {
+							mu.Lock()
+							errs = append(errs, err)
+							mu.Unlock()
+							return
+						}
+
+						mu.Lock()
+						chapters[manga] = mangaChapters
+						mu.Unlock()
+
+						for _, chapter := range mangaChapters {
+							chapterPages, err := scraper.PagesOf(chapter)
+
+							mu.Lock()
+							if err != nil {
+								errs = append(errs, err)
+							} else {
+								pages[chapter] = chapterPages
+							}
+							mu.Unlock()
+						}
+					}(manga)
+				}
+			}
+
+			wg.Wait()
+
+			Convey("Then results of the calls should not be mixed", func() {
+				So(errs, ShouldBeEmpty)
+
+				for _, manga := range mangas {
+					So(chapters[manga], ShouldHaveLength, 2)
+
+					for i, chapter := range chapters[manga] {
+						So(chapter.URL, ShouldEqual, fmt.Sprintf("%s/chapter/manga/%s-%d", server.URL, manga.URL[len(server.URL+"/manga/"):], i+1))
+
+						So(pages[chapter], ShouldHaveLength, 3)
+						for j, page := range pages[chapter] {
+							So(page.Index, ShouldEqual, j)
+							So(page.Chapter, ShouldEqual, chapter)
+							So(page.URL, ShouldEqual, fmt.Sprintf("/chapter/manga/%s-%d/%d.png", manga.URL[len(server.URL+"/manga/"):], i+1, j+1))
+						}
+					}
+				}
+			})
+		})
+	})
+}
+
+func TestScraperChaptersOrder(t *testing.T) {
+	Convey("Given a generic scraper that reverses the chapters of the local server", t, func() {
+		server := newTestServer()
+		defer server.Close()
+
+		config := testConfiguration(server.URL)
+		config.ReverseChapters = true
+		scraper := New(config)
+
+		manga := &source.Manga{
+			Name:   "Reversed",
+			URL:    fmt.Sprintf("%s/manga/reversed-%d", server.URL, time.Now().UnixNano()),
+			Source: scraper,
+		}
+
+		Convey("When chapters are requested", func() {
+			chapters, err := scraper.ChaptersOf(manga)
+
+			Convey("Then they should be sorted by the parsed numbers", func() {
+				So(err, ShouldBeNil)
+				So(chapters, ShouldHaveLength, 2)
+
+				for i, chapter := range chapters {
+					So(chapter.Name, ShouldEqual, fmt.Sprintf("Chapter %d", i+1))
+					So(chapter.Index, ShouldEqual, i+1)
+				}
+			})
+		})
+	})
+}
@@ -0,0 +1,70 @@
+package generic
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly/v2"
+	"github.com/metafates/mangal/source"
+)
+
+// GetManga by the URL of its page. IDs are resolved relative to the base URL
+func (s *Scraper) GetManga(id string) (*source.Manga, error) {
+	mangaURL, err := s.resolve(id)
+	if err != nil {
+		return nil, err
+	}
+
+	manga := &source.Manga{
+		URL:      mangaURL,
+		ID:       filepath.Base(mangaURL),
+		Chapters: make([]*source.Chapter, 0),
+		Source:   s,
+	}
+
+	names, err := (&call[string]{}).request(s.mangaCollector, mangaURL, colly.NewContext())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(names) == 0 || names[0] == "" {
+		return nil, fmt.Errorf("manga name not found at %s", mangaURL)
+	}
+
+	manga.Name = names[0]
+
+	return manga, nil
+}
+
+// resolve returns the absolute URL of the manga page
+func (s *Scraper) resolve(id string) (string, error) {
+	base, err := url.Parse(s.config.BaseURL)
+	if err != nil {
+		return "", err
+	}
+
+	ref, err := url.Parse(id)
+	if err != nil {
+		return "", fmt.Errorf("invalid manga URL %q: %w", id, err)
+	}
+
+	return base.ResolveReference(ref).String(), nil
+}
+
+// mangaName finds the manga name in the document of the manga page
+func (s *Scraper) mangaName(document *goquery.Selection) string {
+	if s.config.MangaName != nil {
+		if name := strings.TrimSpace(s.config.MangaName(document)); name != "" {
+			return name
+		}
+	}
+
+	if name := strings.TrimSpace(document.Find(`meta[property="og:title"]`).AttrOr("content", "")); name != "" {
+		return name
+	}
+
+	return strings.TrimSpace(document.Find("title").First().Text())
+}
@@ -3,11 +3,13 @@ package generic
 import (
 	"github.com/PuerkitoBio/goquery"
 	"github.com/gocolly/colly/v2"
+	"github.com/metafates/mangal/cache"
 	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/network"
 	"github.com/metafates/mangal/source"
-	"github.com/metafates/mangal/where"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -20,14 +22,20 @@ func New(conf *Configuration) source.Source {
 		config:   conf,
 	}
 
+	s.cache.mangas = cache.New[[]*source.Manga](conf.Name, cache.Search)
+	s.cache.chapters = cache.New[[]*source.Chapter](conf.Name, cache.Chapters)
+	s.cache.pages = cache.New[[]*source.Page](conf.Name, cache.Pages)
+
 	collectorOptions := []colly.CollectorOption{
 		colly.AllowURLRevisit(),
 		colly.Async(true),
-		colly.CacheDir(where.Cache()),
 	}
 
 	baseCollector := colly.NewCollector(collectorOptions...)
 	baseCollector.SetRequestTimeout(20 * time.Second)
+	// share cookies and solved challenges with other sources
+	baseCollector.WithTransport(network.Transport)
+	baseCollector.SetCookieJar(network.Jar)
 
 	mangasCollector := baseCollector.Clone()
 	mangasCollector.OnRequest(func(r *colly.Request) {
@@ -40,9 +48,9 @@ func New(conf *Configuration) source.Source {
 
 	// Get mangas
 	mangasCollector.OnHTML("html", func(e *colly.HTMLElement) {
+		c := callOf[*source.Manga](e.Request)
 		elements := e.DOM.Find(s.config.MangaExtractor.Selector)
-		path := e.Request.URL.String()
-		s.mangas[path] = make([]*source.Manga, elements.Length())
+		mangas := make([]*source.Manga, elements.Length())
 
 		elements.Each(func(i int, selection *goquery.Selection) {
 			link := s.config.MangaExtractor.URL(selection)
@@ -57,9 +65,13 @@ func New(conf *Configuration) source.Source {
 			}
 			manga.Metadata.Cover.ExtraLarge = s.config.MangaExtractor.Cover(selection)
 
-			s.mangas[path][i] = &manga
+			mangas[i] = &manga
 		})
+
+		c.add(mangas...)
+		nextPage(e, s.config.MangaExtractor, &c.pending)
 	})
+	track[*source.Manga](mangasCollector)
 
 	_ = mangasCollector.Limit(&colly.LimitRule{
 		Parallelism: int(s.config.Parallelism),
@@ -67,6 +79,19 @@ func New(conf *Configuration) source.Source {
 		DomainGlob:  "*",
 	})
 
+	mangaCollector := baseCollector.Clone()
+	mangaCollector.OnRequest(func(r *colly.Request) {
+		r.Headers.Set("accept-language", "en-US")
+		r.Headers.Set("Accept", "text/html")
+		r.Headers.Set("User-Agent", constant.UserAgent)
+	})
+
+	// Get manga by its page
+	mangaCollector.OnHTML("html", func(e *colly.HTMLElement) {
+		callOf[string](e.Request).add(s.mangaName(e.DOM))
+	})
+	track[string](mangaCollector)
+
 	chaptersCollector := baseCollector.Clone()
 	chaptersCollector.OnRequest(func(r *colly.Request) {
 		r.Headers.Set("Referer", r.Ctx.GetAny("manga").(*source.Manga).URL)
@@ -78,9 +103,9 @@ func New(conf *Configuration) source.Source {
 
 	// Get chapters
 	chaptersCollector.OnHTML("html", func(e *colly.HTMLElement) {
+		c := callOf[*source.Chapter](e.Request)
 		elements := e.DOM.Find(s.config.ChapterExtractor.Selector)
-		path := e.Request.AbsoluteURL(e.Request.URL.Path)
-		s.chapters[path] = make([]*source.Chapter, elements.Length())
+		chapters := make([]*source.Chapter, elements.Length())
 		manga := e.Request.Ctx.GetAny("manga").(*source.Manga)
 
 		elements.Each(func(i int, selection *goquery.Selection) {
@@ -96,10 +121,13 @@ func New(conf *Configuration) source.Source {
 				Manga:  manga,
 				Volume: s.config.ChapterExtractor.Volume(selection),
 			}
-			s.chapters[path][i] = &chapter
+			chapters[i] = &chapter
 		})
-		manga.Chapters = s.chapters[path]
+
+		c.add(chapters...)
+		nextPage(e, s.config.ChapterExtractor, &c.pending)
 	})
+	track[*source.Chapter](chaptersCollector)
 	_ = chaptersCollector.Limit(&colly.LimitRule{
 		Parallelism: int(s.config.Parallelism),
 		RandomDelay: s.config.Delay,
@@ -116,9 +144,9 @@ func New(conf *Configuration) source.Source {
 
 	// Get pages
 	pagesCollector.OnHTML("html", func(e *colly.HTMLElement) {
+		c := callOf[*source.Page](e.Request)
 		elements := e.DOM.Find(s.config.PageExtractor.Selector)
-		path := e.Request.AbsoluteURL(e.Request.URL.Path)
-		s.pages[path] = make([]*source.Page, elements.Length())
+		pages := make([]*source.Page, elements.Length())
 		chapter := e.Request.Ctx.GetAny("chapter").(*source.Chapter)
 
 		elements.Each(func(i int, selection *goquery.Selection) {
@@ -129,14 +157,21 @@ func New(conf *Configuration) source.Source {
 
 			page := source.Page{
 				URL:       link,
-				Index:     uint16(i),
 				Chapter:   chapter,
 				Extension: ext,
 			}
-			s.pages[path][i] = &page
+			pages[i] = &page
 		})
-		chapter.Pages = s.pages[path]
+
+		// indexes are set after the pages are added, so that they continue the previous pages
+		offset := c.add(pages...)
+		for i, page := range pages {
+			page.Index = uint16(offset + i)
+		}
+
+		nextPage(e, s.config.PageExtractor, &c.pending)
 	})
+	track[*source.Page](pagesCollector)
 	_ = pagesCollector.Limit(&colly.LimitRule{
 		Parallelism: int(s.config.Parallelism),
 		RandomDelay: s.config.Delay,
@@ -144,8 +179,43 @@ func New(conf *Configuration) source.Source {
 	})
 
 	s.mangasCollector = mangasCollector
+	s.mangaCollector = mangaCollector
 	s.chaptersCollector = chaptersCollector
 	s.pagesCollector = pagesCollector
 
 	return &s
 }
+
+// nextPage visits the next page of the results, if the extractor has pagination.
+// The visit is added to the pending requests of the call
+func nextPage(e *colly.HTMLElement, extractor *Extractor, pending *sync.WaitGroup) {
+	pagination := extractor.Pagination
+	if pagination == nil || pagination.NextPage == nil || stopped(e.Request) {
+		return
+	}
+
+	// number of the visited pages, including the current one
+	visited, _ := e.Request.Ctx.GetAny("visited").(uint)
+	visited++
+
+	if pagination.Limit > 0 && visited >= pagination.Limit {
+		return
+	}
+
+	next := pagination.NextPage(e.DOM)
+	if next == "" {
+		return
+	}
+
+	next = e.Request.AbsoluteURL(next)
+	if next == "" || next == e.Request.URL.String() {
+		return
+	}
+
+	e.Request.Ctx.Put("visited", visited)
+
+	pending.Add(1)
+	if err := e.Request.Visit(next); err != nil {
+		pending.Done()
+	}
+}
@@ -3,24 +3,55 @@ package generic
 import (
 	"github.com/gocolly/colly/v2"
 	"github.com/metafates/mangal/source"
-	"net/http"
 )
 
 // PagesOf given source.Chapter
 func (s *Scraper) PagesOf(chapter *source.Chapter) ([]*source.Page, error) {
-	if pages, ok := s.pages[chapter.URL]; ok {
-		return pages, nil
+	s.mu.Lock()
+	pages, ok := s.pages[chapter.URL]
+	s.mu.Unlock()
+
+	if ok {
+		return s.pagesFor(chapter, pages), nil
+	}
+
+	if cached, ok := s.cache.pages.Get(chapter.URL).Get(); ok {
+		s.mu.Lock()
+		s.pages[chapter.URL] = cached
+		s.mu.Unlock()
+		return s.pagesFor(chapter, cached), nil
 	}
 
 	ctx := colly.NewContext()
 	ctx.Put("chapter", chapter)
-	err := s.pagesCollector.Request(http.MethodGet, chapter.URL, nil, ctx, nil)
-
+	pages, err := (&call[*source.Page]{}).request(s.pagesCollector, chapter.URL, ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	s.pagesCollector.Wait()
+	s.mu.Lock()
+	s.pages[chapter.URL] = pages
+	s.mu.Unlock()
+
+	if len(pages) > 0 {
+		_ = s.cache.pages.Set(chapter.URL, pages)
+	}
+
+	return s.pagesFor(chapter, pages), nil
+}
+
+// pagesFor returns copies of the pages bound to the chapter.
+// Pages are downloaded into their contents, so chapters must not share them
+func (s *Scraper) pagesFor(chapter *source.Chapter, pages []*source.Page) []*source.Page {
+	copies := make([]*source.Page, len(pages))
+	for i, page := range pages {
+		copied := *page
+		copied.Chapter = chapter
+		copies[i] = &copied
+	}
 
-	return s.pages[chapter.URL], nil
+	s.mu.Lock()
+	chapter.Pages = copies
+	s.mu.Unlock()
+	return copies
 }
@@ -1,20 +1,32 @@
 package generic
 
 import (
+	"sync"
+
 	"github.com/gocolly/colly/v2"
+	"github.com/metafates/mangal/cache"
 	"github.com/metafates/mangal/source"
 )
 
 // Scraper is a generic scraper downloads html pages and parses them
 type Scraper struct {
 	mangasCollector   *colly.Collector
+	mangaCollector    *colly.Collector
 	chaptersCollector *colly.Collector
 	pagesCollector    *colly.Collector
 
+	// mu guards the maps below, scraper is used from several goroutines by the download workers
+	mu       sync.Mutex
 	mangas   map[string][]*source.Manga
 	chapters map[string][]*source.Chapter
 	pages    map[string][]*source.Page
 
+	cache struct {
+		mangas   *cache.Cache[[]*source.Manga]
+		chapters *cache.Cache[[]*source.Chapter]
+		pages    *cache.Cache[[]*source.Page]
+	}
+
 	config *Configuration
 }
 
@@ -1,23 +1,53 @@
 package generic
 
 import (
+	"context"
+
+	"github.com/gocolly/colly/v2"
 	"github.com/metafates/mangal/source"
 )
 
 // Search for mangas by given title
 func (s *Scraper) Search(query string) ([]*source.Manga, error) {
+	return s.SearchContext(context.Background(), query)
+}
+
+// SearchContext searches for mangas and stops following the next pages of the results when the context is done
+func (s *Scraper) SearchContext(ctx context.Context, query string) ([]*source.Manga, error) {
 	address := s.config.GenerateSearchURL(query)
 
-	if urls, ok := s.mangas[address]; ok {
-		return urls, nil
+	s.mu.Lock()
+	mangas, ok := s.mangas[address]
+	s.mu.Unlock()
+
+	if ok {
+		return mangas, nil
 	}
 
-	err := s.mangasCollector.Visit(address)
+	if cached, ok := s.cache.mangas.Get(address).Get(); ok {
+		for _, manga := range cached {
+			manga.Source = s
+		}
 
+		s.mu.Lock()
+		s.mangas[address] = cached
+		s.mu.Unlock()
+		return cached, nil
+	}
+
+	mangas, err := (&call[*source.Manga]{}).requestContext(ctx, s.mangasCollector, address, colly.NewContext())
 	if err != nil {
 		return nil, err
 	}
 
-	s.mangasCollector.Wait()
-	return s.mangas[address], nil
+	s.mu.Lock()
+	s.mangas[address] = mangas
+	s.mu.Unlock()
+
+	// empty results may be caused by a failed request
+	if len(mangas) > 0 {
+		_ = s.cache.mangas.Set(address, mangas)
+	}
+
+	return mangas, nil
 }
@@ -13,8 +13,9 @@ const CustomProviderExtension = ".lua"
 
 var builtinProviders = []*Provider{
 	{
-		ID:   mangadex.ID,
-		Name: mangadex.Name,
+		ID:    mangadex.ID,
+		Name:  mangadex.Name,
+		Hosts: []string{"mangadex.org"},
 		CreateSource: func() (source.Source, error) {
 			return mangadex.New(), nil
 		},
@@ -29,8 +30,9 @@ func init() {
 	} {
 		conf := conf
 		builtinProviders = append(builtinProviders, &Provider{
-			ID:   conf.ID(),
-			Name: conf.Name,
+			ID:    conf.ID(),
+			Name:  conf.Name,
+			Hosts: hostsOf(conf),
 			CreateSource: func() (source.Source, error) {
 				return generic.New(conf), nil
 			},
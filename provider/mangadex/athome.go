@@ -0,0 +1,196 @@
+package mangadex
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/network"
+	"github.com/metafates/mangal/util"
+)
+
+var (
+	// atHomeServerURL is the endpoint that gives the MangaDex@Home server for the chapter
+	atHomeServerURL = "https://api.mangadex.org/at-home/server/%s"
+	// atHomeReportURL is the endpoint that collects the results of image downloads
+	atHomeReportURL = "https://api.mangadex.network/report"
+)
+
+// serverLifetime is how long the server URL is used before it is re-fetched.
+// Tokens of the server URL expire after 15 minutes
+const serverLifetime = 10 * time.Minute
+
+const (
+	qualityData      = "data"
+	qualityDataSaver = "data-saver"
+)
+
+// errTokenExpired is returned when the server refuses the image because its token has expired
+var errTokenExpired = errors.New("at-home server token has expired")
+
+// atHome downloads chapter images from the MangaDex@Home network
+// and reports the result of each download back to it
+type atHome struct {
+	chapterID string
+	quality   string
+	client    *http.Client
+
+	baseURL   string
+	hash      string
+	pages     []string
+	fetchedAt time.Time
+}
+
+func newAtHome(chapterID string, dataSaver bool) (*atHome, error) {
+	a := &atHome{
+		chapterID: chapterID,
+		quality:   qualityData,
+		client:    network.Client,
+	}
+
+	if dataSaver {
+		a.quality = qualityDataSaver
+	}
+
+	return a, a.refresh()
+}
+
+// refresh fetches the server URL for the chapter
+func (a *atHome) refresh() error {
+	res, err := a.client.Get(fmt.Sprintf(atHomeServerURL, a.chapterID))
+	if err != nil {
+		return err
+	}
+
+	defer util.Ignore(res.Body.Close)
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to get at-home server: %s", res.Status)
+	}
+
+	var server struct {
+		Result  string `json:"result"`
+		BaseURL string `json:"baseUrl"`
+		Chapter struct {
+			Hash      string   `json:"hash"`
+			Data      []string `json:"data"`
+			DataSaver []string `json:"dataSaver"`
+		} `json:"chapter"`
+	}
+
+	if err = json.NewDecoder(res.Body).Decode(&server); err != nil {
+		return err
+	}
+
+	if server.Result != "ok" {
+		return fmt.Errorf("failed to get at-home server: result is %q", server.Result)
+	}
+
+	a.baseURL = server.BaseURL
+	a.hash = server.Chapter.Hash
+	a.pages = server.Chapter.Data
+	if a.quality == qualityDataSaver {
+		a.pages = server.Chapter.DataSaver
+	}
+
+	a.fetchedAt = time.Now()
+	return nil
+}
+
+// page downloads the image of the page.
+// Server URL is re-fetched when it gets old or its token expires
+func (a *atHome) page(name string) ([]byte, error) {
+	if time.Since(a.fetchedAt) > serverLifetime {
+		if err := a.refresh(); err != nil {
+			return nil, err
+		}
+	}
+
+	image, err := a.download(name)
+	if !errors.Is(err, errTokenExpired) {
+		return image, err
+	}
+
+	log.Warn("mangadex: " + err.Error() + ", getting a new one")
+	if err = a.refresh(); err != nil {
+		return nil, err
+	}
+
+	return a.download(name)
+}
+
+func (a *atHome) download(name string) ([]byte, error) {
+	address := strings.Join([]string{a.baseURL, a.quality, a.hash, name}, "/")
+
+	req, err := http.NewRequest(http.MethodGet, address, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", constant.UserAgent)
+
+	start := time.Now()
+	res, err := a.client.Do(req)
+	if err != nil {
+		a.report(address, false, false, 0, start)
+		return nil, err
+	}
+
+	defer util.Ignore(res.Body.Close)
+
+	image, err := io.ReadAll(res.Body)
+	cached := strings.HasPrefix(res.Header.Get("X-Cache"), "HIT")
+
+	switch {
+	case err != nil:
+	case res.StatusCode == http.StatusForbidden:
+		err = errTokenExpired
+	case res.StatusCode != http.StatusOK:
+		err = fmt.Errorf("failed to get %s: %s", name, res.Status)
+	case len(image) == 0:
+		err = errors.New("image is empty")
+	}
+
+	a.report(address, err == nil, cached, len(image), start)
+	return image, err
+}
+
+// report sends the result of the image download to the MangaDex@Home network.
+// Images served by mangadex.org itself are not reported
+func (a *atHome) report(address string, success, cached bool, size int, start time.Time) {
+	if strings.Contains(a.baseURL, "mangadex.org") {
+		return
+	}
+
+	payload, err := json.Marshal(struct {
+		URL      string `json:"url"`
+		Success  bool   `json:"success"`
+		Cached   bool   `json:"cached"`
+		Bytes    int    `json:"bytes"`
+		Duration int64  `json:"duration"`
+	}{
+		URL:      address,
+		Success:  success,
+		Cached:   cached,
+		Bytes:    size,
+		Duration: time.Since(start).Milliseconds(),
+	})
+	if err != nil {
+		return
+	}
+
+	res, err := a.client.Post(atHomeReportURL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		log.Warn("mangadex: failed to send at-home report: " + err.Error())
+		return
+	}
+
+	util.Ignore(res.Body.Close)
+}
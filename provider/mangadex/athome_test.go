@@ -0,0 +1,108 @@
+package mangadex
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fakeAtHome is MangaDex@Home that expires the token after the given number of images
+type fakeAtHome struct {
+	mu        sync.Mutex
+	token     int
+	expireAt  int
+	served    int
+	refreshes int
+	reports   []map[string]any
+}
+
+func (f *fakeAtHome) handler(t *testing.T, url func() string) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/at-home/server/", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		f.token++
+		f.refreshes++
+		_, _ = fmt.Fprintf(w, `{"result": "ok", "baseUrl": "%s/token%d", "chapter": {"hash": "h", "data": ["1.png", "2.png", "3.png"], "dataSaver": ["1.jpg", "2.jpg", "3.jpg"]}}`, url(), f.token)
+	})
+
+	mux.HandleFunc("/report", func(w http.ResponseWriter, r *http.Request) {
+		var report map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+			t.Error(err)
+		}
+
+		f.mu.Lock()
+		f.reports = append(f.reports, report)
+		f.mu.Unlock()
+	})
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		if !strings.HasPrefix(r.URL.Path, fmt.Sprintf("/token%d/", f.token)) || f.served == f.expireAt {
+			f.expireAt = -1
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		f.served++
+		_, _ = fmt.Fprint(w, r.URL.Path)
+	})
+
+	return mux
+}
+
+func TestAtHome(t *testing.T) {
+	Convey("Given MangaDex@Home server that expires the token mid-chapter", t, func() {
+		fake := &fakeAtHome{expireAt: 1}
+
+		var server *httptest.Server
+		server = httptest.NewServer(fake.handler(t, func() string { return server.URL }))
+		defer server.Close()
+
+		defaultServerURL, defaultReportURL := atHomeServerURL, atHomeReportURL
+		atHomeServerURL, atHomeReportURL = server.URL+"/at-home/server/%s", server.URL+"/report"
+		defer func() {
+			atHomeServerURL, atHomeReportURL = defaultServerURL, defaultReportURL
+		}()
+
+		Convey("When downloading every page in data saver mode", func() {
+			a, err := newAtHome("chapter", true)
+			So(err, ShouldBeNil)
+
+			var images []string
+			for _, name := range a.pages {
+				image, err := a.page(name)
+				So(err, ShouldBeNil)
+				images = append(images, string(image))
+			}
+
+			Convey("Then compressed images should be downloaded with a new token after it expires", func() {
+				So(images, ShouldResemble, []string{
+					"/token1/data-saver/h/1.jpg",
+					"/token2/data-saver/h/2.jpg",
+					"/token2/data-saver/h/3.jpg",
+				})
+				So(fake.refreshes, ShouldEqual, 2)
+			})
+
+			Convey("Then every download should be reported", func() {
+				So(fake.reports, ShouldHaveLength, 4)
+				So(fake.reports[1]["success"], ShouldEqual, false)
+				So(fake.reports[1]["url"], ShouldEqual, server.URL+"/token1/data-saver/h/2.jpg")
+				So(fake.reports[2]["success"], ShouldEqual, true)
+				So(fake.reports[2]["bytes"], ShouldEqual, len("/token2/data-saver/h/2.jpg"))
+			})
+		})
+	})
+}
@@ -0,0 +1,188 @@
+package mangadex
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/network"
+	"github.com/metafates/mangal/util"
+	"github.com/spf13/viper"
+)
+
+var (
+	// authTokenURL is the OAuth token endpoint of the personal API clients
+	authTokenURL = "https://auth.mangadex.org/realms/mangadex/protocol/openid-connect/token"
+	// apiURL is the base URL of the mangadex api
+	apiURL = "https://api.mangadex.org"
+)
+
+// ErrNotLoggedIn is returned when the feature requires mangadex account
+var ErrNotLoggedIn = errors.New(`not logged in to mangadex, type "mangal mangadex login"`)
+
+// session of the logged-in user.
+// Access token is kept in memory only, refresh token is stored in the session file
+var session struct {
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int    `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// LoggedIn checks if the refresh token and client credentials are set
+func LoggedIn() bool {
+	loadCredentials()
+
+	return viper.GetString(key.MangadexRefreshToken) != "" &&
+		viper.GetString(key.MangadexClientID) != "" &&
+		viper.GetString(key.MangadexClientSecret) != ""
+}
+
+// Login gets the session with the personal API client.
+// Client credentials and the refresh token are saved, the password is never stored
+func Login(username, password string) error {
+	loadCredentials()
+
+	return requestToken(url.Values{
+		"grant_type": {"password"},
+		"username":   {username},
+		"password":   {password},
+	})
+}
+
+// Logout forgets the session
+func Logout() error {
+	loadCredentials()
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	session.accessToken = ""
+	session.expiresAt = time.Time{}
+	viper.Set(key.MangadexRefreshToken, "")
+
+	return saveCredentials()
+}
+
+// token returns the access token, refreshing it when it expires
+func token() (string, error) {
+	if !LoggedIn() {
+		return "", ErrNotLoggedIn
+	}
+
+	session.mu.Lock()
+	valid := session.accessToken != "" && time.Now().Before(session.expiresAt)
+	session.mu.Unlock()
+
+	if !valid {
+		err := requestToken(url.Values{
+			"grant_type":    {"refresh_token"},
+			"refresh_token": {viper.GetString(key.MangadexRefreshToken)},
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	return session.accessToken, nil
+}
+
+// requestToken exchanges the grant for the tokens and remembers them
+func requestToken(form url.Values) error {
+	form.Set("client_id", viper.GetString(key.MangadexClientID))
+	form.Set("client_secret", viper.GetString(key.MangadexClientSecret))
+
+	res, err := network.Client.PostForm(authTokenURL, form)
+	if err != nil {
+		return err
+	}
+
+	defer util.Ignore(res.Body.Close)
+
+	var response tokenResponse
+	if err = json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return fmt.Errorf("mangadex auth: %s", res.Status)
+	}
+
+	if res.StatusCode != http.StatusOK || response.AccessToken == "" {
+		if response.ErrorDescription != "" {
+			return fmt.Errorf("mangadex auth: %s", response.ErrorDescription)
+		}
+
+		return fmt.Errorf("mangadex auth: %s", res.Status)
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	session.accessToken = response.AccessToken
+	// refresh a bit earlier so that the token does not expire mid-request
+	session.expiresAt = time.Now().Add(time.Duration(response.ExpiresIn)*time.Second - 30*time.Second)
+
+	if response.RefreshToken == "" || response.RefreshToken == viper.GetString(key.MangadexRefreshToken) {
+		return nil
+	}
+
+	// refresh token may be rotated, the old one is revoked then
+	viper.Set(key.MangadexRefreshToken, response.RefreshToken)
+	if err = saveCredentials(); err != nil {
+		return fmt.Errorf("mangadex: failed to save the session: %w", err)
+	}
+
+	return nil
+}
+
+// get requests the mangadex api on behalf of the user and decodes the response into v
+func get(path string, params url.Values, v any) error {
+	res, err := request(http.MethodGet, path, params)
+	if err != nil {
+		return err
+	}
+
+	defer util.Ignore(res.Body.Close)
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("mangadex: failed to get %s: %s", path, res.Status)
+	}
+
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// request sends the request of the logged-in user to the api
+func request(method, path string, params url.Values) (*http.Response, error) {
+	accessToken, err := token()
+	if err != nil {
+		return nil, err
+	}
+
+	address := strings.TrimSuffix(apiURL, "/") + path
+	if len(params) > 0 {
+		address += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequest(method, address, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("User-Agent", constant.UserAgent)
+
+	return network.Client.Do(req)
+}
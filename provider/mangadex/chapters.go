@@ -4,17 +4,18 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
-	"strings"
 
 	"github.com/darylhjd/mangodex"
 	"github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/source"
 	"github.com/spf13/viper"
-	"golang.org/x/exp/slices"
 )
 
 func (m *Mangadex) ChaptersOf(manga *source.Manga) ([]*source.Chapter, error) {
-	if cached, ok := m.cache.chapters.Get(manga.URL).Get(); ok {
+	prefs := preferencesFromConfig()
+	cacheKey := manga.URL + "#" + prefs.key()
+
+	if cached, ok := m.cache.chapters.Get(cacheKey).Get(); ok {
 		for _, chapter := range cached {
 			chapter.Manga = manga
 		}
@@ -34,18 +35,20 @@ func (m *Mangadex) ChaptersOf(manga *source.Manga) ([]*source.Chapter, error) {
 		params.Add("contentRating[]", mangodex.Erotica)
 	}
 
+	for _, language := range prefs.languages {
+		params.Add("translatedLanguage[]", language)
+	}
+
 	// scanlation group for the chapter
 	params.Add("includes[]", mangodex.ScanlationGroupRel)
 	params.Set("order[chapter]", "asc")
 
 	var (
-		chapters   []*source.Chapter
+		candidates []*candidate
 		currOffset        = 0
 		chapIndex  uint16 = 1
 	)
 
-	language := viper.GetString(key.MangadexLanguage)
-
 	for {
 		params.Set("offset", strconv.Itoa(currOffset))
 		list, err := m.client.Chapter.GetMangaChapters(manga.ID, params)
@@ -59,32 +62,7 @@ func (m *Mangadex) ChaptersOf(manga *source.Manga) ([]*source.Chapter, error) {
 				continue
 			}
 
-			// skip chapters that are not in the current language
-			if language != "any" && chapter.Attributes.TranslatedLanguage != language {
-				currOffset += 500
-				continue
-			}
-
-			name := chapter.GetTitle()
-			if name == "" {
-				name = fmt.Sprintf("Chapter %s", chapter.GetChapterNum())
-			} else {
-				name = fmt.Sprintf("Chapter %s - %s", chapter.GetChapterNum(), name)
-			}
-
-			var volume string
-			if chapter.Attributes.Volume != nil {
-				volume = fmt.Sprintf("Vol.%s", *chapter.Attributes.Volume)
-			}
-			chapters = append(chapters, &source.Chapter{
-				Name:  name,
-				Index: chapIndex,
-				ID:    chapter.ID,
-				URL:   fmt.Sprintf("https://mangadex.org/chapter/%s", chapter.ID),
-				Manga: manga,
-
-				Volume: volume,
-			})
+			candidates = append(candidates, candidateFrom(&chapter, manga, chapIndex))
 			chapIndex++
 		}
 		currOffset += 500
@@ -93,29 +71,67 @@ func (m *Mangadex) ChaptersOf(manga *source.Manga) ([]*source.Chapter, error) {
 		}
 	}
 
+	// Drop blocked groups and duplicates
+	chapters := prefs.filter(candidates)
+
 	// Sort chapters by volume and chapter number
-	slices.SortFunc(chapters, func(a, b *source.Chapter) int {
-		// Compare volumes first
-		if a.Volume != b.Volume {
-			if a.Volume == "" {
-				return 1
-			}
-			if b.Volume == "" {
-				return -1
-			}
-			return strings.Compare(a.Volume, b.Volume)
-		}
-		// If volumes are equal, compare indices
-		if a.Index < b.Index {
-			return -1
-		}
-		if a.Index > b.Index {
-			return 1
-		}
-		return 0
-	})
+	source.SortChapters(chapters)
+	reindex(chapters)
 
 	manga.Chapters = chapters
-	_ = m.cache.chapters.Set(manga.URL, chapters)
+	_ = m.cache.chapters.Set(cacheKey, chapters)
 	return chapters, nil
 }
+
+// reindex numbers the chapters in their order starting from 1.
+// Dropped chapters would leave gaps in the indices otherwise, and they are used in the file names
+func reindex(chapters []*source.Chapter) {
+	for i, chapter := range chapters {
+		chapter.Index = uint16(i + 1)
+	}
+}
+
+// candidateFrom converts the chapter from the mangadex api
+func candidateFrom(chapter *mangodex.Chapter, manga *source.Manga, index uint16) *candidate {
+	name := chapter.GetTitle()
+	if name == "" {
+		name = fmt.Sprintf("Chapter %s", chapter.GetChapterNum())
+	} else {
+		name = fmt.Sprintf("Chapter %s - %s", chapter.GetChapterNum(), name)
+	}
+
+	var volume string
+	if chapter.Attributes.Volume != nil {
+		volume = fmt.Sprintf("Vol.%s", *chapter.Attributes.Volume)
+	}
+
+	groups := groupsOf(chapter)
+
+	c := &source.Chapter{
+		Name:  name,
+		Index: index,
+		ID:    chapter.ID,
+		URL:   fmt.Sprintf("https://mangadex.org/chapter/%s", chapter.ID),
+		Manga: manga,
+		Group: groupName(groups),
+
+		Volume: volume,
+	}
+
+	if number, err := strconv.ParseFloat(chapter.GetChapterNum(), 64); err == nil {
+		c.Number = &number
+	}
+
+	if chapter.Attributes.Volume != nil {
+		if number, err := strconv.ParseFloat(*chapter.Attributes.Volume, 64); err == nil {
+			c.VolumeNumber = &number
+		}
+	}
+
+	return &candidate{
+		chapter:  c,
+		number:   chapter.Attributes.Chapter,
+		language: chapter.Attributes.TranslatedLanguage,
+		groups:   groups,
+	}
+}
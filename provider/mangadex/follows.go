@@ -0,0 +1,200 @@
+package mangadex
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/darylhjd/mangodex"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/util"
+	"github.com/spf13/viper"
+)
+
+// feedDateLayout is the date format of the publishAtSince parameter, without timezone
+const feedDateLayout = "2006-01-02T15:04:05"
+
+// Follows returns the manga followed by the logged-in user
+func (m *Mangadex) Follows() ([]*source.Manga, error) {
+	params := url.Values{}
+	params.Set("limit", strconv.Itoa(100))
+	params.Add("includes[]", "cover_art")
+
+	var (
+		mangas     []*source.Manga
+		currOffset = 0
+	)
+
+	for {
+		params.Set("offset", strconv.Itoa(currOffset))
+
+		var list mangodex.MangaList
+		if err := get("/user/follows/manga", params, &list); err != nil {
+			return nil, err
+		}
+
+		for _, manga := range list.Data {
+			converted := mangaFrom(&manga)
+			converted.Index = uint16(len(mangas))
+			converted.Source = m
+			mangas = append(mangas, converted)
+		}
+
+		currOffset += len(list.Data)
+		if len(list.Data) == 0 || currOffset >= list.Total {
+			break
+		}
+	}
+
+	return mangas, nil
+}
+
+// FollowsFeed returns the followed manga that have chapters published since the given time.
+// Only the new chapters are set for each manga, same preferences as for the chapters list apply
+func (m *Mangadex) FollowsFeed(since time.Time) ([]*source.Manga, error) {
+	prefs := preferencesFromConfig()
+
+	params := url.Values{}
+	params.Set("limit", strconv.Itoa(500))
+	params.Set("publishAtSince", since.UTC().Format(feedDateLayout))
+	params.Set("order[publishAt]", "asc")
+	params.Add("includes[]", mangodex.ScanlationGroupRel)
+	params.Add("includes[]", mangodex.MangaRel)
+
+	ratings := []string{mangodex.Safe, mangodex.Suggestive}
+	for _, rating := range ratings {
+		params.Add("contentRating[]", rating)
+	}
+
+	if viper.GetBool(key.MangadexNSFW) {
+		params.Add("contentRating[]", mangodex.Porn)
+		params.Add("contentRating[]", mangodex.Erotica)
+	}
+
+	for _, language := range prefs.languages {
+		params.Add("translatedLanguage[]", language)
+	}
+
+	var (
+		mangas     []*source.Manga
+		candidates = make(map[string][]*candidate)
+		currOffset = 0
+	)
+
+	for {
+		params.Set("offset", strconv.Itoa(currOffset))
+
+		var list mangodex.ChapterList
+		if err := get("/user/follows/manga/feed", params, &list); err != nil {
+			return nil, err
+		}
+
+		for _, chapter := range list.Data {
+			if chapter.Attributes.ExternalURL != nil && !viper.GetBool(key.MangadexShowUnavailableChapters) {
+				continue
+			}
+
+			manga := mangaOf(&chapter)
+			if manga == nil {
+				continue
+			}
+
+			if _, ok := candidates[manga.ID]; !ok {
+				manga.Source = m
+				mangas = append(mangas, manga)
+			} else {
+				manga = candidates[manga.ID][0].chapter.Manga
+			}
+
+			index := uint16(len(candidates[manga.ID]) + 1)
+			candidates[manga.ID] = append(candidates[manga.ID], candidateFrom(&chapter, manga, index))
+		}
+
+		currOffset += len(list.Data)
+		if len(list.Data) == 0 || currOffset >= list.Total {
+			break
+		}
+	}
+
+	// manga with chapters of the blocked groups only are skipped
+	var updated []*source.Manga
+	for _, manga := range mangas {
+		chapters := prefs.filter(candidates[manga.ID])
+		if len(chapters) == 0 {
+			continue
+		}
+
+		source.SortChapters(chapters)
+		reindex(chapters)
+		manga.Chapters = chapters
+		manga.Index = uint16(len(updated))
+		updated = append(updated, manga)
+	}
+
+	return updated, nil
+}
+
+// mangaOf returns the manga of the chapter from its relationships
+func mangaOf(chapter *mangodex.Chapter) *source.Manga {
+	for _, relationship := range chapter.Relationships {
+		if relationship.Type != mangodex.MangaRel {
+			continue
+		}
+
+		manga := mangodex.Manga{ID: relationship.ID, Type: relationship.Type}
+		if attributes, ok := relationship.Attributes.(*mangodex.MangaAttributes); ok {
+			manga.Attributes = *attributes
+		}
+
+		return mangaFrom(&manga)
+	}
+
+	return nil
+}
+
+// IsFollowing checks if the logged-in user follows the manga
+func IsFollowing(mangaID string) (bool, error) {
+	res, err := request(http.MethodGet, "/user/follows/manga/"+mangaID, nil)
+	if err != nil {
+		return false, err
+	}
+
+	defer util.Ignore(res.Body.Close)
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("mangadex: failed to check follow of %s: %s", mangaID, res.Status)
+	}
+}
+
+// Follow follows the manga, so that its new chapters appear in the feed
+func Follow(mangaID string) error {
+	return setFollow(http.MethodPost, mangaID)
+}
+
+// Unfollow stops following the manga
+func Unfollow(mangaID string) error {
+	return setFollow(http.MethodDelete, mangaID)
+}
+
+func setFollow(method, mangaID string) error {
+	res, err := request(method, "/manga/"+mangaID+"/follow", nil)
+	if err != nil {
+		return err
+	}
+
+	defer util.Ignore(res.Body.Close)
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("mangadex: failed to change follow of %s: %s", mangaID, res.Status)
+	}
+
+	return nil
+}
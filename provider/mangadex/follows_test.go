@@ -0,0 +1,153 @@
+package mangadex
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/where"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+)
+
+const fakeFeed = `{"result": "ok", "total": 3, "data": [
+	{"id": "c1", "type": "chapter", "attributes": {"chapter": "10", "translatedLanguage": "en"}, "relationships": [
+		{"id": "m1", "type": "manga", "attributes": {"title": {"en": "First"}}},
+		{"id": "g1", "type": "scanlation_group", "attributes": {"name": "Alpha"}}
+	]},
+	{"id": "c2", "type": "chapter", "attributes": {"chapter": "5", "translatedLanguage": "en"}, "relationships": [
+		{"id": "m2", "type": "manga", "attributes": {"title": {"en": "Second"}}},
+		{"id": "g2", "type": "scanlation_group", "attributes": {"name": "Blocked"}}
+	]},
+	{"id": "c3", "type": "chapter", "attributes": {"chapter": "9", "translatedLanguage": "en"}, "relationships": [
+		{"id": "m1", "type": "manga", "attributes": {"title": {"en": "First"}}},
+		{"id": "g1", "type": "scanlation_group", "attributes": {"name": "Alpha"}}
+	]}
+]}`
+
+func TestFollowsFeed(t *testing.T) {
+	defer viper.Reset()
+
+	Convey("Given a logged-in mangadex user", t, func() {
+		var (
+			refreshes int
+			since     string
+		)
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+			if r.FormValue("grant_type") != "refresh_token" || r.FormValue("client_id") != "client" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = fmt.Fprint(w, `{"error": "invalid_grant", "error_description": "invalid grant"}`)
+				return
+			}
+
+			refreshes++
+			_, _ = fmt.Fprintf(w, `{"access_token": "access", "refresh_token": "rotated%d", "expires_in": 900}`, refreshes)
+		})
+		mux.HandleFunc("/user/follows/manga/feed", func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer access" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			since = r.URL.Query().Get("publishAtSince")
+			_, _ = fmt.Fprint(w, fakeFeed)
+		})
+
+		followed := make(map[string]bool)
+		mux.HandleFunc("/manga/m1/follow", func(w http.ResponseWriter, r *http.Request) {
+			followed["m1"] = r.Method == http.MethodPost
+			_, _ = fmt.Fprint(w, `{"result": "ok"}`)
+		})
+		mux.HandleFunc("/user/follows/manga/", func(w http.ResponseWriter, r *http.Request) {
+			if !followed[strings.TrimPrefix(r.URL.Path, "/user/follows/manga/")] {
+				w.WriteHeader(http.StatusNotFound)
+			}
+
+			_, _ = fmt.Fprint(w, `{"result": "ok"}`)
+		})
+
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		defaultTokenURL, defaultAPIURL := authTokenURL, apiURL
+		authTokenURL, apiURL = server.URL+"/token", server.URL
+		defer func() {
+			authTokenURL, apiURL = defaultTokenURL, defaultAPIURL
+		}()
+
+		filesystem.SetMemMapFs()
+		viper.SetFs(filesystem.Api())
+		viper.SetConfigFile("/mangal.toml")
+
+		Logout()
+		viper.Set(key.MangadexClientID, "client")
+		viper.Set(key.MangadexClientSecret, "secret")
+		viper.Set(key.MangadexRefreshToken, "refresh")
+		viper.Set(key.MangadexLanguage, []string{"en"})
+		viper.Set(key.MangadexBlockedGroups, []string{"blocked"})
+
+		Convey("When getting the followed feed", func() {
+			mangas, err := New().FollowsFeed(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+
+			Convey("Then new chapters should be grouped by manga", func() {
+				So(err, ShouldBeNil)
+				So(mangas, ShouldHaveLength, 1)
+				So(mangas[0].Name, ShouldEqual, "First")
+				So(mangas[0].Chapters, ShouldHaveLength, 2)
+				So(mangas[0].Chapters[0].ID, ShouldEqual, "c3")
+				So(mangas[0].Chapters[0].Manga, ShouldEqual, mangas[0])
+				So(since, ShouldEqual, "2024-01-02T03:04:05")
+			})
+
+			Convey("Then the rotated refresh token should be kept", func() {
+				So(viper.GetString(key.MangadexRefreshToken), ShouldEqual, "rotated1")
+			})
+
+			Convey("Then the rotated refresh token should be saved to the session file only", func() {
+				saved, err := filesystem.Api().ReadFile(where.MangadexSession())
+				So(err, ShouldBeNil)
+				So(string(saved), ShouldContainSubstring, `"refresh_token":"rotated1"`)
+
+				exists, err := filesystem.Api().Exists("/mangal.toml")
+				So(err, ShouldBeNil)
+				So(exists, ShouldBeFalse)
+			})
+		})
+
+		Convey("When following the manga", func() {
+			So(Follow("m1"), ShouldBeNil)
+
+			Convey("Then it should be followed", func() {
+				following, err := IsFollowing("m1")
+				So(err, ShouldBeNil)
+				So(following, ShouldBeTrue)
+			})
+
+			Convey("And unfollowing it", func() {
+				So(Unfollow("m1"), ShouldBeNil)
+
+				Convey("Then it should not be followed", func() {
+					following, err := IsFollowing("m1")
+					So(err, ShouldBeNil)
+					So(following, ShouldBeFalse)
+				})
+			})
+		})
+
+		Convey("When logged out", func() {
+			Logout()
+			_, err := New().FollowsFeed(time.Now())
+
+			Convey("Then error should be returned", func() {
+				So(err, ShouldEqual, ErrNotLoggedIn)
+			})
+		})
+	})
+}
@@ -0,0 +1,59 @@
+package mangadex
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/darylhjd/mangodex"
+	"github.com/metafates/mangal/source"
+)
+
+var mangaIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// GetManga returns the manga by its ID or URL, e.g. https://mangadex.org/title/<id>/<slug>
+func (m *Mangadex) GetManga(id string) (*source.Manga, error) {
+	id, err := mangaID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Add("ids[]", id)
+
+	// the manga is requested explicitly, so it is not filtered by the content rating
+	for _, rating := range []string{mangodex.Safe, mangodex.Suggestive, mangodex.Erotica, mangodex.Porn} {
+		params.Add("contentRating[]", rating)
+	}
+
+	mangaList, err := m.client.Manga.GetMangaList(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(mangaList.Data) == 0 {
+		return nil, fmt.Errorf("manga %s not found", id)
+	}
+
+	manga := mangaFrom(&mangaList.Data[0])
+	manga.Source = m
+	return manga, nil
+}
+
+// mangaID extracts the manga ID from the URL of the manga page
+func mangaID(id string) (string, error) {
+	if mangaIDPattern.MatchString(id) {
+		return id, nil
+	}
+
+	parsed, err := url.Parse(id)
+	if err == nil {
+		segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+		if len(segments) >= 2 && (segments[0] == "title" || segments[0] == "manga") && mangaIDPattern.MatchString(segments[1]) {
+			return segments[1], nil
+		}
+	}
+
+	return "", fmt.Errorf("%q is not a MangaDex manga URL or ID", id)
+}
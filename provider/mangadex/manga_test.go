@@ -0,0 +1,36 @@
+package mangadex
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMangaID(t *testing.T) {
+	const id = "a1c7c817-4e59-43b7-9365-09675a149a6f"
+
+	Convey("Given the manga URLs and IDs", t, func() {
+		for _, input := range []string{
+			id,
+			"https://mangadex.org/title/" + id,
+			"https://mangadex.org/title/" + id + "/one-piece?tab=chapters",
+		} {
+			Convey("When "+input+" is parsed", func() {
+				parsed, err := mangaID(input)
+
+				Convey("Then the ID should be extracted", func() {
+					So(err, ShouldBeNil)
+					So(parsed, ShouldEqual, id)
+				})
+			})
+		}
+	})
+
+	Convey("Given the URL of another page", t, func() {
+		_, err := mangaID("https://mangadex.org/chapter/" + id)
+
+		Convey("Then error should be returned", func() {
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
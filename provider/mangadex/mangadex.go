@@ -2,6 +2,7 @@ package mangadex
 
 import (
 	"github.com/darylhjd/mangodex"
+	"github.com/metafates/mangal/cache"
 	"github.com/metafates/mangal/source"
 )
 
@@ -13,8 +14,8 @@ const (
 type Mangadex struct {
 	client *mangodex.DexClient
 	cache  struct {
-		mangas   *cacher[[]*source.Manga]
-		chapters *cacher[[]*source.Chapter]
+		mangas   *cache.Cache[[]*source.Manga]
+		chapters *cache.Cache[[]*source.Chapter]
 	}
 }
 
@@ -31,8 +32,8 @@ func New() *Mangadex {
 		client: mangodex.NewDexClient(),
 	}
 
-	dex.cache.mangas = newCacher[[]*source.Manga](ID + "_mangas")
-	dex.cache.chapters = newCacher[[]*source.Chapter](ID + "_chapters")
+	dex.cache.mangas = cache.New[[]*source.Manga](Name, cache.Search)
+	dex.cache.chapters = cache.New[[]*source.Chapter](Name, cache.Chapters)
 
 	return dex
 }
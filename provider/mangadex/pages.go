@@ -6,31 +6,30 @@ import (
 	"errors"
 	"path/filepath"
 
+	"github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/source"
+	"github.com/spf13/viper"
 )
 
 func (m *Mangadex) PagesOf(chapter *source.Chapter) ([]*source.Page, error) {
-	downloader, err := m.client.AtHome.NewMDHomeClient(chapter.ID, "data", false)
+	downloader, err := newAtHome(chapter.ID, viper.GetBool(key.MangadexDataSaver))
 	if err != nil {
 		return nil, err
 	}
 
-	if len(downloader.Pages) == 0 {
+	if len(downloader.pages) == 0 {
 		return nil, errors.New("there were no pages for this chapter")
 	}
 
-	var pages = make([]*source.Page, len(downloader.Pages))
+	var pages = make([]*source.Page, len(downloader.pages))
 
-	for i, name := range downloader.Pages {
-		image, err := downloader.GetChapterPage(name)
+	// names of the pages are the same for every server of the chapter
+	for i, name := range downloader.pages {
+		image, err := downloader.page(name)
 		if err != nil {
 			return nil, err
 		}
 
-		if len(image) == 0 {
-			return nil, errors.New("image is empty")
-		}
-
 		page := source.Page{
 			Index:     uint16(i),
 			Chapter:   chapter,
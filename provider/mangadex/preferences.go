@@ -0,0 +1,179 @@
+package mangadex
+
+import (
+	"strings"
+
+	"github.com/darylhjd/mangodex"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	"github.com/spf13/viper"
+)
+
+// preferences of the chapters feed
+type preferences struct {
+	// languages in the order of priority, any language if empty
+	languages []string
+	// preferred scanlation groups in the order of priority, names or ids
+	preferred []string
+	// blocked scanlation groups, names or ids
+	blocked []string
+	// deduplicate keeps a single chapter for each chapter number
+	deduplicate bool
+}
+
+func preferencesFromConfig() preferences {
+	normalize := func(values []string) []string {
+		return lo.Map(values, func(value string, _ int) string {
+			return strings.ToLower(strings.TrimSpace(value))
+		})
+	}
+
+	languages := normalize(viper.GetStringSlice(key.MangadexLanguage))
+	if lo.Contains(languages, "any") {
+		languages = nil
+	}
+
+	return preferences{
+		languages:   languages,
+		preferred:   normalize(viper.GetStringSlice(key.MangadexPreferredGroups)),
+		blocked:     normalize(viper.GetStringSlice(key.MangadexBlockedGroups)),
+		deduplicate: viper.GetBool(key.MangadexDeduplicate),
+	}
+}
+
+// titleLanguage is the language of the manga titles, the first preferred one
+func titleLanguage() string {
+	if languages := preferencesFromConfig().languages; len(languages) > 0 {
+		return languages[0]
+	}
+
+	return "en"
+}
+
+// key identifies the preferences, so that chapters cached with other preferences are not reused
+func (p preferences) key() string {
+	return strings.Join([]string{
+		strings.Join(p.languages, ","),
+		strings.Join(p.preferred, ","),
+		strings.Join(p.blocked, ","),
+		lo.Ternary(p.deduplicate, "dedup", ""),
+	}, "|")
+}
+
+// group is the scanlation group of the chapter
+type group struct {
+	id, name string
+}
+
+func (g group) is(nameOrID string) bool {
+	return g.id == nameOrID || strings.ToLower(g.name) == nameOrID
+}
+
+// candidate is a chapter from the feed with the details needed to choose between duplicates
+type candidate struct {
+	chapter  *source.Chapter
+	number   *string
+	language string
+	groups   []group
+}
+
+// groupsOf returns the scanlation groups of the chapter
+func groupsOf(chapter *mangodex.Chapter) []group {
+	var groups []group
+	for _, relationship := range chapter.Relationships {
+		if relationship.Type != mangodex.ScanlationGroupRel {
+			continue
+		}
+
+		g := group{id: strings.ToLower(relationship.ID)}
+		if attributes, ok := relationship.Attributes.(*mangodex.ScanlationGroupAttributes); ok {
+			g.name = attributes.Name
+		}
+
+		groups = append(groups, g)
+	}
+
+	return groups
+}
+
+// groupName joins the names of the groups
+func groupName(groups []group) string {
+	names := lo.FilterMap(groups, func(g group, _ int) (string, bool) {
+		return g.name, g.name != ""
+	})
+
+	return strings.Join(names, " & ")
+}
+
+// isBlocked checks if any group of the chapter is blocked
+func (p preferences) isBlocked(c *candidate) bool {
+	for _, g := range c.groups {
+		for _, blocked := range p.blocked {
+			if g.is(blocked) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// rank of the chapter, lower is better.
+// Language goes first, then the preferred group
+func (p preferences) rank(c *candidate) (byLanguage, byGroup int) {
+	byLanguage = len(p.languages)
+	if i := lo.IndexOf(p.languages, strings.ToLower(c.language)); i >= 0 {
+		byLanguage = i
+	}
+
+	byGroup = len(p.preferred)
+	for i, preferred := range p.preferred {
+		if lo.ContainsBy(c.groups, func(g group) bool { return g.is(preferred) }) {
+			byGroup = i
+			break
+		}
+	}
+
+	return
+}
+
+// filter removes chapters of the blocked groups and, if enabled,
+// keeps the best ranked chapter for each chapter number.
+// Chapters without number are never deduplicated
+func (p preferences) filter(candidates []*candidate) []*source.Chapter {
+	var (
+		chapters []*source.Chapter
+		// best is the position of the chapter with the number in chapters
+		best   = make(map[string]int)
+		ranked = make(map[string]*candidate)
+	)
+
+	for _, c := range candidates {
+		if p.isBlocked(c) {
+			continue
+		}
+
+		if !p.deduplicate || c.number == nil {
+			chapters = append(chapters, c.chapter)
+			continue
+		}
+
+		current, ok := ranked[*c.number]
+		if !ok {
+			best[*c.number] = len(chapters)
+			ranked[*c.number] = c
+			chapters = append(chapters, c.chapter)
+			continue
+		}
+
+		language, group := p.rank(c)
+		currentLanguage, currentGroup := p.rank(current)
+		if language < currentLanguage || (language == currentLanguage && group < currentGroup) {
+			chapters[best[*c.number]] = c.chapter
+			ranked[*c.number] = c
+		}
+	}
+
+	return chapters
+}
@@ -0,0 +1,93 @@
+package mangadex
+
+import (
+	"github.com/metafates/mangal/source"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func newCandidate(name, number, language string, groups ...group) *candidate {
+	c := &candidate{
+		chapter:  &source.Chapter{Name: name, Group: groupName(groups)},
+		language: language,
+		groups:   groups,
+	}
+
+	if number != "" {
+		c.number = &number
+	}
+
+	return c
+}
+
+func TestPreferences(t *testing.T) {
+	var (
+		alpha = group{id: "a1", name: "Alpha Scans"}
+		beta  = group{id: "b2", name: "Beta"}
+		gamma = group{id: "c3", name: "Gamma"}
+	)
+
+	Convey("Given chapters translated by several groups", t, func() {
+		candidates := []*candidate{
+			newCandidate("1 alpha", "1", "en", alpha),
+			newCandidate("1 beta", "1", "en", beta),
+			newCandidate("1 es", "1", "es", beta),
+			newCandidate("2 es", "2", "es", alpha),
+			newCandidate("2 gamma", "2", "en", gamma),
+			newCandidate("oneshot", "", "en", alpha),
+			newCandidate("extra", "", "en", beta),
+		}
+
+		Convey("When deduplicating with preferences", func() {
+			prefs := preferences{
+				languages:   []string{"en", "es"},
+				preferred:   []string{"beta", "a1"},
+				deduplicate: true,
+			}
+
+			chapters := prefs.filter(candidates)
+
+			Convey("Then the best ranked chapter of each number should be kept", func() {
+				names := make([]string, len(chapters))
+				for i, c := range chapters {
+					names[i] = c.Name
+				}
+
+				So(names, ShouldResemble, []string{"1 beta", "2 gamma", "oneshot", "extra"})
+			})
+
+			Convey("Then the kept chapters should be indexed without gaps", func() {
+				reindex(chapters)
+				for i, c := range chapters {
+					So(c.Index, ShouldEqual, i+1)
+				}
+			})
+		})
+
+		Convey("When blocking a group", func() {
+			prefs := preferences{
+				languages: []string{"en", "es"},
+				blocked:   []string{"alpha scans"},
+			}
+
+			chapters := prefs.filter(candidates)
+
+			Convey("Then its chapters should be removed", func() {
+				So(chapters, ShouldHaveLength, 4)
+				for _, c := range chapters {
+					So(c.Group, ShouldNotEqual, "Alpha Scans")
+				}
+			})
+		})
+	})
+
+	Convey("Given a chapter translated by several groups together", t, func() {
+		Convey("When getting its group name", func() {
+			name := groupName([]group{alpha, {id: "x"}, beta})
+
+			Convey("Then names should be joined", func() {
+				So(name, ShouldEqual, "Alpha Scans & Beta")
+			})
+		})
+	})
+}
@@ -0,0 +1,65 @@
+package mangadex
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/where"
+	"github.com/spf13/viper"
+)
+
+// credentials of the session saved by the login.
+// They are kept apart from the config, so that saving a rotated
+// refresh token never rewrites the config with the flags of the current run
+type credentials struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+var loadCredentialsOnce sync.Once
+
+// loadCredentials applies the saved credentials once.
+// The saved refresh token wins, even an empty one after the logout,
+// since the one in the config may be revoked by the rotation.
+// Client credentials are used only if they are not configured
+func loadCredentials() {
+	loadCredentialsOnce.Do(func() {
+		data, err := filesystem.Api().ReadFile(where.MangadexSession())
+		if err != nil {
+			return
+		}
+
+		var saved credentials
+		if json.Unmarshal(data, &saved) != nil {
+			return
+		}
+
+		viper.Set(key.MangadexRefreshToken, saved.RefreshToken)
+
+		for k, value := range map[string]string{
+			key.MangadexClientID:     saved.ClientID,
+			key.MangadexClientSecret: saved.ClientSecret,
+		} {
+			if value != "" && viper.GetString(k) == "" {
+				viper.Set(k, value)
+			}
+		}
+	})
+}
+
+// saveCredentials writes the current credentials to the session file
+func saveCredentials() error {
+	data, err := json.Marshal(credentials{
+		ClientID:     viper.GetString(key.MangadexClientID),
+		ClientSecret: viper.GetString(key.MangadexClientSecret),
+		RefreshToken: viper.GetString(key.MangadexRefreshToken),
+	})
+	if err != nil {
+		return err
+	}
+
+	return filesystem.Api().WriteFile(where.MangadexSession(), data, 0600)
+}
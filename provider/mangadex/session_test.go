@@ -0,0 +1,53 @@
+package mangadex
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/where"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+)
+
+func TestLoadCredentials(t *testing.T) {
+	defer viper.Reset()
+
+	Convey("Given a saved session and a stale refresh token in the config", t, func() {
+		filesystem.SetMemMapFs()
+		loadCredentialsOnce = sync.Once{}
+
+		viper.Set(key.MangadexClientID, "configured")
+		viper.Set(key.MangadexClientSecret, "")
+		viper.Set(key.MangadexRefreshToken, "revoked")
+
+		err := filesystem.Api().WriteFile(where.MangadexSession(), []byte(
+			`{"client_id": "saved", "client_secret": "secret", "refresh_token": "rotated"}`,
+		), 0600)
+		So(err, ShouldBeNil)
+
+		Convey("When the credentials are loaded", func() {
+			So(LoggedIn(), ShouldBeTrue)
+
+			Convey("Then the saved refresh token should be used", func() {
+				So(viper.GetString(key.MangadexRefreshToken), ShouldEqual, "rotated")
+			})
+
+			Convey("And the configured client credentials should be kept", func() {
+				So(viper.GetString(key.MangadexClientID), ShouldEqual, "configured")
+				So(viper.GetString(key.MangadexClientSecret), ShouldEqual, "secret")
+			})
+		})
+
+		Convey("When logged out", func() {
+			So(Logout(), ShouldBeNil)
+			loadCredentialsOnce = sync.Once{}
+			viper.Set(key.MangadexRefreshToken, "revoked")
+
+			Convey("Then the session should stay logged out on the next run", func() {
+				So(LoggedIn(), ShouldBeFalse)
+			})
+		})
+	})
+}
@@ -17,6 +17,7 @@ var Config = &generic.Configuration{
 	Parallelism:     50,
 	ReverseChapters: true,
 	BaseURL:         "https://manganato.com/",
+	Hosts:           []string{"manganato.com", "chapmanganato.to", "readmanganato.com"},
 	GenerateSearchURL: func(query string) string {
 		query = strings.ToLower(query)
 
@@ -42,6 +43,9 @@ var Config = &generic.Configuration{
 
 		return fmt.Sprintf("https://manganato.com/search/story/%s", query)
 	},
+	MangaName: func(selection *goquery.Selection) string {
+		return selection.Find(".story-info-right h1").Text()
+	},
 	MangaExtractor: &generic.Extractor{
 		Selector: "div.search-story-item",
 		Name: func(selection *goquery.Selection) string {
@@ -22,6 +22,9 @@ var Config = &generic.Configuration{
 		template := "https://mangapill.com/search?q=%s&type=&status="
 		return fmt.Sprintf(template, url.QueryEscape(query))
 	},
+	MangaName: func(selection *goquery.Selection) string {
+		return selection.Find("h1").First().Text()
+	},
 	MangaExtractor: &generic.Extractor{
 		Selector: "body > div.container.py-3 > div.my-3.grid.justify-end.gap-3.grid-cols-2.md\\:grid-cols-3.lg\\:grid-cols-5 > div",
 		Name: func(selection *goquery.Selection) string {
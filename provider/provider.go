@@ -1,14 +1,22 @@
 package provider
 
 import (
+	"fmt"
 	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/log"
 	"github.com/metafates/mangal/provider/custom"
+	"github.com/metafates/mangal/provider/declarative"
+	"github.com/metafates/mangal/provider/generic"
 	"github.com/metafates/mangal/source"
 	"github.com/metafates/mangal/util"
 	"github.com/metafates/mangal/where"
 	"github.com/samber/lo"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 )
 
 type Provider struct {
@@ -16,6 +24,10 @@ type Provider struct {
 	Name         string
 	UsesHeadless bool
 	IsCustom     bool
+	// Path to the file of the custom provider
+	Path string
+	// Hosts of the manga pages handled by the provider, e.g. "example.com" or "*.example.com"
+	Hosts        []string
 	CreateSource func() (source.Source, error)
 }
 
@@ -27,6 +39,15 @@ func Builtins() []*Provider {
 	return builtinProviders
 }
 
+// customs are the loaded custom providers, reloaded when the sources directory changes
+var customs struct {
+	sync.Mutex
+	signature string
+	providers []*Provider
+}
+
+// Customs returns providers of the custom sources.
+// Sources are read once and then only when the files in the sources directory change
 func Customs() []*Provider {
 	files, err := filesystem.Api().ReadDir(where.Sources())
 
@@ -34,15 +55,47 @@ func Customs() []*Provider {
 		return make([]*Provider, 0)
 	}
 
-	paths := lo.FilterMap(files, func(f os.FileInfo, _ int) (string, bool) {
-		if filepath.Ext(f.Name()) == CustomProviderExtension {
-			return filepath.Join(where.Sources(), f.Name()), true
-		}
-		return "", false
+	files = lo.Filter(files, func(f os.FileInfo, _ int) bool {
+		return IsCustomSource(f.Name())
 	})
-	providers := make([]*Provider, len(paths))
 
-	for i, path := range paths {
+	signature := signatureOf(files)
+
+	customs.Lock()
+	defer customs.Unlock()
+
+	if customs.providers == nil || customs.signature != signature {
+		customs.providers = loadCustoms(files)
+		customs.signature = signature
+	}
+
+	return slices.Clone(customs.providers)
+}
+
+// signatureOf identifies the state of the custom source files, it changes when they are added, removed or modified
+func signatureOf(files []os.FileInfo) string {
+	var b strings.Builder
+	b.WriteString(where.Sources())
+
+	for _, f := range files {
+		_, _ = fmt.Fprintf(&b, "\n%s %d %d", f.Name(), f.Size(), f.ModTime().UnixNano())
+	}
+
+	return b.String()
+}
+
+// loadCustoms creates providers of the custom source files
+func loadCustoms(files []os.FileInfo) []*Provider {
+	providers := make([]*Provider, len(files))
+
+	for i, f := range files {
+		path := filepath.Join(where.Sources(), f.Name())
+
+		if declarative.IsDefinition(path) {
+			providers[i] = definitionProvider(path)
+			continue
+		}
+
 		// Check if source contains line `require("headless")`
 		// if so, set UsesHeadless to true.
 		// This approach is not ideal, but it's the only way to do it without
@@ -54,13 +107,19 @@ func Customs() []*Provider {
 			[]byte("require'headless'"),
 		})
 
+		hosts, err := custom.ReadHosts(path)
+		if err != nil {
+			log.Warn(err)
+		}
+
 		name := util.FileStem(path)
-		path := path
 		providers[i] = &Provider{
 			ID:           custom.IDfromName(name),
 			UsesHeadless: usesHeadless,
 			IsCustom:     true,
 			Name:         name,
+			Path:         path,
+			Hosts:        hosts,
 			CreateSource: func() (source.Source, error) {
 				return custom.LoadSource(path, true)
 			},
@@ -70,6 +129,36 @@ func Customs() []*Provider {
 	return providers
 }
 
+// IsCustomSource checks if the file is a custom source, either Lua script or declarative definition
+func IsCustomSource(path string) bool {
+	return filepath.Ext(path) == CustomProviderExtension || declarative.IsDefinition(path)
+}
+
+// definitionProvider creates a provider of the declarative scraper definition
+func definitionProvider(path string) *Provider {
+	var hosts []string
+	if conf, err := declarative.LoadConfiguration(path); err == nil {
+		hosts = hostsOf(conf)
+	}
+
+	name := util.FileStem(path)
+	return &Provider{
+		ID:       custom.IDfromName(name),
+		Name:     name,
+		IsCustom: true,
+		Path:     path,
+		Hosts:    hosts,
+		CreateSource: func() (source.Source, error) {
+			conf, err := declarative.LoadConfiguration(path)
+			if err != nil {
+				return nil, err
+			}
+
+			return generic.New(conf), nil
+		},
+	}
+}
+
 func Get(name string) (*Provider, bool) {
 	for _, provider := range Builtins() {
 		if provider.Name == name {
@@ -85,3 +174,38 @@ func Get(name string) (*Provider, bool) {
 
 	return nil, false
 }
+
+// hostsOf returns the hosts handled by the generic scraper, host of the base URL is used by default
+func hostsOf(conf *generic.Configuration) []string {
+	if len(conf.Hosts) > 0 {
+		return conf.Hosts
+	}
+
+	parsed, err := url.Parse(conf.BaseURL)
+	if err != nil || parsed.Hostname() == "" {
+		return nil
+	}
+
+	return []string{parsed.Hostname()}
+}
+
+// ForURL finds the provider that handles the host of the manga URL
+func ForURL(address string) (*Provider, error) {
+	parsed, err := url.Parse(address)
+	if err != nil {
+		return nil, err
+	}
+
+	host := parsed.Hostname()
+	if host == "" {
+		return nil, fmt.Errorf("%q is not an absolute URL", address)
+	}
+
+	for _, provider := range lo.Flatten([][]*Provider{Builtins(), Customs()}) {
+		if custom.MatchHost(provider.Hosts, host) || custom.MatchHost(provider.Hosts, strings.TrimPrefix(host, "www.")) {
+			return provider, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no source handles %s", host)
+}
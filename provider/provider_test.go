@@ -1,8 +1,12 @@
 package provider
 
 import (
+	"github.com/metafates/mangal/provider/mangadex"
 	"github.com/metafates/mangal/provider/manganelo"
+	"github.com/metafates/mangal/where"
 	. "github.com/smartystreets/goconvey/convey"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -21,3 +25,61 @@ func TestGet(t *testing.T) {
 		})
 	})
 }
+
+func TestForURL(t *testing.T) {
+	Convey("When trying to find a provider by manga URL", t, func() {
+		p, err := ForURL("https://www.mangadex.org/title/a1c7c817-4e59-43b7-9365-09675a149a6f")
+		Convey("Then the provider that handles the host should be found", func() {
+			So(err, ShouldBeNil)
+			So(p.ID, ShouldEqual, mangadex.ID)
+		})
+	})
+
+	Convey("When trying to find a provider by URL of unknown host", t, func() {
+		_, err := ForURL("https://example.com/manga/1")
+		Convey("Then error should be returned", func() {
+			So(err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("When trying to find a provider by relative URL", t, func() {
+		_, err := ForURL("manga/1")
+		Convey("Then error should be returned", func() {
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestCustoms(t *testing.T) {
+	Convey("Given a custom source in the sources directory", t, func() {
+		t.Setenv(where.EnvConfigPath, t.TempDir())
+		write := func(name string) {
+			path := filepath.Join(where.Sources(), name)
+			So(os.WriteFile(path, []byte("-- @hosts example.com\n"), os.ModePerm), ShouldBeNil)
+		}
+
+		write("first.lua")
+		first := Customs()
+		So(first, ShouldHaveLength, 1)
+
+		Convey("When the sources are listed again", func() {
+			again := Customs()
+
+			Convey("Then the loaded providers should be reused", func() {
+				So(again, ShouldHaveLength, 1)
+				So(again[0], ShouldEqual, first[0])
+			})
+		})
+
+		Convey("When another source is added", func() {
+			write("second.lua")
+			providers := Customs()
+
+			Convey("Then the sources should be loaded again", func() {
+				So(providers, ShouldHaveLength, 2)
+				So(providers[1].Name, ShouldEqual, "second")
+				So(providers[1].Hosts, ShouldResemble, []string{"example.com"})
+			})
+		})
+	})
+}
@@ -0,0 +1,81 @@
+package search
+
+import (
+	levenshtein "github.com/ka-weihe/fast-levenshtein"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/util"
+	"github.com/samber/lo"
+	"sort"
+)
+
+const (
+	// minSimilarity is the name similarity required to consider titles the same
+	// when there is nothing else to compare them by
+	minSimilarity = 0.85
+
+	// maxAnilistCandidates limits how many candidates are bound with Anilist
+	// to compare their ids, since each bind is a request
+	maxAnilistCandidates = 3
+)
+
+// similarity of the normalized names from 0 to 1
+func similarity(a, b string) float64 {
+	a, b = normalize(a), normalize(b)
+
+	longest := util.Max(len(a), len(b))
+	if longest == 0 {
+		return 0
+	}
+
+	return 1 - float64(levenshtein.Distance(a, b))/float64(longest)
+}
+
+// Closest returns the manga among the candidates that is the same title as the given one.
+// Titles are matched by names, synonyms and Anilist ids first.
+// If the manga is bound with Anilist, the most similar candidates are bound too to compare the ids.
+// Otherwise, the most similar name is used if it is close enough.
+func Closest(manga *source.Manga, candidates []*source.Manga) (*source.Manga, bool) {
+	if len(candidates) == 0 {
+		return nil, false
+	}
+
+	ids := lo.SliceToMap(identities(manga), func(id string) (string, struct{}) {
+		return id, struct{}{}
+	})
+
+	for _, candidate := range candidates {
+		for _, id := range identities(candidate) {
+			if _, ok := ids[id]; ok {
+				return candidate, true
+			}
+		}
+	}
+
+	sorted := make([]*source.Manga, len(candidates))
+	copy(sorted, candidates)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return similarity(manga.Name, sorted[i].Name) > similarity(manga.Name, sorted[j].Name)
+	})
+
+	if al, ok := manga.Anilist.Get(); ok {
+		for _, candidate := range sorted[:util.Min(len(sorted), maxAnilistCandidates)] {
+			if err := candidate.BindWithAnilist(); err != nil {
+				log.Warn(err)
+				continue
+			}
+
+			if candidate.Anilist.MustGet().ID == al.ID {
+				return candidate, true
+			}
+		}
+
+		return nil, false
+	}
+
+	if best := sorted[0]; similarity(manga.Name, best.Name) >= minSimilarity {
+		return best, true
+	}
+
+	return nil, false
+}
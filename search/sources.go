@@ -0,0 +1,390 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/util"
+	"github.com/spf13/viper"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// ErrTimeout is returned when a source did not respond in time
+var ErrTimeout = errors.New("source timed out")
+
+// Timeout returns the configured time to wait for each source
+func Timeout() time.Duration {
+	return time.Duration(viper.GetInt(key.SearchTimeout)) * time.Second
+}
+
+// Result is a search result of a single source
+type Result struct {
+	// Source that was searched
+	Source source.Source
+	// Mangas found. Empty if the search has failed
+	Mangas []*source.Manga
+	// Err is set if the search has failed or timed out
+	Err error
+	// Took is how long the search took
+	Took time.Duration
+}
+
+// withTimeout runs fn and waits for it for the given duration at most.
+// Zero timeout means no timeout.
+// The context passed to fn is canceled after the timeout,
+// fn that doesn't stop on it keeps running in the background
+func withTimeout[T any](timeout time.Duration, fn func(ctx context.Context) (T, error)) (T, error) {
+	if timeout <= 0 {
+		return fn(context.Background())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	type result struct {
+		value T
+		err   error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		value, err := fn(ctx)
+		done <- result{value, err}
+	}()
+
+	select {
+	case r := <-done:
+		// fn has stopped because of the timeout
+		if r.err != nil && ctx.Err() != nil {
+			var zero T
+			return zero, ErrTimeout
+		}
+
+		return r.value, r.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ErrTimeout
+	}
+}
+
+// searchContext searches the source until the context is done,
+// if the source supports it
+func searchContext(ctx context.Context, src source.Source, query string) ([]*source.Manga, error) {
+	if searcher, ok := src.(source.ContextSearcher); ok {
+		return searcher.SearchContext(ctx, query)
+	}
+
+	return src.Search(query)
+}
+
+// Sources searches all the given sources concurrently.
+// Each source has the timeout to respond, zero means no timeout.
+// Sources that implement source.ContextSearcher are stopped after the timeout.
+// Failed sources do not affect others, their errors are reported in the results.
+// Results are in the same order as the sources
+func Sources(sources []source.Source, query string, timeout time.Duration) []*Result {
+	results := make([]*Result, len(sources))
+
+	var wg sync.WaitGroup
+	wg.Add(len(sources))
+	for i, src := range sources {
+		go func(i int, src source.Source) {
+			defer wg.Done()
+
+			start := time.Now()
+			mangas, err := withTimeout(timeout, func(ctx context.Context) ([]*source.Manga, error) {
+				return searchContext(ctx, src, query)
+			})
+
+			if err != nil {
+				log.Errorf("search on %s failed: %s", src.Name(), err)
+				mangas = nil
+			} else {
+				log.Infof("found %s from source %s", util.Quantify(len(mangas), "manga", "mangas"), src.Name())
+			}
+
+			results[i] = &Result{
+				Source: src,
+				Mangas: mangas,
+				Err:    err,
+				Took:   time.Since(start),
+			}
+		}(i, src)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// Mangas returns all the found mangas from the results.
+// Error is returned only if every source has failed
+func Mangas(results []*Result) ([]*source.Manga, error) {
+	var (
+		mangas []*source.Manga
+		errs   []string
+	)
+
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", result.Source.Name(), result.Err))
+			continue
+		}
+
+		mangas = append(mangas, result.Mangas...)
+	}
+
+	if len(results) > 0 && len(errs) == len(results) {
+		return nil, fmt.Errorf("all sources have failed: %s", strings.Join(errs, "; "))
+	}
+
+	return mangas, nil
+}
+
+// Entry is a manga of the group from a single source
+type Entry struct {
+	// Source is the name of the source
+	Source string `json:"source" jsonschema:"description=Name of the source"`
+	// Manga from the source
+	Manga *source.Manga `json:"manga" jsonschema:"description=Manga from the source"`
+	// Chapters is the number of chapters that the source has. Zero if not counted
+	Chapters int `json:"chapters" jsonschema:"description=Number of chapters that the source has. Zero if not counted"`
+	// Error is set if chapters could not be counted
+	Error string `json:"error,omitempty" jsonschema:"description=Set if chapters could not be counted"`
+}
+
+// Group is the same title found in different sources
+type Group struct {
+	// Name of the title
+	Name string `json:"name" jsonschema:"description=Name of the title"`
+	// AnilistID is the id of the Anilist manga the title is bound to, if any
+	AnilistID int `json:"anilist_id,omitempty" jsonschema:"description=ID of the Anilist manga the title is bound to"`
+	// Entries of the title, the most complete first after chapters are counted
+	Entries []*Entry `json:"entries" jsonschema:"description=Entries of the title from different sources"`
+}
+
+// Best returns the entry with the most chapters
+func (g *Group) Best() *Entry {
+	best := g.Entries[0]
+	for _, entry := range g.Entries[1:] {
+		if entry.Chapters > best.Chapters {
+			best = entry
+		}
+	}
+
+	return best
+}
+
+// Sources returns names of the sources that carry the title
+func (g *Group) Sources() []string {
+	names := make([]string, len(g.Entries))
+	for i, entry := range g.Entries {
+		names[i] = entry.Source
+	}
+
+	return names
+}
+
+// normalize returns name in the form used for comparison.
+// Case, punctuation and extra spaces are ignored
+func normalize(name string) string {
+	var b strings.Builder
+
+	for _, word := range strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		if b.Len() > 0 {
+			b.WriteRune(' ')
+		}
+
+		b.WriteString(word)
+	}
+
+	return b.String()
+}
+
+// identities returns keys that identify the manga
+func identities(manga *source.Manga) []string {
+	names := []string{manga.Name}
+	names = append(names, manga.Metadata.Synonyms...)
+
+	var keys []string
+	if al, ok := manga.Anilist.Get(); ok {
+		keys = append(keys, fmt.Sprintf("anilist:%d", al.ID))
+		names = append(names, al.Title.English, al.Title.Romaji, al.Title.Native)
+		names = append(names, al.Synonyms...)
+	}
+
+	for _, name := range names {
+		if name = normalize(name); name != "" {
+			keys = append(keys, "name:"+name)
+		}
+	}
+
+	return keys
+}
+
+// Merge groups the same titles from different results together.
+// Titles are matched by normalized names, Anilist ids and synonyms.
+// Mangas of the same source are never merged, since they are different titles of the source.
+// Groups are ordered by the first appearance of the title in the results
+func Merge(results []*Result) []*Group {
+	var mangas []*source.Manga
+	for _, result := range results {
+		mangas = append(mangas, result.Mangas...)
+	}
+
+	// union-find over the mangas indices
+	parent := make([]int, len(mangas))
+	// sources of the mangas in the group, by the root index
+	sources := make([]map[string]bool, len(mangas))
+	for i, manga := range mangas {
+		parent[i] = i
+		sources[i] = map[string]bool{sourceName(manga): true}
+	}
+
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+
+		return parent[i]
+	}
+
+	union := func(a, b int) {
+		a, b = find(a), find(b)
+		if a == b {
+			return
+		}
+
+		for name := range sources[b] {
+			if sources[a][name] {
+				return
+			}
+		}
+
+		// keep the smallest index as a root to preserve the order
+		if a > b {
+			a, b = b, a
+		}
+
+		parent[b] = a
+		for name := range sources[b] {
+			sources[a][name] = true
+		}
+	}
+
+	// indices of the mangas by their identities
+	seen := make(map[string][]int)
+	for i, manga := range mangas {
+		for _, id := range identities(manga) {
+			for _, j := range seen[id] {
+				union(i, j)
+			}
+
+			seen[id] = append(seen[id], i)
+		}
+	}
+
+	var (
+		groups  []*Group
+		byIndex = make(map[int]*Group)
+	)
+
+	for i, manga := range mangas {
+		root := find(i)
+		group, ok := byIndex[root]
+		if !ok {
+			group = &Group{Name: mangas[root].Name}
+			byIndex[root] = group
+			groups = append(groups, group)
+		}
+
+		if al, ok := manga.Anilist.Get(); ok && group.AnilistID == 0 {
+			group.AnilistID = al.ID
+		}
+
+		group.Entries = append(group.Entries, &Entry{
+			Source: sourceName(manga),
+			Manga:  manga,
+		})
+	}
+
+	return groups
+}
+
+// sourceName returns the name of the source of the manga, empty if it has none
+func sourceName(manga *source.Manga) string {
+	if manga.Source == nil {
+		return ""
+	}
+
+	return manga.Source.Name()
+}
+
+// CountChapters fetches chapters of each entry of the groups to count them.
+// Only groups with more than one entry are counted, since there is nothing to choose from otherwise.
+// Sources are queried concurrently, but entries of the same source one by one.
+// Each call has the timeout, after the first timeout the rest entries of the source are skipped.
+// Chapters are fetched for copies of the mangas, so only the counts are kept.
+// Entries are then sorted from the most complete.
+func CountChapters(groups []*Group, timeout time.Duration) {
+	bySource := make(map[string][]*Entry)
+	for _, group := range groups {
+		if len(group.Entries) < 2 {
+			continue
+		}
+
+		for _, entry := range group.Entries {
+			bySource[entry.Source] = append(bySource[entry.Source], entry)
+		}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(len(bySource))
+	for _, entries := range bySource {
+		go func(entries []*Entry) {
+			defer wg.Done()
+
+			for i, entry := range entries {
+				// the source works on a copy, since it may keep running after the timeout
+				// while the manga is already shown
+				manga := *entry.Manga
+				chapters, err := withTimeout(timeout, func(context.Context) ([]*source.Chapter, error) {
+					return manga.Source.ChaptersOf(&manga)
+				})
+
+				if err != nil {
+					entry.Error = err.Error()
+
+					if errors.Is(err, ErrTimeout) {
+						for _, rest := range entries[i+1:] {
+							rest.Error = ErrTimeout.Error()
+						}
+
+						return
+					}
+
+					continue
+				}
+
+				entry.Chapters = len(chapters)
+			}
+		}(entries)
+	}
+
+	wg.Wait()
+
+	for _, group := range groups {
+		sort.SliceStable(group.Entries, func(i, j int) bool {
+			return group.Entries[i].Chapters > group.Entries[j].Chapters
+		})
+	}
+}
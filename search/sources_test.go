@@ -0,0 +1,240 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/metafates/mangal/anilist"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/mo"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type testSource struct {
+	name     string
+	mangas   []*source.Manga
+	chapters int
+	delay    time.Duration
+	err      error
+}
+
+func (s *testSource) Name() string {
+	return s.name
+}
+
+func (s *testSource) ID() string {
+	return s.name
+}
+
+func (s *testSource) Search(_ string) ([]*source.Manga, error) {
+	time.Sleep(s.delay)
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	for _, manga := range s.mangas {
+		manga.Source = s
+	}
+
+	return s.mangas, nil
+}
+
+func (s *testSource) ChaptersOf(_ *source.Manga) ([]*source.Chapter, error) {
+	return make([]*source.Chapter, s.chapters), nil
+}
+
+func (s *testSource) PagesOf(_ *source.Chapter) ([]*source.Page, error) {
+	return nil, nil
+}
+
+// contextSource is the source that searches until the context is done
+type contextSource struct {
+	testSource
+	canceled chan struct{}
+}
+
+func (s *contextSource) SearchContext(ctx context.Context, _ string) ([]*source.Manga, error) {
+	<-ctx.Done()
+	close(s.canceled)
+	return nil, ctx.Err()
+}
+
+func TestSources(t *testing.T) {
+	Convey("Given sources where one fails and one is slow", t, func() {
+		sources := []source.Source{
+			&testSource{name: "a", mangas: []*source.Manga{{Name: "One Piece"}}},
+			&testSource{name: "b", err: errors.New("broken")},
+			&testSource{name: "c", delay: time.Second},
+		}
+
+		Convey("When searching with a timeout", func() {
+			results := Sources(sources, "one piece", 50*time.Millisecond)
+
+			Convey("Then partial results should be returned", func() {
+				So(results, ShouldHaveLength, 3)
+				So(results[0].Err, ShouldBeNil)
+				So(results[0].Mangas, ShouldHaveLength, 1)
+				So(results[1].Err, ShouldNotBeNil)
+				So(results[2].Err, ShouldEqual, ErrTimeout)
+
+				mangas, err := Mangas(results)
+				So(err, ShouldBeNil)
+				So(mangas, ShouldHaveLength, 1)
+			})
+		})
+	})
+
+	Convey("Given a source that supports the context", t, func() {
+		src := &contextSource{testSource: testSource{name: "a"}, canceled: make(chan struct{})}
+
+		Convey("When it times out", func() {
+			results := Sources([]source.Source{src}, "one piece", 10*time.Millisecond)
+
+			Convey("Then its search should be stopped", func() {
+				So(results[0].Err, ShouldEqual, ErrTimeout)
+
+				select {
+				case <-src.canceled:
+				case <-time.After(time.Second):
+					So("search was not stopped", ShouldBeEmpty)
+				}
+			})
+		})
+	})
+}
+
+func TestMerge(t *testing.T) {
+	Convey("Given results with the same titles from different sources", t, func() {
+		al := &anilist.Manga{ID: 30013}
+		al.Synonyms = []string{"Kimetsu no Yaiba"}
+
+		a := &testSource{name: "a", chapters: 10, mangas: []*source.Manga{
+			{Name: "One Piece"},
+			{Name: "Demon Slayer", Anilist: mo.Some(al)},
+		}}
+		b := &testSource{name: "b", chapters: 20, mangas: []*source.Manga{
+			{Name: "one piece!"},
+			{Name: "Kimetsu no Yaiba"},
+			{Name: "Naruto"},
+		}}
+
+		results := Sources([]source.Source{a, b}, "", 0)
+
+		Convey("When merging", func() {
+			groups := Merge(results)
+
+			Convey("Then titles should be grouped by names and synonyms", func() {
+				So(groups, ShouldHaveLength, 3)
+				So(groups[0].Name, ShouldEqual, "One Piece")
+				So(groups[0].Sources(), ShouldResemble, []string{"a", "b"})
+				So(groups[1].Name, ShouldEqual, "Demon Slayer")
+				So(groups[1].AnilistID, ShouldEqual, 30013)
+				So(groups[1].Sources(), ShouldResemble, []string{"a", "b"})
+				So(groups[2].Sources(), ShouldResemble, []string{"b"})
+			})
+
+			Convey("And counting chapters", func() {
+				CountChapters(groups, 0)
+
+				Convey("Then the most complete source should be first", func() {
+					So(groups[0].Entries[0].Source, ShouldEqual, "b")
+					So(groups[0].Entries[0].Chapters, ShouldEqual, 20)
+					So(groups[0].Best().Source, ShouldEqual, "b")
+				})
+			})
+		})
+	})
+}
+
+// slowChaptersSource fills the chapters of the manga after the delay
+type slowChaptersSource struct {
+	testSource
+	done chan struct{}
+}
+
+func (s *slowChaptersSource) ChaptersOf(manga *source.Manga) ([]*source.Chapter, error) {
+	defer close(s.done)
+
+	time.Sleep(s.delay)
+	manga.Chapters = make([]*source.Chapter, s.chapters)
+	return manga.Chapters, nil
+}
+
+func TestCountChaptersTimeout(t *testing.T) {
+	Convey("Given a group with the source that lists chapters slowly", t, func() {
+		slow := &slowChaptersSource{
+			testSource: testSource{name: "slow", chapters: 5, delay: 50 * time.Millisecond},
+			done:       make(chan struct{}),
+		}
+		fast := &testSource{name: "fast", chapters: 3}
+
+		group := &Group{Entries: []*Entry{
+			{Source: "slow", Manga: &source.Manga{Name: "Berserk", Source: slow}},
+			{Source: "fast", Manga: &source.Manga{Name: "Berserk", Source: fast}},
+		}}
+
+		Convey("When counting chapters with the shorter timeout", func() {
+			CountChapters([]*Group{group}, 10*time.Millisecond)
+			<-slow.done
+
+			Convey("Then the slow source should time out", func() {
+				So(group.Entries[0].Source, ShouldEqual, "fast")
+				So(group.Entries[1].Error, ShouldEqual, ErrTimeout.Error())
+			})
+
+			Convey("And the late chapters should not be written to the shown manga", func() {
+				So(group.Entries[1].Manga.Chapters, ShouldBeNil)
+			})
+		})
+	})
+}
+
+func TestMergeSameSource(t *testing.T) {
+	Convey("Given different titles with the same name from one source", t, func() {
+		a := &testSource{name: "a", mangas: []*source.Manga{
+			{Name: "Berserk", URL: "/berserk"},
+			{Name: "Berserk!", URL: "/berserk-parody"},
+		}}
+		b := &testSource{name: "b", mangas: []*source.Manga{
+			{Name: "berserk", URL: "/berserk"},
+		}}
+
+		Convey("When merging", func() {
+			groups := Merge(Sources([]source.Source{a, b}, "", 0))
+
+			Convey("Then they should not be grouped together", func() {
+				So(groups, ShouldHaveLength, 2)
+				So(groups[0].Sources(), ShouldResemble, []string{"a", "b"})
+				So(groups[1].Sources(), ShouldResemble, []string{"a"})
+				So(groups[1].Entries[0].Manga.URL, ShouldEqual, "/berserk-parody")
+			})
+		})
+	})
+}
+
+func TestClosest(t *testing.T) {
+	Convey("Given a manga and candidates from another source", t, func() {
+		manga := &source.Manga{Name: "Jujutsu Kaisen"}
+		candidates := []*source.Manga{
+			{Name: "Jujutsu Kaisen Official Fanbook"},
+			{Name: "Jujutsu Kaisen!"},
+		}
+
+		Convey("When looking for the closest one", func() {
+			closest, ok := Closest(manga, candidates)
+			Convey("Then the same title should be found", func() {
+				So(ok, ShouldBeTrue)
+				So(closest, ShouldEqual, candidates[1])
+			})
+		})
+
+		Convey("When there is no similar title", func() {
+			_, ok := Closest(manga, []*source.Manga{{Name: "Chainsaw Man"}})
+			Convey("Then nothing should be found", func() {
+				So(ok, ShouldBeFalse)
+			})
+		})
+	})
+}
@@ -0,0 +1,525 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/invopop/jsonschema"
+	"github.com/metafates/mangal/event"
+	"github.com/metafates/mangal/fallback"
+	"github.com/metafates/mangal/history"
+	"github.com/metafates/mangal/inline"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/provider"
+	"github.com/metafates/mangal/search"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	"github.com/spf13/viper"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+type handler func(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string) error
+
+// Endpoint of the server API
+type Endpoint struct {
+	// Method is an HTTP method of the endpoint
+	Method string
+	// Path of the endpoint. Segments in braces are parameters
+	Path string
+	// Description of the endpoint
+	Description string
+	// Request is a value of the request body type. Nil if there is no body
+	Request any
+	// Response is a value of the response type
+	Response any
+
+	handle handler
+}
+
+// match checks if the given path matches the endpoint path and returns its parameters
+func (e *Endpoint) match(path string) (map[string]string, bool) {
+	want := strings.Split(strings.Trim(e.Path, "/"), "/")
+	got := strings.Split(strings.Trim(path, "/"), "/")
+
+	if len(want) != len(got) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for i, segment := range want {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if got[i] == "" {
+				return nil, false
+			}
+
+			params[strings.Trim(segment, "{}")] = got[i]
+			continue
+		}
+
+		if segment != got[i] {
+			return nil, false
+		}
+	}
+
+	return params, true
+}
+
+// Endpoints returns all endpoints of the server API
+func Endpoints() []*Endpoint {
+	return []*Endpoint{
+		{
+			Method:      http.MethodGet,
+			Path:        "/api/providers",
+			Description: "List available providers",
+			Response:    []*Provider{},
+			handle:      handleProviders,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/api/search",
+			Description: "Search manga in all sources concurrently. Query parameters: query, source (can be repeated, defaults to the default sources)",
+			Response:    []*SearchResult{},
+			handle:      handleSearch,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/api/chapters",
+			Description: "Get chapters of the manga",
+			Request:     &MangaRequest{},
+			Response:    []*source.Chapter{},
+			handle:      handleChapters,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/api/pages",
+			Description: "Get pages of the chapter",
+			Request:     &PagesRequest{},
+			Response:    []*source.Page{},
+			handle:      handlePages,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/api/downloads",
+			Description: "Start downloading chapters of the manga in the background",
+			Request:     &DownloadRequest{},
+			Response:    &Job{},
+			handle:      handleDownload,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/api/downloads",
+			Description: "List download jobs",
+			Response:    []*Job{},
+			handle:      handleJobs,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/api/downloads/{id}",
+			Description: "Get download job",
+			Response:    &Job{},
+			handle:      handleJob,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/api/downloads/{id}/events",
+			Description: "Stream progress events of the download job as newline delimited JSON until it is finished",
+			Response:    &Event{},
+			handle:      handleJobEvents,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/api/history",
+			Description: "List read and downloaded chapters",
+			Response:    []*history.SavedChapter{},
+			handle:      handleHistory,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/api/metadata",
+			Description: "Fetch metadata of the manga",
+			Request:     &MangaRequest{},
+			Response:    &MetadataResponse{},
+			handle:      handleMetadata,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/api/schema",
+			Description: "JSON schemas of the API",
+			Response:    []*EndpointSchema{},
+			handle:      handleSchema,
+		},
+	}
+}
+
+// EndpointSchema describes an endpoint with JSON schemas of its request and response
+type EndpointSchema struct {
+	Method      string             `json:"method"`
+	Path        string             `json:"path"`
+	Description string             `json:"description"`
+	Request     *jsonschema.Schema `json:"request,omitempty"`
+	Response    *jsonschema.Schema `json:"response"`
+}
+
+// Schema returns JSON schemas of all endpoints
+func Schema() []*EndpointSchema {
+	reflector := inline.NewSchemaReflector()
+
+	return lo.Map(Endpoints(), func(e *Endpoint, _ int) *EndpointSchema {
+		schema := &EndpointSchema{
+			Method:      e.Method,
+			Path:        e.Path,
+			Description: e.Description,
+			Response:    reflector.Reflect(e.Response),
+		}
+
+		if e.Request != nil {
+			schema.Request = reflector.Reflect(e.Request)
+		}
+
+		return schema
+	})
+}
+
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e *httpError) Error() string {
+	return e.err.Error()
+}
+
+func badRequest(err error) error {
+	return &httpError{status: http.StatusBadRequest, err: err}
+}
+
+func notFound(err error) error {
+	return &httpError{status: http.StatusNotFound, err: err}
+}
+
+func decode(r *http.Request, v any) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return badRequest(fmt.Errorf("invalid request body: %w", err))
+	}
+
+	return nil
+}
+
+func reply(w http.ResponseWriter, status int, v any) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(v)
+}
+
+func handleProviders(_ *Server, w http.ResponseWriter, _ *http.Request, _ map[string]string) error {
+	providers := lo.Map(append(provider.Builtins(), provider.Customs()...), func(p *provider.Provider, _ int) *Provider {
+		return &Provider{
+			ID:           p.ID,
+			Name:         p.Name,
+			Custom:       p.IsCustom,
+			UsesHeadless: p.UsesHeadless,
+		}
+	})
+
+	return reply(w, http.StatusOK, providers)
+}
+
+func handleSearch(s *Server, w http.ResponseWriter, r *http.Request, _ map[string]string) error {
+	query := r.URL.Query().Get("query")
+	if query == "" {
+		return badRequest(errors.New("query is required"))
+	}
+
+	sources := r.URL.Query()["source"]
+	if len(sources) == 0 {
+		sources = viper.GetStringSlice(key.DownloaderDefaultSources)
+	}
+
+	if len(sources) == 0 {
+		return badRequest(errors.New("source not set"))
+	}
+
+	var (
+		results = make([]*SearchResult, len(sources))
+		loaded  []source.Source
+		indices []int
+	)
+
+	for i, name := range sources {
+		results[i] = &SearchResult{Source: name, Mangas: []*source.Manga{}}
+
+		src, err := s.sources.Get(name)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+
+		loaded = append(loaded, src)
+		indices = append(indices, i)
+	}
+
+	for i, found := range search.Sources(loaded, query, search.Timeout()) {
+		result := results[indices[i]]
+		if found.Err != nil {
+			result.Error = found.Err.Error()
+			continue
+		}
+
+		if found.Mangas != nil {
+			result.Mangas = found.Mangas
+		}
+	}
+
+	return reply(w, http.StatusOK, results)
+}
+
+func handleChapters(s *Server, w http.ResponseWriter, r *http.Request, _ map[string]string) error {
+	var request MangaRequest
+	if err := decode(r, &request); err != nil {
+		return err
+	}
+
+	manga, err := s.manga(request.Manga)
+	if err != nil {
+		return err
+	}
+
+	chapters, err := s.chaptersOf(manga)
+	if err != nil {
+		return err
+	}
+
+	return reply(w, http.StatusOK, chapters)
+}
+
+func handlePages(s *Server, w http.ResponseWriter, r *http.Request, _ map[string]string) error {
+	var request PagesRequest
+	if err := decode(r, &request); err != nil {
+		return err
+	}
+
+	if request.Chapter == nil {
+		return badRequest(errors.New("chapter is required"))
+	}
+
+	manga, err := s.manga(request.Manga)
+	if err != nil {
+		return err
+	}
+
+	chapter := &source.Chapter{
+		Name:   request.Chapter.Name,
+		URL:    request.Chapter.URL,
+		ID:     request.Chapter.ID,
+		Index:  request.Chapter.Index,
+		Volume: request.Chapter.Volume,
+		Manga:  manga,
+	}
+
+	// chapters added by the fallback download pages from their alternates
+	if added, ok := s.fallbackChapter(manga, chapter.URL); ok {
+		chapter = added.WithManga(manga)
+		manga.Chapters = append(manga.Chapters, chapter)
+	} else {
+		manga.Chapters = append(manga.Chapters, chapter)
+	}
+
+	var pages []*source.Page
+	if alternate, ok := chapter.Alternate().Get(); ok {
+		pages, err = fallback.PagesOf(alternate)
+	} else {
+		pages, err = manga.Source.PagesOf(chapter)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return reply(w, http.StatusOK, pages)
+}
+
+// chaptersOf returns chapters of the manga completed by the fallback if it is enabled.
+// Chapters added by the fallback are remembered for the pages requests
+func (s *Server) chaptersOf(manga *source.Manga) ([]*source.Chapter, error) {
+	chapters, err := manga.Source.ChaptersOf(manga)
+	if err != nil {
+		return nil, err
+	}
+
+	if fallback.Enabled() {
+		chapters = fallback.Complete(manga, chapters)
+		s.fallbacks.Store(manga, chapters)
+	}
+
+	return chapters, nil
+}
+
+// fallbackChapter returns the chapter with the url that was added to the manga chapters by the fallback.
+// Chapters of the manga are resolved only if they are not remembered already
+func (s *Server) fallbackChapter(manga *source.Manga, url string) (*source.Chapter, bool) {
+	if !fallback.Enabled() {
+		return nil, false
+	}
+
+	if chapter, known := s.fallbacks.Get(manga, url); known {
+		return chapter, chapter != nil
+	}
+
+	if _, err := s.chaptersOf(&source.Manga{
+		Name:     manga.Name,
+		URL:      manga.URL,
+		ID:       manga.ID,
+		Source:   manga.Source,
+		Chapters: make([]*source.Chapter, 0),
+	}); err != nil {
+		return nil, false
+	}
+
+	chapter, _ := s.fallbacks.Get(manga, url)
+	return chapter, chapter != nil
+}
+
+func handleDownload(s *Server, w http.ResponseWriter, r *http.Request, _ map[string]string) error {
+	var request DownloadRequest
+	if err := decode(r, &request); err != nil {
+		return err
+	}
+
+	if len(request.Chapters) == 0 && request.Selector == "" {
+		return badRequest(errors.New("either chapters or selector is required"))
+	}
+
+	manga, err := s.manga(request.Manga)
+	if err != nil {
+		return err
+	}
+
+	chapters, err := s.chaptersOf(manga)
+	if err != nil {
+		return err
+	}
+
+	if request.Selector != "" {
+		filter, err := inline.ParseChaptersFilter(request.Selector)
+		if err != nil {
+			return badRequest(err)
+		}
+
+		chapters, err = filter(chapters)
+		if err != nil {
+			return badRequest(err)
+		}
+	} else {
+		urls := lo.SliceToMap(request.Chapters, func(c *ChapterRef) (string, struct{}) {
+			return c.URL, struct{}{}
+		})
+
+		chapters = lo.Filter(chapters, func(c *source.Chapter, _ int) bool {
+			_, ok := urls[c.URL]
+			return ok
+		})
+	}
+
+	if len(chapters) == 0 {
+		return notFound(errors.New("no chapters to download"))
+	}
+
+	job := s.jobs.Add(request.Manga, chapters)
+	return reply(w, http.StatusAccepted, job.snapshot())
+}
+
+func handleJobs(s *Server, w http.ResponseWriter, _ *http.Request, _ map[string]string) error {
+	jobs := lo.Map(s.jobs.All(), func(j *Job, _ int) *Job {
+		return j.snapshot()
+	})
+
+	return reply(w, http.StatusOK, jobs)
+}
+
+func handleJob(s *Server, w http.ResponseWriter, _ *http.Request, params map[string]string) error {
+	job, err := s.jobs.Get(params["id"])
+	if err != nil {
+		return notFound(err)
+	}
+
+	return reply(w, http.StatusOK, job.snapshot())
+}
+
+func handleJobEvents(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string) error {
+	job, err := s.jobs.Get(params["id"])
+	if err != nil {
+		return notFound(err)
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+
+	var sent int
+	for {
+		events, changed, finished := job.eventsSince(sent)
+		for _, event := range events {
+			if err := encoder.Encode(event); err != nil {
+				return nil
+			}
+		}
+		sent += len(events)
+
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		if finished {
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return nil
+		}
+	}
+}
+
+func handleHistory(_ *Server, w http.ResponseWriter, _ *http.Request, _ map[string]string) error {
+	saved, err := history.Get()
+	if err != nil {
+		return err
+	}
+
+	chapters := lo.Values(saved)
+	sort.Slice(chapters, func(i, j int) bool {
+		return chapters[i].MangaName < chapters[j].MangaName
+	})
+
+	return reply(w, http.StatusOK, chapters)
+}
+
+func handleMetadata(s *Server, w http.ResponseWriter, r *http.Request, _ map[string]string) error {
+	var request MangaRequest
+	if err := decode(r, &request); err != nil {
+		return err
+	}
+
+	manga, err := s.manga(request.Manga)
+	if err != nil {
+		return err
+	}
+
+	if err := manga.PopulateMetadata(event.Discard); err != nil {
+		return err
+	}
+
+	return reply(w, http.StatusOK, &MetadataResponse{
+		Manga:    request.Manga,
+		Metadata: manga.Metadata,
+	})
+}
+
+func handleSchema(_ *Server, w http.ResponseWriter, _ *http.Request, _ map[string]string) error {
+	return reply(w, http.StatusOK, Schema())
+}
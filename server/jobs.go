@@ -0,0 +1,301 @@
+package server
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/downloader"
+	"github.com/metafates/mangal/event"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/source"
+	"github.com/spf13/viper"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// JobStatus is a status of the download job or its chapter
+type JobStatus string
+
+const (
+	JobQueued  JobStatus = "queued"
+	JobRunning JobStatus = "running"
+	JobDone    JobStatus = "done"
+	JobFailed  JobStatus = "failed"
+)
+
+func (s JobStatus) finished() bool {
+	return s == JobDone || s == JobFailed
+}
+
+// JobChapter is a chapter that is downloaded by the job
+type JobChapter struct {
+	// Name of the chapter
+	Name string `json:"name" jsonschema:"description=Name of the chapter"`
+	// URL of the chapter
+	URL string `json:"url" jsonschema:"description=URL of the chapter"`
+	// Status of the chapter download
+	Status JobStatus `json:"status" jsonschema:"enum=queued,enum=running,enum=done,enum=failed,description=Status of the chapter download"`
+	// Path to the downloaded chapter
+	Path string `json:"path,omitempty" jsonschema:"description=Path to the downloaded chapter"`
+	// Error is set if the chapter download has failed
+	Error string `json:"error,omitempty" jsonschema:"description=Set if the chapter download has failed"`
+
+	chapter *source.Chapter
+}
+
+// Event is a progress event of the download job
+type Event struct {
+	// Job is an ID of the job
+	Job string `json:"job" jsonschema:"description=ID of the job"`
+	// Status of the job
+	Status JobStatus `json:"status" jsonschema:"enum=queued,enum=running,enum=done,enum=failed,description=Status of the job"`
+
+	*event.Event
+}
+
+// Job is an asynchronous download of manga chapters
+type Job struct {
+	// ID of the job
+	ID string `json:"id" jsonschema:"description=ID of the job"`
+	// Status of the job
+	Status JobStatus `json:"status" jsonschema:"enum=queued,enum=running,enum=done,enum=failed,description=Status of the job"`
+	// Manga that is downloaded
+	Manga *MangaRef `json:"manga" jsonschema:"description=Manga that is downloaded"`
+	// Chapters that are downloaded
+	Chapters []*JobChapter `json:"chapters" jsonschema:"description=Chapters that are downloaded"`
+	// Created is the time when the job was created
+	Created time.Time `json:"created" jsonschema:"description=Time when the job was created"`
+	// Error is set if the job has failed
+	Error string `json:"error,omitempty" jsonschema:"description=Set if the job has failed"`
+
+	mu      sync.Mutex
+	events  []*Event
+	changed chan struct{}
+}
+
+func newJob(id string, manga *MangaRef, chapters []*source.Chapter) *Job {
+	job := &Job{
+		ID:      id,
+		Status:  JobQueued,
+		Manga:   manga,
+		Created: time.Now(),
+		changed: make(chan struct{}),
+	}
+
+	for _, chapter := range chapters {
+		job.Chapters = append(job.Chapters, &JobChapter{
+			Name:    chapter.Name,
+			URL:     chapter.URL,
+			Status:  JobQueued,
+			chapter: chapter,
+		})
+	}
+
+	job.emit(&event.Event{Kind: event.Progress, Message: "Queued"})
+	return job
+}
+
+// emit records a new event and wakes up everyone waiting for it.
+// Must not be called with the lock held.
+func (j *Job) emit(e *event.Event) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	j.events = append(j.events, &Event{
+		Job:    j.ID,
+		Status: j.Status,
+		Event:  e,
+	})
+
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+// eventsSince returns events that happened after the first n ones,
+// a channel that is closed on the next event and whether the job is finished
+func (j *Job) eventsSince(n int) ([]*Event, <-chan struct{}, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var events []*Event
+	if n < len(j.events) {
+		events = j.events[n:]
+	}
+
+	// the job is considered finished only after its final event was recorded
+	finished := len(j.events) > 0 && j.events[len(j.events)-1].Status.finished()
+	return events, j.changed, finished
+}
+
+// snapshot returns a copy of the job that is safe to encode
+func (j *Job) snapshot() *Job {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	chapters := make([]*JobChapter, len(j.Chapters))
+	for i, chapter := range j.Chapters {
+		c := *chapter
+		chapters[i] = &c
+	}
+
+	return &Job{
+		ID:       j.ID,
+		Status:   j.Status,
+		Manga:    j.Manga,
+		Chapters: chapters,
+		Created:  j.Created,
+		Error:    j.Error,
+	}
+}
+
+func (j *Job) status() JobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.Status
+}
+
+func (j *Job) setStatus(status JobStatus, err error) {
+	j.mu.Lock()
+	j.Status = status
+	if err != nil {
+		j.Error = err.Error()
+	}
+	j.mu.Unlock()
+}
+
+func (j *Job) run() {
+	j.setStatus(JobRunning, nil)
+	j.emit(&event.Event{Kind: event.Progress, Message: "Started"})
+
+	var failed error
+	for _, c := range j.Chapters {
+		j.mu.Lock()
+		c.Status = JobRunning
+		j.mu.Unlock()
+
+		path, err := downloader.Download(c.chapter, j.emit)
+
+		j.mu.Lock()
+		if err != nil {
+			c.Status = JobFailed
+			c.Error = err.Error()
+		} else {
+			c.Status = JobDone
+			c.Path = path
+		}
+		j.mu.Unlock()
+
+		if err != nil {
+			log.Error(err)
+			failed = err
+
+			if viper.GetBool(key.DownloaderStopOnError) {
+				break
+			}
+		}
+	}
+
+	if failed != nil {
+		j.setStatus(JobFailed, failed)
+		j.emit(&event.Event{Kind: event.Error, Message: "Failed", Error: failed.Error()})
+		return
+	}
+
+	j.setStatus(JobDone, nil)
+	j.emit(&event.Event{Kind: event.Progress, Message: "Done"})
+}
+
+// maxFinishedJobs is how many finished jobs are kept, the oldest ones are forgotten
+const maxFinishedJobs = 100
+
+// jobsQueue runs download jobs with a limited concurrency
+type jobsQueue struct {
+	mu        sync.Mutex
+	jobs      []*Job
+	semaphore chan struct{}
+	counter   int
+}
+
+func newJobsQueue(concurrency int) *jobsQueue {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	return &jobsQueue{semaphore: make(chan struct{}, concurrency)}
+}
+
+// Add creates a new job and schedules it
+func (q *jobsQueue) Add(manga *MangaRef, chapters []*source.Chapter) *Job {
+	q.mu.Lock()
+	q.counter++
+	job := newJob(strconv.Itoa(q.counter), manga, chapters)
+	q.evict()
+	q.jobs = append(q.jobs, job)
+	q.mu.Unlock()
+
+	go func() {
+		q.semaphore <- struct{}{}
+		defer func() { <-q.semaphore }()
+
+		job.run()
+	}()
+
+	return job
+}
+
+// evict forgets the oldest finished jobs above maxFinishedJobs.
+// Must be called with the lock held
+func (q *jobsQueue) evict() {
+	var finished int
+	for _, job := range q.jobs {
+		if job.status().finished() {
+			finished++
+		}
+	}
+
+	if finished <= maxFinishedJobs {
+		return
+	}
+
+	jobs := q.jobs[:0]
+	for _, job := range q.jobs {
+		if finished > maxFinishedJobs && job.status().finished() {
+			finished--
+			continue
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	clear(q.jobs[len(jobs):])
+	q.jobs = jobs
+}
+
+// Get returns a job by its id
+func (q *jobsQueue) Get(id string) (*Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, job := range q.jobs {
+		if job.ID == id {
+			return job, nil
+		}
+	}
+
+	return nil, fmt.Errorf("job not found: %s", id)
+}
+
+// All returns all jobs in the order they were created
+func (q *jobsQueue) All() []*Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	jobs := make([]*Job, len(q.jobs))
+	copy(jobs, q.jobs)
+	return jobs
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/source"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Options of the server
+type Options struct {
+	// Address to listen on, e.g. localhost:6969. Ignored if Socket is set
+	Address string
+	// Socket is a path to the unix socket to listen on
+	Socket string
+	// Jobs is a number of downloads that can run at the same time
+	Jobs int
+}
+
+// Server exposes mangal functionality over HTTP with JSON bodies
+type Server struct {
+	options   *Options
+	sources   *sourcesPool
+	jobs      *jobsQueue
+	fallbacks *fallbackChapters
+	endpoints []*Endpoint
+}
+
+// New creates a new server
+func New(options *Options) *Server {
+	return &Server{
+		options:   options,
+		sources:   newSourcesPool(),
+		jobs:      newJobsQueue(options.Jobs),
+		fallbacks: newFallbackChapters(),
+		endpoints: Endpoints(),
+	}
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var pathMatched bool
+
+	for _, endpoint := range s.endpoints {
+		params, ok := endpoint.match(r.URL.Path)
+		if !ok {
+			continue
+		}
+
+		pathMatched = true
+		if endpoint.Method != r.Method {
+			continue
+		}
+
+		if err := endpoint.handle(s, w, r, params); err != nil {
+			status := http.StatusInternalServerError
+
+			var httpErr *httpError
+			if errors.As(err, &httpErr) {
+				status = httpErr.status
+			}
+
+			log.Error(err)
+			_ = reply(w, status, &Error{Error: err.Error()})
+		}
+
+		return
+	}
+
+	if pathMatched {
+		_ = reply(w, http.StatusMethodNotAllowed, &Error{Error: "method not allowed"})
+		return
+	}
+
+	_ = reply(w, http.StatusNotFound, &Error{Error: "not found"})
+}
+
+// manga creates a manga from the reference that can be used with its source
+func (s *Server) manga(ref *MangaRef) (*source.Manga, error) {
+	if ref == nil {
+		return nil, badRequest(errors.New("manga is required"))
+	}
+
+	src, err := s.sources.Get(ref.Source)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	return &source.Manga{
+		Name:     ref.Name,
+		URL:      ref.URL,
+		ID:       ref.ID,
+		Source:   src,
+		Chapters: make([]*source.Chapter, 0),
+	}, nil
+}
+
+// Listen creates a listener according to the options
+func (s *Server) Listen() (net.Listener, error) {
+	if s.options.Socket != "" {
+		// remove the stale socket left after the previous run,
+		// but never anything else found at its path.
+		// The socket is always on the disk, so the os is used
+		info, err := os.Lstat(s.options.Socket)
+		switch {
+		case err == nil && info.Mode()&os.ModeSocket == 0:
+			return nil, fmt.Errorf("%s exists and is not a socket", s.options.Socket)
+		case err == nil:
+			if err := os.Remove(s.options.Socket); err != nil {
+				return nil, err
+			}
+		case !errors.Is(err, os.ErrNotExist):
+			return nil, err
+		}
+
+		return net.Listen("unix", s.options.Socket)
+	}
+
+	return net.Listen("tcp", s.options.Address)
+}
+
+// Run starts the server and blocks until it is interrupted
+func Run(options *Options) error {
+	s := New(options)
+
+	listener, err := s.Listen()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	httpServer := &http.Server{Handler: s}
+
+	go func() {
+		<-ctx.Done()
+		_ = httpServer.Shutdown(context.Background())
+	}()
+
+	log.Info(fmt.Sprintf("listening on %s", listener.Addr()))
+	if err = httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
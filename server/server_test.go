@@ -0,0 +1,253 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/source"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func init() {
+	filesystem.SetMemMapFs()
+}
+
+func TestEndpoint(t *testing.T) {
+	Convey("Given an endpoint with a parameter", t, func() {
+		endpoint := &Endpoint{Method: http.MethodGet, Path: "/api/downloads/{id}/events"}
+
+		Convey("When matching a path with the same segments", func() {
+			params, ok := endpoint.match("/api/downloads/42/events")
+			Convey("Then it should match and capture the parameter", func() {
+				So(ok, ShouldBeTrue)
+				So(params["id"], ShouldEqual, "42")
+			})
+		})
+
+		Convey("When matching a path with a different length", func() {
+			_, ok := endpoint.match("/api/downloads/42")
+			Convey("Then it should not match", func() {
+				So(ok, ShouldBeFalse)
+			})
+		})
+	})
+}
+
+func TestServer(t *testing.T) {
+	Convey("Given a server", t, func() {
+		s := New(&Options{Jobs: 1})
+
+		Convey("When requesting providers", func() {
+			recorder := httptest.NewRecorder()
+			s.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/providers", nil))
+
+			Convey("Then builtin providers should be returned", func() {
+				So(recorder.Code, ShouldEqual, http.StatusOK)
+
+				var providers []*Provider
+				So(json.Unmarshal(recorder.Body.Bytes(), &providers), ShouldBeNil)
+				So(providers, ShouldNotBeEmpty)
+			})
+		})
+
+		Convey("When using a wrong method", func() {
+			recorder := httptest.NewRecorder()
+			s.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/providers", nil))
+
+			Convey("Then it should respond with an error", func() {
+				So(recorder.Code, ShouldEqual, http.StatusMethodNotAllowed)
+			})
+		})
+
+		Convey("When requesting a missing job", func() {
+			recorder := httptest.NewRecorder()
+			s.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/downloads/1", nil))
+
+			Convey("Then it should respond with not found", func() {
+				So(recorder.Code, ShouldEqual, http.StatusNotFound)
+			})
+		})
+	})
+}
+
+func TestSchema(t *testing.T) {
+	Convey("When generating the schema", t, func() {
+		schemas := Schema()
+		Convey("Then every endpoint should be described", func() {
+			So(schemas, ShouldHaveLength, len(Endpoints()))
+			for _, schema := range schemas {
+				So(schema.Response, ShouldNotBeNil)
+			}
+		})
+	})
+}
+
+// contextSource is a source that only can be searched with a context
+type contextSource struct {
+	source.Source
+	searched chan context.Context
+}
+
+func (s *contextSource) Name() string {
+	return "context"
+}
+
+func (s *contextSource) ID() string {
+	return "context"
+}
+
+func (s *contextSource) SearchContext(ctx context.Context, _ string) ([]*source.Manga, error) {
+	s.searched <- ctx
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestLockedSource(t *testing.T) {
+	Convey("Given a locked source that can be searched with a context", t, func() {
+		inner := &contextSource{searched: make(chan context.Context, 1)}
+		locked := newLockedSource(inner)
+
+		Convey("Then optional interfaces should be forwarded", func() {
+			var src source.Source = locked
+			_, ok := src.(source.ContextSearcher)
+			So(ok, ShouldBeTrue)
+
+			So(locked.MangaDetails(&source.Manga{}), ShouldEqual, source.ErrNoDetails)
+
+			_, err := locked.GetManga("42")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("When the search is canceled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() {
+				_, err := locked.SearchContext(ctx, "query")
+				done <- err
+			}()
+
+			So(<-inner.searched, ShouldEqual, ctx)
+
+			waiting, stop := context.WithCancel(context.Background())
+			waited := make(chan error, 1)
+			go func() {
+				_, err := locked.SearchContext(waiting, "query")
+				waited <- err
+			}()
+
+			Convey("Then the wrapped search and the one waiting for the lock should stop", func() {
+				stop()
+				So(<-waited, ShouldEqual, context.Canceled)
+
+				cancel()
+				So(<-done, ShouldEqual, context.Canceled)
+			})
+		})
+	})
+}
+
+func TestListenSocket(t *testing.T) {
+	Convey("Given a socket path", t, func() {
+		path := filepath.Join(t.TempDir(), "mangal.sock")
+		s := New(&Options{Socket: path})
+
+		Convey("When a regular file is there", func() {
+			So(os.WriteFile(path, []byte("data"), 0600), ShouldBeNil)
+
+			Convey("Then it should not be removed", func() {
+				_, err := s.Listen()
+				So(err, ShouldNotBeNil)
+
+				data, err := os.ReadFile(path)
+				So(err, ShouldBeNil)
+				So(string(data), ShouldEqual, "data")
+			})
+		})
+
+		Convey("When a stale socket is there", func() {
+			stale, err := net.Listen("unix", path)
+			So(err, ShouldBeNil)
+			stale.(*net.UnixListener).SetUnlinkOnClose(false)
+			So(stale.Close(), ShouldBeNil)
+
+			Convey("Then it should be replaced", func() {
+				listener, err := s.Listen()
+				So(err, ShouldBeNil)
+				So(listener.Close(), ShouldBeNil)
+			})
+		})
+	})
+}
+
+func TestJobsQueueEviction(t *testing.T) {
+	Convey("Given a queue with more finished jobs than it keeps", t, func() {
+		q := newJobsQueue(1)
+		for i := 0; i < maxFinishedJobs+5; i++ {
+			job := newJob(strconv.Itoa(i), nil, nil)
+			job.Status = JobDone
+			q.jobs = append(q.jobs, job)
+		}
+
+		running := newJob("running", nil, nil)
+		running.Status = JobRunning
+		q.jobs = append([]*Job{running}, q.jobs...)
+
+		Convey("When the old jobs are evicted", func() {
+			q.evict()
+
+			Convey("Then the oldest finished jobs should be forgotten", func() {
+				So(q.All(), ShouldHaveLength, maxFinishedJobs+1)
+
+				_, err := q.Get("running")
+				So(err, ShouldBeNil)
+
+				_, err = q.Get("4")
+				So(err, ShouldNotBeNil)
+
+				_, err = q.Get("5")
+				So(err, ShouldBeNil)
+			})
+		})
+	})
+}
+
+func TestFallbackChapters(t *testing.T) {
+	Convey("Given chapters of a manga where one was added by the fallback", t, func() {
+		manga := &source.Manga{URL: "https://example.com/manga", Source: &contextSource{}}
+		present := &source.Chapter{URL: "https://example.com/1", Manga: manga}
+		added := &source.Chapter{URL: "https://example.org/2", Manga: manga}
+		added.UseAlternate(&source.Chapter{URL: "https://example.org/2"})
+
+		fallbacks := newFallbackChapters()
+
+		Convey("When they are not stored", func() {
+			_, known := fallbacks.Get(manga, added.URL)
+
+			Convey("Then they should be unknown", func() {
+				So(known, ShouldBeFalse)
+			})
+		})
+
+		Convey("When they are stored", func() {
+			fallbacks.Store(manga, []*source.Chapter{present, added})
+
+			Convey("Then only the added chapter should be found", func() {
+				chapter, known := fallbacks.Get(manga, added.URL)
+				So(known, ShouldBeTrue)
+				So(chapter, ShouldEqual, added)
+
+				chapter, known = fallbacks.Get(manga, present.URL)
+				So(known, ShouldBeTrue)
+				So(chapter, ShouldBeNil)
+			})
+		})
+	})
+}
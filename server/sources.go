@@ -0,0 +1,199 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"github.com/metafates/mangal/provider"
+	"github.com/metafates/mangal/source"
+	"sync"
+	"time"
+)
+
+// lockedSource serializes calls to the wrapped source.
+// Sources are not safe for concurrent use (e.g. lua state),
+// while the server may handle many requests at once.
+//
+// Optional interfaces of the wrapped source are forwarded:
+// SearchContext falls back to Search, MangaDetails to source.ErrNoDetails
+// and GetManga fails if the wrapped source does not implement them
+type lockedSource struct {
+	// lock is a channel so that waiting for it can be stopped by the context
+	lock   chan struct{}
+	source source.Source
+}
+
+func newLockedSource(src source.Source) *lockedSource {
+	return &lockedSource{lock: make(chan struct{}, 1), source: src}
+}
+
+func (l *lockedSource) acquire(ctx context.Context) error {
+	select {
+	case l.lock <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *lockedSource) release() {
+	<-l.lock
+}
+
+func (l *lockedSource) Name() string {
+	return l.source.Name()
+}
+
+func (l *lockedSource) ID() string {
+	return l.source.ID()
+}
+
+func (l *lockedSource) Search(query string) ([]*source.Manga, error) {
+	return l.SearchContext(context.Background(), query)
+}
+
+func (l *lockedSource) SearchContext(ctx context.Context, query string) ([]*source.Manga, error) {
+	if err := l.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer l.release()
+
+	if searcher, ok := l.source.(source.ContextSearcher); ok {
+		return searcher.SearchContext(ctx, query)
+	}
+
+	return l.source.Search(query)
+}
+
+func (l *lockedSource) GetManga(id string) (*source.Manga, error) {
+	getter, ok := l.source.(source.MangaGetter)
+	if !ok {
+		return nil, fmt.Errorf("source %s can't get manga without searching", l.source.Name())
+	}
+
+	_ = l.acquire(context.Background())
+	defer l.release()
+
+	return getter.GetManga(id)
+}
+
+func (l *lockedSource) MangaDetails(manga *source.Manga) error {
+	details, ok := l.source.(source.DetailsProvider)
+	if !ok {
+		return source.ErrNoDetails
+	}
+
+	_ = l.acquire(context.Background())
+	defer l.release()
+
+	return details.MangaDetails(manga)
+}
+
+func (l *lockedSource) ChaptersOf(manga *source.Manga) ([]*source.Chapter, error) {
+	_ = l.acquire(context.Background())
+	defer l.release()
+
+	return l.source.ChaptersOf(manga)
+}
+
+func (l *lockedSource) PagesOf(chapter *source.Chapter) ([]*source.Page, error) {
+	_ = l.acquire(context.Background())
+	defer l.release()
+
+	return l.source.PagesOf(chapter)
+}
+
+// sourcesPool loads sources lazily and keeps them for the lifetime of the server
+type sourcesPool struct {
+	mu      sync.Mutex
+	sources map[string]source.Source
+}
+
+func newSourcesPool() *sourcesPool {
+	return &sourcesPool{sources: make(map[string]source.Source)}
+}
+
+// Get returns a source of the provider with the given name
+func (p *sourcesPool) Get(name string) (source.Source, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if src, ok := p.sources[name]; ok {
+		return src, nil
+	}
+
+	prov, ok := provider.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("source not found: %s", name)
+	}
+
+	src, err := prov.CreateSource()
+	if err != nil {
+		return nil, err
+	}
+
+	locked := newLockedSource(src)
+	p.sources[name] = locked
+	return locked, nil
+}
+
+// fallbackLifetime is how long the chapters added by the fallback are kept for the pages requests
+const fallbackLifetime = 10 * time.Minute
+
+// fallbackChapters keeps chapters added by the fallback by the source id and url of the manga,
+// so that listing pages of such a chapter does not search other sources again
+type fallbackChapters struct {
+	mu      sync.Mutex
+	entries map[string]*fallbackEntry
+}
+
+type fallbackEntry struct {
+	chapters map[string]*source.Chapter
+	expires  time.Time
+}
+
+func newFallbackChapters() *fallbackChapters {
+	return &fallbackChapters{entries: make(map[string]*fallbackEntry)}
+}
+
+func fallbackKey(manga *source.Manga) string {
+	return manga.Source.ID() + " " + manga.URL
+}
+
+// Store remembers chapters of the manga that download pages from their alternates
+func (f *fallbackChapters) Store(manga *source.Manga, chapters []*source.Chapter) {
+	entry := &fallbackEntry{
+		chapters: make(map[string]*source.Chapter),
+		expires:  time.Now().Add(fallbackLifetime),
+	}
+
+	for _, chapter := range chapters {
+		if chapter.Alternate().IsPresent() {
+			entry.chapters[chapter.URL] = chapter
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for key, e := range f.entries {
+		if time.Now().After(e.expires) {
+			delete(f.entries, key)
+		}
+	}
+
+	f.entries[fallbackKey(manga)] = entry
+}
+
+// Get returns the chapter added by the fallback with the url.
+// The second value is false if the chapters of the manga are not known or have expired
+func (f *fallbackChapters) Get(manga *source.Manga, url string) (chapter *source.Chapter, known bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entry, ok := f.entries[fallbackKey(manga)]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+
+	return entry.chapters[url], true
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"github.com/metafates/mangal/model"
+	"github.com/metafates/mangal/source"
+)
+
+// Provider describes a provider that can be used as a source.
+type Provider struct {
+	// ID of the provider
+	ID string `json:"id" jsonschema:"description=ID of the provider"`
+	// Name of the provider. Used to reference it in other requests
+	Name string `json:"name" jsonschema:"description=Name of the provider. Used to reference it in other requests"`
+	// Custom is true if the provider is a custom lua source
+	Custom bool `json:"custom" jsonschema:"description=Whether the provider is a custom lua source"`
+	// UsesHeadless is true if the provider requires headless chrome
+	UsesHeadless bool `json:"uses_headless" jsonschema:"description=Whether the provider requires headless chrome"`
+}
+
+// MangaRef references a manga of the source.
+// It is usually taken from the search results.
+type MangaRef struct {
+	// Source is the name of the provider that the manga belongs to
+	Source string `json:"source" jsonschema:"required,description=Name of the provider that the manga belongs to"`
+	// Name of the manga
+	Name string `json:"name" jsonschema:"required,description=Name of the manga"`
+	// URL of the manga
+	URL string `json:"url" jsonschema:"required,description=URL of the manga"`
+	// ID of the manga in the source
+	ID string `json:"id" jsonschema:"description=ID of the manga in the source"`
+}
+
+// ChapterRef references a chapter of the manga.
+// It is usually taken from the chapters response.
+type ChapterRef struct {
+	// Name of the chapter
+	Name string `json:"name" jsonschema:"description=Name of the chapter"`
+	// URL of the chapter
+	URL string `json:"url" jsonschema:"required,description=URL of the chapter"`
+	// ID of the chapter in the source
+	ID string `json:"id" jsonschema:"description=ID of the chapter in the source"`
+	// Index of the chapter in the manga
+	Index uint16 `json:"index" jsonschema:"description=Index of the chapter in the manga"`
+	// Volume which the chapter belongs to
+	Volume string `json:"volume" jsonschema:"description=Volume which the chapter belongs to"`
+}
+
+// SearchResult is a search result of a single source.
+type SearchResult struct {
+	// Source that was searched
+	Source string `json:"source" jsonschema:"description=Source that was searched"`
+	// Mangas found
+	Mangas []*source.Manga `json:"mangas" jsonschema:"description=Mangas found"`
+	// Error is set if the search has failed
+	Error string `json:"error,omitempty" jsonschema:"description=Set if the search has failed"`
+}
+
+// MangaRequest is a request that is performed on a manga.
+type MangaRequest struct {
+	// Manga to perform the request on
+	Manga *MangaRef `json:"manga" jsonschema:"required,description=Manga to perform the request on"`
+}
+
+// PagesRequest is a request to get pages of the chapter.
+type PagesRequest struct {
+	// Manga that the chapter belongs to
+	Manga *MangaRef `json:"manga" jsonschema:"required,description=Manga that the chapter belongs to"`
+	// Chapter to get pages of
+	Chapter *ChapterRef `json:"chapter" jsonschema:"required,description=Chapter to get pages of"`
+}
+
+// DownloadRequest is a request to download chapters of the manga.
+// Either Chapters or Selector must be set.
+type DownloadRequest struct {
+	// Manga to download chapters of
+	Manga *MangaRef `json:"manga" jsonschema:"required,description=Manga to download chapters of"`
+	// Chapters to download
+	Chapters []*ChapterRef `json:"chapters,omitempty" jsonschema:"description=Chapters to download"`
+	// Selector is a chapters selector that is used in the inline mode. E.g. "all", "1-5" or "v2,c30-,!@Extra@"
+	Selector string `json:"selector,omitempty" jsonschema:"description=Chapters selector that is used in the inline mode. E.g. all or v2-3"`
+}
+
+// MetadataResponse is a response with the manga metadata.
+type MetadataResponse struct {
+	// Manga that the metadata belongs to
+	Manga *MangaRef `json:"manga" jsonschema:"description=Manga that the metadata belongs to"`
+	// Metadata of the manga
+	Metadata model.MangaMetadata `json:"metadata" jsonschema:"description=Metadata of the manga"`
+}
+
+// Error is returned when the request has failed.
+type Error struct {
+	// Error message
+	Error string `json:"error" jsonschema:"description=Error message"`
+}
@@ -1,19 +1,24 @@
 package source
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/dustin/go-humanize"
 	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/event"
 	"github.com/metafates/mangal/filesystem"
 	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
 	"github.com/metafates/mangal/model"
-	"github.com/metafates/mangal/style"
+	"github.com/metafates/mangal/numbering"
 	"github.com/metafates/mangal/util"
 	"github.com/samber/mo"
 	"github.com/spf13/viper"
@@ -31,6 +36,14 @@ type Chapter struct {
 	ID string `json:"id" jsonschema:"description=ID of the chapter in the source"`
 	// Volume which the chapter belongs to.
 	Volume string `json:"volume" jsonschema:"description=Volume which the chapter belongs to"`
+	// Number of the chapter, if provided by the source. Parsed from the name otherwise.
+	Number *float64 `json:"number,omitempty" jsonschema:"description=Number of the chapter"`
+	// VolumeNumber is the number of the volume, if provided by the source. Parsed from the volume otherwise.
+	VolumeNumber *float64 `json:"volume_number,omitempty" jsonschema:"description=Number of the volume which the chapter belongs to"`
+	// Date when the chapter was uploaded, if provided by the source.
+	Date *time.Time `json:"date,omitempty" jsonschema:"description=Date when the chapter was uploaded"`
+	// Group that translated the chapter, if provided by the source.
+	Group string `json:"group,omitempty" jsonschema:"description=Scanlation group that translated the chapter"`
 	// Manga that the chapter belongs to.
 	Manga *Manga `json:"-"`
 	// Pages of the chapter.
@@ -38,6 +51,8 @@ type Chapter struct {
 
 	isDownloaded mo.Option[bool]
 	size         uint64
+	// alternate is the same chapter from another source that pages are downloaded from
+	alternate *Chapter
 }
 
 func (c *Chapter) ToModel() *model.Chapter {
@@ -95,18 +110,24 @@ func (c *Chapter) String() string {
 
 // DownloadPages downloads the Pages contents of the Chapter.
 // Pages needs to be set before calling this function.
-func (c *Chapter) DownloadPages(temp bool, progress func(string)) (err error) {
+func (c *Chapter) DownloadPages(temp bool, handler event.Handler) (err error) {
+	return c.DownloadPagesContext(context.Background(), temp, handler)
+}
+
+// DownloadPagesContext downloads pages of the chapter, remaining pages are not downloaded when the context is done
+func (c *Chapter) DownloadPagesContext(ctx context.Context, temp bool, handler event.Handler) (err error) {
 	c.size = 0
+	var downloaded int64
 	status := func() string {
 		return fmt.Sprintf(
 			"Downloading %s: %s",
 			c.Name,
-			style.Faint(humanize.Bytes(c.size)),
+			humanize.Bytes(atomic.LoadUint64(&c.size)),
 		)
 	}
 
 	// For CBZ format, we'll download to a temporary directory first
-	isCBZ := viper.GetString(key.FormatsUse) == "cbz"
+	isCBZ := c.Manga.Format() == constant.FormatCBZ
 	var tempDir string
 	if isCBZ {
 		tempDir = filepath.Join(os.TempDir(), fmt.Sprintf("mangal-%d", time.Now().UnixNano()))
@@ -149,15 +170,18 @@ func (c *Chapter) DownloadPages(temp bool, progress func(string)) (err error) {
 			semaphore <- struct{}{} // Acquire
 			defer func() { <-semaphore }() // Release
 
-			progress(fmt.Sprintf("%s [%d/%d]", status(), i+1, len(c.Pages)))
-
 			pagePath := filepath.Join(path, fmt.Sprintf("%s.%s",
 				util.PadZero(fmt.Sprint(i+1), len(fmt.Sprint(len(c.Pages)))),
 				page.Extension,
 			))
 
+			if err := ctx.Err(); err != nil {
+				errChan <- err
+				return
+			}
+
 			// Download page
-			if err := page.Download(); err != nil {
+			if err := page.DownloadContext(ctx); err != nil {
 				errChan <- fmt.Errorf("failed to download page %d: %w", i+1, err)
 				return
 			}
@@ -168,7 +192,14 @@ func (c *Chapter) DownloadPages(temp bool, progress func(string)) (err error) {
 				return
 			}
 
-			c.size += page.Size
+			atomic.AddUint64(&c.size, page.Size)
+			done := atomic.AddInt64(&downloaded, 1)
+
+			e := c.Event(event.PageDownloaded, fmt.Sprintf("%s [%d/%d]", status(), done, len(c.Pages)))
+			e.Page = int(done)
+			e.Pages = len(c.Pages)
+			e.Bytes = atomic.LoadUint64(&c.size)
+			handler.Emit(e)
 		}(i, page)
 	}
 
@@ -186,14 +217,76 @@ func (c *Chapter) DownloadPages(temp bool, progress func(string)) (err error) {
 	return nil
 }
 
-func (c *Chapter) formattedName() (name string) {
-	name = viper.GetString(key.DownloaderChapterNameTemplate)
+// Event creates a new event of the given kind that refers to this chapter
+func (c *Chapter) Event(kind event.Kind, message string) *event.Event {
+	e := &event.Event{
+		Kind:        kind,
+		Message:     message,
+		ChapterID:   c.ID,
+		ChapterName: c.Name,
+	}
+
+	if c.Manga != nil {
+		e.MangaID = c.Manga.ID
+		e.MangaName = c.Manga.Name
+	}
+
+	return e
+}
+
+// ParsedNumber returns the number of the chapter.
+// The number provided by the source is preferred, otherwise it is parsed from the name.
+func (c *Chapter) ParsedNumber() (numbering.Chapter, bool) {
+	parsed, ok := numbering.ParseChapter(c.Name)
+	if c.Number != nil {
+		parsed.Number = *c.Number
+		return parsed, true
+	}
+
+	return parsed, ok
+}
+
+// ParsedVolume returns the number of the volume which the chapter belongs to.
+// The number provided by the source is preferred, otherwise it is parsed from the volume or the name.
+func (c *Chapter) ParsedVolume() (float64, bool) {
+	if c.VolumeNumber != nil {
+		return *c.VolumeNumber, true
+	}
+
+	if c.Volume != "" {
+		return numbering.ParseVolume(c.Volume)
+	}
+
+	return numbering.ParseVolume(c.Name)
+}
+
+// formattedNumber returns the chapter number or the index if the number is unknown
+func (c *Chapter) formattedNumber(padded bool) string {
+	number := float64(c.Index)
+	if parsed, ok := c.ParsedNumber(); ok {
+		number = parsed.Number
+	}
+
+	if padded {
+		return numbering.Pad(number, 4)
+	}
+
+	return numbering.Format(number)
+}
+
+func (c *Chapter) formattedName(template string) (name string) {
+	name = template
 
 	var sourceName string
 	if c.Source() != nil {
 		sourceName = c.Source().Name()
 	}
 
+	var volumeNumber string
+	if volume, ok := c.ParsedVolume(); ok {
+		volumeNumber = numbering.Format(volume)
+	}
+
 	for variable, value := range map[string]string{
 		"manga":          c.Manga.Name,
 		"chapter":        c.Name,
@@ -202,6 +295,10 @@ func (c *Chapter) formattedName() (name string) {
 		"chapters-count": fmt.Sprintf("%d", len(c.Manga.Chapters)),
 		"volume":         c.Volume,
 		"source":         sourceName,
+		"number":         c.formattedNumber(false),
+		"padded-number":  c.formattedNumber(true),
+		"volume-number":  volumeNumber,
+		"group":          c.Group,
 	} {
 		name = strings.ReplaceAll(name, fmt.Sprintf("{%s}", variable), value)
 	}
@@ -209,17 +306,29 @@ func (c *Chapter) formattedName() (name string) {
 	return
 }
 
+// Size is the number of bytes of the downloaded pages.
+func (c *Chapter) Size() uint64 {
+	return atomic.LoadUint64(&c.size)
+}
+
 // SizeHuman is the same as Size but returns a human-readable string.
 func (c *Chapter) SizeHuman() string {
 	return humanize.Bytes(c.size)
 }
 
+// Filename returns the last element of the chapter path with the extension of the format
 func (c *Chapter) Filename() (filename string) {
-	filename = util.SanitizeFilename(c.formattedName())
+	path, err := c.chapterPath()
+	if err != nil {
+		log.Warn(err)
+		path = util.SanitizeFilename(c.formattedName(viper.GetString(key.DownloaderChapterNameTemplate)))
+	}
+
+	filename = filepath.Base(path)
 
 	// plain format assumes that chapter is a directory with images
 	// rather than a single file. So no need to add extension to it
-	if f := viper.GetString(key.FormatsUse); f != constant.FormatPlain {
+	if f := c.Manga.Format(); f != constant.FormatPlain {
 		return filename + "." + f
 	}
 
@@ -231,24 +340,44 @@ func (c *Chapter) IsDownloaded() bool {
 		return c.isDownloaded.MustGet()
 	}
 
-	path, _ := c.path(c.Manga.peekPath())
+	// checks must not fetch the metadata, the chapter can't be found until the manga has it
+	if l, err := Layout(); err == nil && !c.Manga.layoutResolved(l) {
+		return false
+	}
+
+	manga, err := c.Manga.peekPath()
+	if err != nil {
+		log.Warn(err)
+		return false
+	}
+
+	path, err := c.path(manga, false)
+	if err != nil {
+		log.Warn(err)
+		return false
+	}
+
 	exists, _ := filesystem.Api().Exists(path)
 	c.isDownloaded = mo.Some(exists)
 	return exists
 }
 
-func (c *Chapter) path(relativeTo string) (path string, err error) {
-	if c.Volume != "" && viper.GetBool(key.DownloaderCreateVolumeDir) {
-		path = filepath.Join(relativeTo, util.SanitizeFilename(c.Volume))
-		err = filesystem.Api().MkdirAll(path, os.ModePerm)
-		if err != nil {
-			return
-		}
-		path = filepath.Join(path, c.Filename())
+// path joins the manga directory with the chapter path rendered by the layout
+func (c *Chapter) path(relativeTo string, mkdir bool) (path string, err error) {
+	relative, err := c.chapterPath()
+	if err != nil {
 		return
 	}
 
-	path = filepath.Join(relativeTo, c.Filename())
+	path = filepath.Join(relativeTo, relative)
+	if f := c.Manga.Format(); f != constant.FormatPlain {
+		path += "." + f
+	}
+
+	if mkdir {
+		err = filesystem.Api().MkdirAll(filepath.Dir(path), os.ModePerm)
+	}
+
 	return
 }
 
@@ -257,19 +386,73 @@ func (c *Chapter) Path(temp bool) (path string, err error) {
 		return "", fmt.Errorf("Chapter instance is nil")
 	}
 
+	// files are saved by this path, so unlike the lookups it fetches the metadata the layout refers to
+	c.Manga.populateForLayout()
+
 	var manga string
 	manga, err = c.Manga.Path(temp)
 	if err != nil {
 		return
 	}
 
-	return c.path(manga)
+	return c.path(manga, true)
+}
+
+// WithManga returns a copy of the chapter that belongs to the manga.
+// Sources cache chapters by the manga URL, so the same chapters can be shared by manga with different download settings
+func (c *Chapter) WithManga(manga *Manga) *Chapter {
+	copied := *c
+	copied.Manga = manga
+	copied.isDownloaded = mo.None[bool]()
+	copied.size = 0
+	return &copied
 }
 
 func (c *Chapter) Source() Source {
 	return c.Manga.Source
 }
 
+// UseAlternate makes the chapter download its pages from
+// the same chapter of another source.
+func (c *Chapter) UseAlternate(alternate *Chapter) {
+	c.alternate = alternate
+	c.Pages = alternate.Pages
+}
+
+// Alternate returns the chapter of another source that pages are downloaded from, if any
+func (c *Chapter) Alternate() mo.Option[*Chapter] {
+	if c.alternate == nil {
+		return mo.None[*Chapter]()
+	}
+
+	return mo.Some(c.alternate)
+}
+
+// PagesSource returns the source that pages of the chapter are downloaded from
+func (c *Chapter) PagesSource() Source {
+	if c.alternate != nil {
+		return c.alternate.Source()
+	}
+
+	return c.Source()
+}
+
+// notes for the ComicInfo that include the source the pages were downloaded from
+func (c *Chapter) notes() string {
+	const suffix = "https://github.com/metafates/mangal"
+
+	src := c.PagesSource()
+	if src == nil {
+		return "Downloaded with Mangal. " + suffix
+	}
+
+	if c.alternate != nil {
+		return fmt.Sprintf("Downloaded with Mangal from %s (fallback for %s). %s", src.Name(), c.Source().Name(), suffix)
+	}
+
+	return fmt.Sprintf("Downloaded with Mangal from %s. %s", src.Name(), suffix)
+}
+
 func (c *Chapter) ComicInfo() *ComicInfo {
 	var (
 		day, month, year int
@@ -282,6 +465,10 @@ func (c *Chapter) ComicInfo() *ComicInfo {
 			day = t.Day()
 			month = int(t.Month())
 			year = t.Year()
+		} else if c.Date != nil {
+			day = c.Date.Day()
+			month = int(c.Date.Month())
+			year = c.Date.Year()
 		} else {
 			day = c.Manga.Metadata.StartDate.Day
 			month = c.Manga.Metadata.StartDate.Month
@@ -289,13 +476,26 @@ func (c *Chapter) ComicInfo() *ComicInfo {
 		}
 	} // empty dates will be omitted
 
+	// ComicInfo volume is an integer, fractional volumes are rounded down
+	// so that e.g. volume 2.5 is grouped with volume 2
+	var volume int
+	if number, ok := c.ParsedVolume(); ok {
+		volume = int(math.Floor(number))
+	}
+
+	translator := strings.Join(c.Manga.Metadata.Staff.Translation, ",")
+	if c.Group != "" {
+		translator = c.Group
+	}
+
 	return &ComicInfo{
 		XmlnsXsd: "http://www.w3.org/2001/XMLSchema",
 		XmlnsXsi: "http://www.w3.org/2001/XMLSchema-instance",
 
 		Title:      c.Name,
 		Series:     c.Manga.Name,
-		Number:     int(c.Index),
+		Number:     c.formattedNumber(false),
+		Volume:     volume,
 		Web:        c.URL,
 		Genre:      strings.Join(c.Manga.Metadata.Genres, ","),
 		PageCount:  len(c.Pages),
@@ -308,9 +508,11 @@ func (c *Chapter) ComicInfo() *ComicInfo {
 		Writer:     strings.Join(c.Manga.Metadata.Staff.Story, ","),
 		Penciller:  strings.Join(c.Manga.Metadata.Staff.Art, ","),
 		Letterer:   strings.Join(c.Manga.Metadata.Staff.Lettering, ","),
-		Translator: strings.Join(c.Manga.Metadata.Staff.Translation, ","),
+		Translator: translator,
 		Tags:       strings.Join(c.Manga.Metadata.Tags, ","),
-		Notes:      "Downloaded with Mangal. https://github.com/metafates/mangal",
+		Notes:      c.notes(),
 		Manga:      "YesAndRightToLeft",
+
+		LanguageISO: c.Manga.Metadata.Language,
 	}
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/metafates/mangal/util"
 	. "github.com/smartystreets/goconvey/convey"
 	"github.com/spf13/viper"
+	"path/filepath"
 	"testing"
 )
 
@@ -52,3 +53,34 @@ func TestChapter_ComicInfoXML(t *testing.T) {
 		})
 	})
 }
+
+func TestChapter_Path(t *testing.T) {
+	Convey("Given a chapter with a volume", t, func() {
+		viper.Set(key.DownloaderChapterNameTemplate, "[{padded-index}] {chapter}")
+		viper.Set(key.DownloaderCreateMangaDir, true)
+		viper.Set(key.DownloaderCreateVolumeDir, true)
+		defer viper.Set(key.DownloaderCreateVolumeDir, false)
+
+		Convey("When layout is not set", func() {
+			viper.Set(key.DownloaderLayout, "")
+			path, err := testChapter.Path(false)
+
+			Convey("Then the path should be built from the directory options", func() {
+				So(err, ShouldBeNil)
+				So(path, ShouldEndWith, filepath.Join(testManga.Dirname(), "1", util.SanitizeFilename("[0001] test chapter")+".pdf"))
+			})
+		})
+
+		Convey("When layout is set", func() {
+			viper.Set(key.DownloaderLayout, `{{ .Manga.Source }}/{{ .Manga.Name }}/Vol {{ pad 2 .Chapter.VolumeNumber }}/{{ .Chapter.Name }}`)
+			defer viper.Set(key.DownloaderLayout, "")
+			path, err := testChapter.Path(false)
+
+			Convey("Then the path should be rendered by the layout", func() {
+				So(err, ShouldBeNil)
+				So(path, ShouldEndWith, filepath.Join("test", testManga.Name, "Vol 01", "test chapter.pdf"))
+				So(testChapter.Filename(), ShouldEqual, "test chapter.pdf")
+			})
+		})
+	})
+}
@@ -10,7 +10,8 @@ type ComicInfo struct {
 	// General
 	Title      string `xml:"Title,omitempty"`
 	Series     string `xml:"Series,omitempty"`
-	Number     int    `xml:"Number,omitempty"`
+	Number     string `xml:"Number,omitempty"`
+	Volume     int    `xml:"Volume,omitempty"` // integer in the schema, fractional volumes are rounded down
 	Web        string `xml:"Web,omitempty"`
 	Genre      string `xml:"Genre,omitempty"`
 	PageCount  int    `xml:"PageCount,omitempty"`
@@ -27,4 +28,7 @@ type ComicInfo struct {
 	Tags       string `xml:"Tags,omitempty"`
 	Notes      string `xml:"Notes,omitempty"`
 	Manga      string `xml:"Manga,omitempty"`
+
+	// Language
+	LanguageISO string `xml:"LanguageISO,omitempty"`
 }
@@ -0,0 +1,78 @@
+package source
+
+import (
+	"errors"
+
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/model"
+)
+
+// populateFromSource fills metadata with the details provided by the source.
+// Returns false if the source can't provide details
+func (m *Manga) populateFromSource() bool {
+	details, ok := m.Source.(DetailsProvider)
+	if !ok {
+		return false
+	}
+
+	if err := details.MangaDetails(m); err != nil {
+		if !errors.Is(err, ErrNoDetails) {
+			log.Warn("Failed to get details from the source:", err)
+		}
+
+		return false
+	}
+
+	m.populated = true
+	return true
+}
+
+// mergeMetadata fills empty fields of the metadata with the fields of another one
+func mergeMetadata(metadata *model.MangaMetadata, other model.MangaMetadata) {
+	fillString := func(field *string, value string) {
+		if *field == "" {
+			*field = value
+		}
+	}
+
+	fillList := func(field *[]string, value []string) {
+		if len(*field) == 0 && len(value) > 0 {
+			*field = value
+		}
+	}
+
+	fillInt := func(field *int, value int) {
+		if *field == 0 {
+			*field = value
+		}
+	}
+
+	fillString(&metadata.Summary, other.Summary)
+	fillString(&metadata.Cover.ExtraLarge, other.Cover.ExtraLarge)
+	fillString(&metadata.Cover.Large, other.Cover.Large)
+	fillString(&metadata.Cover.Medium, other.Cover.Medium)
+	fillString(&metadata.BannerImage, other.BannerImage)
+	fillString(&metadata.Publisher, other.Publisher)
+	fillString(&metadata.Language, other.Language)
+
+	if (metadata.Status == "" || metadata.Status == "Unknown") && other.Status != "" {
+		metadata.Status = other.Status
+	}
+
+	fillList(&metadata.Genres, other.Genres)
+	fillList(&metadata.Tags, other.Tags)
+	fillList(&metadata.Characters, other.Characters)
+	fillList(&metadata.Synonyms, other.Synonyms)
+	fillList(&metadata.URLs, other.URLs)
+	fillList(&metadata.Staff.Story, other.Staff.Story)
+	fillList(&metadata.Staff.Art, other.Staff.Art)
+	fillList(&metadata.Staff.Translation, other.Staff.Translation)
+	fillList(&metadata.Staff.Lettering, other.Staff.Lettering)
+
+	fillInt(&metadata.Chapters, other.Chapters)
+	fillInt(&metadata.Volumes, other.Volumes)
+
+	if metadata.StartDate.Year == 0 {
+		metadata.StartDate = other.StartDate
+	}
+}
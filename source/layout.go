@@ -0,0 +1,190 @@
+package source
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/metafates/mangal/event"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/layout"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/model"
+	"github.com/spf13/viper"
+)
+
+// LayoutManga is the manga available in the layout as .Manga
+type LayoutManga struct {
+	// Name of the manga
+	Name string
+	// ID of the manga in the source
+	ID string
+	// URL of the manga page
+	URL string
+	// Source name
+	Source string
+	// Chapters is the total number of chapters
+	Chapters int
+	// Metadata of the manga, empty unless it was fetched
+	Metadata model.MangaMetadata
+}
+
+// LayoutChapter is the chapter available in the layout as .Chapter
+type LayoutChapter struct {
+	// Name of the chapter
+	Name string
+	// FormattedName is the name by downloader.chapter_name_template
+	FormattedName string
+	// ID of the chapter in the source
+	ID string
+	// URL of the chapter
+	URL string
+	// Index of the chapter in the manga
+	Index int
+	// Number of the chapter, index is used if unknown
+	Number float64
+	// Volume of the chapter as it is given by the source
+	Volume string
+	// VolumeNumber is the number of the volume, 0 if unknown
+	VolumeNumber float64
+	// Group that translated the chapter
+	Group string
+	// Date when the chapter was uploaded, zero if unknown
+	Date time.Time
+}
+
+// LayoutData is the data that the layout is rendered with
+type LayoutData struct {
+	Manga   LayoutManga
+	Chapter LayoutChapter
+}
+
+var layouts sync.Map
+
+// Layout returns the parsed downloader.layout.
+// If it is empty, the layout is built from downloader.create_manga_dir and downloader.create_volume_dir
+func Layout() (*layout.Layout, error) {
+	text := viper.GetString(key.DownloaderLayout)
+	if text == "" {
+		text = legacyLayout()
+	}
+
+	if cached, ok := layouts.Load(text); ok {
+		return cached.(*layout.Layout), nil
+	}
+
+	parsed, err := layout.Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", key.DownloaderLayout, err)
+	}
+
+	layouts.Store(text, parsed)
+	return parsed, nil
+}
+
+// legacyLayout is the layout that matches paths of the previous versions
+func legacyLayout() string {
+	var segments []string
+
+	if viper.GetBool(key.DownloaderCreateMangaDir) {
+		segments = append(segments, `{{ sanitize .Manga.Name }}`)
+	}
+
+	if viper.GetBool(key.DownloaderCreateVolumeDir) {
+		segments = append(segments, `{{ with .Chapter.Volume }}{{ sanitize . }}{{ end }}`)
+	}
+
+	segments = append(segments, `{{ sanitize .Chapter.FormattedName }}`)
+	return strings.Join(segments, "/")
+}
+
+func (m *Manga) layoutData() LayoutData {
+	data := LayoutData{
+		Manga: LayoutManga{
+			Name:     m.Name,
+			ID:       m.ID,
+			URL:      m.URL,
+			Chapters: len(m.Chapters),
+			Metadata: m.Metadata,
+		},
+	}
+
+	if m.Source != nil {
+		data.Manga.Source = m.Source.Name()
+	}
+
+	return data
+}
+
+func (c *Chapter) layoutData(nameTemplate string) LayoutData {
+	data := c.Manga.layoutData()
+	data.Chapter = LayoutChapter{
+		Name:          c.Name,
+		FormattedName: c.formattedName(nameTemplate),
+		ID:            c.ID,
+		URL:           c.URL,
+		Index:         int(c.Index),
+		Number:        float64(c.Index),
+		Volume:        c.Volume,
+		Group:         c.Group,
+	}
+
+	if parsed, ok := c.ParsedNumber(); ok {
+		data.Chapter.Number = parsed.Number
+	}
+
+	if volume, ok := c.ParsedVolume(); ok {
+		data.Chapter.VolumeNumber = volume
+	}
+
+	if c.Date != nil {
+		data.Chapter.Date = *c.Date
+	}
+
+	return data
+}
+
+// RenderPath renders the manga directory and the chapter path relative to it by the layout, without the extension.
+// Name template is used for .Chapter.FormattedName
+func (c *Chapter) RenderPath(l *layout.Layout, nameTemplate string) (manga, chapter string, err error) {
+	return l.Render(c.layoutData(nameTemplate))
+}
+
+// PopulateForLayout fetches the metadata of the manga if the layout refers to it and fetching is enabled.
+// Paths of the downloads and the lookups must be rendered with the same metadata, so it is tried once
+func (m *Manga) PopulateForLayout(l *layout.Layout, handler event.Handler) {
+	if m.layoutResolved(l) {
+		return
+	}
+
+	m.layoutPopulated = true
+	if err := m.PopulateMetadata(handler); err != nil {
+		log.Warn(fmt.Sprintf("failed to populate metadata: %v", err))
+	}
+}
+
+// populateForLayout is PopulateForLayout with the configured layout, used before the paths are created
+func (m *Manga) populateForLayout() {
+	if l, err := Layout(); err == nil {
+		m.PopulateForLayout(l, nil)
+	}
+}
+
+// layoutResolved checks if the layout can be rendered without fetching the metadata,
+// so that its paths are the same as the ones of the downloads
+func (m *Manga) layoutResolved(l *layout.Layout) bool {
+	return m.populated || m.layoutPopulated || !l.Uses("Manga", "Metadata") || !viper.GetBool(key.MetadataFetchAnilist)
+}
+
+// chapterPath renders the chapter path relative to the manga directory without the extension.
+// Metadata is not fetched here, the layout is rendered with what the manga already has
+func (c *Chapter) chapterPath() (string, error) {
+	l, err := Layout()
+	if err != nil {
+		return "", err
+	}
+
+	_, path, err := c.RenderPath(l, viper.GetString(key.DownloaderChapterNameTemplate))
+	return path, err
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/metafates/mangal/anilist"
 	"github.com/metafates/mangal/db"
+	"github.com/metafates/mangal/event"
 	"github.com/metafates/mangal/filesystem"
 	"github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/log"
@@ -44,10 +45,16 @@ type Manga struct {
 	// Anilist is the closest anilist match
 	Anilist  mo.Option[*anilist.Manga] `json:"-"`
 	Metadata model.MangaMetadata `json:"metadata"`
+	// DownloadPath overrides downloader.path for the chapters of this manga if set
+	DownloadPath string `json:"-"`
+	// DownloadFormat overrides formats.use for the chapters of this manga if set
+	DownloadFormat string `json:"-"`
 	
 	cachedTempPath  string
 	populated       bool
 	coverDownloaded bool
+	// layoutPopulated is set when the metadata was fetched for the layout, even if it has failed
+	layoutPopulated bool
 }
 
 func (m *Manga) ToModel() *model.Manga {
@@ -81,14 +88,34 @@ func (m *Manga) Dirname() string {
 	return util.SanitizeFilename(m.Name)
 }
 
-func (m *Manga) peekPath() string {
+// Format returns the format that chapters of the manga are downloaded in
+func (m *Manga) Format() string {
+	if m.DownloadFormat != "" {
+		return m.DownloadFormat
+	}
+
+	return viper.GetString(key.FormatsUse)
+}
+
+// peekPath returns the manga directory by the layout without creating it.
+// Metadata is not fetched here, the layout is rendered with what the manga already has
+func (m *Manga) peekPath() (string, error) {
 	path := where.Downloads()
+	if m.DownloadPath != "" {
+		path = m.DownloadPath
+	}
+
+	l, err := Layout()
+	if err != nil {
+		return "", err
+	}
 
-	if viper.GetBool(key.DownloaderCreateMangaDir) {
-		path = filepath.Join(path, m.Dirname())
+	dir, err := l.RenderManga(m.layoutData())
+	if err != nil {
+		return "", err
 	}
 
-	return path
+	return filepath.Join(path, dir), nil
 }
 
 func (m *Manga) Path(temp bool) (path string, err error) {
@@ -102,7 +129,13 @@ func (m *Manga) Path(temp bool) (path string, err error) {
 		return
 	}
 
-	path = m.peekPath()
+	m.populateForLayout()
+
+	path, err = m.peekPath()
+	if err != nil {
+		return
+	}
+
 	_ = filesystem.Api().MkdirAll(path, os.ModePerm)
 	return
 }
@@ -123,14 +156,14 @@ func (m *Manga) GetCover() (string, error) {
 	return "", fmt.Errorf("no cover found")
 }
 
-func (m *Manga) DownloadCover(overwrite bool, path string, progress func(string)) error {
+func (m *Manga) DownloadCover(overwrite bool, path string, handler event.Handler) error {
 	if m.coverDownloaded {
 		return nil
 	}
 	m.coverDownloaded = true
 
 	log.Info("Downloading cover for ", m.Name)
-	progress("Downloading cover")
+	handler.Emit(m.Event(event.Progress, "Downloading cover"))
 
 	cover, err := m.GetCover()
 	if err != nil {
@@ -184,6 +217,10 @@ func (m *Manga) DownloadCover(overwrite bool, path string, progress func(string)
 	}
 
 	log.Info("Cover downloaded")
+	e := m.Event(event.CoverDownloaded, "Cover downloaded")
+	e.Path = path
+	e.Bytes = uint64(len(data))
+	handler.Emit(e)
 	return nil
 }
 
@@ -204,15 +241,36 @@ func (m *Manga) BindWithAnilist() error {
 	return nil
 }
 
-func (m *Manga) PopulateMetadata(progress func(string)) error {
+// BindTo binds the manga with the given Anilist manga and drops the fetched metadata,
+// so that it is fetched again from the new binding
+func (m *Manga) BindTo(manga *anilist.Manga) {
+	m.Anilist = mo.Some(manga)
+	m.populated = false
+}
+
+// PopulateMetadata fetches metadata of the manga from Anilist, MangaDex or the database
+func (m *Manga) PopulateMetadata(handler event.Handler) error {
 	if m.populated {
 		return nil
 	}
 
-	if progress != nil {
-		progress("Getting metadata...")
+	handler.Emit(m.Event(event.Progress, "Getting metadata..."))
+
+	if err := m.populateMetadata(); err != nil {
+		e := m.Event(event.Error, "Failed to get metadata")
+		e.Error = err.Error()
+		handler.Emit(e)
+		return err
 	}
 
+	handler.Emit(m.Event(event.MetadataFetched, "Metadata fetched"))
+	return nil
+}
+
+func (m *Manga) populateMetadata() error {
+	// Metadata provided by the source along with the manga, e.g. in search results
+	provided := m.Metadata
+
 	// Initialize metadata fields with defaults
 	m.Metadata.Status = "Unknown"
 	m.Metadata.Format = "MANGA"
@@ -229,7 +287,15 @@ func (m *Manga) PopulateMetadata(progress func(string)) error {
 	// Try to bind with Anilist first
 	if err := m.BindWithAnilist(); err != nil {
 		log.Warn("Failed to bind with Anilist:", err)
-		
+
+		// Whatever the source provided fills the gaps left by the fallbacks
+		defer mergeMetadata(&m.Metadata, provided)
+
+		// Prefer details from the source itself
+		if m.populateFromSource() {
+			return nil
+		}
+
 		// Try MangaDex as fallback
 		log.Info("Trying MangaDex as fallback...")
 		client := mangadex.NewClient()
@@ -590,6 +656,16 @@ func (m *Manga) PopulateMetadata(progress func(string)) error {
 	return nil
 }
 
+// Event creates a new event of the given kind that refers to this manga
+func (m *Manga) Event(kind event.Kind, message string) *event.Event {
+	return &event.Event{
+		Kind:      kind,
+		Message:   message,
+		MangaID:   m.ID,
+		MangaName: m.Name,
+	}
+}
+
 func (m *Manga) SaveMetadata() error {
 	seriesJSON := m.SeriesJSON()
 
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/metafates/mangal/database"
+	"github.com/metafates/mangal/event"
 	"github.com/metafates/mangal/filesystem"
 	"github.com/metafates/mangal/util"
 	"github.com/samber/lo"
@@ -114,7 +115,7 @@ func TestManga_Path(t *testing.T) {
 func TestManga_PopulateMetadata(t *testing.T) {
 	Convey("Given a manga", t, func() {
 		Convey("When PopulateMetadata is called", func() {
-			err := testManga.PopulateMetadata(func(string) {})
+			err := testManga.PopulateMetadata(event.Discard)
 			Convey("It should not return an error", func() {
 				So(err, ShouldBeNil)
 
@@ -2,6 +2,7 @@ package source
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"crypto/tls"
 	"errors"
@@ -64,8 +65,8 @@ func NewPage(chapter *Chapter, modelPage *model.Page) *Page {
 	}
 }
 
-func (p *Page) request() (*http.Request, error) {
-	req, err := http.NewRequest(http.MethodGet, p.URL, nil)
+func (p *Page) request(ctx context.Context) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.URL, nil)
 	if err != nil {
 		log.Error(err)
 		return nil, err
@@ -78,6 +79,11 @@ func (p *Page) request() (*http.Request, error) {
 
 // Download Page contents.
 func (p *Page) Download() error {
+	return p.DownloadContext(context.Background())
+}
+
+// DownloadContext downloads Page contents, the request is aborted when the context is done.
+func (p *Page) DownloadContext(ctx context.Context) error {
 	if p.URL == "" {
 		log.Warnf("Page #%d has no URL", p.Index)
 		return nil
@@ -85,7 +91,7 @@ func (p *Page) Download() error {
 
 	log.Tracef("Downloading page #%d (%s)", p.Index, p.URL)
 
-	req, err := p.request()
+	req, err := p.request(ctx)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,39 @@
+package source
+
+import "sort"
+
+// SortChapters sorts chapters by their volume and chapter numbers.
+// Chapters without a volume go after all volumes and chapters without a number after the numbered ones,
+// so that the order is total. Chapters with the same numbers keep their relative order by index.
+// Indices are updated to match the new order.
+func SortChapters(chapters []*Chapter) {
+	sort.SliceStable(chapters, func(i, j int) bool {
+		a, b := chapters[i], chapters[j]
+
+		aVolume, aHasVolume := a.ParsedVolume()
+		bVolume, bHasVolume := b.ParsedVolume()
+		if aHasVolume != bHasVolume {
+			return aHasVolume
+		}
+
+		if aHasVolume && aVolume != bVolume {
+			return aVolume < bVolume
+		}
+
+		aNumber, aHasNumber := a.ParsedNumber()
+		bNumber, bHasNumber := b.ParsedNumber()
+		if aHasNumber != bHasNumber {
+			return aHasNumber
+		}
+
+		if aHasNumber && aNumber != bNumber {
+			return aNumber.Less(bNumber)
+		}
+
+		return a.Index < b.Index
+	})
+
+	for i, chapter := range chapters {
+		chapter.Index = uint16(i + 1)
+	}
+}
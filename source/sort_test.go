@@ -0,0 +1,62 @@
+package source
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSortChapters(t *testing.T) {
+	Convey("Given chapters with and without volumes and numbers", t, func() {
+		names := func(chapters []*Chapter) []string {
+			result := make([]string, len(chapters))
+			for i, chapter := range chapters {
+				result[i] = chapter.Name
+			}
+
+			return result
+		}
+
+		newChapters := func() []*Chapter {
+			return []*Chapter{
+				{Name: "Chapter 3", Index: 1},
+				{Name: "Vol.2 Chapter 2", Index: 2},
+				{Name: "Oneshot", Index: 3},
+				{Name: "Vol.1 Chapter 1", Index: 4},
+				{Name: "Chapter 1.5", Index: 5},
+			}
+		}
+
+		Convey("When sorting them", func() {
+			chapters := newChapters()
+			SortChapters(chapters)
+
+			Convey("Then chapters without a volume should go after the volumes", func() {
+				So(names(chapters), ShouldResemble, []string{
+					"Vol.1 Chapter 1",
+					"Vol.2 Chapter 2",
+					"Chapter 1.5",
+					"Chapter 3",
+					"Oneshot",
+				})
+				So(chapters[0].Index, ShouldEqual, 1)
+				So(chapters[4].Index, ShouldEqual, 5)
+			})
+		})
+
+		Convey("When sorting them in another order", func() {
+			chapters := newChapters()
+			for i, j := 0, len(chapters)-1; i < j; i, j = i+1, j-1 {
+				chapters[i], chapters[j] = chapters[j], chapters[i]
+			}
+
+			SortChapters(chapters)
+
+			Convey("Then the order should be the same", func() {
+				expected := newChapters()
+				SortChapters(expected)
+				So(names(chapters), ShouldResemble, names(expected))
+			})
+		})
+	})
+}
@@ -1,5 +1,10 @@
 package source
 
+import (
+	"context"
+	"errors"
+)
+
 // Source is the interface that all sources must implement.
 type Source interface {
 	Name() string
@@ -8,3 +13,25 @@ type Source interface {
 	PagesOf(chapter *Chapter) ([]*Page, error)
 	ID() string
 }
+
+// ErrNoDetails is returned by the DetailsProvider that can't provide details of the manga
+var ErrNoDetails = errors.New("source does not provide manga details")
+
+// DetailsProvider is implemented by sources that can fetch manga details themselves.
+// Details are used when the manga could not be matched with Anilist.
+type DetailsProvider interface {
+	// MangaDetails fills metadata of the manga.
+	MangaDetails(manga *Manga) error
+}
+
+// MangaGetter is implemented by sources that can get the manga without searching.
+type MangaGetter interface {
+	// GetManga returns the manga by its URL or ID.
+	GetManga(id string) (*Manga, error)
+}
+
+// ContextSearcher is implemented by sources that can stop the search when the context is done.
+type ContextSearcher interface {
+	// SearchContext searches for mangas until the context is done.
+	SearchContext(ctx context.Context, query string) ([]*Manga, error)
+}
@@ -0,0 +1,23 @@
+package style
+
+import (
+	"fmt"
+
+	"github.com/dustin/go-humanize"
+	"github.com/metafates/mangal/color"
+	"github.com/metafates/mangal/event"
+)
+
+// Event returns the message of the event for the terminal.
+// Messages of the events are plain, the lines of the downloaded pages and the conversion
+// are built here from the event fields with the size and the format highlighted
+func Event(e *event.Event) string {
+	switch {
+	case e.Kind == event.PageDownloaded:
+		return fmt.Sprintf("Downloading %s: %s [%d/%d]", e.ChapterName, Faint(humanize.Bytes(e.Bytes)), e.Page, e.Pages)
+	case e.Kind == event.Progress && e.Format != "":
+		return fmt.Sprintf("Converting %d pages to %s (%s)", e.Pages, Fg(color.Yellow)(e.Format), humanize.Bytes(e.Bytes))
+	default:
+		return e.Message
+	}
+}
@@ -0,0 +1,206 @@
+package theme
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/metafates/mangal/color"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/style"
+	"github.com/metafates/mangal/where"
+	"github.com/samber/lo"
+	"github.com/spf13/viper"
+)
+
+// Default is the name of the theme used when none is configured
+const Default = "default"
+
+//go:embed themes/*.toml
+var builtins embed.FS
+
+// Title is the style of the title of the list or the view
+type Title struct {
+	Foreground lipgloss.Color `mapstructure:"foreground"`
+	Background lipgloss.Color `mapstructure:"background"`
+}
+
+// Render renders the title with the padding
+func (t Title) Render(s string) string {
+	return t.Style().Render(s)
+}
+
+// Style of the title, used by the lists
+func (t Title) Style() lipgloss.Style {
+	return style.NewColored(t.Foreground, t.Background).Padding(0, 1)
+}
+
+// Theme is the palette used by the TUI and the colored output
+type Theme struct {
+	Name string `mapstructure:"-"`
+
+	Colors struct {
+		Red      lipgloss.Color `mapstructure:"red"`
+		Green    lipgloss.Color `mapstructure:"green"`
+		Yellow   lipgloss.Color `mapstructure:"yellow"`
+		Blue     lipgloss.Color `mapstructure:"blue"`
+		Purple   lipgloss.Color `mapstructure:"purple"`
+		Cyan     lipgloss.Color `mapstructure:"cyan"`
+		White    lipgloss.Color `mapstructure:"white"`
+		Black    lipgloss.Color `mapstructure:"black"`
+		HiRed    lipgloss.Color `mapstructure:"hi_red"`
+		HiGreen  lipgloss.Color `mapstructure:"hi_green"`
+		HiYellow lipgloss.Color `mapstructure:"hi_yellow"`
+		HiBlue   lipgloss.Color `mapstructure:"hi_blue"`
+		HiPurple lipgloss.Color `mapstructure:"hi_purple"`
+		HiCyan   lipgloss.Color `mapstructure:"hi_cyan"`
+		HiWhite  lipgloss.Color `mapstructure:"hi_white"`
+		HiBlack  lipgloss.Color `mapstructure:"hi_black"`
+		Orange   lipgloss.Color `mapstructure:"orange"`
+	} `mapstructure:"colors"`
+
+	UI struct {
+		// Accent is the color of the selected list items
+		Accent lipgloss.Color `mapstructure:"accent"`
+		// Text is the color of the other list items
+		Text    lipgloss.Color `mapstructure:"text"`
+		Spinner lipgloss.Color `mapstructure:"spinner"`
+	} `mapstructure:"ui"`
+
+	Titles struct {
+		Default  Title `mapstructure:"default"`
+		Error    Title `mapstructure:"error"`
+		Install  Title `mapstructure:"install"`
+		History  Title `mapstructure:"history"`
+		Sources  Title `mapstructure:"sources"`
+		Mangas   Title `mapstructure:"mangas"`
+		Chapters Title `mapstructure:"chapters"`
+		Anilist  Title `mapstructure:"anilist"`
+	} `mapstructure:"titles"`
+}
+
+var current *Theme
+
+// Current returns the applied theme, the default one if none was applied
+func Current() *Theme {
+	if current == nil {
+		current = defaultTheme()
+	}
+
+	return current
+}
+
+func defaultTheme() *Theme {
+	theme := &Theme{Name: Default}
+
+	// default theme is embedded, it can't fail
+	data, err := builtins.ReadFile("themes/" + Default + ".toml")
+	if err != nil {
+		panic(err)
+	}
+
+	if err = decode(data, theme); err != nil {
+		panic(err)
+	}
+
+	return theme
+}
+
+// decode reads the theme file over the theme, so that omitted colors stay the same
+func decode(data []byte, theme *Theme) error {
+	v := viper.New()
+	v.SetConfigType("toml")
+
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return err
+	}
+
+	return v.Unmarshal(theme)
+}
+
+// Builtin returns the names of the embedded themes
+func Builtin() []string {
+	entries, _ := builtins.ReadDir("themes")
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".toml"))
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Available returns the names of the embedded themes and the theme files from the themes directory
+func Available() []string {
+	names := Builtin()
+
+	entries, err := filesystem.Api().ReadDir(where.Themes())
+	if err != nil {
+		return names
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".toml" {
+			continue
+		}
+
+		name := strings.TrimSuffix(entry.Name(), ".toml")
+		if !lo.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Load loads the theme by its name or the path to the theme file.
+// Theme files from the themes directory take precedence over the embedded ones
+func Load(name string) (*Theme, error) {
+	if name == "" {
+		name = Default
+	}
+
+	path := name
+	if filepath.Ext(path) != ".toml" {
+		path = filepath.Join(where.Themes(), name+".toml")
+	}
+
+	data, err := filesystem.Api().ReadFile(path)
+	if err != nil {
+		var builtinErr error
+		data, builtinErr = builtins.ReadFile("themes/" + name + ".toml")
+		if builtinErr != nil {
+			return nil, fmt.Errorf("theme %q not found, available themes: %s", name, strings.Join(Available(), ", "))
+		}
+	}
+
+	theme := defaultTheme()
+	theme.Name = strings.TrimSuffix(filepath.Base(name), ".toml")
+
+	if err = decode(data, theme); err != nil {
+		return nil, fmt.Errorf("theme %q: %w", name, err)
+	}
+
+	return theme, nil
+}
+
+// Apply sets the palette of the color and the style packages to the theme
+func Apply(theme *Theme) {
+	current = theme
+
+	c := theme.Colors
+	color.Red, color.Green, color.Yellow, color.Blue = c.Red, c.Green, c.Yellow, c.Blue
+	color.Purple, color.Cyan, color.White, color.Black = c.Purple, c.Cyan, c.White, c.Black
+	color.HiRed, color.HiGreen, color.HiYellow, color.HiBlue = c.HiRed, c.HiGreen, c.HiYellow, c.HiBlue
+	color.HiPurple, color.HiCyan, color.HiWhite, color.HiBlack = c.HiPurple, c.HiCyan, c.HiWhite, c.HiBlack
+	color.Orange = c.Orange
+
+	style.Title = theme.Titles.Default.Style().Render
+	style.ErrorTitle = theme.Titles.Error.Style().Render
+}
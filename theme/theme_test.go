@@ -0,0 +1,80 @@
+package theme
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/metafates/mangal/color"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/where"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTheme(t *testing.T) {
+	filesystem.SetMemMapFs()
+	defer filesystem.SetOsFs()
+	defer Apply(defaultTheme())
+
+	Convey("Given the built-in themes", t, func() {
+		Convey("Then each of them should be loaded", func() {
+			for _, name := range Builtin() {
+				theme, err := Load(name)
+				So(err, ShouldBeNil)
+				So(theme.Name, ShouldEqual, name)
+				So(theme.Colors.Red, ShouldNotBeEmpty)
+				So(theme.Titles.Chapters.Background, ShouldNotBeEmpty)
+			}
+		})
+
+		Convey("Then the default theme should keep the default palette", func() {
+			theme, err := Load("")
+			So(err, ShouldBeNil)
+			So(theme.Colors.Red, ShouldEqual, lipgloss.Color("1"))
+			So(theme.Colors.Orange, ShouldEqual, lipgloss.Color("#ffb703"))
+		})
+	})
+
+	Convey("Given the theme file that overrides some colors", t, func() {
+		path := filepath.Join(where.Themes(), "mine.toml")
+		So(filesystem.Api().WriteFile(path, []byte(`
+[colors]
+red = "#ff0000"
+
+[titles]
+chapters = { background = "#00ff00" }
+`), 0644), ShouldBeNil)
+
+		theme, err := Load("mine")
+		So(err, ShouldBeNil)
+
+		Convey("Then other colors should be taken from the default theme", func() {
+			So(theme.Colors.Red, ShouldEqual, lipgloss.Color("#ff0000"))
+			So(theme.Colors.Green, ShouldEqual, lipgloss.Color("2"))
+			So(theme.Titles.Chapters.Background, ShouldEqual, lipgloss.Color("#00ff00"))
+			So(theme.Titles.Chapters.Foreground, ShouldEqual, lipgloss.Color("#000814"))
+		})
+
+		Convey("Then it should be listed", func() {
+			So(Available(), ShouldContain, "mine")
+		})
+
+		Convey("When it is applied", func() {
+			Apply(theme)
+
+			Convey("Then the palette should be changed", func() {
+				So(color.Red, ShouldEqual, lipgloss.Color("#ff0000"))
+				So(Current(), ShouldEqual, theme)
+			})
+		})
+	})
+
+	Convey("Given unknown theme", t, func() {
+		_, err := Load("unknown")
+
+		Convey("Then error should list available themes", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "gruvbox")
+		})
+	})
+}
@@ -10,13 +10,14 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/metafates/mangal/anilist"
-	"github.com/metafates/mangal/color"
+	"github.com/metafates/mangal/downloader"
 	"github.com/metafates/mangal/history"
 	"github.com/metafates/mangal/installer"
 	key2 "github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/provider"
+	"github.com/metafates/mangal/search"
 	"github.com/metafates/mangal/source"
-	"github.com/metafates/mangal/style"
+	"github.com/metafates/mangal/theme"
 	"github.com/metafates/mangal/util"
 	"github.com/samber/lo"
 	"github.com/samber/mo"
@@ -49,20 +50,29 @@ type statefulBubble struct {
 	selectedManga     *source.Manga
 	selectedChapters  map[*source.Chapter]struct{} // mathematical set
 
+	// chaptersSort is the order of the chapters list
+	chaptersSort chapterSort
+	// readingProgress is the progress of the selected manga
+	readingProgress *history.Progress
+	// scrapers is the listing shown in the install state, dependencies are resolved from it
+	scrapers []*installer.Scraper
+
 	scrapersLoadedChannel       chan []*installer.Scraper
 	scraperInstalledChannel     chan *installer.Scraper
 	sourcesLoadedChannel        chan []source.Source
-	foundMangasChannel          chan []*source.Manga
+	foundMangasChannel          chan []*search.Group
 	foundChaptersChannel        chan []*source.Chapter
 	fetchedAnilistMangasChannel chan []*anilist.Manga
 	closestAnilistMangaChannel  chan *anilist.Manga
 	chapterReadChannel          chan struct{}
-	chapterDownloadChannel      chan struct{}
 	errorChannel                chan error
 
 	progressStatus string
 
-	chaptersToDownload util.Stack[*source.Chapter]
+	// downloads are the chapters downloaded in the background
+	downloads *downloader.Manager
+	// downloadsCursor is the index of the selected job in the downloads view
+	downloadsCursor int
 
 	currentDownloadingChapter *source.Chapter
 	lastError                 error
@@ -70,10 +80,10 @@ type statefulBubble struct {
 	width, height int
 	errorPlot     string
 
-	failedChapters   []*source.Chapter
-	succededChapters []*source.Chapter
-
 	searchSuggestion mo.Option[string]
+
+	// details of the selected manga, nil while they are fetched
+	details *mangaDetails
 }
 
 func (b *statefulBubble) raiseError(err error) {
@@ -85,6 +95,11 @@ func (b *statefulBubble) raiseError(err error) {
 func (b *statefulBubble) setState(s state) {
 	b.state = s
 	b.keymap.setState(s)
+
+	// lists have their own copies of the keys, which may be remapped for the state
+	for _, l := range []*list.Model{&b.scrapersInstallC, &b.historyC, &b.sourcesC, &b.mangasC, &b.chaptersC, &b.anilistC} {
+		l.KeyMap = b.keymap.forList()
+	}
 }
 
 func (b *statefulBubble) newState(s state) {
@@ -97,8 +112,6 @@ func (b *statefulBubble) newState(s state) {
 	if !lo.Contains([]state{
 		loadingState,
 		readState,
-		downloadDoneState,
-		downloadState,
 		confirmState,
 		anilistSelectState,
 	}, b.state) {
@@ -164,8 +177,12 @@ func (b *statefulBubble) stopLoading() tea.Cmd {
 	return nil
 }
 
-func newBubble() *statefulBubble {
-	keymap := newStatefulKeymap()
+func newBubble() (*statefulBubble, error) {
+	keymap, err := newStatefulKeymap()
+	if err != nil {
+		return nil, err
+	}
+
 	bubble := statefulBubble{
 		statesHistory: util.Stack[state]{},
 		keymap:        keymap,
@@ -173,36 +190,38 @@ func newBubble() *statefulBubble {
 		scrapersLoadedChannel:       make(chan []*installer.Scraper),
 		scraperInstalledChannel:     make(chan *installer.Scraper),
 		sourcesLoadedChannel:        make(chan []source.Source),
-		foundMangasChannel:          make(chan []*source.Manga),
+		foundMangasChannel:          make(chan []*search.Group),
 		foundChaptersChannel:        make(chan []*source.Chapter),
 		fetchedAnilistMangasChannel: make(chan []*anilist.Manga),
 		closestAnilistMangaChannel:  make(chan *anilist.Manga),
 		chapterReadChannel:          make(chan struct{}),
-		chapterDownloadChannel:      make(chan struct{}),
 		errorChannel:                make(chan error),
 
-		selectedProviders:  make(map[*provider.Provider]struct{}),
-		selectedChapters:   make(map[*source.Chapter]struct{}),
-		chaptersToDownload: util.Stack[*source.Chapter]{},
+		downloads: downloader.NewManager(viper.GetInt(key2.DownloaderWorkers)),
 
-		failedChapters:   make([]*source.Chapter, 0),
-		succededChapters: make([]*source.Chapter, 0),
+		selectedProviders: make(map[*provider.Provider]struct{}),
+		selectedChapters:  make(map[*source.Chapter]struct{}),
+
+		chaptersSort:    parseChapterSort(viper.GetString(key2.TUIChaptersSort)),
+		readingProgress: &history.Progress{},
 	}
 
 	type listOptions struct {
 		TitleStyle mo.Option[lipgloss.Style]
 	}
 
+	palette := theme.Current()
+
 	makeList := func(title string, description bool, options *listOptions) list.Model {
 		delegate := list.NewDefaultDelegate()
 		delegate.SetSpacing(viper.GetInt(key2.TUIItemSpacing))
 		delegate.ShowDescription = description
 		delegate.Styles.SelectedTitle = lipgloss.NewStyle().
 			Border(lipgloss.ThickBorder(), false, false, false, true).
-			BorderForeground(lipgloss.Color("5")).
-			Foreground(lipgloss.Color("5")).
+			BorderForeground(palette.UI.Accent).
+			Foreground(palette.UI.Accent).
 			Padding(0, 0, 0, 1)
-		delegate.Styles.NormalTitle = delegate.Styles.NormalTitle.Copy().Foreground(lipgloss.Color("7"))
+		delegate.Styles.NormalTitle = delegate.Styles.NormalTitle.Copy().Foreground(palette.UI.Text)
 
 		delegate.Styles.SelectedDesc = delegate.Styles.SelectedTitle.Copy()
 
@@ -228,7 +247,7 @@ func newBubble() *statefulBubble {
 
 	bubble.spinnerC = spinner.New()
 	bubble.spinnerC.Spinner = spinner.Dot
-	bubble.spinnerC.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("4"))
+	bubble.spinnerC.Style = lipgloss.NewStyle().Foreground(palette.UI.Spinner)
 
 	bubble.inputC = textinput.New()
 	bubble.inputC.Placeholder = "Search"
@@ -238,41 +257,33 @@ func newBubble() *statefulBubble {
 	bubble.progressC = progress.New(progress.WithDefaultGradient())
 
 	bubble.scrapersInstallC = makeList("Install Scrapers", true, &listOptions{
-		TitleStyle: mo.Some(
-			style.NewColored("#212529", "#ced4da").Padding(0, 1),
-		),
+		TitleStyle: mo.Some(palette.Titles.Install.Style()),
 	})
 	bubble.scrapersInstallC.SetStatusBarItemName("scraper", "scrapers")
 
-	bubble.historyC = makeList("History", true, &listOptions{})
+	bubble.historyC = makeList("History", true, &listOptions{
+		TitleStyle: mo.Some(palette.Titles.History.Style()),
+	})
 	bubble.sourcesC.SetStatusBarItemName("chapter", "chapters")
 
 	bubble.sourcesC = makeList("Select Source", true, &listOptions{
-		TitleStyle: mo.Some(
-			style.NewColored("#fefae0", "#bc6c25").Padding(0, 1),
-		),
+		TitleStyle: mo.Some(palette.Titles.Sources.Style()),
 	})
 	bubble.sourcesC.SetStatusBarItemName("source", "sources")
 
 	showURLs := viper.GetBool(key2.TUIShowURLs)
 	bubble.mangasC = makeList("Mangas", showURLs, &listOptions{
-		TitleStyle: mo.Some(
-			style.NewColored("#f2e8cf", "#386641").Padding(0, 1),
-		),
+		TitleStyle: mo.Some(palette.Titles.Mangas.Style()),
 	})
 	bubble.mangasC.SetStatusBarItemName("manga", "mangas")
 
 	bubble.chaptersC = makeList("Chapters", showURLs, &listOptions{
-		TitleStyle: mo.Some(
-			style.NewColored("#000814", color.Orange).Padding(0, 1),
-		),
+		TitleStyle: mo.Some(palette.Titles.Chapters.Style()),
 	})
 	bubble.chaptersC.SetStatusBarItemName("chapter", "chapters")
 
 	bubble.anilistC = makeList("Anilist Mangas", showURLs, &listOptions{
-		TitleStyle: mo.Some(
-			style.NewColored("#bcbedc", "#2b2d42").Padding(0, 1),
-		),
+		TitleStyle: mo.Some(palette.Titles.Anilist.Style()),
 	})
 	bubble.anilistC.SetStatusBarItemName("manga", "mangas")
 
@@ -282,7 +293,7 @@ func newBubble() *statefulBubble {
 
 	bubble.inputC.Focus()
 
-	return &bubble
+	return &bubble, nil
 }
 
 func (b *statefulBubble) loadProviders() tea.Cmd {
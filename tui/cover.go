@@ -0,0 +1,112 @@
+package tui
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/network"
+	"github.com/metafates/mangal/util"
+	_ "golang.org/x/image/webp"
+)
+
+// coverWidth is the width of the cover in the details view, in cells
+const coverWidth = 24
+
+// downloadCover downloads and decodes the cover image
+func downloadCover(url string) (image.Image, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", constant.UserAgent)
+
+	res, err := network.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer util.Ignore(res.Body.Close)
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download cover: %s", res.Status)
+	}
+
+	img, _, err := image.Decode(res.Body)
+	return img, err
+}
+
+// renderCover renders the image with the upper half blocks, two pixels per cell.
+// Top pixel is the foreground and bottom pixel is the background of the cell,
+// colors are degraded to the color profile of the terminal
+func renderCover(img image.Image, width int) string {
+	bounds := img.Bounds()
+	if width <= 0 || bounds.Dx() == 0 || bounds.Dy() == 0 {
+		return ""
+	}
+
+	// cells are twice as high as wide, so the pixels of the half blocks are square
+	height := width * bounds.Dy() / bounds.Dx()
+	if height%2 != 0 {
+		height++
+	}
+
+	pixel := func(x, y int) string {
+		return hex(average(img, image.Rect(
+			bounds.Min.X+x*bounds.Dx()/width,
+			bounds.Min.Y+y*bounds.Dy()/height,
+			bounds.Min.X+(x+1)*bounds.Dx()/width,
+			bounds.Min.Y+(y+1)*bounds.Dy()/height,
+		)))
+	}
+
+	var rows []string
+	for y := 0; y < height; y += 2 {
+		var row strings.Builder
+		for x := 0; x < width; x++ {
+			row.WriteString(lipgloss.NewStyle().
+				Foreground(lipgloss.Color(pixel(x, y))).
+				Background(lipgloss.Color(pixel(x, y+1))).
+				Render("▀"),
+			)
+		}
+
+		rows = append(rows, row.String())
+	}
+
+	return strings.Join(rows, "\n")
+}
+
+// average returns the average color of the area of the image
+func average(img image.Image, area image.Rectangle) color.RGBA {
+	if area.Dx() == 0 {
+		area.Max.X = area.Min.X + 1
+	}
+
+	if area.Dy() == 0 {
+		area.Max.Y = area.Min.Y + 1
+	}
+
+	var r, g, b, count uint64
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			pr, pg, pb, _ := img.At(x, y).RGBA()
+			r, g, b = r+uint64(pr>>8), g+uint64(pg>>8), b+uint64(pb>>8)
+			count++
+		}
+	}
+
+	return color.RGBA{R: uint8(r / count), G: uint8(g / count), B: uint8(b / count), A: 0xff}
+}
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -0,0 +1,49 @@
+package tui
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/muesli/reflow/ansi"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRenderCover(t *testing.T) {
+	Convey("Given a cover image of 2:3 ratio", t, func() {
+		img := image.NewRGBA(image.Rect(0, 0, 200, 300))
+		for y := 0; y < 300; y++ {
+			for x := 0; x < 200; x++ {
+				img.Set(x, y, color.RGBA{R: 0xff, A: 0xff})
+			}
+		}
+
+		Convey("When it is rendered", func() {
+			rendered := renderCover(img, 20)
+			rows := strings.Split(rendered, "\n")
+
+			Convey("Then each cell should hold two rows of pixels", func() {
+				So(rows, ShouldHaveLength, 15)
+
+				for _, row := range rows {
+					So(ansi.PrintableRuneWidth(row), ShouldEqual, 20)
+				}
+			})
+		})
+
+		Convey("Then average color of the area should be used", func() {
+			So(hex(average(img, image.Rect(0, 0, 10, 10))), ShouldEqual, "#ff0000")
+		})
+	})
+}
+
+func TestPlainText(t *testing.T) {
+	Convey("Given a summary in html format", t, func() {
+		summary := "<i>Hero</i> &amp; friends.<br><br>Source: somewhere"
+
+		Convey("Then tags should be removed and entities unescaped", func() {
+			So(plainText(summary), ShouldEqual, "Hero & friends.\n\nSource: somewhere")
+		})
+	})
+}
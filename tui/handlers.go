@@ -7,13 +7,18 @@ import (
 	"github.com/metafates/mangal/anilist"
 	"github.com/metafates/mangal/color"
 	"github.com/metafates/mangal/downloader"
+	"github.com/metafates/mangal/event"
+	"github.com/metafates/mangal/fallback"
 	"github.com/metafates/mangal/installer"
 	"github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/log"
 	"github.com/metafates/mangal/provider"
+	"github.com/metafates/mangal/provider/mangadex"
+	"github.com/metafates/mangal/search"
 	"github.com/metafates/mangal/source"
 	"github.com/metafates/mangal/style"
 	"github.com/metafates/mangal/util"
+	"github.com/samber/mo"
 	"github.com/spf13/viper"
 	"golang.org/x/exp/slices"
 	"sync"
@@ -67,9 +72,11 @@ func (b *statefulBubble) waitForScrapersLoaded() tea.Cmd {
 }
 
 func (b *statefulBubble) installScraper(s *installer.Scraper) tea.Cmd {
+	scrapers := b.scrapers
+
 	return func() tea.Msg {
 		b.progressStatus = fmt.Sprintf("Installing %s", s.Name)
-		err := s.Install()
+		err := s.Install(scrapers)
 		if err != nil {
 			log.Error(err)
 			b.errorChannel <- err
@@ -152,30 +159,22 @@ func (b *statefulBubble) searchManga(query string) tea.Cmd {
 		log.Info("searching for " + query)
 		b.progressStatus = fmt.Sprintf("Searching among %s", util.Quantify(len(b.selectedSources), "source", "sources"))
 
-		var mangas = make([]*source.Manga, 0)
-
-		wg := sync.WaitGroup{}
-		wg.Add(len(b.selectedSources))
-		for _, s := range b.selectedSources {
-			go func(s source.Source) {
-				defer wg.Done()
-				sourceMangas, err := s.Search(query)
-
-				if err != nil {
-					log.Error(err)
-					b.errorChannel <- err
-				}
-
-				log.Infof("found %s from source %s", util.Quantify(len(sourceMangas), "manga", "mangas"), s.Name())
-				mangas = append(mangas, sourceMangas...)
-			}(s)
+		results := search.Sources(b.selectedSources, query, search.Timeout())
+		if _, err := search.Mangas(results); err != nil {
+			log.Error(err)
+			b.errorChannel <- err
+			return nil
 		}
 
-		wg.Wait()
+		groups := search.Merge(results)
+		if len(b.selectedSources) > 1 {
+			b.progressStatus = "Comparing sources"
+			search.CountChapters(groups, search.Timeout())
+		}
 
-		log.Infof("found %d mangas from %d sources", len(mangas), len(b.selectedSources))
+		log.Infof("found %d mangas from %d sources", len(groups), len(b.selectedSources))
 
-		b.foundMangasChannel <- mangas
+		b.foundMangasChannel <- groups
 
 		return nil
 	}
@@ -197,6 +196,11 @@ func (b *statefulBubble) getChapters(manga *source.Manga) tea.Cmd {
 	return func() tea.Msg {
 		log.Info("getting chapters of " + manga.Name)
 		chapters, err := manga.Source.ChaptersOf(manga)
+		if err == nil && fallback.Enabled() {
+			b.progressStatus = "Looking for missing chapters in other sources"
+			chapters = fallback.Complete(manga, chapters)
+		}
+
 		if err != nil {
 			log.Error(err)
 			b.errorChannel <- err
@@ -224,8 +228,8 @@ func (b *statefulBubble) waitForChapters() tea.Cmd {
 func (b *statefulBubble) readChapter(chapter *source.Chapter) tea.Cmd {
 	return func() tea.Msg {
 		b.currentDownloadingChapter = chapter
-		err := downloader.Read(chapter, func(s string) {
-			b.progressStatus = s
+		err := downloader.Read(chapter, func(e *event.Event) {
+			b.progressStatus = style.Event(e)
 		})
 
 		if err != nil {
@@ -250,38 +254,13 @@ func (b *statefulBubble) waitForChapterRead() tea.Cmd {
 	}
 }
 
-func (b *statefulBubble) downloadChapter(chapter *source.Chapter) tea.Cmd {
-	return func() tea.Msg {
-		b.currentDownloadingChapter = chapter
-		_, err := downloader.Download(chapter, func(s string) {
-			b.progressStatus = s
-		})
-
-		if err != nil {
-			if viper.GetBool(key.DownloaderStopOnError) {
-				b.errorChannel <- err
-			} else {
-				b.failedChapters = append(b.failedChapters, chapter)
-				b.chapterDownloadChannel <- struct{}{}
-			}
-		} else {
-			b.succededChapters = append(b.succededChapters, chapter)
-			b.chapterDownloadChannel <- struct{}{}
-		}
+// downloadsUpdated is sent when any of the background downloads has changed
+type downloadsUpdated struct{}
 
-		return nil
-	}
-}
-
-func (b *statefulBubble) waitForChapterDownload() tea.Cmd {
+func (b *statefulBubble) waitForDownloads() tea.Cmd {
 	return func() tea.Msg {
-		select {
-		case res := <-b.chapterDownloadChannel:
-			return res
-		case err := <-b.errorChannel:
-			b.lastError = err
-			return err
-		}
+		<-b.downloads.Updates()
+		return downloadsUpdated{}
 	}
 }
 
@@ -347,3 +326,72 @@ func (b *statefulBubble) selectChapterBy(f func(chapter *source.Chapter) bool) t
 		return nil
 	}
 }
+
+// mangaDetails are the metadata, cover and follow status of the manga shown in the details view
+type mangaDetails struct {
+	manga *source.Manga
+	// cover is the rendered cover, empty if it is not available or disabled
+	cover string
+	// following is present for the MangaDex manga of the logged-in user
+	following mo.Option[bool]
+	// status is the result of the last action
+	status string
+}
+
+// followToggled is sent when the manga was followed or unfollowed
+type followToggled struct {
+	manga     *source.Manga
+	following bool
+}
+
+func (b *statefulBubble) fetchDetails(manga *source.Manga) tea.Cmd {
+	return func() tea.Msg {
+		log.Info("fetching details of " + manga.Name)
+		b.progressStatus = fmt.Sprintf("Fetching details of %s", style.Fg(color.Purple)(manga.Name))
+
+		details := &mangaDetails{manga: manga}
+
+		// missing metadata is not critical, whatever was found is shown
+		if err := manga.PopulateMetadata(event.Discard); err != nil {
+			log.Warn(err)
+		}
+
+		if viper.GetBool(key.TUICoverPreview) {
+			if url, err := manga.GetCover(); err == nil {
+				if img, err := downloadCover(url); err != nil {
+					log.Warn(err)
+				} else {
+					details.cover = renderCover(img, coverWidth)
+				}
+			}
+		}
+
+		if _, ok := manga.Source.(*mangadex.Mangadex); ok && mangadex.LoggedIn() {
+			if following, err := mangadex.IsFollowing(manga.ID); err != nil {
+				log.Warn(err)
+			} else {
+				details.following = mo.Some(following)
+			}
+		}
+
+		return details
+	}
+}
+
+func (b *statefulBubble) toggleFollow(manga *source.Manga, following bool) tea.Cmd {
+	return func() tea.Msg {
+		var err error
+		if following {
+			err = mangadex.Unfollow(manga.ID)
+		} else {
+			err = mangadex.Follow(manga.ID)
+		}
+
+		if err != nil {
+			log.Error(err)
+			return err
+		}
+
+		return &followToggled{manga: manga, following: !following}
+	}
+}
@@ -24,8 +24,8 @@ func (b *statefulBubble) Init() tea.Cmd {
 		}
 
 		b.setState(loadingState)
-		return tea.Batch(b.startLoading(), b.loadSources(providers), b.waitForSourcesLoaded())
+		return tea.Batch(b.startLoading(), b.loadSources(providers), b.waitForSourcesLoaded(), b.waitForDownloads())
 	}
 
-	return tea.Batch(textinput.Blink, b.loadProviders())
+	return tea.Batch(textinput.Blink, b.loadProviders(), b.waitForDownloads())
 }
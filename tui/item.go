@@ -7,14 +7,44 @@ import (
 	"github.com/metafates/mangal/icon"
 	"github.com/metafates/mangal/installer"
 	"github.com/metafates/mangal/provider"
+	"github.com/metafates/mangal/search"
 	"github.com/metafates/mangal/source"
 	"github.com/metafates/mangal/style"
+	"github.com/metafates/mangal/util"
 	"strings"
 )
 
 type listItem struct {
 	internal interface{}
 	marked   bool
+
+	// read and new are the badges of the chapters
+	read, new bool
+
+	// entry is the chosen entry of the search group, the best one is used if nil
+	entry *search.Entry
+}
+
+// groupEntry returns the chosen entry of the search group
+func (t *listItem) groupEntry() *search.Entry {
+	if t.entry != nil {
+		return t.entry
+	}
+
+	return t.internal.(*search.Group).Best()
+}
+
+// switchEntry chooses the next entry of the search group
+func (t *listItem) switchEntry() {
+	group := t.internal.(*search.Group)
+	current := t.groupEntry()
+
+	for i, entry := range group.Entries {
+		if entry == current {
+			t.entry = group.Entries[(i+1)%len(group.Entries)]
+			return
+		}
+	}
 }
 
 func (t *listItem) toggleMark() {
@@ -50,6 +80,16 @@ func (t *listItem) Title() (title string) {
 			sb.WriteString(icon.Get(icon.Downloaded))
 		}
 
+		if t.read {
+			sb.WriteString(" ")
+			sb.WriteString(icon.Get(icon.Read))
+		}
+
+		if t.new {
+			sb.WriteString(" ")
+			sb.WriteString(icon.Get(icon.New))
+		}
+
 		title = sb.String()
 	default:
 		title = t.FilterValue()
@@ -69,8 +109,36 @@ func (t *listItem) Description() (description string) {
 		description = e.URL
 	case *source.Manga:
 		description = e.URL
+	case *search.Group:
+		if len(e.Entries) == 1 {
+			description = e.Entries[0].Manga.URL
+			break
+		}
+
+		chosen := t.groupEntry()
+		sources := make([]string, len(e.Entries))
+		for i, entry := range e.Entries {
+			if entry.Error != "" {
+				sources[i] = entry.Source
+			} else {
+				sources[i] = fmt.Sprintf("%s (%s)", entry.Source, util.Quantify(entry.Chapters, "chapter", "chapters"))
+			}
+
+			if entry == chosen {
+				sources[i] = style.Bold(sources[i])
+			}
+		}
+
+		description = strings.Join(sources, ", ")
 	case *installer.Scraper:
-		description = e.GithubURL()
+		switch {
+		case e.Version != "" && e.Description != "":
+			description = fmt.Sprintf("%s · %s", e.Version, e.Description)
+		case e.Version != "":
+			description = fmt.Sprintf("%s · %s", e.Version, e.Location())
+		default:
+			description = e.Location()
+		}
 	case *history.SavedChapter:
 		description = fmt.Sprintf("%s : %d / %d", e.Name, e.Index, e.MangaChaptersTotal)
 	case *provider.Provider:
@@ -99,6 +167,8 @@ func (t *listItem) FilterValue() string {
 		return e.Name
 	case *source.Manga:
 		return e.Name
+	case *search.Group:
+		return e.Name
 	case *history.SavedChapter:
 		return e.MangaName
 	case *anilist.Manga:
@@ -1,10 +1,17 @@
 package tui
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/metafates/mangal/color"
+	key2 "github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/style"
+	"github.com/samber/lo"
+	"github.com/spf13/viper"
 )
 
 type statefulKeymap struct {
@@ -12,123 +19,320 @@ type statefulKeymap struct {
 
 	quit, forceQuit,
 	selectOne, selectAll, selectVolume, clearSelection,
+	selectUnread, selectNotDownloaded, selectFromLastRead,
+	sortChapters,
 	acceptSearchSuggestion,
+	switchSource,
 	anilistSelect,
 	remove,
-	redownloadFailed,
+	downloads,
+	pause, cancel, retry, clearFinished,
 	confirm,
 	openURL,
 	read,
 	openFolder,
+	details,
+	follow,
+	openAnilist,
 	back,
 	filter,
 	up, down, left, right,
 	top, bottom,
 	showHelp key.Binding
+
+	// overrides are the keys of the actions by the tui.keys sections
+	overrides map[string]map[string][]string
+}
+
+// action is the binding that can be remapped in the config
+type action struct {
+	name, description string
+	// keys are the default keys
+	keys []string
+}
+
+// actions are the names of the bindings used in the config, with their default keys and descriptions
+var actions = []action{
+	{"quit", "quit", []string{"q"}},
+	{"force_quit", "quit", []string{"ctrl+c", "ctrl+d"}},
+	{"remove", "remove", []string{"d"}},
+	{"select_one", "select one", []string{" "}},
+	{"select_all", "select all", []string{"ctrl+a", "*"}},
+	{"select_volume", "select volume", []string{"v"}},
+	{"select_unread", "select unread", []string{"u"}},
+	{"select_not_downloaded", "select not downloaded", []string{"n"}},
+	{"select_from_last_read", "select from last read", []string{"c"}},
+	{"sort", "sort", []string{"s"}},
+	{"clear_selection", "clear selection", []string{"backspace"}},
+	{"confirm", "confirm", []string{"enter"}},
+	{"open_url", "open url", []string{"o"}},
+	{"read", "read", []string{"r"}},
+	{"accept_search_suggestion", "accept search suggestion", []string{"tab"}},
+	{"switch_source", "switch source", []string{"tab"}},
+	{"anilist_select", "select anilist manga", []string{"a"}},
+	{"open_folder", "open folder", []string{"o"}},
+	{"details", "details", []string{"i"}},
+	{"follow", "follow", []string{"f"}},
+	{"open_anilist", "open anilist", []string{"u"}},
+	{"downloads", "downloads", []string{"D"}},
+	{"pause", "pause/resume", []string{"p"}},
+	{"cancel", "cancel", []string{"x"}},
+	{"retry", "retry", []string{"r"}},
+	{"clear_finished", "clear finished", []string{"c"}},
+	{"back", "back", []string{"esc"}},
+	{"filter", "filter", []string{"/"}},
+	{"up", "up", []string{"up", "k"}},
+	{"down", "down", []string{"down", "j"}},
+	{"left", "left", []string{"left", "h"}},
+	{"right", "right", []string{"right", "l"}},
+	{"top", "top", []string{"g"}},
+	{"bottom", "bottom", []string{"G"}},
+	{"show_help", "help", []string{"?"}},
+}
+
+// globalActions are handled in every state
+var globalActions = []string{"force_quit", "back"}
+
+// listActions are handled by the lists
+var listActions = []string{"quit", "filter", "up", "down", "left", "right", "top", "bottom", "show_help"}
+
+// stateActions are the actions handled in the state, besides the global ones.
+// Same key can be used by different actions only if they are not handled in the same state
+var stateActions = map[state][]string{
+	scrapersInstallState: append([]string{"open_url", "select_one", "confirm"}, listActions...),
+	loadingState:         {},
+	historyState:         append([]string{"open_url", "remove", "downloads", "select_one", "confirm"}, listActions...),
+	sourcesState:         append([]string{"select_all", "clear_selection", "downloads", "select_one", "confirm"}, listActions...),
+	searchState:          {"confirm", "accept_search_suggestion"},
+	mangasState:          append([]string{"open_url", "details", "switch_source", "downloads", "select_one", "confirm"}, listActions...),
+	chaptersState:        append([]string{"open_url", "details", "anilist_select", "select_volume", "select_unread", "select_not_downloaded", "select_from_last_read", "sort", "select_one", "select_all", "clear_selection", "read", "downloads", "confirm"}, listActions...),
+	anilistSelectState:   append([]string{"open_url", "select_one", "confirm"}, listActions...),
+	confirmState:         {"quit", "confirm"},
+	readState:            {},
+	downloadsState:       {"quit", "up", "down", "pause", "cancel", "retry", "clear_finished", "open_folder"},
+	errorState:           {"quit"},
+	mangaDetailsState:    {"quit", "confirm", "anilist_select", "follow", "open_url", "open_anilist", "downloads"},
+}
+
+// globalSection is the section of the tui.keys that remaps the actions in all states
+const globalSection = "global"
+
+// newStatefulKeymap creates a keymap with the keys remapped by the tui.keys config section.
+// Returns error if the section is invalid or actions of any state have the same keys
+func newStatefulKeymap() (*statefulKeymap, error) {
+	k := &statefulKeymap{}
+
+	overrides, err := keyOverrides(viper.GetStringMap(key2.TUIKeys))
+	if err != nil {
+		return nil, err
+	}
+
+	k.overrides = overrides
+
+	if err = k.validate(); err != nil {
+		return nil, err
+	}
+
+	k.setState(0)
+	return k, nil
+}
+
+// keyOverrides parses the tui.keys config section.
+// Section is a table of global remaps and the tables of remaps for the states, e.g.
+//
+//	[tui.keys.global]
+//	select_all = ["ctrl+a", "tab"]
+//
+//	[tui.keys.chapters]
+//	read = "enter"
+func keyOverrides(section map[string]any) (map[string]map[string][]string, error) {
+	overrides := make(map[string]map[string][]string)
+
+	sections := lo.Keys(section)
+	sort.Strings(sections)
+
+	for _, name := range sections {
+		if name != globalSection && !lo.Contains(lo.Values(stateNames), name) {
+			return nil, fmt.Errorf(
+				"tui.keys: unknown section %q, expected %s or one of the states: %s",
+				name,
+				globalSection,
+				strings.Join(lo.Map(lo.Range(len(stateNames)), func(i, _ int) string {
+					return state(i + 1).String()
+				}), ", "),
+			)
+		}
+
+		remaps, ok := section[name].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("tui.keys.%s: expected a table of actions", name)
+		}
+
+		overrides[name] = make(map[string][]string)
+		for actionName, value := range remaps {
+			if _, ok := lo.Find(actions, func(a action) bool {
+				return a.name == actionName
+			}); !ok {
+				return nil, fmt.Errorf("tui.keys.%s: unknown action %q", name, actionName)
+			}
+
+			var keys []string
+			switch value := value.(type) {
+			case string:
+				keys = []string{value}
+			case []any:
+				for _, v := range value {
+					s, ok := v.(string)
+					if !ok {
+						return nil, fmt.Errorf("tui.keys.%s.%s: expected a list of keys, got %v", name, actionName, v)
+					}
+
+					keys = append(keys, s)
+				}
+			case []string:
+				keys = value
+			default:
+				return nil, fmt.Errorf("tui.keys.%s.%s: expected a key or a list of keys, got %v", name, actionName, value)
+			}
+
+			overrides[name][actionName] = keys
+		}
+	}
+
+	return overrides, nil
+}
+
+// keysFor returns the keys of the actions in the state
+func (k *statefulKeymap) keysFor(s state) map[string][]string {
+	keys := make(map[string][]string)
+
+	for _, a := range actions {
+		keys[a.name] = a.keys
+	}
+
+	for _, section := range []string{globalSection, s.String()} {
+		for action, remapped := range k.overrides[section] {
+			keys[action] = remapped
+		}
+	}
+
+	return keys
 }
 
+// validate checks that the actions handled in the same state do not share keys
+func (k *statefulKeymap) validate() error {
+	var problems []string
+
+	for s := state(1); int(s) <= len(stateNames); s++ {
+		keys := k.keysFor(s)
+		owners := make(map[string]string)
+
+		for _, action := range append(globalActions, stateActions[s]...) {
+			for _, pressed := range keys[action] {
+				if owner, ok := owners[pressed]; ok && owner != action {
+					problems = append(problems, fmt.Sprintf(
+						"%q is bound to both %s and %s in the %s state",
+						pressed, owner, action, s,
+					))
+					continue
+				}
+
+				owners[pressed] = action
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("tui.keys: conflicting keys:\n%s", strings.Join(problems, "\n"))
+	}
+
+	return nil
+}
+
+// bindings returns the bindings of the keymap by the action names
+func (k *statefulKeymap) bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"quit":                     &k.quit,
+		"force_quit":               &k.forceQuit,
+		"remove":                   &k.remove,
+		"select_one":               &k.selectOne,
+		"select_all":               &k.selectAll,
+		"select_volume":            &k.selectVolume,
+		"select_unread":            &k.selectUnread,
+		"select_not_downloaded":    &k.selectNotDownloaded,
+		"select_from_last_read":    &k.selectFromLastRead,
+		"sort":                     &k.sortChapters,
+		"clear_selection":          &k.clearSelection,
+		"confirm":                  &k.confirm,
+		"open_url":                 &k.openURL,
+		"read":                     &k.read,
+		"accept_search_suggestion": &k.acceptSearchSuggestion,
+		"switch_source":            &k.switchSource,
+		"anilist_select":           &k.anilistSelect,
+		"open_folder":              &k.openFolder,
+		"details":                  &k.details,
+		"follow":                   &k.follow,
+		"open_anilist":             &k.openAnilist,
+		"downloads":                &k.downloads,
+		"pause":                    &k.pause,
+		"cancel":                   &k.cancel,
+		"retry":                    &k.retry,
+		"clear_finished":           &k.clearFinished,
+		"back":                     &k.back,
+		"filter":                   &k.filter,
+		"up":                       &k.up,
+		"down":                     &k.down,
+		"left":                     &k.left,
+		"right":                    &k.right,
+		"top":                      &k.top,
+		"bottom":                   &k.bottom,
+		"show_help":                &k.showHelp,
+	}
+}
+
+// setState rebinds the actions with the keys of the state
 func (k *statefulKeymap) setState(newState state) {
 	k.state = newState
+
+	keys := k.keysFor(newState)
+	bindings := k.bindings()
+
+	for _, a := range actions {
+		binding := key.NewBinding(
+			key.WithKeys(keys[a.name]...),
+			key.WithHelp(helpKey(keys[a.name]), a.description),
+		)
+
+		// read is highlighted
+		if a.name == "read" {
+			binding.SetHelp(style.Fg(color.Orange)(helpKey(keys[a.name])), style.Fg(color.Orange)(a.description))
+		}
+
+		// empty list of keys unbinds the action
+		binding.SetEnabled(len(keys[a.name]) > 0)
+		*bindings[a.name] = binding
+	}
 }
 
-func newStatefulKeymap() *statefulKeymap {
-	k := key.NewBinding
-	keys := key.WithKeys
-	help := key.WithHelp
-
-	return &statefulKeymap{
-		quit: k(
-			keys("q"),
-			help("q", "quit"),
-		),
-		forceQuit: k(
-			keys("ctrl+c", "ctrl+d"),
-			help("ctrl+c", "quit"),
-		),
-		remove: k(
-			keys("d"),
-			help("d", "remove"),
-		),
-		selectOne: k(
-			keys(" "),
-			help("space", "select one"),
-		),
-		selectAll: k(
-			keys("ctrl+a", "tab", "*"),
-			help("tab", "select all"),
-		),
-		selectVolume: k(
-			keys("v"),
-			help("v", "select volume"),
-		),
-		clearSelection: k(
-			keys("backspace"),
-			help("backspace", "clear selection"),
-		),
-		confirm: k(
-			keys("enter"),
-			help("enter", "confirm"),
-		),
-		openURL: k(
-			keys("o"),
-			help("o", "open url"),
-		),
-		read: k(
-			keys("r"),
-			help(style.Fg(color.Orange)("r"), style.Fg(color.Orange)("read")),
-		),
-		acceptSearchSuggestion: k(
-			keys("tab"),
-			help("tab", "accept search suggestion"),
-		),
-		redownloadFailed: k(
-			keys("r"),
-			help("r", "redownload failed"),
-		),
-		anilistSelect: k(
-			keys("a"),
-			help("a", "select anilist manga"),
-		),
-		openFolder: k(
-			keys("o"),
-			help("o", "open folder"),
-		),
-		back: k(
-			keys("esc"),
-			help("esc", "back"),
-		),
-		filter: k(
-			keys("/"),
-			help("/", "filter"),
-		),
-		up: k(
-			keys("up", "k"),
-			help("↑", "up"),
-		),
-		down: k(
-			keys("down", "j"),
-			help("↓", "down"),
-		),
-		left: k(
-			keys("left", "h"),
-			help("←", "left"),
-		),
-		right: k(
-			keys("right", "l"),
-			help("→", "right"),
-		),
-		top: k(
-			keys("g"),
-			help("g", "top"),
-		),
-		bottom: k(
-			keys("G"),
-			help("G", "bottom"),
-		),
-		showHelp: k(
-			keys("?", "h"),
-			help("?", "help"),
-		),
+// helpKey returns the first key as it is shown in the help
+func helpKey(keys []string) string {
+	if len(keys) == 0 {
+		return ""
+	}
+
+	switch keys[0] {
+	case " ":
+		return "space"
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	case "left":
+		return "←"
+	case "right":
+		return "→"
+	default:
+		return keys[0]
 	}
 }
 
@@ -150,29 +354,31 @@ func (k *statefulKeymap) help() ([]key.Binding, []key.Binding) {
 	case loadingState:
 		return to2(h(k.forceQuit, k.back))
 	case historyState:
-		return to2(h(k.confirm, k.remove, k.back, k.openURL))
+		return h(k.confirm, k.remove, k.back, k.openURL), h(k.confirm, k.remove, k.back, k.openURL, k.downloads)
 	case sourcesState:
 		search := withDescription(k.confirm, "search with selected")
-		return h(k.selectOne, k.selectAll, search), h(k.selectOne, k.selectAll, k.clearSelection, search)
+		return h(k.selectOne, k.selectAll, search), h(k.selectOne, k.selectAll, k.clearSelection, search, k.downloads)
 	case searchState:
 		return to2(h(k.confirm, k.acceptSearchSuggestion, k.forceQuit))
 	case mangasState:
-		return to2(h(k.confirm, k.back, k.openURL))
+		return h(k.confirm, k.details, k.switchSource, k.back), h(k.confirm, k.details, k.switchSource, k.back, k.openURL, k.downloads)
 	case chaptersState:
 		download := withDescription(k.confirm, "download selected")
-		return h(k.read, k.selectOne, k.selectAll, download, k.back), h(k.read, k.selectOne, k.selectAll, k.clearSelection, k.openURL, download, k.selectVolume, k.anilistSelect, k.back)
+		return h(k.read, k.selectOne, k.selectAll, download, k.back), h(k.read, k.selectOne, k.selectAll, k.clearSelection, k.selectUnread, k.selectNotDownloaded, k.selectFromLastRead, k.selectVolume, k.sortChapters, k.openURL, k.details, download, k.downloads, k.anilistSelect, k.back)
 	case anilistSelectState:
 		return to2(h(k.confirm, k.openURL, k.back))
 	case confirmState:
 		return to2(h(k.confirm, k.back, k.quit))
 	case readState:
 		return to2(h(k.back, k.forceQuit))
-	case downloadState:
-		return to2(h(k.back, k.forceQuit))
-	case downloadDoneState:
-		return to2(h(k.back, k.quit, k.openFolder, k.redownloadFailed))
+	case downloadsState:
+		return h(k.pause, k.cancel, k.retry, k.back), h(k.up, k.down, k.pause, k.cancel, k.retry, k.clearFinished, k.openFolder, k.back, k.quit)
 	case errorState:
 		return to2(h(k.back, k.quit))
+	case mangaDetailsState:
+		chapters := withDescription(k.confirm, "chapters")
+		rebind := withDescription(k.anilistSelect, "rebind anilist")
+		return h(chapters, rebind, k.follow, k.openURL, k.back), h(chapters, rebind, k.follow, k.openURL, k.openAnilist, k.downloads, k.back, k.quit)
 	default:
 		return to2(h())
 	}
@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	key2 "github.com/metafates/mangal/key"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+)
+
+func press(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestKeymap(t *testing.T) {
+	defer viper.Reset()
+
+	Convey("Given the default keys", t, func() {
+		viper.Set(key2.TUIKeys, map[string]any{})
+
+		keymap, err := newStatefulKeymap()
+
+		Convey("Then they should not conflict", func() {
+			So(err, ShouldBeNil)
+		})
+
+		Convey("Then help should not be bound to the left key", func() {
+			keymap.setState(chaptersState)
+			So(key.Matches(press("h"), keymap.left), ShouldBeTrue)
+			So(key.Matches(press("h"), keymap.showHelp), ShouldBeFalse)
+		})
+	})
+
+	Convey("Given the keys remapped for the state", t, func() {
+		viper.Set(key2.TUIKeys, map[string]any{
+			"global":   map[string]any{"select_all": []any{"ctrl+a", "A"}},
+			"chapters": map[string]any{"read": "R", "select_volume": []any{}},
+		})
+
+		keymap, err := newStatefulKeymap()
+		So(err, ShouldBeNil)
+
+		Convey("When the state is set", func() {
+			keymap.setState(chaptersState)
+
+			Convey("Then the remapped keys should be used", func() {
+				So(key.Matches(press("R"), keymap.read), ShouldBeTrue)
+				So(key.Matches(press("r"), keymap.read), ShouldBeFalse)
+				So(key.Matches(press("A"), keymap.selectAll), ShouldBeTrue)
+				So(keymap.selectVolume.Enabled(), ShouldBeFalse)
+				So(keymap.forList().CursorUp.Keys(), ShouldResemble, []string{"up", "k"})
+			})
+		})
+
+		Convey("When another state is set", func() {
+			keymap.setState(downloadsState)
+
+			Convey("Then only global remaps should be used", func() {
+				So(key.Matches(press("r"), keymap.retry), ShouldBeTrue)
+				So(key.Matches(press("R"), keymap.read), ShouldBeFalse)
+				So(key.Matches(press("A"), keymap.selectAll), ShouldBeTrue)
+			})
+		})
+	})
+
+	Convey("Given the keys that conflict in the state", t, func() {
+		viper.Set(key2.TUIKeys, map[string]any{
+			"chapters": map[string]any{"read": "o"},
+		})
+
+		_, err := newStatefulKeymap()
+
+		Convey("Then error should describe the conflict", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, `"o" is bound to both open_url and read in the chapters state`)
+		})
+	})
+
+	Convey("Given the same key for the actions of different states", t, func() {
+		viper.Set(key2.TUIKeys, map[string]any{
+			"global": map[string]any{"open_folder": "a"},
+		})
+
+		_, err := newStatefulKeymap()
+
+		Convey("Then it should not conflict", func() {
+			So(err, ShouldBeNil)
+		})
+	})
+
+	Convey("Given unknown names", t, func() {
+		Convey("When the state is unknown", func() {
+			viper.Set(key2.TUIKeys, map[string]any{"reader": map[string]any{"read": "r"}})
+			_, err := newStatefulKeymap()
+
+			Convey("Then error should list the states", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, `unknown section "reader"`)
+				So(err.Error(), ShouldContainSubstring, "downloads")
+			})
+		})
+
+		Convey("When the action is unknown", func() {
+			viper.Set(key2.TUIKeys, map[string]any{"global": map[string]any{"jump": "j"}})
+			_, err := newStatefulKeymap()
+
+			Convey("Then error should be returned", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, `unknown action "jump"`)
+			})
+		})
+	})
+}
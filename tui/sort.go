@@ -0,0 +1,118 @@
+package tui
+
+import (
+	"sort"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+)
+
+// chapterSort is the order of the chapters list
+type chapterSort string
+
+const (
+	sortBySource chapterSort = "source"
+	sortByNumber chapterSort = "number"
+	sortByVolume chapterSort = "volume"
+	sortByDate   chapterSort = "date"
+)
+
+// chapterSorts are the orders in the order they are switched
+var chapterSorts = []chapterSort{sortBySource, sortByNumber, sortByVolume, sortByDate}
+
+// parseChapterSort returns the order by its name, source order is used for unknown names
+func parseChapterSort(name string) chapterSort {
+	if lo.Contains(chapterSorts, chapterSort(name)) {
+		return chapterSort(name)
+	}
+
+	return sortBySource
+}
+
+// next returns the order that follows this one
+func (s chapterSort) next() chapterSort {
+	return chapterSorts[(lo.IndexOf(chapterSorts, s)+1)%len(chapterSorts)]
+}
+
+// sortChapterItems sorts the items of the chapters list.
+// Chapters with unknown numbers or dates come after the known ones, ties keep the source order
+func sortChapterItems(items []list.Item, by chapterSort, reverse bool) {
+	chapter := func(i int) *source.Chapter {
+		return items[i].(*listItem).internal.(*source.Chapter)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := chapter(i), chapter(j)
+
+		var result comparison
+		switch by {
+		case sortByNumber:
+			result = compareNumbers(a, b)
+		case sortByVolume:
+			result = compareVolumes(a, b)
+			if result == equal {
+				result = compareNumbers(a, b)
+			}
+		case sortByDate:
+			result = compareDates(a, b)
+		}
+
+		if result == equal {
+			return a.Index < b.Index
+		}
+
+		return result == before
+	})
+
+	if reverse {
+		lo.Reverse(items)
+	}
+}
+
+// comparison is the result of comparing two chapters
+type comparison int
+
+const (
+	equal comparison = iota
+	before
+	after
+)
+
+// compareKnown compares the values that may be unknown, unknown values come last
+func compareKnown(aKnown, bKnown bool, less, greater bool) comparison {
+	switch {
+	case aKnown && !bKnown:
+		return before
+	case !aKnown && bKnown:
+		return after
+	case !aKnown:
+		return equal
+	case less:
+		return before
+	case greater:
+		return after
+	default:
+		return equal
+	}
+}
+
+func compareNumbers(a, b *source.Chapter) comparison {
+	aNumber, aOk := a.ParsedNumber()
+	bNumber, bOk := b.ParsedNumber()
+	return compareKnown(aOk, bOk, aNumber.Less(bNumber), bNumber.Less(aNumber))
+}
+
+func compareVolumes(a, b *source.Chapter) comparison {
+	aVolume, aOk := a.ParsedVolume()
+	bVolume, bOk := b.ParsedVolume()
+	return compareKnown(aOk, bOk, aVolume < bVolume, aVolume > bVolume)
+}
+
+func compareDates(a, b *source.Chapter) comparison {
+	if a.Date == nil || b.Date == nil {
+		return compareKnown(a.Date != nil, b.Date != nil, false, false)
+	}
+
+	return compareKnown(true, true, a.Date.Before(*b.Date), a.Date.After(*b.Date))
+}
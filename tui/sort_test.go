@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/metafates/mangal/source"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSortChapterItems(t *testing.T) {
+	Convey("Given the chapters in the source order", t, func() {
+		date := func(day int) *time.Time {
+			d := time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC)
+			return &d
+		}
+
+		chapters := []*source.Chapter{
+			{Name: "Chapter 2", Volume: "Vol. 1", Index: 1, Date: date(3)},
+			{Name: "Chapter 1", Volume: "Vol. 1", Index: 2, Date: date(1)},
+			{Name: "Extra", Index: 3},
+			{Name: "Chapter 10", Volume: "Vol. 2", Index: 4, Date: date(2)},
+		}
+
+		items := make([]list.Item, len(chapters))
+		for i, c := range chapters {
+			items[i] = &listItem{internal: c}
+		}
+
+		names := func() []string {
+			result := make([]string, len(items))
+			for i, item := range items {
+				result[i] = item.(*listItem).internal.(*source.Chapter).Name
+			}
+
+			return result
+		}
+
+		Convey("When sorted by number", func() {
+			sortChapterItems(items, sortByNumber, false)
+
+			Convey("Then chapters without numbers should come last", func() {
+				So(names(), ShouldResemble, []string{"Chapter 1", "Chapter 2", "Chapter 10", "Extra"})
+			})
+		})
+
+		Convey("When sorted by date in reverse", func() {
+			sortChapterItems(items, sortByDate, true)
+
+			Convey("Then the latest chapters should come first", func() {
+				So(names(), ShouldResemble, []string{"Extra", "Chapter 2", "Chapter 10", "Chapter 1"})
+			})
+		})
+
+		Convey("When sorted back by source", func() {
+			sortChapterItems(items, sortByVolume, false)
+			sortChapterItems(items, sortBySource, false)
+
+			Convey("Then the source order should be restored", func() {
+				So(names(), ShouldResemble, []string{"Chapter 2", "Chapter 1", "Extra", "Chapter 10"})
+			})
+		})
+	})
+
+	Convey("Given the sort orders", t, func() {
+		Convey("Then they should be switched in a cycle", func() {
+			So(sortBySource.next(), ShouldEqual, sortByNumber)
+			So(sortByDate.next(), ShouldEqual, sortBySource)
+			So(parseChapterSort("unknown"), ShouldEqual, sortBySource)
+		})
+	})
+}
@@ -14,6 +14,27 @@ const (
 	anilistSelectState
 	confirmState
 	readState
-	downloadState
-	downloadDoneState
+	downloadsState
+	mangaDetailsState
 )
+
+// stateNames are the names of the states used in the config
+var stateNames = map[state]string{
+	scrapersInstallState: "install",
+	errorState:           "error",
+	loadingState:         "loading",
+	historyState:         "history",
+	sourcesState:         "sources",
+	searchState:          "search",
+	mangasState:          "mangas",
+	chaptersState:        "chapters",
+	anilistSelectState:   "anilist",
+	confirmState:         "confirm",
+	readState:            "read",
+	downloadsState:       "downloads",
+	mangaDetailsState:    "details",
+}
+
+func (s state) String() string {
+	return stateNames[s]
+}
@@ -2,6 +2,9 @@ package tui
 
 import (
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/theme"
+	"github.com/spf13/viper"
 )
 
 type Options struct {
@@ -10,13 +13,22 @@ type Options struct {
 }
 
 func Run(options *Options) error {
+	palette, err := theme.Load(viper.GetString(key.TUITheme))
+	if err != nil {
+		return err
+	}
+
+	theme.Apply(palette)
 
-	bubble := newBubble()
+	bubble, err := newBubble()
+	if err != nil {
+		return err
+	}
 
 	if options.Install {
 		bubble.newState(scrapersInstallState)
 	} else if options.Continue {
-		_, err := bubble.loadHistory()
+		_, err = bubble.loadHistory()
 		if err != nil {
 			return err
 		}
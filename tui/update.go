@@ -4,16 +4,18 @@ import (
 	"fmt"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
-	"github.com/charmbracelet/bubbles/progress"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/metafates/mangal/anilist"
 	"github.com/metafates/mangal/color"
+	"github.com/metafates/mangal/downloader"
 	"github.com/metafates/mangal/history"
 	"github.com/metafates/mangal/installer"
 	key2 "github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
 	"github.com/metafates/mangal/open"
 	"github.com/metafates/mangal/provider"
 	"github.com/metafates/mangal/query"
+	"github.com/metafates/mangal/search"
 	"github.com/metafates/mangal/source"
 	"github.com/metafates/mangal/style"
 	"github.com/metafates/mangal/util"
@@ -22,7 +24,6 @@ import (
 	"github.com/spf13/viper"
 	"golang.org/x/exp/slices"
 	"strings"
-	"time"
 )
 
 func (b *statefulBubble) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -95,10 +96,12 @@ func (b *statefulBubble) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			b.previousState()
 			b.stopLoading()
-			b.failedChapters = make([]*source.Chapter, 0)
-			b.succededChapters = make([]*source.Chapter, 0)
 			return b, cmd
 		}
+	case downloadsUpdated:
+		// downloads are rendered by the view, just keep the cursor on the jobs and keep listening
+		b.clampDownloadsCursor()
+		return b, b.waitForDownloads()
 	}
 
 	switch b.state {
@@ -120,14 +123,14 @@ func (b *statefulBubble) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return b.updateConfirm(msg)
 	case readState:
 		return b.updateRead(msg)
-	case downloadState:
-		return b.updateDownload(msg)
-	case downloadDoneState:
-		return b.updateDownloadDone(msg)
+	case downloadsState:
+		return b.updateDownloads(msg)
 	case scrapersInstallState:
 		return b.updateScrapersInstall(msg)
 	case errorState:
 		return b.updateError(msg)
+	case mangaDetailsState:
+		return b.updateDetails(msg)
 	}
 
 	panic("unreachable")
@@ -147,7 +150,7 @@ func (b *statefulBubble) updateScrapersInstall(msg tea.Msg) (tea.Model, tea.Cmd)
 		case b.scrapersInstallC.FilterState() == list.Filtering:
 			break
 		case key.Matches(msg, b.keymap.openURL):
-			url := b.scrapersInstallC.SelectedItem().(*listItem).internal.(*installer.Scraper).GithubURL()
+			url := b.scrapersInstallC.SelectedItem().(*listItem).internal.(*installer.Scraper).Location()
 			err := open.Run(url)
 			if err != nil {
 				b.lastError = err
@@ -201,13 +204,14 @@ func (b *statefulBubble) updateLoading(msg tea.Msg) (tea.Model, tea.Cmd) {
 		b.anilistC.Select(marked)
 		return b, tea.Batch(cmd, b.stopLoading())
 	case []*installer.Scraper:
+		b.scrapers = msg
 		b.newState(scrapersInstallState)
 		return b, b.stopLoading()
 	case *installer.Scraper:
 		b.newState(scrapersInstallState)
 		b.scrapersInstallC.NewStatusMessage(fmt.Sprintf("Installed %s", msg.Name))
 		return b, b.stopLoading()
-	case []*source.Manga:
+	case []*search.Group:
 		items := make([]list.Item, len(msg))
 		for i, m := range msg {
 			items[i] = &listItem{internal: m}
@@ -267,14 +271,9 @@ func (b *statefulBubble) updateHistory(msg tea.Msg) (tea.Model, tea.Cmd) {
 			b.waitForChapters(),
 		)
 	case []*source.Chapter:
-		items := make([]list.Item, len(msg))
 		selected := b.historyC.SelectedItem().(*listItem).internal.(*history.SavedChapter)
 
-		for i, c := range msg {
-			items[i] = &listItem{internal: c}
-		}
-
-		cmd = b.chaptersC.SetItems(items)
+		cmd = b.chaptersC.SetItems(b.chapterItems(msg))
 		b.newState(chaptersState)
 		b.stopLoading()
 		selectCmd := b.selectChapterBy(func(chapter *source.Chapter) bool {
@@ -293,6 +292,8 @@ func (b *statefulBubble) updateHistory(msg tea.Msg) (tea.Model, tea.Cmd) {
 					b.raiseError(err)
 				}
 			}
+		case key.Matches(msg, b.keymap.downloads):
+			return b, b.showDownloads()
 		case key.Matches(msg, b.keymap.remove):
 			if b.historyC.SelectedItem() != nil {
 				chapter := b.historyC.SelectedItem().(*listItem).internal.(*history.SavedChapter)
@@ -339,6 +340,8 @@ func (b *statefulBubble) updateSources(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch {
 		case b.sourcesC.FilterState() == list.Filtering:
 			break
+		case key.Matches(msg, b.keymap.downloads):
+			return b, b.showDownloads()
 		case key.Matches(msg, b.keymap.selectAll):
 			for _, item := range b.sourcesC.Items() {
 				item := item.(*listItem)
@@ -433,7 +436,8 @@ func (b *statefulBubble) updateMangas(msg tea.Msg) (tea.Model, tea.Cmd) {
 				break
 			}
 
-			m, _ := b.mangasC.SelectedItem().(*listItem).internal.(*source.Manga)
+			// the most complete source is used for the titles found in several sources, unless another one is chosen
+			m := b.mangasC.SelectedItem().(*listItem).groupEntry().Manga
 			b.selectedManga = m
 			go query.Remember(m.Name, 2)
 			return b, tea.Batch(b.getChapters(m), b.waitForChapters(), b.startLoading())
@@ -442,37 +446,170 @@ func (b *statefulBubble) updateMangas(msg tea.Msg) (tea.Model, tea.Cmd) {
 				break
 			}
 
-			m, _ := b.mangasC.SelectedItem().(*listItem).internal.(*source.Manga)
+			m := b.mangasC.SelectedItem().(*listItem).groupEntry().Manga
 			err := open.Start(m.URL)
 			if err != nil {
 				b.raiseError(err)
 			}
+		case key.Matches(msg, b.keymap.details):
+			if b.mangasC.SelectedItem() == nil {
+				break
+			}
+
+			b.selectedManga = b.mangasC.SelectedItem().(*listItem).groupEntry().Manga
+			return b, b.showDetails()
+		case key.Matches(msg, b.keymap.switchSource):
+			if b.mangasC.SelectedItem() == nil {
+				break
+			}
+
+			b.mangasC.SelectedItem().(*listItem).switchEntry()
+			return b, nil
+		case key.Matches(msg, b.keymap.downloads):
+			return b, b.showDownloads()
 		}
 	case []*source.Chapter:
-		items := make([]list.Item, len(msg))
+		return b, b.showChapters(msg)
+	}
 
-		if viper.GetBool(key2.TUIReverseChapters) {
-			for i, c := range msg {
-				items[len(msg)-i-1] = &listItem{internal: c}
-			}
-		} else {
-			for i, c := range msg {
-				items[i] = &listItem{internal: c}
-			}
+	b.mangasC, cmd = b.mangasC.Update(msg)
+	return b, cmd
+}
+
+// chapterItems returns the sorted items of the chapters list with the read and new badges.
+// Chapters of the selected manga are remembered to find the new ones on the next visit
+func (b *statefulBubble) chapterItems(chapters []*source.Chapter) []list.Item {
+	progress, err := history.ProgressOf(b.selectedManga)
+	if err != nil {
+		log.Warn(err)
+		progress = &history.Progress{}
+	}
+
+	b.readingProgress = progress
+
+	fresh, err := history.RecordVisit(b.selectedManga, chapters)
+	if err != nil {
+		log.Warn(err)
+	}
+
+	items := make([]list.Item, len(chapters))
+	for i, c := range chapters {
+		items[i] = &listItem{
+			internal: c,
+			read:     progress.IsRead(c),
+			new:      lo.Contains(fresh, c),
 		}
+	}
 
-		cmd = b.chaptersC.SetItems(items)
-		b.newState(chaptersState)
-		b.stopLoading()
+	sortChapterItems(items, b.chaptersSort, viper.GetBool(key2.TUIReverseChapters))
+	return items
+}
 
-		if viper.GetBool(key2.AnilistLinkOnMangaSelect) {
-			return b, tea.Batch(cmd, b.fetchAndSetAnilist(b.selectedManga), b.waitForAnilistFetchAndSet())
+// markChapters adds the chapters of the items that satisfy the predicate to the selection
+func (b *statefulBubble) markChapters(predicate func(item *listItem) bool) tea.Cmd {
+	var count int
+	for _, item := range b.chaptersC.Items() {
+		item := item.(*listItem)
+		if !predicate(item) {
+			continue
 		}
 
-		return b, cmd
+		item.marked = true
+		b.selectedChapters[item.internal.(*source.Chapter)] = struct{}{}
+		count++
 	}
 
-	b.mangasC, cmd = b.mangasC.Update(msg)
+	return b.chaptersC.NewStatusMessage(fmt.Sprintf("%s selected", util.Quantify(count, "chapter", "chapters")))
+}
+
+// showChapters sets the chapters of the selected manga and switches to the chapters list
+func (b *statefulBubble) showChapters(chapters []*source.Chapter) tea.Cmd {
+	cmd := b.chaptersC.SetItems(b.chapterItems(chapters))
+	b.newState(chaptersState)
+	b.stopLoading()
+
+	if viper.GetBool(key2.AnilistLinkOnMangaSelect) {
+		return tea.Batch(cmd, b.fetchAndSetAnilist(b.selectedManga), b.waitForAnilistFetchAndSet())
+	}
+
+	return cmd
+}
+
+// showDetails switches to the details of the selected manga, fetching them if needed
+func (b *statefulBubble) showDetails() tea.Cmd {
+	b.newState(mangaDetailsState)
+
+	if b.details != nil && b.details.manga == b.selectedManga {
+		return nil
+	}
+
+	b.details = nil
+	return tea.Batch(b.fetchDetails(b.selectedManga), b.spinnerC.Tick)
+}
+
+func (b *statefulBubble) updateDetails(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case *mangaDetails:
+		// details of the manga that is not selected anymore
+		if msg.manga == b.selectedManga {
+			b.details = msg
+		}
+
+		return b, nil
+	case *followToggled:
+		if b.details != nil && b.details.manga == msg.manga {
+			b.details.following = mo.Some(msg.following)
+			b.details.status = "Unfollowed"
+			if msg.following {
+				b.details.status = "Followed"
+			}
+		}
+
+		return b, nil
+	case []*source.Chapter:
+		return b, b.showChapters(msg)
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, b.keymap.quit):
+			return b, tea.Quit
+		case key.Matches(msg, b.keymap.confirm):
+			go query.Remember(b.selectedManga.Name, 2)
+			return b, tea.Batch(b.getChapters(b.selectedManga), b.waitForChapters(), b.startLoading())
+		case key.Matches(msg, b.keymap.openURL):
+			if err := open.Start(b.selectedManga.URL); err != nil {
+				b.raiseError(err)
+			}
+		case key.Matches(msg, b.keymap.downloads):
+			return b, b.showDownloads()
+		case b.details == nil:
+			break
+		case key.Matches(msg, b.keymap.anilistSelect):
+			b.newState(loadingState)
+			return b, tea.Batch(b.startLoading(), b.fetchAnilist(b.selectedManga), b.waitForAnilist())
+		case key.Matches(msg, b.keymap.openAnilist):
+			anilistManga, ok := b.selectedManga.Anilist.Get()
+			if !ok {
+				b.details.status = "Not bound with Anilist"
+				break
+			}
+
+			if err := open.Start(anilistManga.SiteURL); err != nil {
+				b.raiseError(err)
+			}
+		case key.Matches(msg, b.keymap.follow):
+			following, ok := b.details.following.Get()
+			if !ok {
+				b.details.status = "Following is available for MangaDex manga after \"mangal mangadex login\""
+				break
+			}
+
+			return b, b.toggleFollow(b.selectedManga, following)
+		}
+	}
+
+	b.spinnerC, cmd = b.spinnerC.Update(msg)
 	return b, cmd
 }
 
@@ -500,6 +637,10 @@ func (b *statefulBubble) updateChapters(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, b.keymap.anilistSelect):
 			b.newState(loadingState)
 			return b, tea.Batch(b.startLoading(), b.fetchAnilist(b.selectedManga), b.waitForAnilist())
+		case key.Matches(msg, b.keymap.details):
+			return b, b.showDetails()
+		case key.Matches(msg, b.keymap.downloads):
+			return b, b.showDownloads()
 		case key.Matches(msg, b.keymap.selectVolume):
 			if b.chaptersC.SelectedItem() == nil {
 				break
@@ -520,6 +661,37 @@ func (b *statefulBubble) updateChapters(msg tea.Msg) (tea.Model, tea.Cmd) {
 					item.marked = true
 				}
 			}
+		case key.Matches(msg, b.keymap.selectUnread):
+			return b, b.markChapters(func(item *listItem) bool {
+				return !item.read
+			})
+		case key.Matches(msg, b.keymap.selectNotDownloaded):
+			return b, b.markChapters(func(item *listItem) bool {
+				return !item.internal.(*source.Chapter).IsDownloaded()
+			})
+		case key.Matches(msg, b.keymap.selectFromLastRead):
+			chapters := lo.Map(b.chaptersC.Items(), func(item list.Item, _ int) *source.Chapter {
+				return item.(*listItem).internal.(*source.Chapter)
+			})
+
+			// reading order does not depend on the order of the list
+			slices.SortFunc(chapters, func(a, b *source.Chapter) int {
+				return int(a.Index) - int(b.Index)
+			})
+
+			fromLastRead := b.readingProgress.FromLastRead(chapters)
+			return b, b.markChapters(func(item *listItem) bool {
+				return lo.Contains(fromLastRead, item.internal.(*source.Chapter))
+			})
+		case key.Matches(msg, b.keymap.sortChapters):
+			b.chaptersSort = b.chaptersSort.next()
+
+			items := b.chaptersC.Items()
+			sortChapterItems(items, b.chaptersSort, viper.GetBool(key2.TUIReverseChapters))
+			return b, tea.Batch(
+				b.chaptersC.SetItems(items),
+				b.chaptersC.NewStatusMessage("Sorted by "+string(b.chaptersSort)),
+			)
 		case key.Matches(msg, b.keymap.selectOne):
 			if b.chaptersC.SelectedItem() == nil {
 				break
@@ -615,7 +787,15 @@ func (b *statefulBubble) updateAnilistSelect(msg tea.Msg) (tea.Model, tea.Cmd) {
 				break
 			}
 
+			b.selectedManga.BindTo(manga)
 			b.previousState()
+
+			// metadata of the details depends on the binding
+			if b.state == mangaDetailsState {
+				b.details = nil
+				return b, tea.Batch(b.fetchDetails(b.selectedManga), b.spinnerC.Tick)
+			}
+
 			cmd = b.chaptersC.NewStatusMessage(fmt.Sprintf(`Linked to %s %s`, style.Fg(color.Orange)(manga.Name()), style.Faint(manga.SiteURL)))
 			return b, cmd
 		}
@@ -656,11 +836,16 @@ func (b *statefulBubble) updateConfirm(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return 0
 			})
 
-			for _, chapter := range chapters {
-				b.chaptersToDownload.Push(chapter)
+			b.downloads.Add(chapters...)
+
+			// selection is cleared so that other chapters can be queued
+			b.selectedChapters = make(map[*source.Chapter]struct{})
+			for _, item := range b.chaptersC.Items() {
+				item.(*listItem).marked = false
 			}
-			b.newState(downloadState)
-			return b, tea.Batch(b.startLoading(), b.downloadChapter(b.chaptersToDownload.Pop()), b.waitForChapterDownload(), b.progressC.SetPercent(0))
+
+			b.newState(downloadsState)
+			return b, nil
 		case key.Matches(msg, b.keymap.back):
 			b.previousState()
 		}
@@ -682,69 +867,74 @@ func (b *statefulBubble) updateRead(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return b, cmd
 }
 
-func (b *statefulBubble) updateDownload(msg tea.Msg) (tea.Model, tea.Cmd) {
-	var cmd tea.Cmd
-
-	switch msg := msg.(type) {
-	case struct{}:
-		inc := 1 / float64(len(b.selectedChapters))
-
-		if b.chaptersToDownload.Len() == 0 {
-			// a little hack to make the progress render to the end
-			go func() {
-				time.Sleep(time.Millisecond * 400)
-				b.newState(downloadDoneState)
-			}()
+// showDownloads switches to the list of the background downloads
+func (b *statefulBubble) showDownloads() tea.Cmd {
+	b.newState(downloadsState)
+	return nil
+}
 
-			return b, b.progressC.IncrPercent(inc)
-		}
+// clampDownloadsCursor keeps the cursor of the downloads view within the jobs
+func (b *statefulBubble) clampDownloadsCursor() {
+	b.downloadsCursor = util.Max(0, util.Min(b.downloadsCursor, len(b.downloads.Jobs())-1))
+}
 
-		return b, tea.Batch(b.progressC.IncrPercent(inc), b.downloadChapter(b.chaptersToDownload.Pop()), b.waitForChapterDownload())
-	case progress.FrameMsg:
-		model, cmd := b.progressC.Update(msg)
-		b.progressC = model.(progress.Model)
+// selectedJob returns the download job under the cursor
+func (b *statefulBubble) selectedJob() (*downloader.Job, bool) {
+	b.clampDownloadsCursor()
 
-		return b, cmd
+	jobs := b.downloads.Jobs()
+	if b.downloadsCursor >= len(jobs) {
+		return nil, false
 	}
 
-	b.spinnerC, cmd = b.spinnerC.Update(msg)
-	return b, cmd
+	return jobs[b.downloadsCursor], true
 }
 
-func (b *statefulBubble) updateDownloadDone(msg tea.Msg) (tea.Model, tea.Cmd) {
-	var cmd tea.Cmd
-
+func (b *statefulBubble) updateDownloads(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch {
 		case key.Matches(msg, b.keymap.quit):
 			return b, tea.Quit
-		case key.Matches(msg, b.keymap.openFolder):
-			err := open.StartWith(
-				lo.Must(b.currentDownloadingChapter.Manga.Path(false)),
-				viper.GetString(key2.ReaderFolder),
-			)
+		case key.Matches(msg, b.keymap.up):
+			b.downloadsCursor--
+		case key.Matches(msg, b.keymap.down):
+			b.downloadsCursor++
+		case key.Matches(msg, b.keymap.clearFinished):
+			b.downloads.ClearFinished()
+			b.clampDownloadsCursor()
+		}
+
+		job, ok := b.selectedJob()
+		if !ok {
+			break
+		}
 
+		switch {
+		case key.Matches(msg, b.keymap.pause):
+			if job.State().Status == downloader.JobPaused {
+				b.downloads.Resume(job)
+			} else {
+				b.downloads.Pause(job)
+			}
+		case key.Matches(msg, b.keymap.cancel):
+			b.downloads.Cancel(job)
+		case key.Matches(msg, b.keymap.retry):
+			b.downloads.Retry(job)
+		case key.Matches(msg, b.keymap.openFolder):
+			path, err := job.Chapter.Manga.Path(false)
 			if err != nil {
 				b.raiseError(err)
-			}
-		case key.Matches(msg, b.keymap.redownloadFailed):
-			if len(b.failedChapters) == 0 {
 				break
 			}
 
-			b.chaptersToDownload = util.Stack[*source.Chapter]{}
-			for _, chapter := range b.failedChapters {
-				b.chaptersToDownload.Push(chapter)
+			if err = open.StartWith(path, viper.GetString(key2.ReaderFolder)); err != nil {
+				b.raiseError(err)
 			}
-			b.failedChapters = make([]*source.Chapter, 0)
-			b.succededChapters = make([]*source.Chapter, 0)
-			b.newState(downloadState)
-			return b, tea.Batch(b.startLoading(), b.downloadChapter(b.chaptersToDownload.Pop()), b.waitForChapterDownload(), b.progressC.SetPercent(0))
 		}
 	}
 
-	return b, cmd
+	return b, nil
 }
 
 func (b *statefulBubble) updateError(msg tea.Msg) (tea.Model, tea.Cmd) {
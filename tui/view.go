@@ -4,14 +4,19 @@ import (
 	"fmt"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/metafates/mangal/color"
+	"github.com/metafates/mangal/downloader"
 	"github.com/metafates/mangal/icon"
 	"github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/style"
 	"github.com/metafates/mangal/source"
 	"github.com/metafates/mangal/util"
+	"github.com/muesli/reflow/wordwrap"
 	"github.com/muesli/reflow/wrap"
+	"github.com/samber/lo"
 	"github.com/spf13/viper"
+	"html"
 	"math/rand"
+	"regexp"
 	"strconv"
 	"strings"
 )
@@ -38,12 +43,12 @@ func (b *statefulBubble) View() string {
 		return b.viewConfirm()
 	case readState:
 		return b.viewRead()
-	case downloadState:
-		return b.viewDownload()
-	case downloadDoneState:
-		return b.viewDownloadDone()
+	case downloadsState:
+		return b.viewDownloads()
 	case errorState:
 		return b.viewError()
+	case mangaDetailsState:
+		return b.viewDetails()
 	}
 
 	panic("unknown state")
@@ -103,6 +108,104 @@ func (b *statefulBubble) viewAniList() string {
 	return listExtraPaddingStyle.Render(b.anilistC.View())
 }
 
+func (b *statefulBubble) viewDetails() string {
+	if b.details == nil {
+		return b.renderLines(
+			true,
+			[]string{
+				style.Title("Details"),
+				"",
+				b.spinnerC.View() + b.progressStatus,
+			},
+		)
+	}
+
+	manga := b.details.manga
+	metadata := manga.Metadata
+
+	width := b.width
+	if b.details.cover != "" {
+		width -= coverWidth + 2
+	}
+
+	field := func(name, value string) string {
+		return style.Bold(name+" ") + value
+	}
+
+	info := []string{style.Bold(manga.Name) + " " + style.Faint(manga.Source.Name())}
+	if len(metadata.Synonyms) > 0 {
+		info = append(info, style.Faint(strings.Join(metadata.Synonyms, ", ")))
+	}
+
+	facts := []string{metadata.Status, metadata.Format}
+	if metadata.Chapters > 0 {
+		facts = append(facts, util.Quantify(metadata.Chapters, "chapter", "chapters"))
+	}
+	if metadata.Volumes > 0 {
+		facts = append(facts, util.Quantify(metadata.Volumes, "volume", "volumes"))
+	}
+	if metadata.StartDate.Year > 0 {
+		facts = append(facts, strconv.Itoa(metadata.StartDate.Year))
+	}
+	if metadata.AverageScore > 0 {
+		facts = append(facts, fmt.Sprintf("%d%%", metadata.AverageScore))
+	}
+
+	info = append(info, "", strings.Join(lo.Compact(facts), " · "))
+
+	if len(metadata.Staff.Story) > 0 {
+		info = append(info, field("Story", strings.Join(metadata.Staff.Story, ", ")))
+	}
+	if len(metadata.Staff.Art) > 0 {
+		info = append(info, field("Art", strings.Join(metadata.Staff.Art, ", ")))
+	}
+	if len(metadata.Genres) > 0 {
+		info = append(info, field("Genres", style.Fg(color.Purple)(strings.Join(metadata.Genres, ", "))))
+	}
+	if len(metadata.Tags) > 0 {
+		info = append(info, field("Tags", style.Faint(strings.Join(lo.Slice(metadata.Tags, 0, 10), ", "))))
+	}
+
+	if anilistManga, ok := manga.Anilist.Get(); ok {
+		info = append(info, field("Anilist", style.Fg(color.Orange)(anilistManga.Name())+" "+style.Faint(anilistManga.SiteURL)))
+	} else {
+		info = append(info, field("Anilist", style.Faint("not bound")))
+	}
+
+	if following, ok := b.details.following.Get(); ok {
+		info = append(info, field("MangaDex", lo.Ternary(following, style.Fg(color.Green)("following"), style.Faint("not following"))))
+	}
+
+	if summary := plainText(metadata.Summary); summary != "" {
+		info = append(info, "", wordwrap.String(summary, width))
+	}
+
+	body := style.New().Width(width).Render(strings.Join(info, "\n"))
+	if b.details.cover != "" {
+		body = lipgloss.JoinHorizontal(lipgloss.Top, b.details.cover, "  ", body)
+	}
+
+	lines := append([]string{style.Title("Details"), ""}, strings.Split(body, "\n")...)
+
+	// status and help are always visible
+	status := b.details.status
+	if b.loading {
+		status = b.spinnerC.View() + "Fetching chapters"
+	}
+
+	lines = lo.Slice(lines, 0, util.Max(b.height-2, 1))
+	return b.renderLines(true, append(lines, "", status))
+}
+
+// htmlTag matches the tags of the summaries in html format
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// plainText converts the summary in html format to plain text
+func plainText(summary string) string {
+	summary = strings.NewReplacer("<br>", "\n", "<br/>", "\n", "<br />", "\n").Replace(summary)
+	return strings.TrimSpace(html.UnescapeString(htmlTag.ReplaceAllString(summary, "")))
+}
+
 func (b *statefulBubble) viewConfirm() string {
 	return b.renderLines(
 		true,
@@ -154,65 +257,94 @@ func (b *statefulBubble) viewRead() string {
 	)
 }
 
-func (b *statefulBubble) viewDownload() string {
-	var chapterName string
+// jobStatusStyles are the colors of the download job statuses
+var jobStatusStyles = map[downloader.JobStatus]func(string) string{
+	downloader.JobQueued:   style.Faint,
+	downloader.JobActive:   style.Fg(color.Purple),
+	downloader.JobPaused:   style.Fg(color.Yellow),
+	downloader.JobDone:     style.Fg(color.Green),
+	downloader.JobFailed:   style.Fg(color.Red),
+	downloader.JobCanceled: style.Faint,
+}
 
-	chapter := b.currentDownloadingChapter
-	if chapter != nil {
-		chapterName = chapter.Name
+// jobStatusOrder is the order of the statuses in the summary
+var jobStatusOrder = []downloader.JobStatus{
+	downloader.JobActive,
+	downloader.JobQueued,
+	downloader.JobPaused,
+	downloader.JobDone,
+	downloader.JobFailed,
+	downloader.JobCanceled,
+}
+
+func (b *statefulBubble) viewDownloads() string {
+	lines := []string{style.Title("Downloads"), ""}
+
+	jobs := b.downloads.Jobs()
+	if len(jobs) == 0 {
+		lines = append(lines, style.Faint("Nothing to download. Select chapters and press enter to queue them"))
+		return b.renderLines(true, lines)
 	}
 
-	lines := []string{
-		style.Title("Downloading"),
-		"",
-		truncatedText(fmt.Sprintf(icon.Get(icon.Progress)+" Downloading %s", style.Fg(color.Purple)(chapterName)), b.width),
-		"",
-		b.progressC.View(),
-		"",
-		truncatedText(b.spinnerC.View()+b.progressStatus, b.width),
-		"",
-		truncatedText(b.downloadingChapterMetainfo(), b.width),
+	states := make([]downloader.JobState, len(jobs))
+	counts := make(map[downloader.JobStatus]int)
+	for i, job := range jobs {
+		states[i] = job.State()
+		counts[states[i].Status]++
 	}
 
-	return b.renderLines(
-		true,
-		lines,
-	)
-}
+	var summary []string
+	for _, status := range jobStatusOrder {
+		if counts[status] > 0 {
+			summary = append(summary, fmt.Sprintf("%s %s", jobStatusStyles[status](strconv.Itoa(counts[status])), status))
+		}
+	}
 
-func (b *statefulBubble) viewDownloadDone() string {
-	failed := len(b.failedChapters)
-	succeded := len(b.succededChapters)
+	lines = append(lines, strings.Join(summary, ", "), "")
 
-	var msg string
+	// each job takes 3 lines, only the jobs around the cursor are shown.
+	// The cursor is kept within the jobs by the update, jobs may have changed since then though
+	selected := util.Min(b.downloadsCursor, len(jobs)-1)
+	visible := util.Max(1, (b.height-len(lines)-lipgloss.Height(b.helpC.View(b.keymap)))/3)
+	first := util.Max(0, util.Min(selected-visible/2, len(jobs)-visible))
+	last := util.Min(len(jobs), first+visible)
 
-	{
-		temp := strings.Split(util.Quantify(succeded, "chapter", "chapters"), " ")
-		temp[0] = style.Fg(color.Green)(temp[0])
-		s := strings.Join(temp, " ") + " downloaded"
-		f := fmt.Sprintf("%s failed", style.Fg(color.Red)(strconv.Itoa(failed)))
+	bar := b.progressC
+	bar.Width = util.Max(10, b.width/3)
 
-		msg = fmt.Sprintf("%s, %s", s, f)
-	}
+	for i := first; i < last; i++ {
+		job, state := jobs[i], states[i]
 
-	lines := []string{
-		style.Title("Finish"),
-		"",
-		msg,
-	}
+		cursor := "  "
+		if i == selected {
+			cursor = style.Fg(color.Purple)("▸ ")
+		}
+
+		title := fmt.Sprintf(
+			"%s%s %s %s",
+			cursor,
+			jobStatusStyles[state.Status]("["+string(state.Status)+"]"),
+			job.Chapter.Name,
+			style.Faint(job.Chapter.Manga.Name),
+		)
 
-	if succeded > 0 && viper.GetBool(key.TUIShowDownloadedPath) {
-		path, err := b.selectedManga.Path(false)
-		if err == nil {
-			lines = append(lines, "")
-			lines = append(lines, fmt.Sprintf("Downloaded to %s", style.Faint(path)))
+		message := state.Message
+		switch {
+		case state.Status == downloader.JobFailed:
+			message = style.Fg(color.Red)(message)
+		case state.Status == downloader.JobDone && viper.GetBool(key.TUIShowDownloadedPath):
+			message = "Downloaded to " + style.Faint(state.Path)
 		}
+
+		lines = append(
+			lines,
+			truncatedText(title, b.width),
+			truncatedText("  "+bar.ViewAs(state.Progress())+" "+message, b.width),
+			"",
+		)
 	}
 
-	return b.renderLines(
-		true,
-		lines,
-	)
+	return b.renderLines(true, lines)
 }
 
 func (b *statefulBubble) viewError() string {
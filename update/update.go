@@ -5,12 +5,14 @@ import (
 	"encoding/json"
 	"github.com/metafates/mangal/constant"
 	"github.com/metafates/mangal/converter/cbz"
+	"github.com/metafates/mangal/event"
 	"github.com/metafates/mangal/filesystem"
 	"github.com/metafates/mangal/log"
 	"github.com/metafates/mangal/source"
 	"github.com/metafates/mangal/util"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -46,8 +48,8 @@ func Metadata(mangaPath string) error {
 	}
 
 	// will set new metadata from anilist
-	err = manga.PopulateMetadata(func(s string) {
-		log.Info(s)
+	err = manga.PopulateMetadata(func(e *event.Event) {
+		log.Info(e.Message)
 	})
 	if err != nil {
 		log.Error(err)
@@ -79,7 +81,16 @@ func Metadata(mangaPath string) error {
 			Name:  comicInfo.Title,
 			Manga: manga,
 			URL:   comicInfo.Web,
-			Index: uint16(comicInfo.Number),
+		}
+
+		if number, err := strconv.ParseFloat(comicInfo.Number, 64); err == nil {
+			chap.Number = &number
+			chap.Index = uint16(number)
+		}
+
+		if comicInfo.Volume != 0 {
+			volume := float64(comicInfo.Volume)
+			chap.VolumeNumber = &volume
 		}
 		manga.Chapters = append(manga.Chapters, chap)
 		chaptersPaths[chap] = chapter.path
@@ -117,7 +128,7 @@ func Metadata(mangaPath string) error {
 			}
 		}
 	}
-	err = manga.DownloadCover(true, mangaPath, func(string) {})
+	err = manga.DownloadCover(true, mangaPath, event.Discard)
 	if err != nil {
 		log.Error(err)
 	}
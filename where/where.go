@@ -43,10 +43,26 @@ func Sources() string {
 	return mkdir(filepath.Join(Config(), "sources"))
 }
 
+// Cookies path to the file in Netscape format
+func Cookies() string {
+	return filepath.Join(Config(), "cookies.txt")
+}
+
+// Themes path to the directory with the theme files
+// Will create the directory if it doesn't exist
+func Themes() string {
+	return mkdir(filepath.Join(Config(), "themes"))
+}
+
 func AnilistBinds() string {
 	return filepath.Join(Config(), "anilist.json")
 }
 
+// MangadexSession path to the file with the mangadex client credentials and refresh token
+func MangadexSession() string {
+	return filepath.Join(Config(), "mangadex.json")
+}
+
 // Logs path
 // Will create the directory if it doesn't exist
 func Logs() string {
@@ -65,6 +81,11 @@ func History() string {
 	return filepath.Join(Config(), "history.json")
 }
 
+// Visits path to the file with the chapters seen on the last visit of each manga
+func Visits() string {
+	return filepath.Join(Config(), "visits.json")
+}
+
 // Downloads path
 // Will create the directory if it doesn't exist
 func Downloads() string {
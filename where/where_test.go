@@ -55,3 +55,18 @@ func TestLogs(t *testing.T) {
 		})
 	})
 }
+
+func TestThemes(t *testing.T) {
+	Convey("When gettings themes path", t, func() {
+		path := Themes()
+		Convey("It should exist", func() {
+			exists := lo.Must(filesystem.Api().Exists(path))
+			So(exists, ShouldBeTrue)
+
+			Convey("And it should be a directory", func() {
+				isDir := lo.Must(filesystem.Api().IsDir(path))
+				So(isDir, ShouldBeTrue)
+			})
+		})
+	})
+}